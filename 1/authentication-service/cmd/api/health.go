@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// healthCheckTimeout bounds each dependency check
+	healthCheckTimeout = 2 * time.Second
+	// healthCheckInterval is how often dependencies are checked
+	healthCheckInterval = 10 * time.Second
+	// drainDelay keeps the server answering, with readiness failing, so k8s stops routing to it before it stops
+	drainDelay = 5 * time.Second
+	// livenessService stays SERVING while the server runs, probing it tells a hung process from an unready one
+	livenessService = "liveness"
+)
+
+type healthCheck func(ctx context.Context) error
+
+// watchHealth sets the overall status of the grpc.health.v1 service and that of services to SERVING while every
+// check passes, and to NOT_SERVING otherwise, until ctx is done.
+func watchHealth(ctx context.Context, healthServer *health.Server, services []string, checks map[string]healthCheck) {
+	healthServer.SetServingStatus(livenessService, healthpb.HealthCheckResponse_SERVING)
+
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		for name, check := range checks {
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			err := check(checkCtx)
+			cancel()
+			if err != nil {
+				log.Warn().Err(err).Str("check", name).Msg("health check failed")
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+
+		healthServer.SetServingStatus("", status)
+		for _, service := range services {
+			healthServer.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkRabbit opens and closes a channel, which fails once the connection is lost.
+func checkRabbit(conn *amqp.Connection) healthCheck {
+	return func(ctx context.Context) error {
+		if conn == nil || conn.IsClosed() {
+			return errors.New("rabbitmq connection is closed")
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			ch, err := conn.Channel()
+			if err == nil {
+				err = ch.Close()
+			}
+			errCh <- err
+		}()
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -16,7 +16,10 @@ import (
 	"github.com/steve-mir/bukka_backend/authentication/data"
 	"github.com/steve-mir/bukka_backend/authentication/gapi"
 	"github.com/steve-mir/bukka_backend/authentication/pb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	_ "github.com/jackc/pgconn"
@@ -41,6 +44,18 @@ type Config struct {
 func main() {
 	log.Info().Msg("Starting authentication service")
 
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot set up tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
+	}()
+
 	// connect to DB
 	conn := connectToDB()
 	if conn == nil {
@@ -145,8 +160,8 @@ func connect() (*amqp.Connection, error) {
 }
 
 func newGRPCServer() *grpc.Server {
-
-	return grpc.NewServer()
+	// Spans of the listener's calls continue here
+	return grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 }
 
 func runGrpcServer(db *sql.DB, rabbitConn *amqp.Connection) {
@@ -172,6 +187,14 @@ func runGrpcServer(db *sql.DB, rabbitConn *amqp.Connection) {
 
 	pb.RegisterUserAuthServer(grpcServer, server)
 
+	// k8s probes the overall status for readiness and livenessService for liveness
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go watchHealth(ctx, healthServer, []string{pb.UserAuth_ServiceDesc.ServiceName}, map[string]healthCheck{
+		"postgres": db.PingContext,
+		"rabbitmq": checkRabbit(rabbitConn),
+	})
+
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", gRPCPort)
@@ -192,6 +215,11 @@ func runGrpcServer(db *sql.DB, rabbitConn *amqp.Connection) {
 	// Wait for the context to be canceled (either by the termination signal or an error)
 	<-ctx.Done()
 
+	// Fail readiness first so k8s stops routing here before the server stops
+	healthServer.Shutdown()
+	log.Info().Msgf("draining for %s before shutting down", drainDelay)
+	time.Sleep(drainDelay)
+
 	// Stop the gRPC server
 	grpcServer.GracefulStop()
 
@@ -57,7 +57,7 @@ func (app *Config) handleRequest(ctx *gin.Context, strategy strategies.ServiceSt
 	}
 	defer responseQueue.Close()
 
-	if err := strategy.Process(payload, correlationID, queueName); err != nil {
+	if err := strategy.Process(ctx.Request.Context(), payload, correlationID, queueName); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// healthCheckTimeout bounds each readiness check
+	healthCheckTimeout = 2 * time.Second
+	// drainDelay keeps the server answering, with readiness failing, so k8s stops routing to it before it stops
+	drainDelay = 5 * time.Second
+)
+
+// Healthz answers as long as the server is up.
+func (app *Config) Healthz() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"status": "up"})
+	}
+}
+
+// Readyz checks RabbitMQ and fails once the server is shutting down.
+func (app *Config) Readyz() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), healthCheckTimeout)
+		defer cancel()
+
+		status, rabbit := "up", "up"
+		if err := checkRabbit(checkCtx, app.Rabbit); err != nil {
+			status, rabbit = "down", "down"
+		}
+		if app.draining.Load() {
+			status = "draining"
+		}
+
+		code := http.StatusOK
+		if status != "up" {
+			code = http.StatusServiceUnavailable
+		}
+		ctx.JSON(code, gin.H{"status": status, "checks": gin.H{"rabbitmq": gin.H{"status": rabbit}}})
+	}
+}
+
+// checkRabbit opens and closes a channel, which fails once the connection is lost.
+func checkRabbit(ctx context.Context, conn *amqp.Connection) error {
+	if conn == nil || conn.IsClosed() {
+		return errors.New("rabbitmq connection is closed")
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		ch, err := conn.Channel()
+		if err == nil {
+			err = ch.Close()
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
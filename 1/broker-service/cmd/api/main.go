@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -20,9 +21,24 @@ const webPort = "80"
 type Config struct {
 	Rabbit          *amqp.Connection
 	StrategyFactory *strategies.StrategyFactory
+	// draining fails readiness once shutdown starts
+	draining atomic.Bool
 }
 
 func main() {
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
 	// try to connect to rabbitmq
 	rabbitConn, err := connect()
 	if err != nil {
@@ -31,7 +47,7 @@ func main() {
 	}
 	defer rabbitConn.Close()
 
-	app := Config{
+	app := &Config{
 		Rabbit:          rabbitConn,
 		StrategyFactory: strategies.NewStrategyFactory(rabbitConn),
 	}
@@ -46,7 +62,7 @@ func main() {
 
 	// start the server
 	// server := setupGinServer(store, db, config, taskDistributor)
-	startGinServer(srv, webPort)
+	startGinServer(srv, webPort, app.draining.Store)
 	// err = srv.ListenAndServe()
 	// if err != nil {
 	// 	log.Panic(err)
@@ -64,7 +80,9 @@ func main() {
 // 	}
 // }
 
-func startGinServer(server *http.Server, address string) {
+// startGinServer serves until SIGINT or SIGTERM, then calls setDraining(true) and keeps serving for drainDelay
+// before shutting down.
+func startGinServer(server *http.Server, address string, setDraining func(bool)) {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -76,6 +94,10 @@ func startGinServer(server *http.Server, address string) {
 
 	<-ctx.Done()
 
+	setDraining(true)
+	log.Printf("draining for %s before shutting down", drainDelay)
+	time.Sleep(drainDelay)
+
 	log.Printf("shutting down gracefully, press Ctrl+C again to force")
 
 	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
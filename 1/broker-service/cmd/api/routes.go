@@ -4,10 +4,12 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func (app *Config) routes() http.Handler {
 	router := gin.Default()
+	router.Use(otelgin.Middleware(serviceName, otelgin.WithFilter(notProbe)))
 
 	// specify who is allowed to connect
 	// router.Use(cors.Handler(cors.Options{
@@ -21,6 +23,9 @@ func (app *Config) routes() http.Handler {
 
 	// mux.Use(middleware.Heartbeat("/ping"))
 
+	router.GET("/healthz", app.Healthz())
+	router.GET("/readyz", app.Readyz())
+
 	router.Handle("POST", "/", app.Broker())
 
 	// router.Handle("POST", "/log-grpc", app.LogViaGRPC)
@@ -29,3 +34,8 @@ func (app *Config) routes() http.Handler {
 
 	return router
 }
+
+// notProbe keeps health probes out of the traces.
+func notProbe(r *http.Request) bool {
+	return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+}
@@ -1,14 +1,18 @@
 package strategies
 
-import amqp "github.com/rabbitmq/amqp091-go"
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
 
 // Concrete strategies
 type AuthStrategy struct {
 	rabbitMQ *amqp.Connection
 }
 
-func (s *AuthStrategy) Process(payload []byte, correlationID, replyTo string) error {
-	return pushToQueue(s.rabbitMQ, s.QueueName(), payload, correlationID, replyTo)
+func (s *AuthStrategy) Process(ctx context.Context, payload []byte, correlationID, replyTo string) error {
+	return pushToQueue(ctx, s.rabbitMQ, s.QueueName(), payload, correlationID, replyTo)
 }
 
 func (s *AuthStrategy) QueueName() string {
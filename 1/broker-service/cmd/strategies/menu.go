@@ -1,13 +1,17 @@
 package strategies
 
-import amqp "github.com/rabbitmq/amqp091-go"
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
 
 type MenuStrategy struct {
 	rabbitMQ *amqp.Connection
 }
 
-func (s *MenuStrategy) Process(payload []byte, correlationID, replyTo string) error {
-	return pushToQueue(s.rabbitMQ, s.QueueName(), payload, correlationID, replyTo)
+func (s *MenuStrategy) Process(ctx context.Context, payload []byte, correlationID, replyTo string) error {
+	return pushToQueue(ctx, s.rabbitMQ, s.QueueName(), payload, correlationID, replyTo)
 }
 
 func (s *MenuStrategy) QueueName() string {
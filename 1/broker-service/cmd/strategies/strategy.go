@@ -1,9 +1,14 @@
 package strategies
 
 import (
+	"context"
 	"fmt"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Strategy pattern along with the Factory pattern to make the broker even more flexible and extensible while maintaining optimal performance.
@@ -11,7 +16,7 @@ import (
 
 // ServiceStrategy interface
 type ServiceStrategy interface {
-	Process(payload []byte, correlationID, replyTo string) error
+	Process(ctx context.Context, payload []byte, correlationID, replyTo string) error
 	QueueName() string
 }
 
@@ -38,7 +43,28 @@ func (f *StrategyFactory) GetStrategy(action string) (ServiceStrategy, error) {
 	return strategy, nil
 }
 
-func pushToQueue(rabbitMQ *amqp.Connection, queueName string, payload []byte, correlationID, replyTo string) error {
+func pushToQueue(ctx context.Context, rabbitMQ *amqp.Connection, queueName string, payload []byte, correlationID, replyTo string) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "publish "+queueName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(queueName),
+			semconv.MessagingMessageConversationID(correlationID),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	// The listener continues the trace from these headers
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
 	ch, err := rabbitMQ.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel: %w", err)
@@ -67,6 +93,7 @@ func pushToQueue(rabbitMQ *amqp.Connection, queueName string, payload []byte, co
 			ContentType:   "application/json",
 			CorrelationId: correlationID,
 			ReplyTo:       replyTo,
+			Headers:       headers,
 			Body:          payload,
 		})
 	if err != nil {
@@ -2,4 +2,4 @@ module github.com/steve-mir/bukka_backend/listener
 
 go 1.20
 
-require github.com/rabbitmq/amqp091-go v1.3.4 // indirect
+require github.com/rabbitmq/amqp091-go v1.3.4
@@ -1,12 +1,17 @@
 package event
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/steve-mir/bukka_backend/listener/factory"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Consumer represents a RabbitMQ consumer
@@ -94,16 +99,33 @@ func (c *Consumer) handleMessages(messages <-chan amqp.Delivery, factory factory
 }
 
 func (c *Consumer) processMessage(d amqp.Delivery, factory factory.CommandFactory) {
+	// Continue the trace the broker started, the gRPC call made by the command joins it too
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(d.Headers))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "process "+d.RoutingKey,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationDeliver,
+			semconv.MessagingDestinationName(d.RoutingKey),
+			semconv.MessagingMessageConversationID(d.CorrelationId),
+		),
+	)
+	defer span.End()
+
 	command, err := factory.CreateCommand(d.Body)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Printf("Error creating command: %v", err)
 		c.sendErrorResponse(err, d.ReplyTo, d.CorrelationId)
 		c.channel.Nack(d.DeliveryTag, false, false)
 		return
 	}
 
-	response, err := command.Execute()
+	response, err := command.Execute(ctx)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Printf("Error executing command: %v", err)
 		c.sendErrorResponse(err, d.ReplyTo, d.CorrelationId)
 		c.channel.Nack(d.DeliveryTag, false, false)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/steve-mir/bukka_backend/listener/pb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -24,8 +25,8 @@ type AuthCommand struct {
 	client  pb.UserAuthClient
 }
 
-func (c AuthCommand) Execute() ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (c AuthCommand) Execute(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var response interface{}
@@ -62,7 +63,11 @@ func NewAuthCommandFactory() *AuthCommandFactory {
 
 func (f *AuthCommandFactory) initClient() {
 	f.once.Do(func() {
-		conn, err := grpc.Dial("authentication-service:5001", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+		conn, err := grpc.Dial("authentication-service:5001",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithBlock(),
+		)
 		if err != nil {
 			f.clientErr = fmt.Errorf("failed to connect to gRPC server: %w", err)
 			return
@@ -2,6 +2,7 @@ package factory
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,7 +14,7 @@ import (
 
 // Command interface
 type Command interface {
-	Execute() ([]byte, error)
+	Execute(ctx context.Context) ([]byte, error)
 }
 
 // CommandFactory interface
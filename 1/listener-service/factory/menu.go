@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/steve-mir/bukka_backend/listener/pb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -22,9 +23,9 @@ type MenuCommand struct {
 	client  pb.MenuClient
 }
 
-func (c MenuCommand) Execute() ([]byte, error) {
+func (c MenuCommand) Execute(ctx context.Context) ([]byte, error) {
 	// Implement gRPC execution here
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var response interface{}
@@ -58,7 +59,11 @@ func NewMenuCommandFactory() *MenuCommandFactory {
 
 func (f *MenuCommandFactory) initClient() {
 	f.once.Do(func() {
-		conn, err := grpc.Dial("menu-service:5001", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+		conn, err := grpc.Dial("menu-service:5001",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithBlock(),
+		)
 		if err != nil {
 			f.clientErr = fmt.Errorf("failed to connect to gRPC server: %w", err)
 			return
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -13,6 +14,19 @@ import (
 )
 
 func main() {
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
 	// try to connect to rabbitmq
 	rabbitConn, err := connect()
 	if err != nil {
@@ -15,7 +15,10 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/steve-mir/bukka_backend/menu/gapi"
 	"github.com/steve-mir/bukka_backend/menu/pb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -33,6 +36,18 @@ type Config struct {
 func main() {
 	log.Info().Msg("Starting menu service")
 
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot set up tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
+	}()
+
 	// connect to DB
 	// conn := connectToDB()
 	// if conn == nil {
@@ -136,7 +151,8 @@ func connect() (*amqp.Connection, error) {
 }
 
 func newGRPCServer() *grpc.Server {
-	return grpc.NewServer()
+	// Spans of the listener's calls continue here
+	return grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 }
 
 func runGrpcServer(rabbitConn *amqp.Connection) {
@@ -162,6 +178,13 @@ func runGrpcServer(rabbitConn *amqp.Connection) {
 
 	pb.RegisterMenuServer(grpcServer, server)
 
+	// k8s probes the overall status for readiness and livenessService for liveness
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go watchHealth(ctx, healthServer, []string{pb.Menu_ServiceDesc.ServiceName}, map[string]healthCheck{
+		"rabbitmq": checkRabbit(rabbitConn),
+	})
+
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", gRPCPort)
@@ -182,6 +205,11 @@ func runGrpcServer(rabbitConn *amqp.Connection) {
 	// Wait for the context to be canceled (either by the termination signal or an error)
 	<-ctx.Done()
 
+	// Fail readiness first so k8s stops routing here before the server stops
+	healthServer.Shutdown()
+	log.Info().Msgf("draining for %s before shutting down", drainDelay)
+	time.Sleep(drainDelay)
+
 	// Stop the gRPC server
 	grpcServer.GracefulStop()
 
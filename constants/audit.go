@@ -0,0 +1,22 @@
+package constants
+
+// Audit log actions
+const (
+	AuditDataExportRequested    = "data_export.requested"
+	AuditDataExportCompleted    = "data_export.completed"
+	AuditDataExportFailed       = "data_export.failed"
+	AuditDataExportDownloaded   = "data_export.downloaded"
+	AuditImpersonationStarted   = "impersonation.started"
+	AuditImpersonationEnded     = "impersonation.ended"
+	AuditAPIKeyCreated          = "api_key.created"
+	AuditAPIKeyRotated          = "api_key.rotated"
+	AuditAPIKeyRevoked          = "api_key.revoked"
+	AuditServiceAccountCreated  = "service_account.created"
+	AuditServiceAccountDisabled = "service_account.disabled"
+	AuditOAuthClientCreated     = "oauth_client.created"
+	AuditOAuthConsentGranted    = "oauth_consent.granted"
+	AuditOAuthConsentRevoked    = "oauth_consent.revoked"
+	AuditFeatureFlagCreated     = "feature_flag.created"
+	AuditFeatureFlagUpdated     = "feature_flag.updated"
+	AuditFeatureFlagDeleted     = "feature_flag.deleted"
+)
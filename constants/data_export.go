@@ -0,0 +1,9 @@
+package constants
+
+// Statuses of data export requests
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportCompleted  = "completed"
+	DataExportFailed     = "failed"
+)
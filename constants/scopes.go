@@ -0,0 +1,42 @@
+package constants
+
+// API key scopes
+const (
+	ScopeProfileRead = "profile:read"
+	ScopeCartRead    = "cart:read"
+	ScopeCartWrite   = "cart:write"
+)
+
+var APIKeyScopes = []string{
+	ScopeProfileRead,
+	ScopeCartRead,
+	ScopeCartWrite,
+}
+
+// OpenID Connect scopes
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeOfflineAccess = "offline_access"
+	ScopeRoles         = "roles"
+)
+
+// RoleScopes are the scopes users of each role can grant to oauth clients, guests can't grant any
+var RoleScopes = map[int8][]string{
+	SuperAdmin:   {ScopeOpenID, ScopeProfile, ScopeEmail, ScopeOfflineAccess, ScopeRoles, ScopeProfileRead, ScopeCartRead, ScopeCartWrite},
+	AppAdmin:     {ScopeOpenID, ScopeProfile, ScopeEmail, ScopeOfflineAccess, ScopeRoles, ScopeProfileRead, ScopeCartRead, ScopeCartWrite},
+	RegularUsers: {ScopeOpenID, ScopeProfile, ScopeEmail, ScopeOfflineAccess, ScopeRoles, ScopeProfileRead, ScopeCartRead, ScopeCartWrite},
+}
+
+// OAuthScopes are all the scopes oauth clients can request
+var OAuthScopes = []string{
+	ScopeOpenID,
+	ScopeProfile,
+	ScopeEmail,
+	ScopeOfflineAccess,
+	ScopeRoles,
+	ScopeProfileRead,
+	ScopeCartRead,
+	ScopeCartWrite,
+}
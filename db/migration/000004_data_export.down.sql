@@ -0,0 +1,10 @@
+ALTER TABLE "audit_logs" DROP CONSTRAINT IF EXISTS "audit_logs_user_id_fkey";
+ALTER TABLE "data_export_requests" DROP CONSTRAINT IF EXISTS "data_export_requests_user_id_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "audit_logs_user_id_action_created_at_idx";
+DROP INDEX IF EXISTS "data_export_requests_user_id_created_at_idx";
+
+-- Drop tables
+DROP TABLE IF EXISTS "data_export_requests";
+DROP TABLE IF EXISTS "audit_logs";
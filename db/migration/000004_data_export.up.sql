@@ -0,0 +1,30 @@
+CREATE TABLE "audit_logs" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" uuid,
+  "actor_id" uuid,
+  "action" varchar NOT NULL,
+  "metadata" jsonb,
+  "ip_address" inet,
+  "user_agent" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "data_export_requests" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "user_id" uuid NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "storage_key" varchar,
+  "download_token" varchar UNIQUE,
+  "error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
+  "expires_at" timestamptz,
+  "downloaded_at" timestamptz
+);
+
+CREATE INDEX ON "audit_logs" ("user_id", "action", "created_at");
+CREATE INDEX ON "data_export_requests" ("user_id", "created_at");
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
+
+ALTER TABLE "data_export_requests" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
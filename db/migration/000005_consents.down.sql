@@ -0,0 +1,12 @@
+ALTER TABLE "user_consents" DROP CONSTRAINT IF EXISTS "user_consents_document_id_fkey";
+ALTER TABLE "user_consents" DROP CONSTRAINT IF EXISTS "user_consents_user_id_fkey";
+ALTER TABLE "policy_documents" DROP CONSTRAINT IF EXISTS "policy_documents_created_by_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "user_consents_user_id_document_id_idx";
+DROP INDEX IF EXISTS "policy_documents_kind_published_at_idx";
+DROP INDEX IF EXISTS "policy_documents_kind_version_idx";
+
+-- Drop tables
+DROP TABLE IF EXISTS "user_consents";
+DROP TABLE IF EXISTS "policy_documents";
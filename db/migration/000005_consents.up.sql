@@ -0,0 +1,29 @@
+CREATE TABLE "policy_documents" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "version" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "summary" varchar,
+  "published_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_by" uuid,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "user_consents" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "document_id" int NOT NULL,
+  "accepted_at" timestamptz NOT NULL DEFAULT (now()),
+  "ip_address" inet,
+  "user_agent" varchar
+);
+
+CREATE UNIQUE INDEX ON "policy_documents" ("kind", "version");
+CREATE INDEX ON "policy_documents" ("kind", "published_at");
+CREATE UNIQUE INDEX ON "user_consents" ("user_id", "document_id");
+
+ALTER TABLE "policy_documents" ADD FOREIGN KEY ("created_by") REFERENCES "authentications" ("id");
+
+ALTER TABLE "user_consents" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
+
+ALTER TABLE "user_consents" ADD FOREIGN KEY ("document_id") REFERENCES "policy_documents" ("id");
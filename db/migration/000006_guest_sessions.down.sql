@@ -0,0 +1,9 @@
+ALTER TABLE "guest_sessions" DROP CONSTRAINT IF EXISTS "guest_sessions_upgraded_to_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "cart_items_owner_id_item_id_idx";
+DROP INDEX IF EXISTS "guest_sessions_refresh_token_exp_retired_at_idx";
+
+-- Drop tables
+DROP TABLE IF EXISTS "cart_items";
+DROP TABLE IF EXISTS "guest_sessions";
@@ -0,0 +1,29 @@
+CREATE TABLE "guest_sessions" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "refresh_token" varchar UNIQUE NOT NULL,
+  "refresh_token_exp" timestamptz NOT NULL,
+  "device_id" varchar,
+  "platform" varchar,
+  "user_agent" text NOT NULL,
+  "ip_address" inet NOT NULL,
+  "created_at" timestamptz DEFAULT (now()),
+  "last_active_at" timestamptz,
+  "retired_at" timestamptz,
+  "upgraded_to" uuid
+);
+
+-- owner_id is either a user id or a guest session id
+CREATE TABLE "cart_items" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "owner_id" uuid NOT NULL,
+  "item_id" varchar NOT NULL,
+  "quantity" int NOT NULL DEFAULT 1,
+  "notes" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "guest_sessions" ("refresh_token_exp", "retired_at");
+CREATE UNIQUE INDEX ON "cart_items" ("owner_id", "item_id");
+
+ALTER TABLE "guest_sessions" ADD FOREIGN KEY ("upgraded_to") REFERENCES "authentications" ("id");
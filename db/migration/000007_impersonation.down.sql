@@ -0,0 +1,10 @@
+ALTER TABLE "impersonation_sessions" DROP CONSTRAINT IF EXISTS "impersonation_sessions_impersonator_id_fkey";
+ALTER TABLE "impersonation_sessions" DROP CONSTRAINT IF EXISTS "impersonation_sessions_target_user_id_fkey";
+ALTER TABLE "impersonation_sessions" DROP CONSTRAINT IF EXISTS "impersonation_sessions_ended_by_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "impersonation_sessions_impersonator_id_idx";
+DROP INDEX IF EXISTS "impersonation_sessions_target_user_id_idx";
+
+-- Drop tables
+DROP TABLE IF EXISTS "impersonation_sessions";
@@ -0,0 +1,19 @@
+CREATE TABLE "impersonation_sessions" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "impersonator_id" uuid NOT NULL,
+  "target_user_id" uuid NOT NULL,
+  "reason" text NOT NULL,
+  "ip_address" inet,
+  "user_agent" text,
+  "started_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "ended_at" timestamptz,
+  "ended_by" uuid
+);
+
+CREATE INDEX ON "impersonation_sessions" ("impersonator_id");
+CREATE INDEX ON "impersonation_sessions" ("target_user_id");
+
+ALTER TABLE "impersonation_sessions" ADD FOREIGN KEY ("impersonator_id") REFERENCES "authentications" ("id");
+ALTER TABLE "impersonation_sessions" ADD FOREIGN KEY ("target_user_id") REFERENCES "authentications" ("id");
+ALTER TABLE "impersonation_sessions" ADD FOREIGN KEY ("ended_by") REFERENCES "authentications" ("id");
@@ -0,0 +1,14 @@
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_rotated_from_fkey";
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_created_by_fkey";
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_service_account_id_fkey";
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_user_id_fkey";
+ALTER TABLE "service_accounts" DROP CONSTRAINT IF EXISTS "service_accounts_created_by_fkey";
+ALTER TABLE "service_accounts" DROP CONSTRAINT IF EXISTS "service_accounts_role_id_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "api_keys_service_account_id_idx";
+DROP INDEX IF EXISTS "api_keys_user_id_idx";
+
+-- Drop tables
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "service_accounts";
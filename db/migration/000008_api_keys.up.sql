@@ -0,0 +1,37 @@
+CREATE TABLE "service_accounts" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "name" varchar UNIQUE NOT NULL,
+  "description" text,
+  "role_id" int NOT NULL,
+  "created_by" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "disabled_at" timestamptz
+);
+
+-- A key belongs either to a user (personal key) or to a service account
+CREATE TABLE "api_keys" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar UNIQUE NOT NULL,
+  "key_hash" varchar UNIQUE NOT NULL,
+  "scopes" text[] NOT NULL DEFAULT '{}',
+  "user_id" uuid,
+  "service_account_id" uuid,
+  "created_by" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "rotated_from" uuid,
+  CHECK (("user_id" IS NULL) <> ("service_account_id" IS NULL))
+);
+
+CREATE INDEX ON "api_keys" ("user_id");
+CREATE INDEX ON "api_keys" ("service_account_id");
+
+ALTER TABLE "service_accounts" ADD FOREIGN KEY ("role_id") REFERENCES "roles" ("id");
+ALTER TABLE "service_accounts" ADD FOREIGN KEY ("created_by") REFERENCES "authentications" ("id");
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("service_account_id") REFERENCES "service_accounts" ("id");
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("created_by") REFERENCES "authentications" ("id");
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("rotated_from") REFERENCES "api_keys" ("id");
@@ -0,0 +1,18 @@
+ALTER TABLE "sessions" DROP CONSTRAINT IF EXISTS "sessions_client_id_fkey";
+ALTER TABLE "oauth_consents" DROP CONSTRAINT IF EXISTS "oauth_consents_user_id_fkey";
+ALTER TABLE "oauth_consents" DROP CONSTRAINT IF EXISTS "oauth_consents_client_id_fkey";
+ALTER TABLE "oauth_authorization_codes" DROP CONSTRAINT IF EXISTS "oauth_authorization_codes_user_id_fkey";
+ALTER TABLE "oauth_authorization_codes" DROP CONSTRAINT IF EXISTS "oauth_authorization_codes_client_id_fkey";
+ALTER TABLE "oauth_clients" DROP CONSTRAINT IF EXISTS "oauth_clients_created_by_fkey";
+
+-- Drop indexes
+DROP INDEX IF EXISTS "sessions_user_id_client_id_idx";
+DROP INDEX IF EXISTS "oauth_authorization_codes_expires_at_idx";
+
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "scopes";
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "client_id";
+
+-- Drop tables
+DROP TABLE IF EXISTS "oauth_consents";
+DROP TABLE IF EXISTS "oauth_authorization_codes";
+DROP TABLE IF EXISTS "oauth_clients";
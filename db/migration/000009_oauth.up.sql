@@ -0,0 +1,52 @@
+CREATE TABLE "oauth_clients" (
+  "id" varchar PRIMARY KEY NOT NULL,
+  "secret_hash" varchar,
+  "name" varchar NOT NULL,
+  "logo_url" varchar,
+  "redirect_uris" text[] NOT NULL,
+  "allowed_scopes" text[] NOT NULL,
+  "is_confidential" boolean NOT NULL DEFAULT false,
+  "created_by" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "disabled_at" timestamptz
+);
+
+CREATE TABLE "oauth_authorization_codes" (
+  "code_hash" varchar PRIMARY KEY NOT NULL,
+  "client_id" varchar NOT NULL,
+  "user_id" uuid NOT NULL,
+  "redirect_uri" varchar NOT NULL,
+  "scopes" text[] NOT NULL,
+  "nonce" varchar,
+  "code_challenge" varchar NOT NULL,
+  "code_challenge_method" varchar NOT NULL,
+  "auth_time" timestamptz NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "session_id" uuid,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_consents" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "client_id" varchar NOT NULL,
+  "scopes" text[] NOT NULL,
+  "granted_at" timestamptz NOT NULL DEFAULT (now()),
+  "revoked_at" timestamptz,
+  UNIQUE ("user_id", "client_id")
+);
+
+-- Tokens issued to oauth clients are tracked as regular sessions
+ALTER TABLE "sessions" ADD COLUMN "client_id" varchar;
+ALTER TABLE "sessions" ADD COLUMN "scopes" text[];
+
+CREATE INDEX ON "oauth_authorization_codes" ("expires_at");
+CREATE INDEX ON "sessions" ("user_id", "client_id");
+
+ALTER TABLE "oauth_clients" ADD FOREIGN KEY ("created_by") REFERENCES "authentications" ("id");
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
+ALTER TABLE "oauth_consents" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+ALTER TABLE "oauth_consents" ADD FOREIGN KEY ("user_id") REFERENCES "authentications" ("id");
+ALTER TABLE "sessions" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
@@ -0,0 +1,5 @@
+ALTER TABLE "feature_flags" DROP CONSTRAINT IF EXISTS "feature_flags_updated_by_fkey";
+ALTER TABLE "feature_flags" DROP CONSTRAINT IF EXISTS "feature_flags_created_by_fkey";
+
+-- Drop tables
+DROP TABLE IF EXISTS "feature_flags";
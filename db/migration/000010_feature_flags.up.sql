@@ -0,0 +1,21 @@
+-- A flag serves one of its variants to each caller, boolean flags have the variants "on" and "off".
+-- The targeting rules and the default served when none matches are evaluated by the featureflag package.
+CREATE TABLE "feature_flags" (
+  "key" varchar PRIMARY KEY NOT NULL,
+  "description" text,
+  "kind" varchar NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT false,
+  "variants" text[] NOT NULL,
+  "off_variant" varchar NOT NULL,
+  "rules" jsonb NOT NULL DEFAULT '[]',
+  "default_rule" jsonb NOT NULL,
+  "version" int NOT NULL DEFAULT 1,
+  "created_by" uuid NOT NULL,
+  "updated_by" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("kind" IN ('boolean', 'variant'))
+);
+
+ALTER TABLE "feature_flags" ADD FOREIGN KEY ("created_by") REFERENCES "authentications" ("id");
+ALTER TABLE "feature_flags" ADD FOREIGN KEY ("updated_by") REFERENCES "authentications" ("id");
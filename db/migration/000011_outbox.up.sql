@@ -0,0 +1,26 @@
+-- Messages written in the transaction of the change they announce, then relayed to asynq or Redis Streams by the
+-- outbox package once it committed. kind is 'task' for asynq tasks, whose topic is the task type and destination
+-- the queue, or 'event' for domain events, whose topic is the event type and destination the stream.
+-- Messages of the same aggregate are relayed in id order, a message waiting to be retried holds back the later ones.
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "topic" varchar NOT NULL,
+  "destination" varchar NOT NULL DEFAULT '',
+  "payload" jsonb NOT NULL,
+  "options" jsonb NOT NULL DEFAULT '{}',
+  "trace" jsonb NOT NULL DEFAULT '{}',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" text,
+  "available_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "delivered_at" timestamptz,
+  "failed_at" timestamptz,
+  CHECK ("kind" IN ('task', 'event'))
+);
+
+-- The messages left to relay, kept small by delivered and failed messages leaving it
+CREATE INDEX ON "outbox" ("aggregate", "id") WHERE "delivered_at" IS NULL AND "failed_at" IS NULL;
+CREATE INDEX ON "outbox" ("delivered_at") WHERE "delivered_at" IS NOT NULL;
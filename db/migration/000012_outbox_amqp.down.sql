@@ -0,0 +1,4 @@
+-- RabbitMQ messages can't be relayed anymore once rolled back
+DELETE FROM "outbox" WHERE "kind" = 'amqp';
+ALTER TABLE "outbox" DROP CONSTRAINT "outbox_kind_check";
+ALTER TABLE "outbox" ADD CONSTRAINT "outbox_kind_check" CHECK ("kind" IN ('task', 'event'));
@@ -0,0 +1,3 @@
+-- Messages of kind 'amqp' are published to RabbitMQ, their topic is the routing key and destination the exchange.
+ALTER TABLE "outbox" DROP CONSTRAINT "outbox_kind_check";
+ALTER TABLE "outbox" ADD CONSTRAINT "outbox_kind_check" CHECK ("kind" IN ('task', 'event', 'amqp'));
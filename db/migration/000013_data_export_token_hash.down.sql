@@ -0,0 +1,3 @@
+-- Hashes can't be turned back into tokens, links already sent stop working once rolled back
+UPDATE "data_export_requests" SET "download_token_hash" = NULL;
+ALTER TABLE "data_export_requests" RENAME COLUMN "download_token_hash" TO "download_token";
@@ -0,0 +1,5 @@
+-- Download tokens are stored as their sha256 like api keys and authorization codes, links already sent keep working
+ALTER TABLE "data_export_requests" RENAME COLUMN "download_token" TO "download_token_hash";
+UPDATE "data_export_requests"
+SET "download_token_hash" = encode(sha256(convert_to("download_token_hash", 'UTF8')), 'hex')
+WHERE "download_token_hash" IS NOT NULL;
@@ -0,0 +1,7 @@
+// Package migration embeds the SQL migrations so the binary can apply them without the migrate CLI.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
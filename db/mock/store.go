@@ -37,6 +37,20 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// BlockAllUserSession mocks base method.
+func (m *MockStore) BlockAllUserSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockAllUserSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockAllUserSession indicates an expected call of BlockAllUserSession.
+func (mr *MockStoreMockRecorder) BlockAllUserSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockAllUserSession", reflect.TypeOf((*MockStore)(nil).BlockAllUserSession), arg0, arg1)
+}
+
 // BlockUser mocks base method.
 func (m *MockStore) BlockUser(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -66,150 +80,1745 @@ func (mr *MockStoreMockRecorder) CheckUsername(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUsername", reflect.TypeOf((*MockStore)(nil).CheckUsername), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 sqlc.CreateUserParams) (sqlc.Authentication, error) {
+// ClaimOutboxMessages mocks base method.
+func (m *MockStore) ClaimOutboxMessages(arg0 context.Context, arg1 int32) ([]sqlc.Outbox, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(sqlc.Authentication)
+	ret := m.ctrl.Call(m, "ClaimOutboxMessages", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Outbox)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// ClaimOutboxMessages indicates an expected call of ClaimOutboxMessages.
+func (mr *MockStoreMockRecorder) ClaimOutboxMessages(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxMessages", reflect.TypeOf((*MockStore)(nil).ClaimOutboxMessages), arg0, arg1)
 }
 
-// DeleteUserByID mocks base method.
-func (m *MockStore) DeleteUserByID(arg0 context.Context, arg1 uuid.UUID) error {
+// CleanupVerifiedAndExpiredRequests mocks base method.
+func (m *MockStore) CleanupVerifiedAndExpiredRequests(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserByID", arg0, arg1)
+	ret := m.ctrl.Call(m, "CleanupVerifiedAndExpiredRequests", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUserByID indicates an expected call of DeleteUserByID.
-func (mr *MockStoreMockRecorder) DeleteUserByID(arg0, arg1 interface{}) *gomock.Call {
+// CleanupVerifiedAndExpiredRequests indicates an expected call of CleanupVerifiedAndExpiredRequests.
+func (mr *MockStoreMockRecorder) CleanupVerifiedAndExpiredRequests(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserByID", reflect.TypeOf((*MockStore)(nil).DeleteUserByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupVerifiedAndExpiredRequests", reflect.TypeOf((*MockStore)(nil).CleanupVerifiedAndExpiredRequests), arg0)
 }
 
-// GetUidsFromUsername mocks base method.
-func (m *MockStore) GetUidsFromUsername(arg0 context.Context, arg1 []string) ([]uuid.UUID, error) {
+// ClearCart mocks base method.
+func (m *MockStore) ClearCart(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUidsFromUsername", arg0, arg1)
-	ret0, _ := ret[0].([]uuid.UUID)
+	ret := m.ctrl.Call(m, "ClearCart", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockStoreMockRecorder) ClearCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockStore)(nil).ClearCart), arg0, arg1)
+}
+
+// CompleteDataExportRequest mocks base method.
+func (m *MockStore) CompleteDataExportRequest(arg0 context.Context, arg1 sqlc.CompleteDataExportRequestParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteDataExportRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteDataExportRequest indicates an expected call of CompleteDataExportRequest.
+func (mr *MockStoreMockRecorder) CompleteDataExportRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteDataExportRequest", reflect.TypeOf((*MockStore)(nil).CompleteDataExportRequest), arg0, arg1)
+}
+
+// ConsumeOAuthAuthorizationCode mocks base method.
+func (m *MockStore) ConsumeOAuthAuthorizationCode(arg0 context.Context, arg1 sqlc.ConsumeOAuthAuthorizationCodeParams) (sqlc.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthAuthorizationCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUidsFromUsername indicates an expected call of GetUidsFromUsername.
-func (mr *MockStoreMockRecorder) GetUidsFromUsername(arg0, arg1 interface{}) *gomock.Call {
+// ConsumeOAuthAuthorizationCode indicates an expected call of ConsumeOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) ConsumeOAuthAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUidsFromUsername", reflect.TypeOf((*MockStore)(nil).GetUidsFromUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).ConsumeOAuthAuthorizationCode), arg0, arg1)
 }
 
-// GetUserAndRoleByIdentifier mocks base method.
-func (m *MockStore) GetUserAndRoleByIdentifier(arg0 context.Context, arg1 pgtype.Text) (sqlc.GetUserAndRoleByIdentifierRow, error) {
+// CountMissingConsents mocks base method.
+func (m *MockStore) CountMissingConsents(arg0 context.Context, arg1 sqlc.CountMissingConsentsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserAndRoleByIdentifier", arg0, arg1)
-	ret0, _ := ret[0].(sqlc.GetUserAndRoleByIdentifierRow)
+	ret := m.ctrl.Call(m, "CountMissingConsents", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserAndRoleByIdentifier indicates an expected call of GetUserAndRoleByIdentifier.
-func (mr *MockStoreMockRecorder) GetUserAndRoleByIdentifier(arg0, arg1 interface{}) *gomock.Call {
+// CountMissingConsents indicates an expected call of CountMissingConsents.
+func (mr *MockStoreMockRecorder) CountMissingConsents(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAndRoleByIdentifier", reflect.TypeOf((*MockStore)(nil).GetUserAndRoleByIdentifier), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountMissingConsents", reflect.TypeOf((*MockStore)(nil).CountMissingConsents), arg0, arg1)
 }
 
-// GetUserByID mocks base method.
-func (m *MockStore) GetUserByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.Authentication, error) {
+// CreateApiKey mocks base method.
+func (m *MockStore) CreateApiKey(arg0 context.Context, arg1 sqlc.CreateApiKeyParams) (sqlc.ApiKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
-	ret0, _ := ret[0].(sqlc.Authentication)
+	ret := m.ctrl.Call(m, "CreateApiKey", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ApiKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockStoreMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockStoreMockRecorder) CreateApiKey(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockStore)(nil).CreateApiKey), arg0, arg1)
 }
 
-// GetUserByIdentifier mocks base method.
-func (m *MockStore) GetUserByIdentifier(arg0 context.Context, arg1 string) (sqlc.Authentication, error) {
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(arg0 context.Context, arg1 sqlc.CreateAuditLogParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByIdentifier", arg0, arg1)
-	ret0, _ := ret[0].(sqlc.Authentication)
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), arg0, arg1)
+}
+
+// CreateClientSession mocks base method.
+func (m *MockStore) CreateClientSession(arg0 context.Context, arg1 sqlc.CreateClientSessionParams) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClientSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByIdentifier indicates an expected call of GetUserByIdentifier.
-func (mr *MockStoreMockRecorder) GetUserByIdentifier(arg0, arg1 interface{}) *gomock.Call {
+// CreateClientSession indicates an expected call of CreateClientSession.
+func (mr *MockStoreMockRecorder) CreateClientSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIdentifier", reflect.TypeOf((*MockStore)(nil).GetUserByIdentifier), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClientSession", reflect.TypeOf((*MockStore)(nil).CreateClientSession), arg0, arg1)
 }
 
-// GetUserByUsername mocks base method.
-func (m *MockStore) GetUserByUsername(arg0 context.Context, arg1 pgtype.Text) (uuid.UUID, error) {
+// CreateDataExportRequest mocks base method.
+func (m *MockStore) CreateDataExportRequest(arg0 context.Context, arg1 sqlc.CreateDataExportRequestParams) (sqlc.DataExportRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
-	ret0, _ := ret[0].(uuid.UUID)
+	ret := m.ctrl.Call(m, "CreateDataExportRequest", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.DataExportRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+// CreateDataExportRequest indicates an expected call of CreateDataExportRequest.
+func (mr *MockStoreMockRecorder) CreateDataExportRequest(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataExportRequest", reflect.TypeOf((*MockStore)(nil).CreateDataExportRequest), arg0, arg1)
 }
 
-// GetUserIDsFromUsernames mocks base method.
-func (m *MockStore) GetUserIDsFromUsernames(arg0 context.Context, arg1 pgtype.Text) ([]uuid.UUID, error) {
+// CreateEmailVerificationRequest mocks base method.
+func (m *MockStore) CreateEmailVerificationRequest(arg0 context.Context, arg1 sqlc.CreateEmailVerificationRequestParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserIDsFromUsernames", arg0, arg1)
-	ret0, _ := ret[0].([]uuid.UUID)
+	ret := m.ctrl.Call(m, "CreateEmailVerificationRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailVerificationRequest indicates an expected call of CreateEmailVerificationRequest.
+func (mr *MockStoreMockRecorder) CreateEmailVerificationRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerificationRequest", reflect.TypeOf((*MockStore)(nil).CreateEmailVerificationRequest), arg0, arg1)
+}
+
+// CreateFeatureFlag mocks base method.
+func (m *MockStore) CreateFeatureFlag(arg0 context.Context, arg1 sqlc.CreateFeatureFlagParams) (sqlc.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeatureFlag", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FeatureFlag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserIDsFromUsernames indicates an expected call of GetUserIDsFromUsernames.
-func (mr *MockStoreMockRecorder) GetUserIDsFromUsernames(arg0, arg1 interface{}) *gomock.Call {
+// CreateFeatureFlag indicates an expected call of CreateFeatureFlag.
+func (mr *MockStoreMockRecorder) CreateFeatureFlag(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDsFromUsernames", reflect.TypeOf((*MockStore)(nil).GetUserIDsFromUsernames), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeatureFlag", reflect.TypeOf((*MockStore)(nil).CreateFeatureFlag), arg0, arg1)
 }
 
-// UpdateUser mocks base method.
-func (m *MockStore) UpdateUser(arg0 context.Context, arg1 sqlc.UpdateUserParams) (sqlc.Authentication, error) {
+// CreateGuestSession mocks base method.
+func (m *MockStore) CreateGuestSession(arg0 context.Context, arg1 sqlc.CreateGuestSessionParams) (sqlc.GuestSession, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateGuestSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GuestSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGuestSession indicates an expected call of CreateGuestSession.
+func (mr *MockStoreMockRecorder) CreateGuestSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuestSession", reflect.TypeOf((*MockStore)(nil).CreateGuestSession), arg0, arg1)
+}
+
+// CreateImpersonationSession mocks base method.
+func (m *MockStore) CreateImpersonationSession(arg0 context.Context, arg1 sqlc.CreateImpersonationSessionParams) (sqlc.ImpersonationSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonationSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ImpersonationSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImpersonationSession indicates an expected call of CreateImpersonationSession.
+func (mr *MockStoreMockRecorder) CreateImpersonationSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonationSession", reflect.TypeOf((*MockStore)(nil).CreateImpersonationSession), arg0, arg1)
+}
+
+// CreateOAuthAuthorizationCode mocks base method.
+func (m *MockStore) CreateOAuthAuthorizationCode(arg0 context.Context, arg1 sqlc.CreateOAuthAuthorizationCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthAuthorizationCode indicates an expected call of CreateOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) CreateOAuthAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).CreateOAuthAuthorizationCode), arg0, arg1)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockStore) CreateOAuthClient(arg0 context.Context, arg1 sqlc.CreateOAuthClientParams) (sqlc.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockStoreMockRecorder) CreateOAuthClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockStore)(nil).CreateOAuthClient), arg0, arg1)
+}
+
+// CreateOutboxMessage mocks base method.
+func (m *MockStore) CreateOutboxMessage(arg0 context.Context, arg1 sqlc.CreateOutboxMessageParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxMessage indicates an expected call of CreateOutboxMessage.
+func (mr *MockStoreMockRecorder) CreateOutboxMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxMessage", reflect.TypeOf((*MockStore)(nil).CreateOutboxMessage), arg0, arg1)
+}
+
+// CreatePasswordResetRequest mocks base method.
+func (m *MockStore) CreatePasswordResetRequest(arg0 context.Context, arg1 sqlc.CreatePasswordResetRequestParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordResetRequest indicates an expected call of CreatePasswordResetRequest.
+func (mr *MockStoreMockRecorder) CreatePasswordResetRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetRequest", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetRequest), arg0, arg1)
+}
+
+// CreatePolicyDocument mocks base method.
+func (m *MockStore) CreatePolicyDocument(arg0 context.Context, arg1 sqlc.CreatePolicyDocumentParams) (sqlc.PolicyDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolicyDocument", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PolicyDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePolicyDocument indicates an expected call of CreatePolicyDocument.
+func (mr *MockStoreMockRecorder) CreatePolicyDocument(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicyDocument", reflect.TypeOf((*MockStore)(nil).CreatePolicyDocument), arg0, arg1)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockStore) CreateServiceAccount(arg0 context.Context, arg1 sqlc.CreateServiceAccountParams) (sqlc.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockStoreMockRecorder) CreateServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockStore)(nil).CreateServiceAccount), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 sqlc.CreateSessionParams) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 sqlc.CreateUserParams) (sqlc.Authentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
 	ret0, _ := ret[0].(sqlc.Authentication)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
-// UpdateUserPassword mocks base method.
-func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 sqlc.UpdateUserPasswordParams) error {
+// CreateUserConsent mocks base method.
+func (m *MockStore) CreateUserConsent(arg0 context.Context, arg1 sqlc.CreateUserConsentParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateUserConsent", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateUserPassword indicates an expected call of UpdateUserPassword.
-func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+// CreateUserConsent indicates an expected call of CreateUserConsent.
+func (mr *MockStoreMockRecorder) CreateUserConsent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserConsent", reflect.TypeOf((*MockStore)(nil).CreateUserConsent), arg0, arg1)
+}
+
+// CreateUserDeleteRequest mocks base method.
+func (m *MockStore) CreateUserDeleteRequest(arg0 context.Context, arg1 sqlc.CreateUserDeleteRequestParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserDeleteRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUserDeleteRequest indicates an expected call of CreateUserDeleteRequest.
+func (mr *MockStoreMockRecorder) CreateUserDeleteRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserDeleteRequest", reflect.TypeOf((*MockStore)(nil).CreateUserDeleteRequest), arg0, arg1)
+}
+
+// CreateUserLogin mocks base method.
+func (m *MockStore) CreateUserLogin(arg0 context.Context, arg1 sqlc.CreateUserLoginParams) (sqlc.UserLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserLogin", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.UserLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserLogin indicates an expected call of CreateUserLogin.
+func (mr *MockStoreMockRecorder) CreateUserLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserLogin", reflect.TypeOf((*MockStore)(nil).CreateUserLogin), arg0, arg1)
+}
+
+// CreateUserProfile mocks base method.
+func (m *MockStore) CreateUserProfile(arg0 context.Context, arg1 sqlc.CreateUserProfileParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUserProfile indicates an expected call of CreateUserProfile.
+func (mr *MockStoreMockRecorder) CreateUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserProfile", reflect.TypeOf((*MockStore)(nil).CreateUserProfile), arg0, arg1)
+}
+
+// CreateUserRole mocks base method.
+func (m *MockStore) CreateUserRole(arg0 context.Context, arg1 sqlc.CreateUserRoleParams) (sqlc.UserRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.UserRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserRole indicates an expected call of CreateUserRole.
+func (mr *MockStoreMockRecorder) CreateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserRole", reflect.TypeOf((*MockStore)(nil).CreateUserRole), arg0, arg1)
+}
+
+// DeleteCartItem mocks base method.
+func (m *MockStore) DeleteCartItem(arg0 context.Context, arg1 sqlc.DeleteCartItemParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCartItem", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCartItem indicates an expected call of DeleteCartItem.
+func (mr *MockStoreMockRecorder) DeleteCartItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCartItem", reflect.TypeOf((*MockStore)(nil).DeleteCartItem), arg0, arg1)
+}
+
+// DeleteDeliveredOutboxMessages mocks base method.
+func (m *MockStore) DeleteDeliveredOutboxMessages(arg0 context.Context, arg1 pgtype.Timestamptz) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeliveredOutboxMessages", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDeliveredOutboxMessages indicates an expected call of DeleteDeliveredOutboxMessages.
+func (mr *MockStoreMockRecorder) DeleteDeliveredOutboxMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeliveredOutboxMessages", reflect.TypeOf((*MockStore)(nil).DeleteDeliveredOutboxMessages), arg0, arg1)
+}
+
+// DeleteExpiredOAuthAuthorizationCodes mocks base method.
+func (m *MockStore) DeleteExpiredOAuthAuthorizationCodes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredOAuthAuthorizationCodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredOAuthAuthorizationCodes indicates an expected call of DeleteExpiredOAuthAuthorizationCodes.
+func (mr *MockStoreMockRecorder) DeleteExpiredOAuthAuthorizationCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredOAuthAuthorizationCodes", reflect.TypeOf((*MockStore)(nil).DeleteExpiredOAuthAuthorizationCodes), arg0)
+}
+
+// DeleteFeatureFlag mocks base method.
+func (m *MockStore) DeleteFeatureFlag(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlag", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeatureFlag indicates an expected call of DeleteFeatureFlag.
+func (mr *MockStoreMockRecorder) DeleteFeatureFlag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlag", reflect.TypeOf((*MockStore)(nil).DeleteFeatureFlag), arg0, arg1)
+}
+
+// DeletePasswordResetRequestByID mocks base method.
+func (m *MockStore) DeletePasswordResetRequestByID(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetRequestByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetRequestByID indicates an expected call of DeletePasswordResetRequestByID.
+func (mr *MockStoreMockRecorder) DeletePasswordResetRequestByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetRequestByID", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetRequestByID), arg0, arg1)
+}
+
+// DeletePasswordResetRequestsByEmail mocks base method.
+func (m *MockStore) DeletePasswordResetRequestsByEmail(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetRequestsByEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetRequestsByEmail indicates an expected call of DeletePasswordResetRequestsByEmail.
+func (mr *MockStoreMockRecorder) DeletePasswordResetRequestsByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetRequestsByEmail", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetRequestsByEmail), arg0, arg1)
+}
+
+// DeleteSession mocks base method.
+func (m *MockStore) DeleteSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockStoreMockRecorder) DeleteSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), arg0, arg1)
+}
+
+// DeleteUserByID mocks base method.
+func (m *MockStore) DeleteUserByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserByID indicates an expected call of DeleteUserByID.
+func (mr *MockStoreMockRecorder) DeleteUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserByID", reflect.TypeOf((*MockStore)(nil).DeleteUserByID), arg0, arg1)
+}
+
+// DeleteUserLogin mocks base method.
+func (m *MockStore) DeleteUserLogin(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserLogin indicates an expected call of DeleteUserLogin.
+func (mr *MockStoreMockRecorder) DeleteUserLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserLogin", reflect.TypeOf((*MockStore)(nil).DeleteUserLogin), arg0, arg1)
+}
+
+// DeleteUserProfileByID mocks base method.
+func (m *MockStore) DeleteUserProfileByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserProfileByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserProfileByID indicates an expected call of DeleteUserProfileByID.
+func (mr *MockStoreMockRecorder) DeleteUserProfileByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserProfileByID", reflect.TypeOf((*MockStore)(nil).DeleteUserProfileByID), arg0, arg1)
+}
+
+// DisableOAuthClient mocks base method.
+func (m *MockStore) DisableOAuthClient(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableOAuthClient indicates an expected call of DisableOAuthClient.
+func (mr *MockStoreMockRecorder) DisableOAuthClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableOAuthClient", reflect.TypeOf((*MockStore)(nil).DisableOAuthClient), arg0, arg1)
+}
+
+// DisableServiceAccount mocks base method.
+func (m *MockStore) DisableServiceAccount(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableServiceAccount indicates an expected call of DisableServiceAccount.
+func (mr *MockStoreMockRecorder) DisableServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableServiceAccount", reflect.TypeOf((*MockStore)(nil).DisableServiceAccount), arg0, arg1)
+}
+
+// EndImpersonationSession mocks base method.
+func (m *MockStore) EndImpersonationSession(arg0 context.Context, arg1 sqlc.EndImpersonationSessionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndImpersonationSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndImpersonationSession indicates an expected call of EndImpersonationSession.
+func (mr *MockStoreMockRecorder) EndImpersonationSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndImpersonationSession", reflect.TypeOf((*MockStore)(nil).EndImpersonationSession), arg0, arg1)
+}
+
+// ExecTx mocks base method.
+func (m *MockStore) ExecTx(arg0 context.Context, arg1 sqlc.TxOptions, arg2 func(*sqlc.Queries) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTx indicates an expected call of ExecTx.
+func (mr *MockStoreMockRecorder) ExecTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTx", reflect.TypeOf((*MockStore)(nil).ExecTx), arg0, arg1, arg2)
+}
+
+// FailDataExportRequest mocks base method.
+func (m *MockStore) FailDataExportRequest(arg0 context.Context, arg1 sqlc.FailDataExportRequestParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDataExportRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDataExportRequest indicates an expected call of FailDataExportRequest.
+func (mr *MockStoreMockRecorder) FailDataExportRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDataExportRequest", reflect.TypeOf((*MockStore)(nil).FailDataExportRequest), arg0, arg1)
+}
+
+// FailOutboxMessage mocks base method.
+func (m *MockStore) FailOutboxMessage(arg0 context.Context, arg1 sqlc.FailOutboxMessageParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailOutboxMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailOutboxMessage indicates an expected call of FailOutboxMessage.
+func (mr *MockStoreMockRecorder) FailOutboxMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOutboxMessage", reflect.TypeOf((*MockStore)(nil).FailOutboxMessage), arg0, arg1)
+}
+
+// GetAccountRecoveryRequestsByUserID mocks base method.
+func (m *MockStore) GetAccountRecoveryRequestsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.AccountRecoveryRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRecoveryRequestsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.AccountRecoveryRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRecoveryRequestsByUserID indicates an expected call of GetAccountRecoveryRequestsByUserID.
+func (mr *MockStoreMockRecorder) GetAccountRecoveryRequestsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRecoveryRequestsByUserID", reflect.TypeOf((*MockStore)(nil).GetAccountRecoveryRequestsByUserID), arg0, arg1)
+}
+
+// GetApiKeyByID mocks base method.
+func (m *MockStore) GetApiKeyByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyByID indicates an expected call of GetApiKeyByID.
+func (mr *MockStoreMockRecorder) GetApiKeyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyByID", reflect.TypeOf((*MockStore)(nil).GetApiKeyByID), arg0, arg1)
+}
+
+// GetApiKeyForAuth mocks base method.
+func (m *MockStore) GetApiKeyForAuth(arg0 context.Context, arg1 string) (sqlc.GetApiKeyForAuthRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyForAuth", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GetApiKeyForAuthRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyForAuth indicates an expected call of GetApiKeyForAuth.
+func (mr *MockStoreMockRecorder) GetApiKeyForAuth(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyForAuth", reflect.TypeOf((*MockStore)(nil).GetApiKeyForAuth), arg0, arg1)
+}
+
+// GetAuditLogsByUserID mocks base method.
+func (m *MockStore) GetAuditLogsByUserID(arg0 context.Context, arg1 uuid.NullUUID) ([]sqlc.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogsByUserID indicates an expected call of GetAuditLogsByUserID.
+func (mr *MockStoreMockRecorder) GetAuditLogsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogsByUserID", reflect.TypeOf((*MockStore)(nil).GetAuditLogsByUserID), arg0, arg1)
+}
+
+// GetCartItems mocks base method.
+func (m *MockStore) GetCartItems(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartItems", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartItems indicates an expected call of GetCartItems.
+func (mr *MockStoreMockRecorder) GetCartItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockStore)(nil).GetCartItems), arg0, arg1)
+}
+
+// GetConsentAcceptanceReport mocks base method.
+func (m *MockStore) GetConsentAcceptanceReport(arg0 context.Context) ([]sqlc.GetConsentAcceptanceReportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsentAcceptanceReport", arg0)
+	ret0, _ := ret[0].([]sqlc.GetConsentAcceptanceReportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsentAcceptanceReport indicates an expected call of GetConsentAcceptanceReport.
+func (mr *MockStoreMockRecorder) GetConsentAcceptanceReport(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsentAcceptanceReport", reflect.TypeOf((*MockStore)(nil).GetConsentAcceptanceReport), arg0)
+}
+
+// GetCurrentPolicyDocuments mocks base method.
+func (m *MockStore) GetCurrentPolicyDocuments(arg0 context.Context) ([]sqlc.PolicyDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentPolicyDocuments", arg0)
+	ret0, _ := ret[0].([]sqlc.PolicyDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentPolicyDocuments indicates an expected call of GetCurrentPolicyDocuments.
+func (mr *MockStoreMockRecorder) GetCurrentPolicyDocuments(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentPolicyDocuments", reflect.TypeOf((*MockStore)(nil).GetCurrentPolicyDocuments), arg0)
+}
+
+// GetDataExportRequestByID mocks base method.
+func (m *MockStore) GetDataExportRequestByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.DataExportRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportRequestByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.DataExportRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportRequestByID indicates an expected call of GetDataExportRequestByID.
+func (mr *MockStoreMockRecorder) GetDataExportRequestByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportRequestByID", reflect.TypeOf((*MockStore)(nil).GetDataExportRequestByID), arg0, arg1)
+}
+
+// GetDataExportRequestByTokenHash mocks base method.
+func (m *MockStore) GetDataExportRequestByTokenHash(arg0 context.Context, arg1 pgtype.Text) (sqlc.DataExportRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportRequestByTokenHash", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.DataExportRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportRequestByTokenHash indicates an expected call of GetDataExportRequestByTokenHash.
+func (mr *MockStoreMockRecorder) GetDataExportRequestByTokenHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportRequestByTokenHash", reflect.TypeOf((*MockStore)(nil).GetDataExportRequestByTokenHash), arg0, arg1)
+}
+
+// GetEmailVerificationRequestByToken mocks base method.
+func (m *MockStore) GetEmailVerificationRequestByToken(arg0 context.Context, arg1 string) (sqlc.EmailVerificationRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationRequestByToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.EmailVerificationRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationRequestByToken indicates an expected call of GetEmailVerificationRequestByToken.
+func (mr *MockStoreMockRecorder) GetEmailVerificationRequestByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationRequestByToken", reflect.TypeOf((*MockStore)(nil).GetEmailVerificationRequestByToken), arg0, arg1)
+}
+
+// GetEmailVerificationRequestsByUserID mocks base method.
+func (m *MockStore) GetEmailVerificationRequestsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.EmailVerificationRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationRequestsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.EmailVerificationRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationRequestsByUserID indicates an expected call of GetEmailVerificationRequestsByUserID.
+func (mr *MockStoreMockRecorder) GetEmailVerificationRequestsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationRequestsByUserID", reflect.TypeOf((*MockStore)(nil).GetEmailVerificationRequestsByUserID), arg0, arg1)
+}
+
+// GetFeatureFlag mocks base method.
+func (m *MockStore) GetFeatureFlag(arg0 context.Context, arg1 string) (sqlc.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatureFlag", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureFlag indicates an expected call of GetFeatureFlag.
+func (mr *MockStoreMockRecorder) GetFeatureFlag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureFlag", reflect.TypeOf((*MockStore)(nil).GetFeatureFlag), arg0, arg1)
+}
+
+// GetGuestSessionByID mocks base method.
+func (m *MockStore) GetGuestSessionByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.GuestSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestSessionByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GuestSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestSessionByID indicates an expected call of GetGuestSessionByID.
+func (mr *MockStoreMockRecorder) GetGuestSessionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestSessionByID", reflect.TypeOf((*MockStore)(nil).GetGuestSessionByID), arg0, arg1)
+}
+
+// GetGuestSessionByRefreshToken mocks base method.
+func (m *MockStore) GetGuestSessionByRefreshToken(arg0 context.Context, arg1 string) (sqlc.GuestSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestSessionByRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GuestSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestSessionByRefreshToken indicates an expected call of GetGuestSessionByRefreshToken.
+func (mr *MockStoreMockRecorder) GetGuestSessionByRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestSessionByRefreshToken", reflect.TypeOf((*MockStore)(nil).GetGuestSessionByRefreshToken), arg0, arg1)
+}
+
+// GetImpersonationSession mocks base method.
+func (m *MockStore) GetImpersonationSession(arg0 context.Context, arg1 uuid.UUID) (sqlc.ImpersonationSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImpersonationSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ImpersonationSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImpersonationSession indicates an expected call of GetImpersonationSession.
+func (mr *MockStoreMockRecorder) GetImpersonationSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImpersonationSession", reflect.TypeOf((*MockStore)(nil).GetImpersonationSession), arg0, arg1)
+}
+
+// GetLatestDataExportRequest mocks base method.
+func (m *MockStore) GetLatestDataExportRequest(arg0 context.Context, arg1 uuid.UUID) (sqlc.DataExportRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestDataExportRequest", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.DataExportRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestDataExportRequest indicates an expected call of GetLatestDataExportRequest.
+func (mr *MockStoreMockRecorder) GetLatestDataExportRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestDataExportRequest", reflect.TypeOf((*MockStore)(nil).GetLatestDataExportRequest), arg0, arg1)
+}
+
+// GetOAuthAuthorizationCode mocks base method.
+func (m *MockStore) GetOAuthAuthorizationCode(arg0 context.Context, arg1 string) (sqlc.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthAuthorizationCode indicates an expected call of GetOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) GetOAuthAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).GetOAuthAuthorizationCode), arg0, arg1)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockStore) GetOAuthClient(arg0 context.Context, arg1 string) (sqlc.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockStoreMockRecorder) GetOAuthClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockStore)(nil).GetOAuthClient), arg0, arg1)
+}
+
+// GetOAuthConsent mocks base method.
+func (m *MockStore) GetOAuthConsent(arg0 context.Context, arg1 sqlc.GetOAuthConsentParams) (sqlc.OauthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthConsent indicates an expected call of GetOAuthConsent.
+func (mr *MockStoreMockRecorder) GetOAuthConsent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthConsent", reflect.TypeOf((*MockStore)(nil).GetOAuthConsent), arg0, arg1)
+}
+
+// GetPasswordResetRequestByID mocks base method.
+func (m *MockStore) GetPasswordResetRequestByID(arg0 context.Context, arg1 int32) (sqlc.PasswordResetRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetRequestByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordResetRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetRequestByID indicates an expected call of GetPasswordResetRequestByID.
+func (mr *MockStoreMockRecorder) GetPasswordResetRequestByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetRequestByID", reflect.TypeOf((*MockStore)(nil).GetPasswordResetRequestByID), arg0, arg1)
+}
+
+// GetPasswordResetRequestByToken mocks base method.
+func (m *MockStore) GetPasswordResetRequestByToken(arg0 context.Context, arg1 string) (sqlc.PasswordResetRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetRequestByToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordResetRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetRequestByToken indicates an expected call of GetPasswordResetRequestByToken.
+func (mr *MockStoreMockRecorder) GetPasswordResetRequestByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetRequestByToken", reflect.TypeOf((*MockStore)(nil).GetPasswordResetRequestByToken), arg0, arg1)
+}
+
+// GetPasswordResetRequestsByUserID mocks base method.
+func (m *MockStore) GetPasswordResetRequestsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.PasswordResetRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetRequestsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.PasswordResetRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetRequestsByUserID indicates an expected call of GetPasswordResetRequestsByUserID.
+func (mr *MockStoreMockRecorder) GetPasswordResetRequestsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetRequestsByUserID", reflect.TypeOf((*MockStore)(nil).GetPasswordResetRequestsByUserID), arg0, arg1)
+}
+
+// GetPolicyDocumentByVersion mocks base method.
+func (m *MockStore) GetPolicyDocumentByVersion(arg0 context.Context, arg1 sqlc.GetPolicyDocumentByVersionParams) (sqlc.PolicyDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicyDocumentByVersion", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PolicyDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicyDocumentByVersion indicates an expected call of GetPolicyDocumentByVersion.
+func (mr *MockStoreMockRecorder) GetPolicyDocumentByVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicyDocumentByVersion", reflect.TypeOf((*MockStore)(nil).GetPolicyDocumentByVersion), arg0, arg1)
+}
+
+// GetServiceAccount mocks base method.
+func (m *MockStore) GetServiceAccount(arg0 context.Context, arg1 uuid.UUID) (sqlc.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceAccount indicates an expected call of GetServiceAccount.
+func (mr *MockStoreMockRecorder) GetServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceAccount", reflect.TypeOf((*MockStore)(nil).GetServiceAccount), arg0, arg1)
+}
+
+// GetSessionAndUserByRefreshToken mocks base method.
+func (m *MockStore) GetSessionAndUserByRefreshToken(arg0 context.Context, arg1 string) (sqlc.GetSessionAndUserByRefreshTokenRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionAndUserByRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GetSessionAndUserByRefreshTokenRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionAndUserByRefreshToken indicates an expected call of GetSessionAndUserByRefreshToken.
+func (mr *MockStoreMockRecorder) GetSessionAndUserByRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionAndUserByRefreshToken", reflect.TypeOf((*MockStore)(nil).GetSessionAndUserByRefreshToken), arg0, arg1)
+}
+
+// GetSessionsByID mocks base method.
+func (m *MockStore) GetSessionsByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByID indicates an expected call of GetSessionsByID.
+func (mr *MockStoreMockRecorder) GetSessionsByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByID", reflect.TypeOf((*MockStore)(nil).GetSessionsByID), arg0, arg1)
+}
+
+// GetSessionsByRefreshToken mocks base method.
+func (m *MockStore) GetSessionsByRefreshToken(arg0 context.Context, arg1 string) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByRefreshToken indicates an expected call of GetSessionsByRefreshToken.
+func (mr *MockStoreMockRecorder) GetSessionsByRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByRefreshToken", reflect.TypeOf((*MockStore)(nil).GetSessionsByRefreshToken), arg0, arg1)
+}
+
+// GetSessionsByUserID mocks base method.
+func (m *MockStore) GetSessionsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUserID indicates an expected call of GetSessionsByUserID.
+func (mr *MockStoreMockRecorder) GetSessionsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUserID", reflect.TypeOf((*MockStore)(nil).GetSessionsByUserID), arg0, arg1)
+}
+
+// GetUidsFromUsername mocks base method.
+func (m *MockStore) GetUidsFromUsername(arg0 context.Context, arg1 []string) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUidsFromUsername", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUidsFromUsername indicates an expected call of GetUidsFromUsername.
+func (mr *MockStoreMockRecorder) GetUidsFromUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUidsFromUsername", reflect.TypeOf((*MockStore)(nil).GetUidsFromUsername), arg0, arg1)
+}
+
+// GetUserAndRoleByIdentifier mocks base method.
+func (m *MockStore) GetUserAndRoleByIdentifier(arg0 context.Context, arg1 pgtype.Text) (sqlc.GetUserAndRoleByIdentifierRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAndRoleByIdentifier", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GetUserAndRoleByIdentifierRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAndRoleByIdentifier indicates an expected call of GetUserAndRoleByIdentifier.
+func (mr *MockStoreMockRecorder) GetUserAndRoleByIdentifier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAndRoleByIdentifier", reflect.TypeOf((*MockStore)(nil).GetUserAndRoleByIdentifier), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(arg0 context.Context, arg1 uuid.UUID) (sqlc.Authentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Authentication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), arg0, arg1)
+}
+
+// GetUserByIdentifier mocks base method.
+func (m *MockStore) GetUserByIdentifier(arg0 context.Context, arg1 string) (sqlc.Authentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByIdentifier", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Authentication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByIdentifier indicates an expected call of GetUserByIdentifier.
+func (mr *MockStoreMockRecorder) GetUserByIdentifier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIdentifier", reflect.TypeOf((*MockStore)(nil).GetUserByIdentifier), arg0, arg1)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockStore) GetUserByUsername(arg0 context.Context, arg1 pgtype.Text) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
+}
+
+// GetUserConsents mocks base method.
+func (m *MockStore) GetUserConsents(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.GetUserConsentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserConsents", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.GetUserConsentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserConsents indicates an expected call of GetUserConsents.
+func (mr *MockStoreMockRecorder) GetUserConsents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserConsents", reflect.TypeOf((*MockStore)(nil).GetUserConsents), arg0, arg1)
+}
+
+// GetUserFromDeleteReqByToken mocks base method.
+func (m *MockStore) GetUserFromDeleteReqByToken(arg0 context.Context, arg1 string) (sqlc.AccountRecoveryRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFromDeleteReqByToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.AccountRecoveryRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFromDeleteReqByToken indicates an expected call of GetUserFromDeleteReqByToken.
+func (mr *MockStoreMockRecorder) GetUserFromDeleteReqByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFromDeleteReqByToken", reflect.TypeOf((*MockStore)(nil).GetUserFromDeleteReqByToken), arg0, arg1)
+}
+
+// GetUserIDsFromUsernames mocks base method.
+func (m *MockStore) GetUserIDsFromUsernames(arg0 context.Context, arg1 pgtype.Text) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDsFromUsernames", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDsFromUsernames indicates an expected call of GetUserIDsFromUsernames.
+func (mr *MockStoreMockRecorder) GetUserIDsFromUsernames(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDsFromUsernames", reflect.TypeOf((*MockStore)(nil).GetUserIDsFromUsernames), arg0, arg1)
+}
+
+// GetUserLoginsByUserID mocks base method.
+func (m *MockStore) GetUserLoginsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.UserLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLoginsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.UserLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLoginsByUserID indicates an expected call of GetUserLoginsByUserID.
+func (mr *MockStoreMockRecorder) GetUserLoginsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLoginsByUserID", reflect.TypeOf((*MockStore)(nil).GetUserLoginsByUserID), arg0, arg1)
+}
+
+// GetUserProfile mocks base method.
+func (m *MockStore) GetUserProfile(arg0 context.Context, arg1 pgtype.Text) (sqlc.GetUserProfileRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.GetUserProfileRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfile indicates an expected call of GetUserProfile.
+func (mr *MockStoreMockRecorder) GetUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockStore)(nil).GetUserProfile), arg0, arg1)
+}
+
+// GetUserProfileByUserID mocks base method.
+func (m *MockStore) GetUserProfileByUserID(arg0 context.Context, arg1 uuid.UUID) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfileByUserID", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfileByUserID indicates an expected call of GetUserProfileByUserID.
+func (mr *MockStoreMockRecorder) GetUserProfileByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfileByUserID", reflect.TypeOf((*MockStore)(nil).GetUserProfileByUserID), arg0, arg1)
+}
+
+// GetUserRolesByUserID mocks base method.
+func (m *MockStore) GetUserRolesByUserID(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.GetUserRolesByUserIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRolesByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.GetUserRolesByUserIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRolesByUserID indicates an expected call of GetUserRolesByUserID.
+func (mr *MockStoreMockRecorder) GetUserRolesByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRolesByUserID", reflect.TypeOf((*MockStore)(nil).GetUserRolesByUserID), arg0, arg1)
+}
+
+// ImportAuditLogs mocks base method.
+func (m *MockStore) ImportAuditLogs(arg0 context.Context, arg1 []sqlc.ImportAuditLogsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportAuditLogs indicates an expected call of ImportAuditLogs.
+func (mr *MockStoreMockRecorder) ImportAuditLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportAuditLogs", reflect.TypeOf((*MockStore)(nil).ImportAuditLogs), arg0, arg1)
+}
+
+// ListActiveImpersonationSessions mocks base method.
+func (m *MockStore) ListActiveImpersonationSessions(arg0 context.Context) ([]sqlc.ImpersonationSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveImpersonationSessions", arg0)
+	ret0, _ := ret[0].([]sqlc.ImpersonationSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveImpersonationSessions indicates an expected call of ListActiveImpersonationSessions.
+func (mr *MockStoreMockRecorder) ListActiveImpersonationSessions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveImpersonationSessions", reflect.TypeOf((*MockStore)(nil).ListActiveImpersonationSessions), arg0)
+}
+
+// ListFeatureFlags mocks base method.
+func (m *MockStore) ListFeatureFlags(arg0 context.Context) ([]sqlc.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeatureFlags", arg0)
+	ret0, _ := ret[0].([]sqlc.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeatureFlags indicates an expected call of ListFeatureFlags.
+func (mr *MockStoreMockRecorder) ListFeatureFlags(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeatureFlags", reflect.TypeOf((*MockStore)(nil).ListFeatureFlags), arg0)
+}
+
+// ListOAuthClients mocks base method.
+func (m *MockStore) ListOAuthClients(arg0 context.Context) ([]sqlc.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", arg0)
+	ret0, _ := ret[0].([]sqlc.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockStoreMockRecorder) ListOAuthClients(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockStore)(nil).ListOAuthClients), arg0)
+}
+
+// ListPolicyDocuments mocks base method.
+func (m *MockStore) ListPolicyDocuments(arg0 context.Context) ([]sqlc.PolicyDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPolicyDocuments", arg0)
+	ret0, _ := ret[0].([]sqlc.PolicyDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPolicyDocuments indicates an expected call of ListPolicyDocuments.
+func (mr *MockStoreMockRecorder) ListPolicyDocuments(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolicyDocuments", reflect.TypeOf((*MockStore)(nil).ListPolicyDocuments), arg0)
+}
+
+// ListServiceAccountApiKeys mocks base method.
+func (m *MockStore) ListServiceAccountApiKeys(arg0 context.Context, arg1 uuid.NullUUID) ([]sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccountApiKeys", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccountApiKeys indicates an expected call of ListServiceAccountApiKeys.
+func (mr *MockStoreMockRecorder) ListServiceAccountApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccountApiKeys", reflect.TypeOf((*MockStore)(nil).ListServiceAccountApiKeys), arg0, arg1)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockStore) ListServiceAccounts(arg0 context.Context) ([]sqlc.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccounts", arg0)
+	ret0, _ := ret[0].([]sqlc.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockStoreMockRecorder) ListServiceAccounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockStore)(nil).ListServiceAccounts), arg0)
+}
+
+// ListUserApiKeys mocks base method.
+func (m *MockStore) ListUserApiKeys(arg0 context.Context, arg1 uuid.NullUUID) ([]sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserApiKeys", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserApiKeys indicates an expected call of ListUserApiKeys.
+func (mr *MockStoreMockRecorder) ListUserApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserApiKeys", reflect.TypeOf((*MockStore)(nil).ListUserApiKeys), arg0, arg1)
+}
+
+// ListUserOAuthConsents mocks base method.
+func (m *MockStore) ListUserOAuthConsents(arg0 context.Context, arg1 uuid.UUID) ([]sqlc.ListUserOAuthConsentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOAuthConsents", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ListUserOAuthConsentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOAuthConsents indicates an expected call of ListUserOAuthConsents.
+func (mr *MockStoreMockRecorder) ListUserOAuthConsents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOAuthConsents", reflect.TypeOf((*MockStore)(nil).ListUserOAuthConsents), arg0, arg1)
+}
+
+// MarkDataExportDownloaded mocks base method.
+func (m *MockStore) MarkDataExportDownloaded(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDataExportDownloaded", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDataExportDownloaded indicates an expected call of MarkDataExportDownloaded.
+func (mr *MockStoreMockRecorder) MarkDataExportDownloaded(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDataExportDownloaded", reflect.TypeOf((*MockStore)(nil).MarkDataExportDownloaded), arg0, arg1)
+}
+
+// MarkDataExportProcessing mocks base method.
+func (m *MockStore) MarkDataExportProcessing(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDataExportProcessing", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDataExportProcessing indicates an expected call of MarkDataExportProcessing.
+func (mr *MockStoreMockRecorder) MarkDataExportProcessing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDataExportProcessing", reflect.TypeOf((*MockStore)(nil).MarkDataExportProcessing), arg0, arg1)
+}
+
+// MarkDeleteAsUsedByToken mocks base method.
+func (m *MockStore) MarkDeleteAsUsedByToken(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeleteAsUsedByToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeleteAsUsedByToken indicates an expected call of MarkDeleteAsUsedByToken.
+func (mr *MockStoreMockRecorder) MarkDeleteAsUsedByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeleteAsUsedByToken", reflect.TypeOf((*MockStore)(nil).MarkDeleteAsUsedByToken), arg0, arg1)
+}
+
+// MarkOutboxMessageDelivered mocks base method.
+func (m *MockStore) MarkOutboxMessageDelivered(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessageDelivered", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessageDelivered indicates an expected call of MarkOutboxMessageDelivered.
+func (mr *MockStoreMockRecorder) MarkOutboxMessageDelivered(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessageDelivered", reflect.TypeOf((*MockStore)(nil).MarkOutboxMessageDelivered), arg0, arg1)
+}
+
+// MergeCart mocks base method.
+func (m *MockStore) MergeCart(arg0 context.Context, arg1 sqlc.MergeCartParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCart", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeCart indicates an expected call of MergeCart.
+func (mr *MockStoreMockRecorder) MergeCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCart", reflect.TypeOf((*MockStore)(nil).MergeCart), arg0, arg1)
+}
+
+// RetireGuestSession mocks base method.
+func (m *MockStore) RetireGuestSession(arg0 context.Context, arg1 sqlc.RetireGuestSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireGuestSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireGuestSession indicates an expected call of RetireGuestSession.
+func (mr *MockStoreMockRecorder) RetireGuestSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireGuestSession", reflect.TypeOf((*MockStore)(nil).RetireGuestSession), arg0, arg1)
+}
+
+// RetryOutboxMessage mocks base method.
+func (m *MockStore) RetryOutboxMessage(arg0 context.Context, arg1 sqlc.RetryOutboxMessageParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOutboxMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryOutboxMessage indicates an expected call of RetryOutboxMessage.
+func (mr *MockStoreMockRecorder) RetryOutboxMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOutboxMessage", reflect.TypeOf((*MockStore)(nil).RetryOutboxMessage), arg0, arg1)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockStore) RevokeApiKey(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApiKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockStoreMockRecorder) RevokeApiKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockStore)(nil).RevokeApiKey), arg0, arg1)
+}
+
+// RevokeClientSessions mocks base method.
+func (m *MockStore) RevokeClientSessions(arg0 context.Context, arg1 sqlc.RevokeClientSessionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeClientSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeClientSessions indicates an expected call of RevokeClientSessions.
+func (mr *MockStoreMockRecorder) RevokeClientSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeClientSessions", reflect.TypeOf((*MockStore)(nil).RevokeClientSessions), arg0, arg1)
+}
+
+// RevokeOAuthConsent mocks base method.
+func (m *MockStore) RevokeOAuthConsent(arg0 context.Context, arg1 sqlc.RevokeOAuthConsentParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOAuthConsent indicates an expected call of RevokeOAuthConsent.
+func (mr *MockStoreMockRecorder) RevokeOAuthConsent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthConsent", reflect.TypeOf((*MockStore)(nil).RevokeOAuthConsent), arg0, arg1)
+}
+
+// RevokeServiceAccountApiKeys mocks base method.
+func (m *MockStore) RevokeServiceAccountApiKeys(arg0 context.Context, arg1 uuid.NullUUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeServiceAccountApiKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeServiceAccountApiKeys indicates an expected call of RevokeServiceAccountApiKeys.
+func (mr *MockStoreMockRecorder) RevokeServiceAccountApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeServiceAccountApiKeys", reflect.TypeOf((*MockStore)(nil).RevokeServiceAccountApiKeys), arg0, arg1)
+}
+
+// RevokeSessionById mocks base method.
+func (m *MockStore) RevokeSessionById(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessionById", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessionById indicates an expected call of RevokeSessionById.
+func (mr *MockStoreMockRecorder) RevokeSessionById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionById", reflect.TypeOf((*MockStore)(nil).RevokeSessionById), arg0, arg1)
+}
+
+// RotateGuestSessionToken mocks base method.
+func (m *MockStore) RotateGuestSessionToken(arg0 context.Context, arg1 sqlc.RotateGuestSessionTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateGuestSessionToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateGuestSessionToken indicates an expected call of RotateGuestSessionToken.
+func (mr *MockStoreMockRecorder) RotateGuestSessionToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateGuestSessionToken", reflect.TypeOf((*MockStore)(nil).RotateGuestSessionToken), arg0, arg1)
+}
+
+// RotateSessionTokens mocks base method.
+func (m *MockStore) RotateSessionTokens(arg0 context.Context, arg1 sqlc.RotateSessionTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSessionTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateSessionTokens indicates an expected call of RotateSessionTokens.
+func (mr *MockStoreMockRecorder) RotateSessionTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionTokens", reflect.TypeOf((*MockStore)(nil).RotateSessionTokens), arg0, arg1)
+}
+
+// SetOAuthAuthorizationCodeSession mocks base method.
+func (m *MockStore) SetOAuthAuthorizationCodeSession(arg0 context.Context, arg1 sqlc.SetOAuthAuthorizationCodeSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOAuthAuthorizationCodeSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOAuthAuthorizationCodeSession indicates an expected call of SetOAuthAuthorizationCodeSession.
+func (mr *MockStoreMockRecorder) SetOAuthAuthorizationCodeSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOAuthAuthorizationCodeSession", reflect.TypeOf((*MockStore)(nil).SetOAuthAuthorizationCodeSession), arg0, arg1)
+}
+
+// TouchApiKey mocks base method.
+func (m *MockStore) TouchApiKey(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchApiKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchApiKey indicates an expected call of TouchApiKey.
+func (mr *MockStoreMockRecorder) TouchApiKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchApiKey", reflect.TypeOf((*MockStore)(nil).TouchApiKey), arg0, arg1)
+}
+
+// UpdateEmailVerificationRequest mocks base method.
+func (m *MockStore) UpdateEmailVerificationRequest(arg0 context.Context, arg1 sqlc.UpdateEmailVerificationRequestParams) (sqlc.EmailVerificationRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmailVerificationRequest", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.EmailVerificationRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmailVerificationRequest indicates an expected call of UpdateEmailVerificationRequest.
+func (mr *MockStoreMockRecorder) UpdateEmailVerificationRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmailVerificationRequest", reflect.TypeOf((*MockStore)(nil).UpdateEmailVerificationRequest), arg0, arg1)
+}
+
+// UpdateFeatureFlag mocks base method.
+func (m *MockStore) UpdateFeatureFlag(arg0 context.Context, arg1 sqlc.UpdateFeatureFlagParams) (sqlc.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFeatureFlag", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFeatureFlag indicates an expected call of UpdateFeatureFlag.
+func (mr *MockStoreMockRecorder) UpdateFeatureFlag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFeatureFlag", reflect.TypeOf((*MockStore)(nil).UpdateFeatureFlag), arg0, arg1)
+}
+
+// UpdateImgUserProfile mocks base method.
+func (m *MockStore) UpdateImgUserProfile(arg0 context.Context, arg1 sqlc.UpdateImgUserProfileParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateImgUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateImgUserProfile indicates an expected call of UpdateImgUserProfile.
+func (mr *MockStoreMockRecorder) UpdateImgUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateImgUserProfile", reflect.TypeOf((*MockStore)(nil).UpdateImgUserProfile), arg0, arg1)
+}
+
+// UpdatePasswordResetRequest mocks base method.
+func (m *MockStore) UpdatePasswordResetRequest(arg0 context.Context, arg1 sqlc.UpdatePasswordResetRequestParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordResetRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordResetRequest indicates an expected call of UpdatePasswordResetRequest.
+func (mr *MockStoreMockRecorder) UpdatePasswordResetRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordResetRequest", reflect.TypeOf((*MockStore)(nil).UpdatePasswordResetRequest), arg0, arg1)
+}
+
+// UpdatePasswordResetRequestByToken mocks base method.
+func (m *MockStore) UpdatePasswordResetRequestByToken(arg0 context.Context, arg1 sqlc.UpdatePasswordResetRequestByTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordResetRequestByToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordResetRequestByToken indicates an expected call of UpdatePasswordResetRequestByToken.
+func (mr *MockStoreMockRecorder) UpdatePasswordResetRequestByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordResetRequestByToken", reflect.TypeOf((*MockStore)(nil).UpdatePasswordResetRequestByToken), arg0, arg1)
+}
+
+// UpdateUser mocks base method.
+func (m *MockStore) UpdateUser(arg0 context.Context, arg1 sqlc.UpdateUserParams) (sqlc.Authentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Authentication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
+}
+
+// UpdateUserLogin mocks base method.
+func (m *MockStore) UpdateUserLogin(arg0 context.Context, arg1 sqlc.UpdateUserLoginParams) (sqlc.UserLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserLogin", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.UserLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserLogin indicates an expected call of UpdateUserLogin.
+func (mr *MockStoreMockRecorder) UpdateUserLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserLogin", reflect.TypeOf((*MockStore)(nil).UpdateUserLogin), arg0, arg1)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 sqlc.UpdateUserPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpdateUserPasswordByEmail mocks base method.
+func (m *MockStore) UpdateUserPasswordByEmail(arg0 context.Context, arg1 sqlc.UpdateUserPasswordByEmailParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPasswordByEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPasswordByEmail indicates an expected call of UpdateUserPasswordByEmail.
+func (mr *MockStoreMockRecorder) UpdateUserPasswordByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordByEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserPasswordByEmail), arg0, arg1)
+}
+
+// UpsertCartItem mocks base method.
+func (m *MockStore) UpsertCartItem(arg0 context.Context, arg1 sqlc.UpsertCartItemParams) (sqlc.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCartItem", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertCartItem indicates an expected call of UpsertCartItem.
+func (mr *MockStoreMockRecorder) UpsertCartItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCartItem", reflect.TypeOf((*MockStore)(nil).UpsertCartItem), arg0, arg1)
+}
+
+// UpsertOAuthConsent mocks base method.
+func (m *MockStore) UpsertOAuthConsent(arg0 context.Context, arg1 sqlc.UpsertOAuthConsentParams) (sqlc.OauthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OauthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOAuthConsent indicates an expected call of UpsertOAuthConsent.
+func (mr *MockStoreMockRecorder) UpsertOAuthConsent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOAuthConsent", reflect.TypeOf((*MockStore)(nil).UpsertOAuthConsent), arg0, arg1)
 }
@@ -0,0 +1,50 @@
+-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (id, name, description, role_id, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetServiceAccount :one
+SELECT * FROM service_accounts WHERE id = $1 LIMIT 1;
+
+-- name: ListServiceAccounts :many
+SELECT * FROM service_accounts ORDER BY created_at DESC;
+
+-- name: DisableServiceAccount :exec
+UPDATE service_accounts SET disabled_at = now() WHERE id = $1 AND disabled_at IS NULL;
+
+-- name: CreateApiKey :one
+INSERT INTO api_keys (id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, expires_at, rotated_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetApiKeyByID :one
+SELECT * FROM api_keys WHERE id = $1 LIMIT 1;
+
+-- name: GetApiKeyForAuth :one
+-- A user holding several roles authenticates with the lowest role ID, the most privileged one.
+SELECT k.*, sa.role_id AS service_account_role_id, sa.disabled_at AS service_account_disabled_at,
+  ur.role_id AS user_role_id, a.is_suspended AS user_suspended, a.is_deleted AS user_deleted
+FROM api_keys k
+LEFT JOIN service_accounts sa ON k.service_account_id = sa.id
+LEFT JOIN user_roles ur ON ur.id = (
+  SELECT id FROM user_roles WHERE user_id = k.user_id ORDER BY role_id, id LIMIT 1
+)
+LEFT JOIN authentications a ON k.user_id = a.id
+WHERE k.prefix = $1
+LIMIT 1;
+
+-- name: ListUserApiKeys :many
+SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: ListServiceAccountApiKeys :many
+SELECT * FROM api_keys WHERE service_account_id = $1 ORDER BY created_at DESC;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeServiceAccountApiKeys :exec
+UPDATE api_keys SET revoked_at = now() WHERE service_account_id = $1 AND revoked_at IS NULL;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
@@ -0,0 +1,12 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (user_id, actor_id, action, metadata, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetAuditLogsByUserID :many
+SELECT /* replica */ * FROM audit_logs
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ImportAuditLogs :copyfrom
+INSERT INTO audit_logs (user_id, actor_id, action, metadata, ip_address, user_agent, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
@@ -0,0 +1,23 @@
+-- name: GetCartItems :many
+SELECT /* replica */ * FROM cart_items WHERE owner_id = $1 ORDER BY created_at;
+
+-- name: UpsertCartItem :one
+INSERT INTO cart_items (owner_id, item_id, quantity, notes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (owner_id, item_id) DO UPDATE
+SET quantity = EXCLUDED.quantity, notes = EXCLUDED.notes, updated_at = now()
+RETURNING *;
+
+-- name: DeleteCartItem :exec
+DELETE FROM cart_items WHERE owner_id = $1 AND item_id = $2;
+
+-- name: ClearCart :exec
+DELETE FROM cart_items WHERE owner_id = $1;
+
+-- name: MergeCart :exec
+INSERT INTO cart_items (owner_id, item_id, quantity, notes)
+SELECT sqlc.arg('to_owner')::uuid, c.item_id, c.quantity, c.notes
+FROM cart_items c
+WHERE c.owner_id = sqlc.arg('from_owner')::uuid
+ON CONFLICT (owner_id, item_id) DO UPDATE
+SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = now();
@@ -0,0 +1,48 @@
+-- name: CreatePolicyDocument :one
+INSERT INTO policy_documents (kind, version, url, summary, published_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetCurrentPolicyDocuments :many
+-- Read from the primary, the result is cached for every instance and a lagging replica would miss a new version
+SELECT DISTINCT ON (kind) * FROM policy_documents
+WHERE published_at <= now()
+ORDER BY kind, published_at DESC;
+
+-- name: GetPolicyDocumentByVersion :one
+SELECT * FROM policy_documents WHERE kind = $1 AND version = $2 LIMIT 1;
+
+-- name: ListPolicyDocuments :many
+SELECT /* replica */ * FROM policy_documents ORDER BY kind, published_at DESC;
+
+-- name: CreateUserConsent :exec
+INSERT INTO user_consents (user_id, document_id, ip_address, user_agent)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, document_id) DO NOTHING;
+
+-- name: GetUserConsents :many
+SELECT uc.*, pd.kind, pd.version
+FROM user_consents uc
+JOIN policy_documents pd ON uc.document_id = pd.id
+WHERE uc.user_id = $1
+ORDER BY uc.accepted_at DESC;
+
+-- name: CountMissingConsents :one
+SELECT COUNT(*) FROM policy_documents pd
+WHERE pd.id = ANY(sqlc.arg('document_ids')::int[])
+AND NOT EXISTS (
+  SELECT 1 FROM user_consents uc WHERE uc.document_id = pd.id AND uc.user_id = sqlc.arg('user_id')
+);
+
+-- name: GetConsentAcceptanceReport :many
+SELECT /* replica */
+  pd.id,
+  pd.kind,
+  pd.version,
+  pd.published_at,
+  COUNT(uc.id) AS accepted_count,
+  (SELECT COUNT(*) FROM authentications a WHERE a.is_deleted IS NOT TRUE) AS total_users
+FROM policy_documents pd
+LEFT JOIN user_consents uc ON uc.document_id = pd.id
+GROUP BY pd.id
+ORDER BY pd.kind, pd.published_at DESC;
@@ -0,0 +1,56 @@
+-- name: CreateDataExportRequest :one
+INSERT INTO data_export_requests (id, user_id)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetDataExportRequestByID :one
+SELECT * FROM data_export_requests WHERE id = $1 LIMIT 1;
+
+-- name: GetDataExportRequestByTokenHash :one
+SELECT * FROM data_export_requests WHERE download_token_hash = $1 LIMIT 1;
+
+-- name: GetLatestDataExportRequest :one
+SELECT * FROM data_export_requests
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: MarkDataExportProcessing :exec
+UPDATE data_export_requests SET status = 'processing' WHERE id = $1;
+
+-- name: CompleteDataExportRequest :exec
+UPDATE data_export_requests
+SET
+  status = 'completed',
+  storage_key = $2,
+  download_token_hash = $3,
+  expires_at = $4,
+  completed_at = now()
+WHERE id = $1;
+
+-- name: FailDataExportRequest :exec
+-- Clears the download token in case the request was completed but its link couldn't be sent
+UPDATE data_export_requests
+SET status = 'failed', error = $2, download_token_hash = NULL, expires_at = NULL
+WHERE id = $1;
+
+-- name: MarkDataExportDownloaded :exec
+UPDATE data_export_requests SET downloaded_at = now() WHERE id = $1;
+
+-- name: GetUserRolesByUserID :many
+SELECT ur.role_id, r.name
+FROM user_roles ur
+JOIN roles r ON ur.role_id = r.id
+WHERE ur.user_id = $1;
+
+-- name: GetUserProfileByUserID :one
+SELECT * FROM users WHERE user_id = $1 LIMIT 1;
+
+-- name: GetEmailVerificationRequestsByUserID :many
+SELECT * FROM email_verification_requests WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetPasswordResetRequestsByUserID :many
+SELECT * FROM password_reset_requests WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetAccountRecoveryRequestsByUserID :many
+SELECT * FROM account_recovery_requests WHERE user_id = $1 ORDER BY requested_at;
@@ -0,0 +1,20 @@
+-- name: CreateFeatureFlag :one
+INSERT INTO feature_flags (key, description, kind, enabled, variants, off_variant, rules, default_rule, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+RETURNING *;
+
+-- name: GetFeatureFlag :one
+SELECT * FROM feature_flags WHERE key = $1 LIMIT 1;
+
+-- name: ListFeatureFlags :many
+SELECT /* replica */ * FROM feature_flags ORDER BY key;
+
+-- name: UpdateFeatureFlag :one
+UPDATE feature_flags
+SET description = $3, enabled = $4, variants = $5, off_variant = $6, rules = $7, default_rule = $8,
+  updated_by = $9, version = version + 1, updated_at = now()
+WHERE key = $1 AND version = $2
+RETURNING *;
+
+-- name: DeleteFeatureFlag :execrows
+DELETE FROM feature_flags WHERE key = $1;
@@ -0,0 +1,22 @@
+-- name: CreateGuestSession :one
+INSERT INTO guest_sessions (id, refresh_token, refresh_token_exp, device_id, platform, user_agent, ip_address)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetGuestSessionByID :one
+SELECT * FROM guest_sessions WHERE id = $1 LIMIT 1;
+
+-- name: GetGuestSessionByRefreshToken :one
+SELECT * FROM guest_sessions WHERE refresh_token = $1 LIMIT 1;
+
+-- name: RotateGuestSessionToken :exec
+UPDATE guest_sessions
+SET
+  refresh_token = $2,
+  refresh_token_exp = $3,
+  last_active_at = now()
+WHERE id = $1;
+
+-- name: RetireGuestSession :exec
+UPDATE guest_sessions SET retired_at = now(), upgraded_to = $2 WHERE id = $1;
+
@@ -0,0 +1,17 @@
+-- name: CreateImpersonationSession :one
+INSERT INTO impersonation_sessions (id, impersonator_id, target_user_id, reason, ip_address, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetImpersonationSession :one
+SELECT * FROM impersonation_sessions WHERE id = $1 LIMIT 1;
+
+-- name: EndImpersonationSession :execrows
+UPDATE impersonation_sessions
+SET ended_at = now(), ended_by = $2
+WHERE id = $1 AND ended_at IS NULL;
+
+-- name: ListActiveImpersonationSessions :many
+SELECT * FROM impersonation_sessions
+WHERE ended_at IS NULL AND expires_at > now()
+ORDER BY started_at DESC;
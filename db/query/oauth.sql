@@ -0,0 +1,60 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id, secret_hash, name, logo_url, redirect_uris, allowed_scopes, is_confidential, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients WHERE id = $1 LIMIT 1;
+
+-- name: ListOAuthClients :many
+SELECT * FROM oauth_clients ORDER BY created_at DESC;
+
+-- name: DisableOAuthClient :execrows
+UPDATE oauth_clients SET disabled_at = now() WHERE id = $1 AND disabled_at IS NULL;
+
+-- name: CreateOAuthAuthorizationCode :exec
+INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, code_challenge_method, auth_time, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ConsumeOAuthAuthorizationCode :one
+-- A code presented by another client, with another redirect URI or once expired stays unused
+UPDATE oauth_authorization_codes SET used_at = now()
+WHERE code_hash = $1 AND client_id = $2 AND redirect_uri = $3 AND used_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: GetOAuthAuthorizationCode :one
+SELECT * FROM oauth_authorization_codes WHERE code_hash = $1 LIMIT 1;
+
+-- name: SetOAuthAuthorizationCodeSession :exec
+UPDATE oauth_authorization_codes SET session_id = $2 WHERE code_hash = $1;
+
+-- name: DeleteExpiredOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE expires_at < now() - interval '1 day';
+
+-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (user_id, client_id, scopes)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, client_id) DO UPDATE
+SET scopes = EXCLUDED.scopes, granted_at = now(), revoked_at = NULL
+RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT * FROM oauth_consents WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL LIMIT 1;
+
+-- name: ListUserOAuthConsents :many
+SELECT oc.*, c.name AS client_name, c.logo_url AS client_logo_url
+FROM oauth_consents oc
+JOIN oauth_clients c ON oc.client_id = c.id
+WHERE oc.user_id = $1 AND oc.revoked_at IS NULL
+ORDER BY oc.granted_at DESC;
+
+-- name: RevokeOAuthConsent :execrows
+UPDATE oauth_consents SET revoked_at = now() WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL;
+
+-- name: CreateClientSession :one
+INSERT INTO sessions (id, user_id, refresh_token, refresh_token_exp, user_agent, ip_address, client_id, scopes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: RevokeClientSessions :exec
+UPDATE sessions SET invalidated_at = now() WHERE user_id = $1 AND client_id = $2 AND invalidated_at IS NULL;
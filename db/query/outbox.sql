@@ -0,0 +1,29 @@
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (aggregate, kind, topic, destination, payload, options, trace, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ClaimOutboxMessages :many
+-- Locks the oldest message due of each aggregate. Relays running at once skip each other's messages, and a later
+-- message of an aggregate waits until the earlier ones were delivered or failed.
+SELECT * FROM outbox o
+WHERE o.delivered_at IS NULL AND o.failed_at IS NULL AND o.available_at <= now()
+  AND NOT EXISTS (
+    SELECT 1 FROM outbox earlier
+    WHERE earlier.aggregate = o.aggregate AND earlier.id < o.id
+      AND earlier.delivered_at IS NULL AND earlier.failed_at IS NULL
+  )
+ORDER BY o.id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxMessageDelivered :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = NULL, delivered_at = now() WHERE id = $1;
+
+-- name: RetryOutboxMessage :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = $2, available_at = $3 WHERE id = $1;
+
+-- name: FailOutboxMessage :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = $2, failed_at = now() WHERE id = $1;
+
+-- name: DeleteDeliveredOutboxMessages :execrows
+DELETE FROM outbox WHERE delivered_at < $1;
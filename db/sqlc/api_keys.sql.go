@@ -0,0 +1,353 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: api_keys.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createApiKey = `-- name: CreateApiKey :one
+INSERT INTO api_keys (id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, expires_at, rotated_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, created_at, expires_at, last_used_at, revoked_at, rotated_from
+`
+
+type CreateApiKeyParams struct {
+	ID               uuid.UUID     `json:"id"`
+	Name             string        `json:"name"`
+	Prefix           string        `json:"prefix"`
+	KeyHash          string        `json:"key_hash"`
+	Scopes           []string      `json:"scopes"`
+	UserID           uuid.NullUUID `json:"user_id"`
+	ServiceAccountID uuid.NullUUID `json:"service_account_id"`
+	CreatedBy        uuid.UUID     `json:"created_by"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	RotatedFrom      uuid.NullUUID `json:"rotated_from"`
+}
+
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createApiKey,
+		arg.ID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scopes,
+		arg.UserID,
+		arg.ServiceAccountID,
+		arg.CreatedBy,
+		arg.ExpiresAt,
+		arg.RotatedFrom,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.UserID,
+		&i.ServiceAccountID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+	)
+	return i, err
+}
+
+const createServiceAccount = `-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (id, name, description, role_id, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, description, role_id, created_by, created_at, disabled_at
+`
+
+type CreateServiceAccountParams struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	RoleID      int32       `json:"role_id"`
+	CreatedBy   uuid.UUID   `json:"created_by"`
+}
+
+func (q *Queries) CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error) {
+	row := q.db.QueryRow(ctx, createServiceAccount,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.RoleID,
+		arg.CreatedBy,
+	)
+	var i ServiceAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.RoleID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const disableServiceAccount = `-- name: DisableServiceAccount :exec
+UPDATE service_accounts SET disabled_at = now() WHERE id = $1 AND disabled_at IS NULL
+`
+
+func (q *Queries) DisableServiceAccount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, disableServiceAccount, id)
+	return err
+}
+
+const getApiKeyByID = `-- name: GetApiKeyByID :one
+SELECT id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, created_at, expires_at, last_used_at, revoked_at, rotated_from FROM api_keys WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetApiKeyByID(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getApiKeyByID, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.UserID,
+		&i.ServiceAccountID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+	)
+	return i, err
+}
+
+const getApiKeyForAuth = `-- name: GetApiKeyForAuth :one
+SELECT k.id, k.name, k.prefix, k.key_hash, k.scopes, k.user_id, k.service_account_id, k.created_by, k.created_at, k.expires_at, k.last_used_at, k.revoked_at, k.rotated_from, sa.role_id AS service_account_role_id, sa.disabled_at AS service_account_disabled_at,
+  ur.role_id AS user_role_id, a.is_suspended AS user_suspended, a.is_deleted AS user_deleted
+FROM api_keys k
+LEFT JOIN service_accounts sa ON k.service_account_id = sa.id
+LEFT JOIN user_roles ur ON ur.id = (
+  SELECT id FROM user_roles WHERE user_id = k.user_id ORDER BY role_id, id LIMIT 1
+)
+LEFT JOIN authentications a ON k.user_id = a.id
+WHERE k.prefix = $1
+LIMIT 1
+`
+
+type GetApiKeyForAuthRow struct {
+	ID                       uuid.UUID          `json:"id"`
+	Name                     string             `json:"name"`
+	Prefix                   string             `json:"prefix"`
+	KeyHash                  string             `json:"key_hash"`
+	Scopes                   []string           `json:"scopes"`
+	UserID                   uuid.NullUUID      `json:"user_id"`
+	ServiceAccountID         uuid.NullUUID      `json:"service_account_id"`
+	CreatedBy                uuid.UUID          `json:"created_by"`
+	CreatedAt                time.Time          `json:"created_at"`
+	ExpiresAt                time.Time          `json:"expires_at"`
+	LastUsedAt               pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt                pgtype.Timestamptz `json:"revoked_at"`
+	RotatedFrom              uuid.NullUUID      `json:"rotated_from"`
+	ServiceAccountRoleID     pgtype.Int4        `json:"service_account_role_id"`
+	ServiceAccountDisabledAt pgtype.Timestamptz `json:"service_account_disabled_at"`
+	UserRoleID               pgtype.Int4        `json:"user_role_id"`
+	UserSuspended            pgtype.Bool        `json:"user_suspended"`
+	UserDeleted              pgtype.Bool        `json:"user_deleted"`
+}
+
+// A user holding several roles authenticates with the lowest role ID, the most privileged one.
+func (q *Queries) GetApiKeyForAuth(ctx context.Context, prefix string) (GetApiKeyForAuthRow, error) {
+	row := q.db.QueryRow(ctx, getApiKeyForAuth, prefix)
+	var i GetApiKeyForAuthRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.UserID,
+		&i.ServiceAccountID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.ServiceAccountRoleID,
+		&i.ServiceAccountDisabledAt,
+		&i.UserRoleID,
+		&i.UserSuspended,
+		&i.UserDeleted,
+	)
+	return i, err
+}
+
+const getServiceAccount = `-- name: GetServiceAccount :one
+SELECT id, name, description, role_id, created_by, created_at, disabled_at FROM service_accounts WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetServiceAccount(ctx context.Context, id uuid.UUID) (ServiceAccount, error) {
+	row := q.db.QueryRow(ctx, getServiceAccount, id)
+	var i ServiceAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.RoleID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const listServiceAccountApiKeys = `-- name: ListServiceAccountApiKeys :many
+SELECT id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, created_at, expires_at, last_used_at, revoked_at, rotated_from FROM api_keys WHERE service_account_id = $1 ORDER BY created_at DESC
+`
+
+func (q *Queries) ListServiceAccountApiKeys(ctx context.Context, serviceAccountID uuid.NullUUID) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, listServiceAccountApiKeys, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.UserID,
+			&i.ServiceAccountID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.RotatedFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccounts = `-- name: ListServiceAccounts :many
+SELECT id, name, description, role_id, created_by, created_at, disabled_at FROM service_accounts ORDER BY created_at DESC
+`
+
+func (q *Queries) ListServiceAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	rows, err := q.db.Query(ctx, listServiceAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ServiceAccount{}
+	for rows.Next() {
+		var i ServiceAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.RoleID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserApiKeys = `-- name: ListUserApiKeys :many
+SELECT id, name, prefix, key_hash, scopes, user_id, service_account_id, created_by, created_at, expires_at, last_used_at, revoked_at, rotated_from FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC
+`
+
+func (q *Queries) ListUserApiKeys(ctx context.Context, userID uuid.NullUUID) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, listUserApiKeys, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.UserID,
+			&i.ServiceAccountID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.RotatedFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :execrows
+UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeApiKey(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeApiKey, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeServiceAccountApiKeys = `-- name: RevokeServiceAccountApiKeys :exec
+UPDATE api_keys SET revoked_at = now() WHERE service_account_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeServiceAccountApiKeys(ctx context.Context, serviceAccountID uuid.NullUUID) error {
+	_, err := q.db.Exec(ctx, revokeServiceAccountApiKeys, serviceAccountID)
+	return err
+}
+
+const touchApiKey = `-- name: TouchApiKey :exec
+UPDATE api_keys SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+`
+
+func (q *Queries) TouchApiKey(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchApiKey, id)
+	return err
+}
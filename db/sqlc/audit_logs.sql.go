@@ -0,0 +1,86 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: audit_logs.sql
+
+package sqlc
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (user_id, actor_id, action, metadata, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateAuditLogParams struct {
+	UserID    uuid.NullUUID `json:"user_id"`
+	ActorID   uuid.NullUUID `json:"actor_id"`
+	Action    string        `json:"action"`
+	Metadata  []byte        `json:"metadata"`
+	IpAddress *netip.Addr   `json:"ip_address"`
+	UserAgent pgtype.Text   `json:"user_agent"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.Exec(ctx, createAuditLog,
+		arg.UserID,
+		arg.ActorID,
+		arg.Action,
+		arg.Metadata,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
+	return err
+}
+
+const getAuditLogsByUserID = `-- name: GetAuditLogsByUserID :many
+SELECT /* replica */ id, user_id, actor_id, action, metadata, ip_address, user_agent, created_at FROM audit_logs
+WHERE user_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetAuditLogsByUserID(ctx context.Context, userID uuid.NullUUID) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, getAuditLogsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ActorID,
+			&i.Action,
+			&i.Metadata,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type ImportAuditLogsParams struct {
+	UserID    uuid.NullUUID `json:"user_id"`
+	ActorID   uuid.NullUUID `json:"actor_id"`
+	Action    string        `json:"action"`
+	Metadata  []byte        `json:"metadata"`
+	IpAddress *netip.Addr   `json:"ip_address"`
+	UserAgent pgtype.Text   `json:"user_agent"`
+	CreatedAt time.Time     `json:"created_at"`
+}
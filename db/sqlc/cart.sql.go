@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: cart.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clearCart = `-- name: ClearCart :exec
+DELETE FROM cart_items WHERE owner_id = $1
+`
+
+func (q *Queries) ClearCart(ctx context.Context, ownerID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, clearCart, ownerID)
+	return err
+}
+
+const deleteCartItem = `-- name: DeleteCartItem :exec
+DELETE FROM cart_items WHERE owner_id = $1 AND item_id = $2
+`
+
+type DeleteCartItemParams struct {
+	OwnerID uuid.UUID `json:"owner_id"`
+	ItemID  string    `json:"item_id"`
+}
+
+func (q *Queries) DeleteCartItem(ctx context.Context, arg DeleteCartItemParams) error {
+	_, err := q.db.Exec(ctx, deleteCartItem, arg.OwnerID, arg.ItemID)
+	return err
+}
+
+const getCartItems = `-- name: GetCartItems :many
+SELECT /* replica */ id, owner_id, item_id, quantity, notes, created_at, updated_at FROM cart_items WHERE owner_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetCartItems(ctx context.Context, ownerID uuid.UUID) ([]CartItem, error) {
+	rows, err := q.db.Query(ctx, getCartItems, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CartItem{}
+	for rows.Next() {
+		var i CartItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.ItemID,
+			&i.Quantity,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeCart = `-- name: MergeCart :exec
+INSERT INTO cart_items (owner_id, item_id, quantity, notes)
+SELECT $1::uuid, c.item_id, c.quantity, c.notes
+FROM cart_items c
+WHERE c.owner_id = $2::uuid
+ON CONFLICT (owner_id, item_id) DO UPDATE
+SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = now()
+`
+
+type MergeCartParams struct {
+	ToOwner   uuid.UUID `json:"to_owner"`
+	FromOwner uuid.UUID `json:"from_owner"`
+}
+
+func (q *Queries) MergeCart(ctx context.Context, arg MergeCartParams) error {
+	_, err := q.db.Exec(ctx, mergeCart, arg.ToOwner, arg.FromOwner)
+	return err
+}
+
+const upsertCartItem = `-- name: UpsertCartItem :one
+INSERT INTO cart_items (owner_id, item_id, quantity, notes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (owner_id, item_id) DO UPDATE
+SET quantity = EXCLUDED.quantity, notes = EXCLUDED.notes, updated_at = now()
+RETURNING id, owner_id, item_id, quantity, notes, created_at, updated_at
+`
+
+type UpsertCartItemParams struct {
+	OwnerID  uuid.UUID   `json:"owner_id"`
+	ItemID   string      `json:"item_id"`
+	Quantity int32       `json:"quantity"`
+	Notes    pgtype.Text `json:"notes"`
+}
+
+func (q *Queries) UpsertCartItem(ctx context.Context, arg UpsertCartItemParams) (CartItem, error) {
+	row := q.db.QueryRow(ctx, upsertCartItem,
+		arg.OwnerID,
+		arg.ItemID,
+		arg.Quantity,
+		arg.Notes,
+	)
+	var i CartItem
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.ItemID,
+		&i.Quantity,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
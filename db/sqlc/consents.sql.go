@@ -0,0 +1,288 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: consents.sql
+
+package sqlc
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countMissingConsents = `-- name: CountMissingConsents :one
+SELECT COUNT(*) FROM policy_documents pd
+WHERE pd.id = ANY($1::int[])
+AND NOT EXISTS (
+  SELECT 1 FROM user_consents uc WHERE uc.document_id = pd.id AND uc.user_id = $2
+)
+`
+
+type CountMissingConsentsParams struct {
+	DocumentIds []int32   `json:"document_ids"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) CountMissingConsents(ctx context.Context, arg CountMissingConsentsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countMissingConsents, arg.DocumentIds, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPolicyDocument = `-- name: CreatePolicyDocument :one
+INSERT INTO policy_documents (kind, version, url, summary, published_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, kind, version, url, summary, published_at, created_by, created_at
+`
+
+type CreatePolicyDocumentParams struct {
+	Kind        string        `json:"kind"`
+	Version     string        `json:"version"`
+	Url         string        `json:"url"`
+	Summary     pgtype.Text   `json:"summary"`
+	PublishedAt time.Time     `json:"published_at"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+}
+
+func (q *Queries) CreatePolicyDocument(ctx context.Context, arg CreatePolicyDocumentParams) (PolicyDocument, error) {
+	row := q.db.QueryRow(ctx, createPolicyDocument,
+		arg.Kind,
+		arg.Version,
+		arg.Url,
+		arg.Summary,
+		arg.PublishedAt,
+		arg.CreatedBy,
+	)
+	var i PolicyDocument
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Version,
+		&i.Url,
+		&i.Summary,
+		&i.PublishedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserConsent = `-- name: CreateUserConsent :exec
+INSERT INTO user_consents (user_id, document_id, ip_address, user_agent)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, document_id) DO NOTHING
+`
+
+type CreateUserConsentParams struct {
+	UserID     uuid.UUID   `json:"user_id"`
+	DocumentID int32       `json:"document_id"`
+	IpAddress  *netip.Addr `json:"ip_address"`
+	UserAgent  pgtype.Text `json:"user_agent"`
+}
+
+func (q *Queries) CreateUserConsent(ctx context.Context, arg CreateUserConsentParams) error {
+	_, err := q.db.Exec(ctx, createUserConsent,
+		arg.UserID,
+		arg.DocumentID,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
+	return err
+}
+
+const getConsentAcceptanceReport = `-- name: GetConsentAcceptanceReport :many
+SELECT /* replica */
+  pd.id,
+  pd.kind,
+  pd.version,
+  pd.published_at,
+  COUNT(uc.id) AS accepted_count,
+  (SELECT COUNT(*) FROM authentications a WHERE a.is_deleted IS NOT TRUE) AS total_users
+FROM policy_documents pd
+LEFT JOIN user_consents uc ON uc.document_id = pd.id
+GROUP BY pd.id
+ORDER BY pd.kind, pd.published_at DESC
+`
+
+type GetConsentAcceptanceReportRow struct {
+	ID            int32     `json:"id"`
+	Kind          string    `json:"kind"`
+	Version       string    `json:"version"`
+	PublishedAt   time.Time `json:"published_at"`
+	AcceptedCount int64     `json:"accepted_count"`
+	TotalUsers    int64     `json:"total_users"`
+}
+
+func (q *Queries) GetConsentAcceptanceReport(ctx context.Context) ([]GetConsentAcceptanceReportRow, error) {
+	rows, err := q.db.Query(ctx, getConsentAcceptanceReport)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetConsentAcceptanceReportRow{}
+	for rows.Next() {
+		var i GetConsentAcceptanceReportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Version,
+			&i.PublishedAt,
+			&i.AcceptedCount,
+			&i.TotalUsers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCurrentPolicyDocuments = `-- name: GetCurrentPolicyDocuments :many
+SELECT DISTINCT ON (kind) id, kind, version, url, summary, published_at, created_by, created_at FROM policy_documents
+WHERE published_at <= now()
+ORDER BY kind, published_at DESC
+`
+
+// Read from the primary, the result is cached for every instance and a lagging replica would miss a new version
+func (q *Queries) GetCurrentPolicyDocuments(ctx context.Context) ([]PolicyDocument, error) {
+	rows, err := q.db.Query(ctx, getCurrentPolicyDocuments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PolicyDocument{}
+	for rows.Next() {
+		var i PolicyDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Version,
+			&i.Url,
+			&i.Summary,
+			&i.PublishedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPolicyDocumentByVersion = `-- name: GetPolicyDocumentByVersion :one
+SELECT id, kind, version, url, summary, published_at, created_by, created_at FROM policy_documents WHERE kind = $1 AND version = $2 LIMIT 1
+`
+
+type GetPolicyDocumentByVersionParams struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+func (q *Queries) GetPolicyDocumentByVersion(ctx context.Context, arg GetPolicyDocumentByVersionParams) (PolicyDocument, error) {
+	row := q.db.QueryRow(ctx, getPolicyDocumentByVersion, arg.Kind, arg.Version)
+	var i PolicyDocument
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Version,
+		&i.Url,
+		&i.Summary,
+		&i.PublishedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserConsents = `-- name: GetUserConsents :many
+SELECT uc.id, uc.user_id, uc.document_id, uc.accepted_at, uc.ip_address, uc.user_agent, pd.kind, pd.version
+FROM user_consents uc
+JOIN policy_documents pd ON uc.document_id = pd.id
+WHERE uc.user_id = $1
+ORDER BY uc.accepted_at DESC
+`
+
+type GetUserConsentsRow struct {
+	ID         int64       `json:"id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	DocumentID int32       `json:"document_id"`
+	AcceptedAt time.Time   `json:"accepted_at"`
+	IpAddress  *netip.Addr `json:"ip_address"`
+	UserAgent  pgtype.Text `json:"user_agent"`
+	Kind       string      `json:"kind"`
+	Version    string      `json:"version"`
+}
+
+func (q *Queries) GetUserConsents(ctx context.Context, userID uuid.UUID) ([]GetUserConsentsRow, error) {
+	rows, err := q.db.Query(ctx, getUserConsents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserConsentsRow{}
+	for rows.Next() {
+		var i GetUserConsentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.DocumentID,
+			&i.AcceptedAt,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Kind,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPolicyDocuments = `-- name: ListPolicyDocuments :many
+SELECT /* replica */ id, kind, version, url, summary, published_at, created_by, created_at FROM policy_documents ORDER BY kind, published_at DESC
+`
+
+func (q *Queries) ListPolicyDocuments(ctx context.Context) ([]PolicyDocument, error) {
+	rows, err := q.db.Query(ctx, listPolicyDocuments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PolicyDocument{}
+	for rows.Next() {
+		var i PolicyDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Version,
+			&i.Url,
+			&i.Summary,
+			&i.PublishedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
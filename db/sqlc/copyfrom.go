@@ -0,0 +1,48 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: copyfrom.go
+
+package sqlc
+
+import (
+	"context"
+)
+
+// iteratorForImportAuditLogs implements pgx.CopyFromSource.
+type iteratorForImportAuditLogs struct {
+	rows                 []ImportAuditLogsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForImportAuditLogs) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForImportAuditLogs) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].UserID,
+		r.rows[0].ActorID,
+		r.rows[0].Action,
+		r.rows[0].Metadata,
+		r.rows[0].IpAddress,
+		r.rows[0].UserAgent,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForImportAuditLogs) Err() error {
+	return nil
+}
+
+func (q *Queries) ImportAuditLogs(ctx context.Context, arg []ImportAuditLogsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"audit_logs"}, []string{"user_id", "actor_id", "action", "metadata", "ip_address", "user_agent", "created_at"}, &iteratorForImportAuditLogs{rows: arg})
+}
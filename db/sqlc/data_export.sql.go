@@ -0,0 +1,320 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: data_export.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const completeDataExportRequest = `-- name: CompleteDataExportRequest :exec
+UPDATE data_export_requests
+SET
+  status = 'completed',
+  storage_key = $2,
+  download_token_hash = $3,
+  expires_at = $4,
+  completed_at = now()
+WHERE id = $1
+`
+
+type CompleteDataExportRequestParams struct {
+	ID                uuid.UUID          `json:"id"`
+	StorageKey        pgtype.Text        `json:"storage_key"`
+	DownloadTokenHash pgtype.Text        `json:"download_token_hash"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) CompleteDataExportRequest(ctx context.Context, arg CompleteDataExportRequestParams) error {
+	_, err := q.db.Exec(ctx, completeDataExportRequest,
+		arg.ID,
+		arg.StorageKey,
+		arg.DownloadTokenHash,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createDataExportRequest = `-- name: CreateDataExportRequest :one
+INSERT INTO data_export_requests (id, user_id)
+VALUES ($1, $2)
+RETURNING id, user_id, status, storage_key, download_token_hash, error, created_at, completed_at, expires_at, downloaded_at
+`
+
+type CreateDataExportRequestParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) CreateDataExportRequest(ctx context.Context, arg CreateDataExportRequestParams) (DataExportRequest, error) {
+	row := q.db.QueryRow(ctx, createDataExportRequest, arg.ID, arg.UserID)
+	var i DataExportRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.StorageKey,
+		&i.DownloadTokenHash,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+	)
+	return i, err
+}
+
+const failDataExportRequest = `-- name: FailDataExportRequest :exec
+UPDATE data_export_requests
+SET status = 'failed', error = $2, download_token_hash = NULL, expires_at = NULL
+WHERE id = $1
+`
+
+type FailDataExportRequestParams struct {
+	ID    uuid.UUID   `json:"id"`
+	Error pgtype.Text `json:"error"`
+}
+
+// Clears the download token in case the request was completed but its link couldn't be sent
+func (q *Queries) FailDataExportRequest(ctx context.Context, arg FailDataExportRequestParams) error {
+	_, err := q.db.Exec(ctx, failDataExportRequest, arg.ID, arg.Error)
+	return err
+}
+
+const getAccountRecoveryRequestsByUserID = `-- name: GetAccountRecoveryRequestsByUserID :many
+SELECT id, user_id, email, used, recovery_token, requested_at, expires_at, completed_at FROM account_recovery_requests WHERE user_id = $1 ORDER BY requested_at
+`
+
+func (q *Queries) GetAccountRecoveryRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]AccountRecoveryRequest, error) {
+	rows, err := q.db.Query(ctx, getAccountRecoveryRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountRecoveryRequest{}
+	for rows.Next() {
+		var i AccountRecoveryRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Used,
+			&i.RecoveryToken,
+			&i.RequestedAt,
+			&i.ExpiresAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDataExportRequestByID = `-- name: GetDataExportRequestByID :one
+SELECT id, user_id, status, storage_key, download_token_hash, error, created_at, completed_at, expires_at, downloaded_at FROM data_export_requests WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetDataExportRequestByID(ctx context.Context, id uuid.UUID) (DataExportRequest, error) {
+	row := q.db.QueryRow(ctx, getDataExportRequestByID, id)
+	var i DataExportRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.StorageKey,
+		&i.DownloadTokenHash,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+	)
+	return i, err
+}
+
+const getDataExportRequestByTokenHash = `-- name: GetDataExportRequestByTokenHash :one
+SELECT id, user_id, status, storage_key, download_token_hash, error, created_at, completed_at, expires_at, downloaded_at FROM data_export_requests WHERE download_token_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetDataExportRequestByTokenHash(ctx context.Context, downloadTokenHash pgtype.Text) (DataExportRequest, error) {
+	row := q.db.QueryRow(ctx, getDataExportRequestByTokenHash, downloadTokenHash)
+	var i DataExportRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.StorageKey,
+		&i.DownloadTokenHash,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+	)
+	return i, err
+}
+
+const getEmailVerificationRequestsByUserID = `-- name: GetEmailVerificationRequestsByUserID :many
+SELECT id, user_id, email, token, is_verified, created_at, expires_at FROM email_verification_requests WHERE user_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetEmailVerificationRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]EmailVerificationRequest, error) {
+	rows, err := q.db.Query(ctx, getEmailVerificationRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []EmailVerificationRequest{}
+	for rows.Next() {
+		var i EmailVerificationRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Token,
+			&i.IsVerified,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestDataExportRequest = `-- name: GetLatestDataExportRequest :one
+SELECT id, user_id, status, storage_key, download_token_hash, error, created_at, completed_at, expires_at, downloaded_at FROM data_export_requests
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestDataExportRequest(ctx context.Context, userID uuid.UUID) (DataExportRequest, error) {
+	row := q.db.QueryRow(ctx, getLatestDataExportRequest, userID)
+	var i DataExportRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.StorageKey,
+		&i.DownloadTokenHash,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+	)
+	return i, err
+}
+
+const getPasswordResetRequestsByUserID = `-- name: GetPasswordResetRequestsByUserID :many
+SELECT id, user_id, email, token, used, created_at, expires_at FROM password_reset_requests WHERE user_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetPasswordResetRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]PasswordResetRequest, error) {
+	rows, err := q.db.Query(ctx, getPasswordResetRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PasswordResetRequest{}
+	for rows.Next() {
+		var i PasswordResetRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Token,
+			&i.Used,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserProfileByUserID = `-- name: GetUserProfileByUserID :one
+SELECT id, user_id, first_name, last_name, image_url FROM users WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserProfileByUserID(ctx context.Context, userID uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUserProfileByUserID, userID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FirstName,
+		&i.LastName,
+		&i.ImageUrl,
+	)
+	return i, err
+}
+
+const getUserRolesByUserID = `-- name: GetUserRolesByUserID :many
+SELECT ur.role_id, r.name
+FROM user_roles ur
+JOIN roles r ON ur.role_id = r.id
+WHERE ur.user_id = $1
+`
+
+type GetUserRolesByUserIDRow struct {
+	RoleID int32  `json:"role_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) GetUserRolesByUserID(ctx context.Context, userID uuid.UUID) ([]GetUserRolesByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, getUserRolesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserRolesByUserIDRow{}
+	for rows.Next() {
+		var i GetUserRolesByUserIDRow
+		if err := rows.Scan(&i.RoleID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDataExportDownloaded = `-- name: MarkDataExportDownloaded :exec
+UPDATE data_export_requests SET downloaded_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkDataExportDownloaded(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markDataExportDownloaded, id)
+	return err
+}
+
+const markDataExportProcessing = `-- name: MarkDataExportProcessing :exec
+UPDATE data_export_requests SET status = 'processing' WHERE id = $1
+`
+
+func (q *Queries) MarkDataExportProcessing(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markDataExportProcessing, id)
+	return err
+}
@@ -2,56 +2,179 @@ package sqlc
 
 import (
 	"context"
-	"database/sql"
-
-	"github.com/rs/zerolog/log"
-
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
 	"github.com/steve-mir/bukka_backend/utils"
 )
 
-func CreatePgxPool(config utils.Config) (*pgxpool.Pool, error) {
-	connPool, err := pgxpool.New(context.Background(), config.DBSource)
+const defaultStatementCacheCapacity = 512
+
+// queryExecModes maps DB_QUERY_EXEC_MODE to pgx, use exec or simple_protocol behind a transaction pooling PgBouncer
+// that cannot keep prepared statements.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"":                pgx.QueryExecModeCacheStatement,
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// NewPoolConfig builds the pgx pool configuration from DB_SOURCE and the DB_* pool settings.
+func NewPoolConfig(config utils.Config) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.DBSource)
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot connect to db")
-		return nil, err
+		return nil, fmt.Errorf("invalid DB_SOURCE: %w", err)
+	}
+
+	if config.DBMaxOpenConn > 0 {
+		poolConfig.MaxConns = int32(config.DBMaxOpenConn)
+	}
+	if config.DBMaxIdleConn > 0 {
+		// pgxpool closes idle connections down to MinConns, so keep the old idle setting as the floor
+		poolConfig.MinConns = int32(config.DBMaxIdleConn)
+		if poolConfig.MinConns > poolConfig.MaxConns {
+			poolConfig.MinConns = poolConfig.MaxConns
+		}
+	}
+	if config.DBMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = time.Duration(config.DBMaxIdleTime) * time.Second
+	}
+	if config.DBMaxLifeTime > 0 {
+		poolConfig.MaxConnLifetime = time.Duration(config.DBMaxLifeTime) * time.Second
 	}
 
-	return connPool, err
+	mode, ok := queryExecModes[config.DBQueryExecMode]
+	if !ok {
+		return nil, fmt.Errorf("unknown DB_QUERY_EXEC_MODE %q", config.DBQueryExecMode)
+	}
+	poolConfig.ConnConfig.DefaultQueryExecMode = mode
+
+	capacity := config.DBStatementCacheCapacity
+	if capacity <= 0 {
+		capacity = defaultStatementCacheCapacity
+	}
+	poolConfig.ConnConfig.StatementCacheCapacity = capacity
+	poolConfig.ConnConfig.DescriptionCacheCapacity = capacity
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	return poolConfig, nil
 }
 
-func CreateDbPool(config utils.Config) (*sql.DB, error) {
+// CreateDbPool connects to Postgres and checks the connection before returning the pool.
+func CreateDbPool(config utils.Config) (*pgxpool.Pool, error) {
 	log.Info().Msg("CreateDbPool Initiated: ")
-	db, err := sql.Open(config.DBDriver, config.DBSource)
+	poolConfig, err := NewPoolConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	connPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Cannot connect to db")
+		return nil, fmt.Errorf("cannot create db pool: %w", err)
 	}
-	// defer db.Close()
 
-	DB_MAX_IDLE_CONN := config.DBMaxIdleConn
-	DB_MAX_OPEN_CONN := config.DBMaxOpenConn
-	DB_MAX_IDLE_TIME := config.DBMaxIdleTime
-	DB_MAX_LIFE_TIME := config.DBMaxLifeTime
+	if err := connPool.Ping(ctx); err != nil {
+		connPool.Close()
+		return nil, fmt.Errorf("cannot connect to db: %w", err)
+	}
 
-	db.SetMaxIdleConns(DB_MAX_IDLE_CONN)
-	db.SetMaxOpenConns(DB_MAX_OPEN_CONN)
-	db.SetConnMaxIdleTime(time.Duration(DB_MAX_IDLE_TIME) * time.Second)
-	db.SetConnMaxLifetime(time.Duration(DB_MAX_LIFE_TIME) * time.Second)
+	log.Info().Msgf("@CreateDbPool POSTGRES_SQL MAX Open Connections: %v", poolConfig.MaxConns)
+	return connPool, nil
+}
 
-	log.Info().Msgf("@CreateDbPool POSTGRES_SQL MAX Open Connections: %v", db.Stats().MaxOpenConnections)
+// CreateReplicaPools opens a pool for every DSN in DB_REPLICA_SOURCES with the same pool settings as the primary.
+// Replicas are not pinged, one that is down at startup is skipped by the router until it comes back.
+func CreateReplicaPools(config utils.Config) ([]*pgxpool.Pool, error) {
+	var pools []*pgxpool.Pool
+	for _, source := range strings.Split(config.DBReplicaSources, ",") {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
 
-	// This is for analyzing the stats after setting a connection
+		replicaConfig := config
+		replicaConfig.DBSource = source
+		poolConfig, err := NewPoolConfig(replicaConfig)
+		if err != nil {
+			closePools(pools)
+			return nil, fmt.Errorf("invalid DB_REPLICA_SOURCES: %w", err)
+		}
 
-	log.Info().Msgf("@CreateDbPool SQL Open Connections: %v", db.Stats().OpenConnections)
-	log.Info().Msgf("@CreateDbPool SQL InUse Connections: %v", db.Stats().InUse)
-	log.Info().Msgf("@CreateDbPool SQL Idle Connections: %v", db.Stats().Idle)
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			closePools(pools)
+			return nil, fmt.Errorf("cannot create replica pool: %w", err)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
 
-	if err != nil {
-		log.Fatal().Err(err).Msg("CreateDbPool Error: ")
-		return nil, err
+func closePools(pools []*pgxpool.Pool) {
+	for _, pool := range pools {
+		pool.Close()
 	}
+}
 
-	return db, err
+// PoolStats is a snapshot of the pool counters.
+type PoolStats struct {
+	TotalConns           int32         `json:"total_conns"`
+	AcquiredConns        int32         `json:"acquired_conns"`
+	IdleConns            int32         `json:"idle_conns"`
+	MaxConns             int32         `json:"max_conns"`
+	AcquireCount         int64         `json:"acquire_count"`
+	AcquireDuration      time.Duration `json:"acquire_duration"`
+	EmptyAcquireCount    int64         `json:"empty_acquire_count"`
+	CanceledAcquireCount int64         `json:"canceled_acquire_count"`
+	NewConnsCount        int64         `json:"new_conns_count"`
+}
+
+func NewPoolStats(connPool *pgxpool.Pool) PoolStats {
+	stat := connPool.Stat()
+	return PoolStats{
+		TotalConns:           stat.TotalConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		NewConnsCount:        stat.NewConnsCount(),
+	}
+}
+
+// ReportPoolStats logs the pool counters every interval until ctx is done. A growing empty acquire count means
+// requests waited for a free connection and DB_MAX_OPEN_CONN is too low.
+func ReportPoolStats(ctx context.Context, connPool *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := NewPoolStats(connPool)
+			log.Info().
+				Int32("total_conns", stats.TotalConns).
+				Int32("acquired_conns", stats.AcquiredConns).
+				Int32("idle_conns", stats.IdleConns).
+				Int32("max_conns", stats.MaxConns).
+				Int64("acquire_count", stats.AcquireCount).
+				Dur("acquire_duration", stats.AcquireDuration).
+				Int64("empty_acquire_count", stats.EmptyAcquireCount).
+				Int64("canceled_acquire_count", stats.CanceledAcquireCount).
+				Msg("db pool stats")
+		}
+	}
 }
@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: feature_flags.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createFeatureFlag = `-- name: CreateFeatureFlag :one
+INSERT INTO feature_flags (key, description, kind, enabled, variants, off_variant, rules, default_rule, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+RETURNING key, description, kind, enabled, variants, off_variant, rules, default_rule, version, created_by, updated_by, created_at, updated_at
+`
+
+type CreateFeatureFlagParams struct {
+	Key         string      `json:"key"`
+	Description pgtype.Text `json:"description"`
+	Kind        string      `json:"kind"`
+	Enabled     bool        `json:"enabled"`
+	Variants    []string    `json:"variants"`
+	OffVariant  string      `json:"off_variant"`
+	Rules       []byte      `json:"rules"`
+	DefaultRule []byte      `json:"default_rule"`
+	CreatedBy   uuid.UUID   `json:"created_by"`
+}
+
+func (q *Queries) CreateFeatureFlag(ctx context.Context, arg CreateFeatureFlagParams) (FeatureFlag, error) {
+	row := q.db.QueryRow(ctx, createFeatureFlag,
+		arg.Key,
+		arg.Description,
+		arg.Kind,
+		arg.Enabled,
+		arg.Variants,
+		arg.OffVariant,
+		arg.Rules,
+		arg.DefaultRule,
+		arg.CreatedBy,
+	)
+	var i FeatureFlag
+	err := row.Scan(
+		&i.Key,
+		&i.Description,
+		&i.Kind,
+		&i.Enabled,
+		&i.Variants,
+		&i.OffVariant,
+		&i.Rules,
+		&i.DefaultRule,
+		&i.Version,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteFeatureFlag = `-- name: DeleteFeatureFlag :execrows
+DELETE FROM feature_flags WHERE key = $1
+`
+
+func (q *Queries) DeleteFeatureFlag(ctx context.Context, key string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFeatureFlag, key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getFeatureFlag = `-- name: GetFeatureFlag :one
+SELECT key, description, kind, enabled, variants, off_variant, rules, default_rule, version, created_by, updated_by, created_at, updated_at FROM feature_flags WHERE key = $1 LIMIT 1
+`
+
+func (q *Queries) GetFeatureFlag(ctx context.Context, key string) (FeatureFlag, error) {
+	row := q.db.QueryRow(ctx, getFeatureFlag, key)
+	var i FeatureFlag
+	err := row.Scan(
+		&i.Key,
+		&i.Description,
+		&i.Kind,
+		&i.Enabled,
+		&i.Variants,
+		&i.OffVariant,
+		&i.Rules,
+		&i.DefaultRule,
+		&i.Version,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listFeatureFlags = `-- name: ListFeatureFlags :many
+SELECT /* replica */ key, description, kind, enabled, variants, off_variant, rules, default_rule, version, created_by, updated_by, created_at, updated_at FROM feature_flags ORDER BY key
+`
+
+func (q *Queries) ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error) {
+	rows, err := q.db.Query(ctx, listFeatureFlags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeatureFlag{}
+	for rows.Next() {
+		var i FeatureFlag
+		if err := rows.Scan(
+			&i.Key,
+			&i.Description,
+			&i.Kind,
+			&i.Enabled,
+			&i.Variants,
+			&i.OffVariant,
+			&i.Rules,
+			&i.DefaultRule,
+			&i.Version,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFeatureFlag = `-- name: UpdateFeatureFlag :one
+UPDATE feature_flags
+SET description = $3, enabled = $4, variants = $5, off_variant = $6, rules = $7, default_rule = $8,
+  updated_by = $9, version = version + 1, updated_at = now()
+WHERE key = $1 AND version = $2
+RETURNING key, description, kind, enabled, variants, off_variant, rules, default_rule, version, created_by, updated_by, created_at, updated_at
+`
+
+type UpdateFeatureFlagParams struct {
+	Key         string      `json:"key"`
+	Version     int32       `json:"version"`
+	Description pgtype.Text `json:"description"`
+	Enabled     bool        `json:"enabled"`
+	Variants    []string    `json:"variants"`
+	OffVariant  string      `json:"off_variant"`
+	Rules       []byte      `json:"rules"`
+	DefaultRule []byte      `json:"default_rule"`
+	UpdatedBy   uuid.UUID   `json:"updated_by"`
+}
+
+func (q *Queries) UpdateFeatureFlag(ctx context.Context, arg UpdateFeatureFlagParams) (FeatureFlag, error) {
+	row := q.db.QueryRow(ctx, updateFeatureFlag,
+		arg.Key,
+		arg.Version,
+		arg.Description,
+		arg.Enabled,
+		arg.Variants,
+		arg.OffVariant,
+		arg.Rules,
+		arg.DefaultRule,
+		arg.UpdatedBy,
+	)
+	var i FeatureFlag
+	err := row.Scan(
+		&i.Key,
+		&i.Description,
+		&i.Kind,
+		&i.Enabled,
+		&i.Variants,
+		&i.OffVariant,
+		&i.Rules,
+		&i.DefaultRule,
+		&i.Version,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
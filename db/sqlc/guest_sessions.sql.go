@@ -0,0 +1,138 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: guest_sessions.sql
+
+package sqlc
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createGuestSession = `-- name: CreateGuestSession :one
+INSERT INTO guest_sessions (id, refresh_token, refresh_token_exp, device_id, platform, user_agent, ip_address)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, refresh_token, refresh_token_exp, device_id, platform, user_agent, ip_address, created_at, last_active_at, retired_at, upgraded_to
+`
+
+type CreateGuestSessionParams struct {
+	ID              uuid.UUID   `json:"id"`
+	RefreshToken    string      `json:"refresh_token"`
+	RefreshTokenExp time.Time   `json:"refresh_token_exp"`
+	DeviceID        pgtype.Text `json:"device_id"`
+	Platform        pgtype.Text `json:"platform"`
+	UserAgent       string      `json:"user_agent"`
+	IpAddress       netip.Addr  `json:"ip_address"`
+}
+
+func (q *Queries) CreateGuestSession(ctx context.Context, arg CreateGuestSessionParams) (GuestSession, error) {
+	row := q.db.QueryRow(ctx, createGuestSession,
+		arg.ID,
+		arg.RefreshToken,
+		arg.RefreshTokenExp,
+		arg.DeviceID,
+		arg.Platform,
+		arg.UserAgent,
+		arg.IpAddress,
+	)
+	var i GuestSession
+	err := row.Scan(
+		&i.ID,
+		&i.RefreshToken,
+		&i.RefreshTokenExp,
+		&i.DeviceID,
+		&i.Platform,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.LastActiveAt,
+		&i.RetiredAt,
+		&i.UpgradedTo,
+	)
+	return i, err
+}
+
+const getGuestSessionByID = `-- name: GetGuestSessionByID :one
+SELECT id, refresh_token, refresh_token_exp, device_id, platform, user_agent, ip_address, created_at, last_active_at, retired_at, upgraded_to FROM guest_sessions WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetGuestSessionByID(ctx context.Context, id uuid.UUID) (GuestSession, error) {
+	row := q.db.QueryRow(ctx, getGuestSessionByID, id)
+	var i GuestSession
+	err := row.Scan(
+		&i.ID,
+		&i.RefreshToken,
+		&i.RefreshTokenExp,
+		&i.DeviceID,
+		&i.Platform,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.LastActiveAt,
+		&i.RetiredAt,
+		&i.UpgradedTo,
+	)
+	return i, err
+}
+
+const getGuestSessionByRefreshToken = `-- name: GetGuestSessionByRefreshToken :one
+SELECT id, refresh_token, refresh_token_exp, device_id, platform, user_agent, ip_address, created_at, last_active_at, retired_at, upgraded_to FROM guest_sessions WHERE refresh_token = $1 LIMIT 1
+`
+
+func (q *Queries) GetGuestSessionByRefreshToken(ctx context.Context, refreshToken string) (GuestSession, error) {
+	row := q.db.QueryRow(ctx, getGuestSessionByRefreshToken, refreshToken)
+	var i GuestSession
+	err := row.Scan(
+		&i.ID,
+		&i.RefreshToken,
+		&i.RefreshTokenExp,
+		&i.DeviceID,
+		&i.Platform,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.LastActiveAt,
+		&i.RetiredAt,
+		&i.UpgradedTo,
+	)
+	return i, err
+}
+
+const retireGuestSession = `-- name: RetireGuestSession :exec
+UPDATE guest_sessions SET retired_at = now(), upgraded_to = $2 WHERE id = $1
+`
+
+type RetireGuestSessionParams struct {
+	ID         uuid.UUID     `json:"id"`
+	UpgradedTo uuid.NullUUID `json:"upgraded_to"`
+}
+
+func (q *Queries) RetireGuestSession(ctx context.Context, arg RetireGuestSessionParams) error {
+	_, err := q.db.Exec(ctx, retireGuestSession, arg.ID, arg.UpgradedTo)
+	return err
+}
+
+const rotateGuestSessionToken = `-- name: RotateGuestSessionToken :exec
+UPDATE guest_sessions
+SET
+  refresh_token = $2,
+  refresh_token_exp = $3,
+  last_active_at = now()
+WHERE id = $1
+`
+
+type RotateGuestSessionTokenParams struct {
+	ID              uuid.UUID `json:"id"`
+	RefreshToken    string    `json:"refresh_token"`
+	RefreshTokenExp time.Time `json:"refresh_token_exp"`
+}
+
+func (q *Queries) RotateGuestSessionToken(ctx context.Context, arg RotateGuestSessionTokenParams) error {
+	_, err := q.db.Exec(ctx, rotateGuestSessionToken, arg.ID, arg.RefreshToken, arg.RefreshTokenExp)
+	return err
+}
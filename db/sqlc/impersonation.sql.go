@@ -0,0 +1,135 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: impersonation.sql
+
+package sqlc
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createImpersonationSession = `-- name: CreateImpersonationSession :one
+INSERT INTO impersonation_sessions (id, impersonator_id, target_user_id, reason, ip_address, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, impersonator_id, target_user_id, reason, ip_address, user_agent, started_at, expires_at, ended_at, ended_by
+`
+
+type CreateImpersonationSessionParams struct {
+	ID             uuid.UUID   `json:"id"`
+	ImpersonatorID uuid.UUID   `json:"impersonator_id"`
+	TargetUserID   uuid.UUID   `json:"target_user_id"`
+	Reason         string      `json:"reason"`
+	IpAddress      *netip.Addr `json:"ip_address"`
+	UserAgent      pgtype.Text `json:"user_agent"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+}
+
+func (q *Queries) CreateImpersonationSession(ctx context.Context, arg CreateImpersonationSessionParams) (ImpersonationSession, error) {
+	row := q.db.QueryRow(ctx, createImpersonationSession,
+		arg.ID,
+		arg.ImpersonatorID,
+		arg.TargetUserID,
+		arg.Reason,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.ExpiresAt,
+	)
+	var i ImpersonationSession
+	err := row.Scan(
+		&i.ID,
+		&i.ImpersonatorID,
+		&i.TargetUserID,
+		&i.Reason,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.StartedAt,
+		&i.ExpiresAt,
+		&i.EndedAt,
+		&i.EndedBy,
+	)
+	return i, err
+}
+
+const endImpersonationSession = `-- name: EndImpersonationSession :execrows
+UPDATE impersonation_sessions
+SET ended_at = now(), ended_by = $2
+WHERE id = $1 AND ended_at IS NULL
+`
+
+type EndImpersonationSessionParams struct {
+	ID      uuid.UUID     `json:"id"`
+	EndedBy uuid.NullUUID `json:"ended_by"`
+}
+
+func (q *Queries) EndImpersonationSession(ctx context.Context, arg EndImpersonationSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, endImpersonationSession, arg.ID, arg.EndedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getImpersonationSession = `-- name: GetImpersonationSession :one
+SELECT id, impersonator_id, target_user_id, reason, ip_address, user_agent, started_at, expires_at, ended_at, ended_by FROM impersonation_sessions WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetImpersonationSession(ctx context.Context, id uuid.UUID) (ImpersonationSession, error) {
+	row := q.db.QueryRow(ctx, getImpersonationSession, id)
+	var i ImpersonationSession
+	err := row.Scan(
+		&i.ID,
+		&i.ImpersonatorID,
+		&i.TargetUserID,
+		&i.Reason,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.StartedAt,
+		&i.ExpiresAt,
+		&i.EndedAt,
+		&i.EndedBy,
+	)
+	return i, err
+}
+
+const listActiveImpersonationSessions = `-- name: ListActiveImpersonationSessions :many
+SELECT id, impersonator_id, target_user_id, reason, ip_address, user_agent, started_at, expires_at, ended_at, ended_by FROM impersonation_sessions
+WHERE ended_at IS NULL AND expires_at > now()
+ORDER BY started_at DESC
+`
+
+func (q *Queries) ListActiveImpersonationSessions(ctx context.Context) ([]ImpersonationSession, error) {
+	rows, err := q.db.Query(ctx, listActiveImpersonationSessions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ImpersonationSession{}
+	for rows.Next() {
+		var i ImpersonationSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImpersonatorID,
+			&i.TargetUserID,
+			&i.Reason,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.StartedAt,
+			&i.ExpiresAt,
+			&i.EndedAt,
+			&i.EndedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
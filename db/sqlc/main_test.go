@@ -1,11 +1,11 @@
 package sqlc
 
 import (
+	"context"
 	"os"
 	"testing"
 
-	// "github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"github.com/steve-mir/bukka_backend/utils"
 )
@@ -18,13 +18,18 @@ func TestMain(m *testing.M) {
 		log.Fatal().Msg("cannot load config " + err.Error())
 	}
 
-	// connPool, err := pgxpool.New(context.Background(), config.DBSource)
-	db, err := CreateDbPool(config)
+	poolConfig, err := NewPoolConfig(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid db config")
+	}
+
+	// The pool connects lazily, so tests that do not touch the database run without one
+	connPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot connect to db")
 	}
 
-	testStore = NewStore(db)
+	testStore = NewStore(connPool)
 
 	os.Exit(m.Run())
 }
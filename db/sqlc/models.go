@@ -5,65 +5,225 @@
 package sqlc
 
 import (
-	"database/sql"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sqlc-dev/pqtype"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type AccountRecoveryRequest struct {
-	ID            int32        `json:"id"`
-	UserID        uuid.UUID    `json:"user_id"`
-	Email         string       `json:"email"`
-	Used          sql.NullBool `json:"used"`
-	RecoveryToken string       `json:"recovery_token"`
-	RequestedAt   sql.NullTime `json:"requested_at"`
-	ExpiresAt     time.Time    `json:"expires_at"`
-	CompletedAt   sql.NullTime `json:"completed_at"`
+	ID            int32              `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	Email         string             `json:"email"`
+	Used          pgtype.Bool        `json:"used"`
+	RecoveryToken string             `json:"recovery_token"`
+	RequestedAt   pgtype.Timestamptz `json:"requested_at"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+}
+
+type ApiKey struct {
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	Prefix           string             `json:"prefix"`
+	KeyHash          string             `json:"key_hash"`
+	Scopes           []string           `json:"scopes"`
+	UserID           uuid.NullUUID      `json:"user_id"`
+	ServiceAccountID uuid.NullUUID      `json:"service_account_id"`
+	CreatedBy        uuid.UUID          `json:"created_by"`
+	CreatedAt        time.Time          `json:"created_at"`
+	ExpiresAt        time.Time          `json:"expires_at"`
+	LastUsedAt       pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt        pgtype.Timestamptz `json:"revoked_at"`
+	RotatedFrom      uuid.NullUUID      `json:"rotated_from"`
+}
+
+type AuditLog struct {
+	ID        int64         `json:"id"`
+	UserID    uuid.NullUUID `json:"user_id"`
+	ActorID   uuid.NullUUID `json:"actor_id"`
+	Action    string        `json:"action"`
+	Metadata  []byte        `json:"metadata"`
+	IpAddress *netip.Addr   `json:"ip_address"`
+	UserAgent pgtype.Text   `json:"user_agent"`
+	CreatedAt time.Time     `json:"created_at"`
 }
 
 type Authentication struct {
-	ID                  uuid.UUID      `json:"id"`
-	Email               string         `json:"email"`
-	Phone               sql.NullString `json:"phone"`
-	Username            sql.NullString `json:"username"`
-	PasswordHash        string         `json:"password_hash"`
-	CreatedAt           sql.NullTime   `json:"created_at"`
-	UpdatedAt           sql.NullTime   `json:"updated_at"`
-	IsSuspended         sql.NullBool   `json:"is_suspended"`
-	IsDeleted           sql.NullBool   `json:"is_deleted"`
-	IsVerified          sql.NullBool   `json:"is_verified"`
-	IsEmailVerified     sql.NullBool   `json:"is_email_verified"`
-	DeletedAt           sql.NullTime   `json:"deleted_at"`
-	VerifiedAt          sql.NullTime   `json:"verified_at"`
-	SuspendedAt         sql.NullTime   `json:"suspended_at"`
-	LoginAttempts       sql.NullInt32  `json:"login_attempts"`
-	PasswordLastChanged sql.NullTime   `json:"password_last_changed"`
-	LockoutDuration     sql.NullInt32  `json:"lockout_duration"`
-	LockoutUntil        sql.NullTime   `json:"lockout_until"`
-	IsMfaEnabled        sql.NullBool   `json:"is_mfa_enabled"`
-	IsOauthUser         sql.NullBool   `json:"is_oauth_user"`
+	ID                  uuid.UUID          `json:"id"`
+	Email               string             `json:"email"`
+	Phone               pgtype.Text        `json:"phone"`
+	Username            pgtype.Text        `json:"username"`
+	PasswordHash        string             `json:"password_hash"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	IsSuspended         pgtype.Bool        `json:"is_suspended"`
+	IsDeleted           pgtype.Bool        `json:"is_deleted"`
+	IsVerified          pgtype.Bool        `json:"is_verified"`
+	IsEmailVerified     pgtype.Bool        `json:"is_email_verified"`
+	DeletedAt           pgtype.Timestamptz `json:"deleted_at"`
+	VerifiedAt          pgtype.Timestamptz `json:"verified_at"`
+	SuspendedAt         pgtype.Timestamptz `json:"suspended_at"`
+	LoginAttempts       pgtype.Int4        `json:"login_attempts"`
+	PasswordLastChanged pgtype.Timestamptz `json:"password_last_changed"`
+	LockoutDuration     pgtype.Int4        `json:"lockout_duration"`
+	LockoutUntil        pgtype.Timestamptz `json:"lockout_until"`
+	IsMfaEnabled        pgtype.Bool        `json:"is_mfa_enabled"`
+	IsOauthUser         pgtype.Bool        `json:"is_oauth_user"`
+}
+
+type CartItem struct {
+	ID        int64       `json:"id"`
+	OwnerID   uuid.UUID   `json:"owner_id"`
+	ItemID    string      `json:"item_id"`
+	Quantity  int32       `json:"quantity"`
+	Notes     pgtype.Text `json:"notes"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type DataExportRequest struct {
+	ID                uuid.UUID          `json:"id"`
+	UserID            uuid.UUID          `json:"user_id"`
+	Status            string             `json:"status"`
+	StorageKey        pgtype.Text        `json:"storage_key"`
+	DownloadTokenHash pgtype.Text        `json:"download_token_hash"`
+	Error             pgtype.Text        `json:"error"`
+	CreatedAt         time.Time          `json:"created_at"`
+	CompletedAt       pgtype.Timestamptz `json:"completed_at"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+	DownloadedAt      pgtype.Timestamptz `json:"downloaded_at"`
 }
 
 type EmailVerificationRequest struct {
-	ID         int64        `json:"id"`
-	UserID     uuid.UUID    `json:"user_id"`
-	Email      string       `json:"email"`
-	Token      string       `json:"token"`
-	IsVerified sql.NullBool `json:"is_verified"`
-	CreatedAt  sql.NullTime `json:"created_at"`
-	ExpiresAt  time.Time    `json:"expires_at"`
+	ID         int64              `json:"id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	Email      string             `json:"email"`
+	Token      string             `json:"token"`
+	IsVerified pgtype.Bool        `json:"is_verified"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+}
+
+type FeatureFlag struct {
+	Key         string      `json:"key"`
+	Description pgtype.Text `json:"description"`
+	Kind        string      `json:"kind"`
+	Enabled     bool        `json:"enabled"`
+	Variants    []string    `json:"variants"`
+	OffVariant  string      `json:"off_variant"`
+	Rules       []byte      `json:"rules"`
+	DefaultRule []byte      `json:"default_rule"`
+	Version     int32       `json:"version"`
+	CreatedBy   uuid.UUID   `json:"created_by"`
+	UpdatedBy   uuid.UUID   `json:"updated_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type GuestSession struct {
+	ID              uuid.UUID          `json:"id"`
+	RefreshToken    string             `json:"refresh_token"`
+	RefreshTokenExp time.Time          `json:"refresh_token_exp"`
+	DeviceID        pgtype.Text        `json:"device_id"`
+	Platform        pgtype.Text        `json:"platform"`
+	UserAgent       string             `json:"user_agent"`
+	IpAddress       netip.Addr         `json:"ip_address"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	LastActiveAt    pgtype.Timestamptz `json:"last_active_at"`
+	RetiredAt       pgtype.Timestamptz `json:"retired_at"`
+	UpgradedTo      uuid.NullUUID      `json:"upgraded_to"`
+}
+
+type ImpersonationSession struct {
+	ID             uuid.UUID          `json:"id"`
+	ImpersonatorID uuid.UUID          `json:"impersonator_id"`
+	TargetUserID   uuid.UUID          `json:"target_user_id"`
+	Reason         string             `json:"reason"`
+	IpAddress      *netip.Addr        `json:"ip_address"`
+	UserAgent      pgtype.Text        `json:"user_agent"`
+	StartedAt      time.Time          `json:"started_at"`
+	ExpiresAt      time.Time          `json:"expires_at"`
+	EndedAt        pgtype.Timestamptz `json:"ended_at"`
+	EndedBy        uuid.NullUUID      `json:"ended_by"`
+}
+
+type OauthAuthorizationCode struct {
+	CodeHash            string             `json:"code_hash"`
+	ClientID            string             `json:"client_id"`
+	UserID              uuid.UUID          `json:"user_id"`
+	RedirectUri         string             `json:"redirect_uri"`
+	Scopes              []string           `json:"scopes"`
+	Nonce               pgtype.Text        `json:"nonce"`
+	CodeChallenge       string             `json:"code_challenge"`
+	CodeChallengeMethod string             `json:"code_challenge_method"`
+	AuthTime            time.Time          `json:"auth_time"`
+	ExpiresAt           time.Time          `json:"expires_at"`
+	UsedAt              pgtype.Timestamptz `json:"used_at"`
+	SessionID           uuid.NullUUID      `json:"session_id"`
+	CreatedAt           time.Time          `json:"created_at"`
+}
+
+type OauthClient struct {
+	ID             string             `json:"id"`
+	SecretHash     pgtype.Text        `json:"secret_hash"`
+	Name           string             `json:"name"`
+	LogoUrl        pgtype.Text        `json:"logo_url"`
+	RedirectUris   []string           `json:"redirect_uris"`
+	AllowedScopes  []string           `json:"allowed_scopes"`
+	IsConfidential bool               `json:"is_confidential"`
+	CreatedBy      uuid.UUID          `json:"created_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+	DisabledAt     pgtype.Timestamptz `json:"disabled_at"`
+}
+
+type OauthConsent struct {
+	ID        int64              `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	ClientID  string             `json:"client_id"`
+	Scopes    []string           `json:"scopes"`
+	GrantedAt time.Time          `json:"granted_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type Outbox struct {
+	ID          int64              `json:"id"`
+	Aggregate   string             `json:"aggregate"`
+	Kind        string             `json:"kind"`
+	Topic       string             `json:"topic"`
+	Destination string             `json:"destination"`
+	Payload     []byte             `json:"payload"`
+	Options     []byte             `json:"options"`
+	Trace       []byte             `json:"trace"`
+	RequestID   string             `json:"request_id"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	AvailableAt time.Time          `json:"available_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	DeliveredAt pgtype.Timestamptz `json:"delivered_at"`
+	FailedAt    pgtype.Timestamptz `json:"failed_at"`
 }
 
 type PasswordResetRequest struct {
-	ID        int32        `json:"id"`
-	UserID    uuid.UUID    `json:"user_id"`
-	Email     string       `json:"email"`
-	Token     string       `json:"token"`
-	Used      sql.NullBool `json:"used"`
-	CreatedAt sql.NullTime `json:"created_at"`
-	ExpiresAt time.Time    `json:"expires_at"`
+	ID        int32              `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Email     string             `json:"email"`
+	Token     string             `json:"token"`
+	Used      pgtype.Bool        `json:"used"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at"`
+}
+
+type PolicyDocument struct {
+	ID          int32         `json:"id"`
+	Kind        string        `json:"kind"`
+	Version     string        `json:"version"`
+	Url         string        `json:"url"`
+	Summary     pgtype.Text   `json:"summary"`
+	PublishedAt time.Time     `json:"published_at"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
 }
 
 type Role struct {
@@ -71,56 +231,77 @@ type Role struct {
 	Name string `json:"name"`
 }
 
+type ServiceAccount struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	RoleID      int32              `json:"role_id"`
+	CreatedBy   uuid.UUID          `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	DisabledAt  pgtype.Timestamptz `json:"disabled_at"`
+}
+
 type Session struct {
-	ID              uuid.UUID      `json:"id"`
-	UserID          uuid.UUID      `json:"user_id"`
-	RefreshToken    string         `json:"refresh_token"`
-	RefreshTokenExp time.Time      `json:"refresh_token_exp"`
-	CreatedAt       sql.NullTime   `json:"created_at"`
-	UpdatedAt       sql.NullTime   `json:"updated_at"`
-	InvalidatedAt   sql.NullTime   `json:"invalidated_at"`
-	LastActiveAt    sql.NullTime   `json:"last_active_at"`
-	BlockedAt       sql.NullTime   `json:"blocked_at"`
-	UserAgent       string         `json:"user_agent"`
-	IpAddress       pqtype.Inet    `json:"ip_address"`
-	FcmToken        sql.NullString `json:"fcm_token"`
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	RefreshToken    string             `json:"refresh_token"`
+	RefreshTokenExp time.Time          `json:"refresh_token_exp"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	InvalidatedAt   pgtype.Timestamptz `json:"invalidated_at"`
+	LastActiveAt    pgtype.Timestamptz `json:"last_active_at"`
+	BlockedAt       pgtype.Timestamptz `json:"blocked_at"`
+	UserAgent       string             `json:"user_agent"`
+	IpAddress       netip.Addr         `json:"ip_address"`
+	FcmToken        pgtype.Text        `json:"fcm_token"`
+	ClientID        pgtype.Text        `json:"client_id"`
+	Scopes          []string           `json:"scopes"`
 }
 
 type TwoFactorBackupCode struct {
-	ID     int32        `json:"id"`
-	UserID uuid.UUID    `json:"user_id"`
-	Code   string       `json:"code"`
-	Used   sql.NullBool `json:"used"`
+	ID     int32       `json:"id"`
+	UserID uuid.UUID   `json:"user_id"`
+	Code   string      `json:"code"`
+	Used   pgtype.Bool `json:"used"`
 }
 
 type TwoFactorRevocation struct {
-	ID               int32          `json:"id"`
-	UserID           uuid.UUID      `json:"user_id"`
-	RevokedAt        sql.NullTime   `json:"revoked_at"`
-	RevocationReason sql.NullString `json:"revocation_reason"`
+	ID               int32              `json:"id"`
+	UserID           uuid.UUID          `json:"user_id"`
+	RevokedAt        pgtype.Timestamptz `json:"revoked_at"`
+	RevocationReason pgtype.Text        `json:"revocation_reason"`
 }
 
 type TwoFactorSecret struct {
-	ID        int32        `json:"id"`
-	UserID    uuid.UUID    `json:"user_id"`
-	SecretKey string       `json:"secret_key"`
-	IsActive  sql.NullBool `json:"is_active"`
+	ID        int32       `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	SecretKey string      `json:"secret_key"`
+	IsActive  pgtype.Bool `json:"is_active"`
 }
 
 type User struct {
-	ID        int32          `json:"id"`
-	UserID    uuid.UUID      `json:"user_id"`
-	FirstName sql.NullString `json:"first_name"`
-	LastName  sql.NullString `json:"last_name"`
-	ImageUrl  sql.NullString `json:"image_url"`
+	ID        int32       `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	FirstName pgtype.Text `json:"first_name"`
+	LastName  pgtype.Text `json:"last_name"`
+	ImageUrl  pgtype.Text `json:"image_url"`
+}
+
+type UserConsent struct {
+	ID         int64       `json:"id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	DocumentID int32       `json:"document_id"`
+	AcceptedAt time.Time   `json:"accepted_at"`
+	IpAddress  *netip.Addr `json:"ip_address"`
+	UserAgent  pgtype.Text `json:"user_agent"`
 }
 
 type UserLogin struct {
-	ID        int32          `json:"id"`
-	UserID    uuid.UUID      `json:"user_id"`
-	LoginAt   sql.NullTime   `json:"login_at"`
-	IpAddress pqtype.Inet    `json:"ip_address"`
-	UserAgent sql.NullString `json:"user_agent"`
+	ID        int32              `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	LoginAt   pgtype.Timestamptz `json:"login_at"`
+	IpAddress *netip.Addr        `json:"ip_address"`
+	UserAgent pgtype.Text        `json:"user_agent"`
 }
 
 type UserRole struct {
@@ -0,0 +1,422 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: oauth.sql
+
+package sqlc
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const consumeOAuthAuthorizationCode = `-- name: ConsumeOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes SET used_at = now()
+WHERE code_hash = $1 AND client_id = $2 AND redirect_uri = $3 AND used_at IS NULL AND expires_at > now()
+RETURNING code_hash, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, code_challenge_method, auth_time, expires_at, used_at, session_id, created_at
+`
+
+type ConsumeOAuthAuthorizationCodeParams struct {
+	CodeHash    string `json:"code_hash"`
+	ClientID    string `json:"client_id"`
+	RedirectUri string `json:"redirect_uri"`
+}
+
+// A code presented by another client, with another redirect URI or once expired stays unused
+func (q *Queries) ConsumeOAuthAuthorizationCode(ctx context.Context, arg ConsumeOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error) {
+	row := q.db.QueryRow(ctx, consumeOAuthAuthorizationCode, arg.CodeHash, arg.ClientID, arg.RedirectUri)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.UserID,
+		&i.RedirectUri,
+		&i.Scopes,
+		&i.Nonce,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+		&i.AuthTime,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.SessionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createClientSession = `-- name: CreateClientSession :one
+INSERT INTO sessions (id, user_id, refresh_token, refresh_token_exp, user_agent, ip_address, client_id, scopes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token, client_id, scopes
+`
+
+type CreateClientSessionParams struct {
+	ID              uuid.UUID   `json:"id"`
+	UserID          uuid.UUID   `json:"user_id"`
+	RefreshToken    string      `json:"refresh_token"`
+	RefreshTokenExp time.Time   `json:"refresh_token_exp"`
+	UserAgent       string      `json:"user_agent"`
+	IpAddress       netip.Addr  `json:"ip_address"`
+	ClientID        pgtype.Text `json:"client_id"`
+	Scopes          []string    `json:"scopes"`
+}
+
+func (q *Queries) CreateClientSession(ctx context.Context, arg CreateClientSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, createClientSession,
+		arg.ID,
+		arg.UserID,
+		arg.RefreshToken,
+		arg.RefreshTokenExp,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.ClientID,
+		arg.Scopes,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.RefreshTokenExp,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InvalidatedAt,
+		&i.LastActiveAt,
+		&i.BlockedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.FcmToken,
+		&i.ClientID,
+		&i.Scopes,
+	)
+	return i, err
+}
+
+const createOAuthAuthorizationCode = `-- name: CreateOAuthAuthorizationCode :exec
+INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, code_challenge_method, auth_time, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type CreateOAuthAuthorizationCodeParams struct {
+	CodeHash            string      `json:"code_hash"`
+	ClientID            string      `json:"client_id"`
+	UserID              uuid.UUID   `json:"user_id"`
+	RedirectUri         string      `json:"redirect_uri"`
+	Scopes              []string    `json:"scopes"`
+	Nonce               pgtype.Text `json:"nonce"`
+	CodeChallenge       string      `json:"code_challenge"`
+	CodeChallengeMethod string      `json:"code_challenge_method"`
+	AuthTime            time.Time   `json:"auth_time"`
+	ExpiresAt           time.Time   `json:"expires_at"`
+}
+
+func (q *Queries) CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) error {
+	_, err := q.db.Exec(ctx, createOAuthAuthorizationCode,
+		arg.CodeHash,
+		arg.ClientID,
+		arg.UserID,
+		arg.RedirectUri,
+		arg.Scopes,
+		arg.Nonce,
+		arg.CodeChallenge,
+		arg.CodeChallengeMethod,
+		arg.AuthTime,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createOAuthClient = `-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id, secret_hash, name, logo_url, redirect_uris, allowed_scopes, is_confidential, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, secret_hash, name, logo_url, redirect_uris, allowed_scopes, is_confidential, created_by, created_at, disabled_at
+`
+
+type CreateOAuthClientParams struct {
+	ID             string      `json:"id"`
+	SecretHash     pgtype.Text `json:"secret_hash"`
+	Name           string      `json:"name"`
+	LogoUrl        pgtype.Text `json:"logo_url"`
+	RedirectUris   []string    `json:"redirect_uris"`
+	AllowedScopes  []string    `json:"allowed_scopes"`
+	IsConfidential bool        `json:"is_confidential"`
+	CreatedBy      uuid.UUID   `json:"created_by"`
+}
+
+func (q *Queries) CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error) {
+	row := q.db.QueryRow(ctx, createOAuthClient,
+		arg.ID,
+		arg.SecretHash,
+		arg.Name,
+		arg.LogoUrl,
+		arg.RedirectUris,
+		arg.AllowedScopes,
+		arg.IsConfidential,
+		arg.CreatedBy,
+	)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.SecretHash,
+		&i.Name,
+		&i.LogoUrl,
+		&i.RedirectUris,
+		&i.AllowedScopes,
+		&i.IsConfidential,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const deleteExpiredOAuthAuthorizationCodes = `-- name: DeleteExpiredOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE expires_at < now() - interval '1 day'
+`
+
+func (q *Queries) DeleteExpiredOAuthAuthorizationCodes(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteExpiredOAuthAuthorizationCodes)
+	return err
+}
+
+const disableOAuthClient = `-- name: DisableOAuthClient :execrows
+UPDATE oauth_clients SET disabled_at = now() WHERE id = $1 AND disabled_at IS NULL
+`
+
+func (q *Queries) DisableOAuthClient(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.Exec(ctx, disableOAuthClient, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOAuthAuthorizationCode = `-- name: GetOAuthAuthorizationCode :one
+SELECT code_hash, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, code_challenge_method, auth_time, expires_at, used_at, session_id, created_at FROM oauth_authorization_codes WHERE code_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error) {
+	row := q.db.QueryRow(ctx, getOAuthAuthorizationCode, codeHash)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.UserID,
+		&i.RedirectUri,
+		&i.Scopes,
+		&i.Nonce,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+		&i.AuthTime,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.SessionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthClient = `-- name: GetOAuthClient :one
+SELECT id, secret_hash, name, logo_url, redirect_uris, allowed_scopes, is_confidential, created_by, created_at, disabled_at FROM oauth_clients WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOAuthClient(ctx context.Context, id string) (OauthClient, error) {
+	row := q.db.QueryRow(ctx, getOAuthClient, id)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.SecretHash,
+		&i.Name,
+		&i.LogoUrl,
+		&i.RedirectUris,
+		&i.AllowedScopes,
+		&i.IsConfidential,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getOAuthConsent = `-- name: GetOAuthConsent :one
+SELECT id, user_id, client_id, scopes, granted_at, revoked_at FROM oauth_consents WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL LIMIT 1
+`
+
+type GetOAuthConsentParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ClientID string    `json:"client_id"`
+}
+
+func (q *Queries) GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OauthConsent, error) {
+	row := q.db.QueryRow(ctx, getOAuthConsent, arg.UserID, arg.ClientID)
+	var i OauthConsent
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ClientID,
+		&i.Scopes,
+		&i.GrantedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const listOAuthClients = `-- name: ListOAuthClients :many
+SELECT id, secret_hash, name, logo_url, redirect_uris, allowed_scopes, is_confidential, created_by, created_at, disabled_at FROM oauth_clients ORDER BY created_at DESC
+`
+
+func (q *Queries) ListOAuthClients(ctx context.Context) ([]OauthClient, error) {
+	rows, err := q.db.Query(ctx, listOAuthClients)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OauthClient{}
+	for rows.Next() {
+		var i OauthClient
+		if err := rows.Scan(
+			&i.ID,
+			&i.SecretHash,
+			&i.Name,
+			&i.LogoUrl,
+			&i.RedirectUris,
+			&i.AllowedScopes,
+			&i.IsConfidential,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserOAuthConsents = `-- name: ListUserOAuthConsents :many
+SELECT oc.id, oc.user_id, oc.client_id, oc.scopes, oc.granted_at, oc.revoked_at, c.name AS client_name, c.logo_url AS client_logo_url
+FROM oauth_consents oc
+JOIN oauth_clients c ON oc.client_id = c.id
+WHERE oc.user_id = $1 AND oc.revoked_at IS NULL
+ORDER BY oc.granted_at DESC
+`
+
+type ListUserOAuthConsentsRow struct {
+	ID            int64              `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	ClientID      string             `json:"client_id"`
+	Scopes        []string           `json:"scopes"`
+	GrantedAt     time.Time          `json:"granted_at"`
+	RevokedAt     pgtype.Timestamptz `json:"revoked_at"`
+	ClientName    string             `json:"client_name"`
+	ClientLogoUrl pgtype.Text        `json:"client_logo_url"`
+}
+
+func (q *Queries) ListUserOAuthConsents(ctx context.Context, userID uuid.UUID) ([]ListUserOAuthConsentsRow, error) {
+	rows, err := q.db.Query(ctx, listUserOAuthConsents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserOAuthConsentsRow{}
+	for rows.Next() {
+		var i ListUserOAuthConsentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ClientID,
+			&i.Scopes,
+			&i.GrantedAt,
+			&i.RevokedAt,
+			&i.ClientName,
+			&i.ClientLogoUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeClientSessions = `-- name: RevokeClientSessions :exec
+UPDATE sessions SET invalidated_at = now() WHERE user_id = $1 AND client_id = $2 AND invalidated_at IS NULL
+`
+
+type RevokeClientSessionsParams struct {
+	UserID   uuid.UUID   `json:"user_id"`
+	ClientID pgtype.Text `json:"client_id"`
+}
+
+func (q *Queries) RevokeClientSessions(ctx context.Context, arg RevokeClientSessionsParams) error {
+	_, err := q.db.Exec(ctx, revokeClientSessions, arg.UserID, arg.ClientID)
+	return err
+}
+
+const revokeOAuthConsent = `-- name: RevokeOAuthConsent :execrows
+UPDATE oauth_consents SET revoked_at = now() WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL
+`
+
+type RevokeOAuthConsentParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ClientID string    `json:"client_id"`
+}
+
+func (q *Queries) RevokeOAuthConsent(ctx context.Context, arg RevokeOAuthConsentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeOAuthConsent, arg.UserID, arg.ClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOAuthAuthorizationCodeSession = `-- name: SetOAuthAuthorizationCodeSession :exec
+UPDATE oauth_authorization_codes SET session_id = $2 WHERE code_hash = $1
+`
+
+type SetOAuthAuthorizationCodeSessionParams struct {
+	CodeHash  string        `json:"code_hash"`
+	SessionID uuid.NullUUID `json:"session_id"`
+}
+
+func (q *Queries) SetOAuthAuthorizationCodeSession(ctx context.Context, arg SetOAuthAuthorizationCodeSessionParams) error {
+	_, err := q.db.Exec(ctx, setOAuthAuthorizationCodeSession, arg.CodeHash, arg.SessionID)
+	return err
+}
+
+const upsertOAuthConsent = `-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (user_id, client_id, scopes)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, client_id) DO UPDATE
+SET scopes = EXCLUDED.scopes, granted_at = now(), revoked_at = NULL
+RETURNING id, user_id, client_id, scopes, granted_at, revoked_at
+`
+
+type UpsertOAuthConsentParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ClientID string    `json:"client_id"`
+	Scopes   []string  `json:"scopes"`
+}
+
+func (q *Queries) UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OauthConsent, error) {
+	row := q.db.QueryRow(ctx, upsertOAuthConsent, arg.UserID, arg.ClientID, arg.Scopes)
+	var i OauthConsent
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ClientID,
+		&i.Scopes,
+		&i.GrantedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,144 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.22.0
+// source: outbox.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimOutboxMessages = `-- name: ClaimOutboxMessages :many
+SELECT id, aggregate, kind, topic, destination, payload, options, trace, request_id, attempts, last_error, available_at, created_at, delivered_at, failed_at FROM outbox o
+WHERE o.delivered_at IS NULL AND o.failed_at IS NULL AND o.available_at <= now()
+  AND NOT EXISTS (
+    SELECT 1 FROM outbox earlier
+    WHERE earlier.aggregate = o.aggregate AND earlier.id < o.id
+      AND earlier.delivered_at IS NULL AND earlier.failed_at IS NULL
+  )
+ORDER BY o.id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+// Locks the oldest message due of each aggregate. Relays running at once skip each other's messages, and a later
+// message of an aggregate waits until the earlier ones were delivered or failed.
+func (q *Queries) ClaimOutboxMessages(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, claimOutboxMessages, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Aggregate,
+			&i.Kind,
+			&i.Topic,
+			&i.Destination,
+			&i.Payload,
+			&i.Options,
+			&i.Trace,
+			&i.RequestID,
+			&i.Attempts,
+			&i.LastError,
+			&i.AvailableAt,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+			&i.FailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxMessage = `-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (aggregate, kind, topic, destination, payload, options, trace, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateOutboxMessageParams struct {
+	Aggregate   string `json:"aggregate"`
+	Kind        string `json:"kind"`
+	Topic       string `json:"topic"`
+	Destination string `json:"destination"`
+	Payload     []byte `json:"payload"`
+	Options     []byte `json:"options"`
+	Trace       []byte `json:"trace"`
+	RequestID   string `json:"request_id"`
+}
+
+func (q *Queries) CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) error {
+	_, err := q.db.Exec(ctx, createOutboxMessage,
+		arg.Aggregate,
+		arg.Kind,
+		arg.Topic,
+		arg.Destination,
+		arg.Payload,
+		arg.Options,
+		arg.Trace,
+		arg.RequestID,
+	)
+	return err
+}
+
+const deleteDeliveredOutboxMessages = `-- name: DeleteDeliveredOutboxMessages :execrows
+DELETE FROM outbox WHERE delivered_at < $1
+`
+
+func (q *Queries) DeleteDeliveredOutboxMessages(ctx context.Context, deliveredAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDeliveredOutboxMessages, deliveredAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failOutboxMessage = `-- name: FailOutboxMessage :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = $2, failed_at = now() WHERE id = $1
+`
+
+type FailOutboxMessageParams struct {
+	ID        int64       `json:"id"`
+	LastError pgtype.Text `json:"last_error"`
+}
+
+func (q *Queries) FailOutboxMessage(ctx context.Context, arg FailOutboxMessageParams) error {
+	_, err := q.db.Exec(ctx, failOutboxMessage, arg.ID, arg.LastError)
+	return err
+}
+
+const markOutboxMessageDelivered = `-- name: MarkOutboxMessageDelivered :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = NULL, delivered_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxMessageDelivered(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, markOutboxMessageDelivered, id)
+	return err
+}
+
+const retryOutboxMessage = `-- name: RetryOutboxMessage :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = $2, available_at = $3 WHERE id = $1
+`
+
+type RetryOutboxMessageParams struct {
+	ID          int64       `json:"id"`
+	LastError   pgtype.Text `json:"last_error"`
+	AvailableAt time.Time   `json:"available_at"`
+}
+
+func (q *Queries) RetryOutboxMessage(ctx context.Context, arg RetryOutboxMessageParams) error {
+	_, err := q.db.Exec(ctx, retryOutboxMessage, arg.ID, arg.LastError, arg.AvailableAt)
+	return err
+}
@@ -7,10 +7,10 @@ package sqlc
 
 import (
 	"context"
-	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createPasswordResetRequest = `-- name: CreatePasswordResetRequest :exec
@@ -26,7 +26,7 @@ type CreatePasswordResetRequestParams struct {
 }
 
 func (q *Queries) CreatePasswordResetRequest(ctx context.Context, arg CreatePasswordResetRequestParams) error {
-	_, err := q.db.ExecContext(ctx, createPasswordResetRequest,
+	_, err := q.db.Exec(ctx, createPasswordResetRequest,
 		arg.Email,
 		arg.UserID,
 		arg.Token,
@@ -40,7 +40,7 @@ DELETE FROM password_reset_requests WHERE id = $1
 `
 
 func (q *Queries) DeletePasswordResetRequestByID(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deletePasswordResetRequestByID, id)
+	_, err := q.db.Exec(ctx, deletePasswordResetRequestByID, id)
 	return err
 }
 
@@ -49,7 +49,7 @@ DELETE FROM password_reset_requests WHERE email = $1
 `
 
 func (q *Queries) DeletePasswordResetRequestsByEmail(ctx context.Context, email string) error {
-	_, err := q.db.ExecContext(ctx, deletePasswordResetRequestsByEmail, email)
+	_, err := q.db.Exec(ctx, deletePasswordResetRequestsByEmail, email)
 	return err
 }
 
@@ -58,7 +58,7 @@ SELECT id, user_id, email, token, used, created_at, expires_at FROM password_res
 `
 
 func (q *Queries) GetPasswordResetRequestByID(ctx context.Context, id int32) (PasswordResetRequest, error) {
-	row := q.db.QueryRowContext(ctx, getPasswordResetRequestByID, id)
+	row := q.db.QueryRow(ctx, getPasswordResetRequestByID, id)
 	var i PasswordResetRequest
 	err := row.Scan(
 		&i.ID,
@@ -77,7 +77,7 @@ SELECT id, user_id, email, token, used, created_at, expires_at FROM password_res
 `
 
 func (q *Queries) GetPasswordResetRequestByToken(ctx context.Context, token string) (PasswordResetRequest, error) {
-	row := q.db.QueryRowContext(ctx, getPasswordResetRequestByToken, token)
+	row := q.db.QueryRow(ctx, getPasswordResetRequestByToken, token)
 	var i PasswordResetRequest
 	err := row.Scan(
 		&i.ID,
@@ -96,12 +96,12 @@ UPDATE password_reset_requests SET used = $1 WHERE id = $2
 `
 
 type UpdatePasswordResetRequestParams struct {
-	Used sql.NullBool `json:"used"`
-	ID   int32        `json:"id"`
+	Used pgtype.Bool `json:"used"`
+	ID   int32       `json:"id"`
 }
 
 func (q *Queries) UpdatePasswordResetRequest(ctx context.Context, arg UpdatePasswordResetRequestParams) error {
-	_, err := q.db.ExecContext(ctx, updatePasswordResetRequest, arg.Used, arg.ID)
+	_, err := q.db.Exec(ctx, updatePasswordResetRequest, arg.Used, arg.ID)
 	return err
 }
 
@@ -110,11 +110,11 @@ UPDATE password_reset_requests SET used = $1 WHERE token = $2
 `
 
 type UpdatePasswordResetRequestByTokenParams struct {
-	Used  sql.NullBool `json:"used"`
-	Token string       `json:"token"`
+	Used  pgtype.Bool `json:"used"`
+	Token string      `json:"token"`
 }
 
 func (q *Queries) UpdatePasswordResetRequestByToken(ctx context.Context, arg UpdatePasswordResetRequestByTokenParams) error {
-	_, err := q.db.ExecContext(ctx, updatePasswordResetRequestByToken, arg.Used, arg.Token)
+	_, err := q.db.Exec(ctx, updatePasswordResetRequestByToken, arg.Used, arg.Token)
 	return err
 }
@@ -6,26 +6,51 @@ package sqlc
 
 import (
 	"context"
-	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	BlockAllUserSession(ctx context.Context, userID uuid.UUID) error
 	BlockUser(ctx context.Context, id uuid.UUID) error
 	CheckUsername(ctx context.Context, lower string) (int64, error)
+	// Locks the oldest message due of each aggregate. Relays running at once skip each other's messages, and a later
+	// message of an aggregate waits until the earlier ones were delivered or failed.
+	ClaimOutboxMessages(ctx context.Context, limit int32) ([]Outbox, error)
 	CleanupVerifiedAndExpiredRequests(ctx context.Context) error
+	ClearCart(ctx context.Context, ownerID uuid.UUID) error
+	CompleteDataExportRequest(ctx context.Context, arg CompleteDataExportRequestParams) error
+	// A code presented by another client, with another redirect URI or once expired stays unused
+	ConsumeOAuthAuthorizationCode(ctx context.Context, arg ConsumeOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CountMissingConsents(ctx context.Context, arg CountMissingConsentsParams) (int64, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error
+	CreateClientSession(ctx context.Context, arg CreateClientSessionParams) (Session, error)
+	CreateDataExportRequest(ctx context.Context, arg CreateDataExportRequestParams) (DataExportRequest, error)
 	CreateEmailVerificationRequest(ctx context.Context, arg CreateEmailVerificationRequestParams) error
+	CreateFeatureFlag(ctx context.Context, arg CreateFeatureFlagParams) (FeatureFlag, error)
+	CreateGuestSession(ctx context.Context, arg CreateGuestSessionParams) (GuestSession, error)
+	CreateImpersonationSession(ctx context.Context, arg CreateImpersonationSessionParams) (ImpersonationSession, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) error
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) error
 	CreatePasswordResetRequest(ctx context.Context, arg CreatePasswordResetRequestParams) error
+	CreatePolicyDocument(ctx context.Context, arg CreatePolicyDocumentParams) (PolicyDocument, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error)
 	// Create a new session
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (Authentication, error)
+	CreateUserConsent(ctx context.Context, arg CreateUserConsentParams) error
 	CreateUserDeleteRequest(ctx context.Context, arg CreateUserDeleteRequestParams) error
 	// Create a new user login
 	CreateUserLogin(ctx context.Context, arg CreateUserLoginParams) (UserLogin, error)
 	CreateUserProfile(ctx context.Context, arg CreateUserProfileParams) error
 	CreateUserRole(ctx context.Context, arg CreateUserRoleParams) (UserRole, error)
+	DeleteCartItem(ctx context.Context, arg DeleteCartItemParams) error
+	DeleteDeliveredOutboxMessages(ctx context.Context, deliveredAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredOAuthAuthorizationCodes(ctx context.Context) error
+	DeleteFeatureFlag(ctx context.Context, key string) (int64, error)
 	DeletePasswordResetRequestByID(ctx context.Context, id int32) error
 	DeletePasswordResetRequestsByEmail(ctx context.Context, email string) error
 	DeleteSession(ctx context.Context, id uuid.UUID) error
@@ -33,27 +58,82 @@ type Querier interface {
 	// Delete a user login
 	DeleteUserLogin(ctx context.Context, id int32) error
 	DeleteUserProfileByID(ctx context.Context, userID uuid.UUID) error
+	DisableOAuthClient(ctx context.Context, id string) (int64, error)
+	DisableServiceAccount(ctx context.Context, id uuid.UUID) error
+	EndImpersonationSession(ctx context.Context, arg EndImpersonationSessionParams) (int64, error)
+	// Clears the download token in case the request was completed but its link couldn't be sent
+	FailDataExportRequest(ctx context.Context, arg FailDataExportRequestParams) error
+	FailOutboxMessage(ctx context.Context, arg FailOutboxMessageParams) error
+	GetAccountRecoveryRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]AccountRecoveryRequest, error)
+	GetApiKeyByID(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	// A user holding several roles authenticates with the lowest role ID, the most privileged one.
+	GetApiKeyForAuth(ctx context.Context, prefix string) (GetApiKeyForAuthRow, error)
+	GetAuditLogsByUserID(ctx context.Context, userID uuid.NullUUID) ([]AuditLog, error)
+	GetCartItems(ctx context.Context, ownerID uuid.UUID) ([]CartItem, error)
+	GetConsentAcceptanceReport(ctx context.Context) ([]GetConsentAcceptanceReportRow, error)
+	// Read from the primary, the result is cached for every instance and a lagging replica would miss a new version
+	GetCurrentPolicyDocuments(ctx context.Context) ([]PolicyDocument, error)
+	GetDataExportRequestByID(ctx context.Context, id uuid.UUID) (DataExportRequest, error)
+	GetDataExportRequestByTokenHash(ctx context.Context, downloadTokenHash pgtype.Text) (DataExportRequest, error)
 	GetEmailVerificationRequestByToken(ctx context.Context, token string) (EmailVerificationRequest, error)
+	GetEmailVerificationRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]EmailVerificationRequest, error)
+	GetFeatureFlag(ctx context.Context, key string) (FeatureFlag, error)
+	GetGuestSessionByID(ctx context.Context, id uuid.UUID) (GuestSession, error)
+	GetGuestSessionByRefreshToken(ctx context.Context, refreshToken string) (GuestSession, error)
+	GetImpersonationSession(ctx context.Context, id uuid.UUID) (ImpersonationSession, error)
+	GetLatestDataExportRequest(ctx context.Context, userID uuid.UUID) (DataExportRequest, error)
+	GetOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	GetOAuthClient(ctx context.Context, id string) (OauthClient, error)
+	GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OauthConsent, error)
 	GetPasswordResetRequestByID(ctx context.Context, id int32) (PasswordResetRequest, error)
 	GetPasswordResetRequestByToken(ctx context.Context, token string) (PasswordResetRequest, error)
+	GetPasswordResetRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]PasswordResetRequest, error)
+	GetPolicyDocumentByVersion(ctx context.Context, arg GetPolicyDocumentByVersionParams) (PolicyDocument, error)
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (ServiceAccount, error)
 	GetSessionAndUserByRefreshToken(ctx context.Context, refreshToken string) (GetSessionAndUserByRefreshTokenRow, error)
 	GetSessionsByID(ctx context.Context, id uuid.UUID) (Session, error)
 	GetSessionsByRefreshToken(ctx context.Context, refreshToken string) (Session, error)
 	GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error)
 	GetUidsFromUsername(ctx context.Context, dollar_1 []string) ([]uuid.UUID, error)
-	GetUserAndRoleByIdentifier(ctx context.Context, username sql.NullString) (GetUserAndRoleByIdentifierRow, error)
+	GetUserAndRoleByIdentifier(ctx context.Context, username pgtype.Text) (GetUserAndRoleByIdentifierRow, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (Authentication, error)
 	GetUserByIdentifier(ctx context.Context, email string) (Authentication, error)
-	GetUserByUsername(ctx context.Context, username sql.NullString) (uuid.UUID, error)
+	GetUserByUsername(ctx context.Context, username pgtype.Text) (uuid.UUID, error)
+	GetUserConsents(ctx context.Context, userID uuid.UUID) ([]GetUserConsentsRow, error)
 	GetUserFromDeleteReqByToken(ctx context.Context, recoveryToken string) (AccountRecoveryRequest, error)
-	GetUserIDsFromUsernames(ctx context.Context, username sql.NullString) ([]uuid.UUID, error)
+	GetUserIDsFromUsernames(ctx context.Context, username pgtype.Text) ([]uuid.UUID, error)
 	// Get user logins by user ID
 	GetUserLoginsByUserID(ctx context.Context, userID uuid.UUID) ([]UserLogin, error)
-	GetUserProfile(ctx context.Context, username sql.NullString) (GetUserProfileRow, error)
+	GetUserProfile(ctx context.Context, username pgtype.Text) (GetUserProfileRow, error)
+	GetUserProfileByUserID(ctx context.Context, userID uuid.UUID) (User, error)
+	GetUserRolesByUserID(ctx context.Context, userID uuid.UUID) ([]GetUserRolesByUserIDRow, error)
+	ImportAuditLogs(ctx context.Context, arg []ImportAuditLogsParams) (int64, error)
+	ListActiveImpersonationSessions(ctx context.Context) ([]ImpersonationSession, error)
+	ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error)
+	ListOAuthClients(ctx context.Context) ([]OauthClient, error)
+	ListPolicyDocuments(ctx context.Context) ([]PolicyDocument, error)
+	ListServiceAccountApiKeys(ctx context.Context, serviceAccountID uuid.NullUUID) ([]ApiKey, error)
+	ListServiceAccounts(ctx context.Context) ([]ServiceAccount, error)
+	ListUserApiKeys(ctx context.Context, userID uuid.NullUUID) ([]ApiKey, error)
+	ListUserOAuthConsents(ctx context.Context, userID uuid.UUID) ([]ListUserOAuthConsentsRow, error)
+	MarkDataExportDownloaded(ctx context.Context, id uuid.UUID) error
+	MarkDataExportProcessing(ctx context.Context, id uuid.UUID) error
 	MarkDeleteAsUsedByToken(ctx context.Context, recoveryToken string) error
+	MarkOutboxMessageDelivered(ctx context.Context, id int64) error
+	MergeCart(ctx context.Context, arg MergeCartParams) error
+	RetireGuestSession(ctx context.Context, arg RetireGuestSessionParams) error
+	RetryOutboxMessage(ctx context.Context, arg RetryOutboxMessageParams) error
+	RevokeApiKey(ctx context.Context, id uuid.UUID) (int64, error)
+	RevokeClientSessions(ctx context.Context, arg RevokeClientSessionsParams) error
+	RevokeOAuthConsent(ctx context.Context, arg RevokeOAuthConsentParams) (int64, error)
+	RevokeServiceAccountApiKeys(ctx context.Context, serviceAccountID uuid.NullUUID) error
 	RevokeSessionById(ctx context.Context, id uuid.UUID) error
+	RotateGuestSessionToken(ctx context.Context, arg RotateGuestSessionTokenParams) error
 	RotateSessionTokens(ctx context.Context, arg RotateSessionTokensParams) error
+	SetOAuthAuthorizationCodeSession(ctx context.Context, arg SetOAuthAuthorizationCodeSessionParams) error
+	TouchApiKey(ctx context.Context, id uuid.UUID) error
 	UpdateEmailVerificationRequest(ctx context.Context, arg UpdateEmailVerificationRequestParams) (EmailVerificationRequest, error)
+	UpdateFeatureFlag(ctx context.Context, arg UpdateFeatureFlagParams) (FeatureFlag, error)
 	UpdateImgUserProfile(ctx context.Context, arg UpdateImgUserProfileParams) error
 	UpdatePasswordResetRequest(ctx context.Context, arg UpdatePasswordResetRequestParams) error
 	UpdatePasswordResetRequestByToken(ctx context.Context, arg UpdatePasswordResetRequestByTokenParams) error
@@ -62,6 +142,8 @@ type Querier interface {
 	UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (UserLogin, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
 	UpdateUserPasswordByEmail(ctx context.Context, arg UpdateUserPasswordByEmailParams) error
+	UpsertCartItem(ctx context.Context, arg UpsertCartItemParams) (CartItem, error)
+	UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OauthConsent, error)
 }
 
 var _ Querier = (*Queries)(nil)
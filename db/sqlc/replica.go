@@ -0,0 +1,340 @@
+package sqlc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// ReplicaAnnotation marks a query in db/query as safe to serve from a replica, e.g. "SELECT /* replica */ ...".
+// sqlc keeps block comments in the generated SQL, which is where the router looks for it.
+const ReplicaAnnotation = "/* replica */"
+
+const (
+	defaultReplicaMaxLag        = 5 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+	defaultReadYourWritesWindow = 5 * time.Second
+)
+
+// replicaLagQuery returns how far the replica's replay is behind in seconds, zero when it replayed everything it
+// received or is not in recovery at all.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8
+END`
+
+type routeKey int
+
+const (
+	routePrimaryKey routeKey = iota
+	routeReplicaKey
+	routeSubjectKey
+)
+
+// WithPrimary sends every query made with ctx to the primary, even annotated ones.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routePrimaryKey, true)
+}
+
+// WithReplica lets reads made with ctx use a replica even when their query is not annotated. Writes always go to
+// the primary.
+func WithReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeReplicaKey, true)
+}
+
+// WithSubject names who the queries made with ctx act for, usually the user ID. After a subject writes, its reads
+// stay on the primary for the read your writes window so it never sees data older than its own changes.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, routeSubjectKey, subject)
+}
+
+func subjectFrom(ctx context.Context) string {
+	subject, _ := ctx.Value(routeSubjectKey).(string)
+	return subject
+}
+
+// ReplicaOptions tunes the router, zero values pick the defaults of five seconds each.
+type ReplicaOptions struct {
+	// MaxLag is how far behind a replica may be before reads go back to the primary
+	MaxLag time.Duration
+	// CheckInterval is how often replica health and lag are checked
+	CheckInterval time.Duration
+	// ReadYourWritesWindow keeps a subject's reads on the primary for this long after its last write
+	ReadYourWritesWindow time.Duration
+}
+
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// Router is a DBTX sending writes to the primary and annotated or flagged reads to a healthy replica. Reads fall
+// back to the primary when no replica is healthy, when the replica fails the query or within a subject's read
+// your writes window.
+type Router struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	next     atomic.Uint32
+	opts     ReplicaOptions
+
+	mu     sync.Mutex
+	writes map[string]time.Time
+}
+
+func NewRouter(primary *pgxpool.Pool, replicaPools []*pgxpool.Pool, opts ReplicaOptions) *Router {
+	if opts.MaxLag <= 0 {
+		opts.MaxLag = defaultReplicaMaxLag
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultReplicaCheckInterval
+	}
+	if opts.ReadYourWritesWindow <= 0 {
+		opts.ReadYourWritesWindow = defaultReadYourWritesWindow
+	}
+
+	r := &Router{
+		primary: primary,
+		opts:    opts,
+		writes:  make(map[string]time.Time),
+	}
+	for _, pool := range replicaPools {
+		rep := &replica{pool: pool}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+func (r *Router) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := r.primary.Exec(ctx, sql, args...)
+	if err == nil {
+		r.markWrite(ctx)
+	}
+	return tag, err
+}
+
+func (r *Router) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rep := r.readReplica(ctx, sql)
+	if rep == nil {
+		rows, err := r.primary.Query(ctx, sql, args...)
+		if err == nil && isWrite(sql) {
+			r.markWrite(ctx)
+		}
+		return rows, err
+	}
+
+	rows, err := rep.pool.Query(ctx, sql, args...)
+	if err != nil && r.replicaFailed(ctx, rep, err) {
+		return r.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+func (r *Router) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rep := r.readReplica(ctx, sql)
+	if rep == nil {
+		if isWrite(sql) {
+			return &writeRow{Row: r.primary.QueryRow(ctx, sql, args...), ctx: ctx, router: r}
+		}
+		return r.primary.QueryRow(ctx, sql, args...)
+	}
+	return &replicaRow{Row: rep.pool.QueryRow(ctx, sql, args...), ctx: ctx, router: r, replica: rep, sql: sql, args: args}
+}
+
+func (r *Router) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	n, err := r.primary.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	if err == nil {
+		r.markWrite(ctx)
+	}
+	return n, err
+}
+
+// Monitor checks replica health and lag every CheckInterval until ctx is done.
+func (r *Router) Monitor(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		r.checkReplicas(ctx)
+		r.sweepWrites()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Router) checkReplicas(ctx context.Context) {
+	for i, rep := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.opts.CheckInterval)
+		var lag float64
+		err := rep.pool.QueryRow(checkCtx, replicaLagQuery).Scan(&lag)
+		cancel()
+
+		healthy := err == nil && time.Duration(lag*float64(time.Second)) <= r.opts.MaxLag
+		if was := rep.healthy.Swap(healthy); was != healthy {
+			event := log.Info()
+			if !healthy {
+				event = log.Warn().Err(err)
+			}
+			event.Int("replica", i).Float64("lag_seconds", lag).Bool("healthy", healthy).Msg("db replica health changed")
+		}
+	}
+}
+
+// readReplica picks the replica for a query, nil means the primary must serve it.
+func (r *Router) readReplica(ctx context.Context, sql string) *replica {
+	if len(r.replicas) == 0 || isWrite(sql) {
+		return nil
+	}
+	if forced, _ := ctx.Value(routePrimaryKey).(bool); forced {
+		return nil
+	}
+	flagged, _ := ctx.Value(routeReplicaKey).(bool)
+	if !flagged && !strings.Contains(sql, ReplicaAnnotation) {
+		return nil
+	}
+	if r.wroteRecently(subjectFrom(ctx)) {
+		return nil
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(int(start)+i)%len(r.replicas)]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// replicaFailed reports whether a read should be retried on the primary. Replicas that cannot be reached are
+// taken out until the next health check brings them back.
+func (r *Router) replicaFailed(ctx context.Context, rep *replica, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 57: the replica is shutting down or not accepting connections, 40001: canceled by a recovery conflict
+		return strings.HasPrefix(pgErr.Code, "57") || pgErr.Code == "40001"
+	}
+
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || pgconn.SafeToRetry(err) {
+		rep.healthy.Store(false)
+		log.Warn().Err(err).Msg("db replica unreachable, reading from the primary")
+		return true
+	}
+	return false
+}
+
+func (r *Router) markWrite(ctx context.Context) {
+	subject := subjectFrom(ctx)
+	if subject == "" || len(r.replicas) == 0 {
+		return
+	}
+	r.mu.Lock()
+	r.writes[subject] = time.Now()
+	r.mu.Unlock()
+}
+
+func (r *Router) wroteRecently(subject string) bool {
+	if subject == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.writes[subject]
+	return ok && time.Since(at) < r.opts.ReadYourWritesWindow
+}
+
+func (r *Router) sweepWrites() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for subject, at := range r.writes {
+		if time.Since(at) >= r.opts.ReadYourWritesWindow {
+			delete(r.writes, subject)
+		}
+	}
+}
+
+// isWrite reports whether sql changes data, going by its first statement keyword after the sqlc name comment.
+func isWrite(sql string) bool {
+	for {
+		sql = strings.TrimSpace(sql)
+		if !strings.HasPrefix(sql, "--") {
+			break
+		}
+		newline := strings.IndexByte(sql, '\n')
+		if newline < 0 {
+			return false
+		}
+		sql = sql[newline+1:]
+	}
+
+	keyword := sql
+	if end := strings.IndexFunc(sql, unicode.IsSpace); end >= 0 {
+		keyword = sql[:end]
+	}
+	switch strings.ToUpper(keyword) {
+	case "SELECT", "VALUES", "SHOW", "TABLE":
+		// Locking reads must see the latest rows and hold their locks on the primary
+		upper := strings.ToUpper(sql)
+		return strings.Contains(upper, " FOR UPDATE") || strings.Contains(upper, " FOR SHARE")
+	}
+	return true
+}
+
+// writeRow records the subject's write once the row of an INSERT or UPDATE ... RETURNING was read.
+type writeRow struct {
+	pgx.Row
+	ctx    context.Context
+	router *Router
+}
+
+func (w *writeRow) Scan(dest ...any) error {
+	err := w.Row.Scan(dest...)
+	if err == nil || errors.Is(err, pgx.ErrNoRows) {
+		w.router.markWrite(w.ctx)
+	}
+	return err
+}
+
+// replicaRow retries on the primary when the replica fails, QueryRow only reports errors on Scan.
+type replicaRow struct {
+	pgx.Row
+	ctx     context.Context
+	router  *Router
+	replica *replica
+	sql     string
+	args    []interface{}
+}
+
+func (rr *replicaRow) Scan(dest ...any) error {
+	err := rr.Row.Scan(dest...)
+	if err != nil && rr.router.replicaFailed(rr.ctx, rr.replica, err) {
+		return rr.router.primary.QueryRow(rr.ctx, rr.sql, rr.args...).Scan(dest...)
+	}
+	return err
+}
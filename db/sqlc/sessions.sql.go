@@ -7,11 +7,11 @@ package sqlc
 
 import (
 	"context"
-	"database/sql"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sqlc-dev/pqtype"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const blockAllUserSession = `-- name: BlockAllUserSession :exec
@@ -19,33 +19,33 @@ UPDATE sessions SET blocked_at = now(), invalidated_at = now() WHERE user_id = $
 `
 
 func (q *Queries) BlockAllUserSession(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, blockAllUserSession, userID)
+	_, err := q.db.Exec(ctx, blockAllUserSession, userID)
 	return err
 }
 
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (id, user_id, refresh_token, refresh_token_exp,
 user_agent, updated_at, ip_address, blocked_at, invalidated_at, last_active_at, fcm_token)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token, client_id, scopes
 `
 
 type CreateSessionParams struct {
-	ID              uuid.UUID      `json:"id"`
-	UserID          uuid.UUID      `json:"user_id"`
-	RefreshToken    string         `json:"refresh_token"`
-	RefreshTokenExp time.Time      `json:"refresh_token_exp"`
-	UserAgent       string         `json:"user_agent"`
-	UpdatedAt       sql.NullTime   `json:"updated_at"`
-	IpAddress       pqtype.Inet    `json:"ip_address"`
-	BlockedAt       sql.NullTime   `json:"blocked_at"`
-	InvalidatedAt   sql.NullTime   `json:"invalidated_at"`
-	LastActiveAt    sql.NullTime   `json:"last_active_at"`
-	FcmToken        sql.NullString `json:"fcm_token"`
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	RefreshToken    string             `json:"refresh_token"`
+	RefreshTokenExp time.Time          `json:"refresh_token_exp"`
+	UserAgent       string             `json:"user_agent"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	IpAddress       netip.Addr         `json:"ip_address"`
+	BlockedAt       pgtype.Timestamptz `json:"blocked_at"`
+	InvalidatedAt   pgtype.Timestamptz `json:"invalidated_at"`
+	LastActiveAt    pgtype.Timestamptz `json:"last_active_at"`
+	FcmToken        pgtype.Text        `json:"fcm_token"`
 }
 
 // Create a new session
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
-	row := q.db.QueryRowContext(ctx, createSession,
+	row := q.db.QueryRow(ctx, createSession,
 		arg.ID,
 		arg.UserID,
 		arg.RefreshToken,
@@ -72,6 +72,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.UserAgent,
 		&i.IpAddress,
 		&i.FcmToken,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
@@ -82,12 +84,12 @@ WHERE id = $1
 `
 
 func (q *Queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	_, err := q.db.Exec(ctx, deleteSession, id)
 	return err
 }
 
 const getSessionAndUserByRefreshToken = `-- name: GetSessionAndUserByRefreshToken :one
-SELECT s.id, s.user_id, s.refresh_token, s.refresh_token_exp, s.created_at, s.updated_at, s.invalidated_at, s.last_active_at, s.blocked_at, s.user_agent, s.ip_address, s.fcm_token, u.username, u.email, u.phone, u.is_email_verified, ur.role_id
+SELECT s.id, s.user_id, s.refresh_token, s.refresh_token_exp, s.created_at, s.updated_at, s.invalidated_at, s.last_active_at, s.blocked_at, s.user_agent, s.ip_address, s.fcm_token, s.client_id, s.scopes, u.username, u.email, u.phone, u.is_email_verified, ur.role_id
 FROM sessions s
 JOIN authentications u ON s.user_id = u.id
 LEFT JOIN user_roles ur ON u.id = ur.user_id
@@ -95,27 +97,29 @@ WHERE s.refresh_token = $1
 `
 
 type GetSessionAndUserByRefreshTokenRow struct {
-	ID              uuid.UUID      `json:"id"`
-	UserID          uuid.UUID      `json:"user_id"`
-	RefreshToken    string         `json:"refresh_token"`
-	RefreshTokenExp time.Time      `json:"refresh_token_exp"`
-	CreatedAt       sql.NullTime   `json:"created_at"`
-	UpdatedAt       sql.NullTime   `json:"updated_at"`
-	InvalidatedAt   sql.NullTime   `json:"invalidated_at"`
-	LastActiveAt    sql.NullTime   `json:"last_active_at"`
-	BlockedAt       sql.NullTime   `json:"blocked_at"`
-	UserAgent       string         `json:"user_agent"`
-	IpAddress       pqtype.Inet    `json:"ip_address"`
-	FcmToken        sql.NullString `json:"fcm_token"`
-	Username        sql.NullString `json:"username"`
-	Email           string         `json:"email"`
-	Phone           sql.NullString `json:"phone"`
-	IsEmailVerified sql.NullBool   `json:"is_email_verified"`
-	RoleID          sql.NullInt32  `json:"role_id"`
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	RefreshToken    string             `json:"refresh_token"`
+	RefreshTokenExp time.Time          `json:"refresh_token_exp"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	InvalidatedAt   pgtype.Timestamptz `json:"invalidated_at"`
+	LastActiveAt    pgtype.Timestamptz `json:"last_active_at"`
+	BlockedAt       pgtype.Timestamptz `json:"blocked_at"`
+	UserAgent       string             `json:"user_agent"`
+	IpAddress       netip.Addr         `json:"ip_address"`
+	FcmToken        pgtype.Text        `json:"fcm_token"`
+	ClientID        pgtype.Text        `json:"client_id"`
+	Scopes          []string           `json:"scopes"`
+	Username        pgtype.Text        `json:"username"`
+	Email           string             `json:"email"`
+	Phone           pgtype.Text        `json:"phone"`
+	IsEmailVerified pgtype.Bool        `json:"is_email_verified"`
+	RoleID          pgtype.Int4        `json:"role_id"`
 }
 
 func (q *Queries) GetSessionAndUserByRefreshToken(ctx context.Context, refreshToken string) (GetSessionAndUserByRefreshTokenRow, error) {
-	row := q.db.QueryRowContext(ctx, getSessionAndUserByRefreshToken, refreshToken)
+	row := q.db.QueryRow(ctx, getSessionAndUserByRefreshToken, refreshToken)
 	var i GetSessionAndUserByRefreshTokenRow
 	err := row.Scan(
 		&i.ID,
@@ -130,6 +134,8 @@ func (q *Queries) GetSessionAndUserByRefreshToken(ctx context.Context, refreshTo
 		&i.UserAgent,
 		&i.IpAddress,
 		&i.FcmToken,
+		&i.ClientID,
+		&i.Scopes,
 		&i.Username,
 		&i.Email,
 		&i.Phone,
@@ -140,11 +146,11 @@ func (q *Queries) GetSessionAndUserByRefreshToken(ctx context.Context, refreshTo
 }
 
 const getSessionsByID = `-- name: GetSessionsByID :one
-SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token FROM sessions WHERE id = $1 LIMIT 1
+SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token, client_id, scopes FROM sessions WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetSessionsByID(ctx context.Context, id uuid.UUID) (Session, error) {
-	row := q.db.QueryRowContext(ctx, getSessionsByID, id)
+	row := q.db.QueryRow(ctx, getSessionsByID, id)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -159,17 +165,19 @@ func (q *Queries) GetSessionsByID(ctx context.Context, id uuid.UUID) (Session, e
 		&i.UserAgent,
 		&i.IpAddress,
 		&i.FcmToken,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
 
 const getSessionsByRefreshToken = `-- name: GetSessionsByRefreshToken :one
-SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token FROM sessions
+SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token, client_id, scopes FROM sessions
 WHERE refresh_token = $1
 `
 
 func (q *Queries) GetSessionsByRefreshToken(ctx context.Context, refreshToken string) (Session, error) {
-	row := q.db.QueryRowContext(ctx, getSessionsByRefreshToken, refreshToken)
+	row := q.db.QueryRow(ctx, getSessionsByRefreshToken, refreshToken)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -184,17 +192,19 @@ func (q *Queries) GetSessionsByRefreshToken(ctx context.Context, refreshToken st
 		&i.UserAgent,
 		&i.IpAddress,
 		&i.FcmToken,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
 
 const getSessionsByUserID = `-- name: GetSessionsByUserID :many
-SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token FROM sessions
+SELECT id, user_id, refresh_token, refresh_token_exp, created_at, updated_at, invalidated_at, last_active_at, blocked_at, user_agent, ip_address, fcm_token, client_id, scopes FROM sessions
 WHERE user_id = $1
 `
 
 func (q *Queries) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error) {
-	rows, err := q.db.QueryContext(ctx, getSessionsByUserID, userID)
+	rows, err := q.db.Query(ctx, getSessionsByUserID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -215,14 +225,13 @@ func (q *Queries) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]
 			&i.UserAgent,
 			&i.IpAddress,
 			&i.FcmToken,
+			&i.ClientID,
+			&i.Scopes,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -234,7 +243,7 @@ UPDATE "sessions" SET invalidated_at = now() WHERE id = $1
 `
 
 func (q *Queries) RevokeSessionById(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, revokeSessionById, id)
+	_, err := q.db.Exec(ctx, revokeSessionById, id)
 	return err
 }
 
@@ -255,6 +264,6 @@ type RotateSessionTokensParams struct {
 }
 
 func (q *Queries) RotateSessionTokens(ctx context.Context, arg RotateSessionTokensParams) error {
-	_, err := q.db.ExecContext(ctx, rotateSessionTokens, arg.ID, arg.RefreshToken, arg.RefreshTokenExp)
+	_, err := q.db.Exec(ctx, rotateSessionTokens, arg.ID, arg.RefreshToken, arg.RefreshTokenExp)
 	return err
 }
@@ -1,39 +1,167 @@
 package sqlc
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNotInTx is returned by Savepoint when the queries do not belong to a transaction.
+var ErrNotInTx = errors.New("savepoint needs queries bound to a transaction")
+
+const (
+	defaultTxAttempts = 3
+	txRetryBackoff    = 20 * time.Millisecond
 )
 
 type Store interface {
 	Querier
+	// ExecTx runs fn in a transaction and commits it when fn returns nil. Serialization failures and deadlocks roll
+	// the transaction back and run fn again, so fn must only touch the database through the queries it is given.
+	ExecTx(ctx context.Context, opts TxOptions, fn func(*Queries) error) error
+}
+
+// TxOptions configures a transaction started by ExecTx, the zero value is a read write transaction at the
+// database's default isolation level tried up to three times.
+type TxOptions struct {
+	Isolation pgx.TxIsoLevel
+	ReadOnly  bool
+	// MaxAttempts caps how often fn runs when the transaction keeps failing to serialize, zero means three
+	MaxAttempts int
+}
+
+// txBeginner starts the transactions of ExecTx, it is the primary pool outside tests.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
 
 type SQLStore struct {
-	// connPool *pgxpool.Pool
-	db *sql.DB
+	connPool txBeginner
+	// router is set when reads may go to replicas
+	router *Router
 	*Queries
 }
 
-func NewStore(db *sql.DB) Store {
+func NewStore(connPool *pgxpool.Pool) Store {
+	return &SQLStore{
+		connPool: connPool,
+		Queries:  New(connPool),
+	}
+}
+
+// NewRoutedStore runs queries through router, transactions always run on its primary.
+func NewRoutedStore(router *Router) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
-	}
-}
-
-// func (store *SQLStore) ExecTx(ctx context.Context, fn func(*Queries) error) error {
-// 	tx, err := store.connPool.Begin(ctx)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	q := New(tx)
-// 	err = fn(q)
-// 	if err != nil {
-// 		if rbErr := tx.Rollback(ctx); rbErr != nil {
-// 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
-// 		}
-// 		return err
-// 	}
-// 	return tx.Commit(ctx)
-// }
+		connPool: router.primary,
+		router:   router,
+		Queries:  New(router),
+	}
+}
+
+func (store *SQLStore) ExecTx(ctx context.Context, opts TxOptions, fn func(*Queries) error) (err error) {
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultTxAttempts
+	}
+
+	// The queries of every attempt become children of this span
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "sqlc.ExecTx", trace.WithAttributes(
+		attribute.String("db.tx.isolation", string(opts.Isolation)),
+		attribute.Bool("db.tx.read_only", opts.ReadOnly),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		span.SetAttributes(attribute.Int("db.tx.attempts", attempt))
+		err = store.execTx(ctx, opts, fn)
+		if err == nil {
+			if store.router != nil && !opts.ReadOnly {
+				store.router.markWrite(ctx)
+			}
+			break
+		}
+		if !IsRetryableTxError(err) || attempt == attempts {
+			break
+		}
+
+		// Back off a little longer each time so the conflicting transaction can finish
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		}
+	}
+	return err
+}
+
+func (store *SQLStore) execTx(ctx context.Context, opts TxOptions, fn func(*Queries) error) error {
+	accessMode := pgx.ReadWrite
+	if opts.ReadOnly {
+		accessMode = pgx.ReadOnly
+	}
+
+	tx, err := store.connPool.BeginTx(ctx, pgx.TxOptions{IsoLevel: opts.Isolation, AccessMode: accessMode})
+	if err != nil {
+		return err
+	}
+
+	if err = fn(New(tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Savepoint runs fn inside a savepoint of the transaction q belongs to. When fn fails only its own changes are
+// undone and the transaction can go on, the error is still returned so the caller decides whether to continue.
+func (q *Queries) Savepoint(ctx context.Context, fn func(*Queries) error) error {
+	tx, ok := q.db.(pgx.Tx)
+	if !ok {
+		return ErrNotInTx
+	}
+
+	// Begin on a transaction creates a savepoint
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(New(sp)); err != nil {
+		if rbErr := sp.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("savepoint err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return sp.Commit(ctx)
+}
+
+// IsRetryableTxError reports whether err is a serialization failure or deadlock, after which the whole
+// transaction can be run again.
+func IsRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return true
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package sqlc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	testCases := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "-- name: GetUserByID :one\nSELECT * FROM authentications WHERE id = $1", want: "GetUserByID"},
+		{sql: "\n  -- name: DeleteSession :exec\nDELETE FROM sessions WHERE id = $1", want: "DeleteSession"},
+		{sql: "select pg_last_xact_replay_timestamp()", want: "SELECT"},
+		{sql: "  ", want: "query"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, queryName(tc.sql))
+	}
+}
@@ -0,0 +1,87 @@
+// Package apikey generates and parses the API keys used by integrations and service accounts.
+//
+// A key looks like bk_<prefix>_<secret>. The prefix is stored in plain text so the key can be looked up and
+// shown to its owner, only a SHA-256 hash of the full key is persisted.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	keyPrefix = "bk_"
+	// prefixLength hex characters give 64 random bits, so generated prefixes don't run into the unique constraint
+	prefixLength = 16
+	// legacyPrefixLength is the length of the prefixes of the keys generated before, which are still accepted
+	legacyPrefixLength = 8
+	secretBytes        = 32
+)
+
+var ErrMalformedKey = errors.New("malformed api key")
+
+type Key struct {
+	// Raw is the full key, it is only available when the key is generated
+	Raw    string
+	Prefix string
+	Hash   string
+}
+
+// Generate creates a new random API key.
+func Generate() (Key, error) {
+	prefix := make([]byte, prefixLength/2)
+	if _, err := rand.Read(prefix); err != nil {
+		return Key{}, err
+	}
+
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, err
+	}
+
+	key := Key{Prefix: hex.EncodeToString(prefix)}
+	key.Raw = keyPrefix + key.Prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = Hash(key.Raw)
+	return key, nil
+}
+
+// Parse validates the shape of a raw key and returns its lookup prefix.
+func Parse(raw string) (string, error) {
+	if !strings.HasPrefix(raw, keyPrefix) {
+		return "", ErrMalformedKey
+	}
+
+	// The prefix is hex so the first underscore ends it, the secret may contain more
+	rest := raw[len(keyPrefix):]
+	length := strings.IndexByte(rest, '_')
+	if length != prefixLength && length != legacyPrefixLength {
+		return "", ErrMalformedKey
+	}
+
+	prefix := rest[:length]
+	if _, err := hex.DecodeString(prefix); err != nil {
+		return "", ErrMalformedKey
+	}
+
+	secret, err := base64.RawURLEncoding.DecodeString(rest[length+1:])
+	if err != nil || len(secret) != secretBytes {
+		return "", ErrMalformedKey
+	}
+
+	return prefix, nil
+}
+
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compares the raw key against a stored hash in constant time.
+func Matches(raw, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(raw)), []byte(hash)) == 1
+}
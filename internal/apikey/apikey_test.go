@@ -0,0 +1,55 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAndParse(t *testing.T) {
+	key, err := Generate()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key.Raw, "bk_"+key.Prefix+"_"))
+	require.Len(t, key.Prefix, prefixLength)
+
+	prefix, err := Parse(key.Raw)
+	require.NoError(t, err)
+	require.Equal(t, key.Prefix, prefix)
+	require.True(t, Matches(key.Raw, key.Hash))
+
+	other, err := Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, key.Raw, other.Raw)
+	require.False(t, Matches(other.Raw, key.Hash))
+}
+
+// Test keys generated with the shorter prefix of before still parse
+func TestParseLegacyPrefix(t *testing.T) {
+	key, err := Generate()
+	require.NoError(t, err)
+	secret := key.Raw[len("bk_")+prefixLength+1:]
+
+	prefix, err := Parse("bk_0a1b2c3d_" + secret)
+	require.NoError(t, err)
+	require.Equal(t, "0a1b2c3d", prefix)
+}
+
+func TestParseMalformed(t *testing.T) {
+	key, err := Generate()
+	require.NoError(t, err)
+	secret := key.Raw[len("bk_")+prefixLength+1:]
+
+	for _, raw := range []string{
+		"",
+		"bk_",
+		"sk_" + key.Raw[3:],
+		"bk_" + strings.Repeat("z", prefixLength) + "_" + secret,
+		"bk_" + key.Prefix + "-" + secret,
+		"bk_" + key.Prefix[:12] + "_" + secret,
+		key.Raw[:len(key.Raw)-4],
+	} {
+		_, err := Parse(raw)
+		require.ErrorIs(t, err, ErrMalformedKey, raw)
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) createServiceAccount(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.CreateServiceAccountReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	account, err := services.CreateServiceAccount(ctx, s.store, authPayload, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, account)
+}
+
+func (s *Server) listServiceAccounts(ctx *gin.Context) {
+	accounts, err := services.ListServiceAccounts(ctx, s.store)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+func (s *Server) disableServiceAccount(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		httpserver.Error(ctx, errInvalidID)
+		return
+	}
+
+	if err := services.DisableServiceAccount(ctx, s.store, authPayload, id, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Service account disabled",
+	})
+}
+
+func (s *Server) createAPIKey(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.CreateAPIKeyReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	key, err := services.CreateAPIKey(ctx, s.store, authPayload, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+func (s *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var serviceAccountID uuid.NullUUID
+	if id := ctx.Query("service_account_id"); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			httpserver.Error(ctx, apperr.InvalidField("service_account_id", "validation.uuid", "must be a valid id"))
+			return
+		}
+		serviceAccountID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	keys, err := services.ListAPIKeys(ctx, s.store, authPayload, serviceAccountID)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (s *Server) rotateAPIKey(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		httpserver.Error(ctx, errInvalidID)
+		return
+	}
+
+	key, err := services.RotateAPIKey(ctx, s.store, authPayload, id, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+func (s *Server) revokeAPIKey(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		httpserver.Error(ctx, errInvalidID)
+		return
+	}
+
+	if err := services.RevokeAPIKey(ctx, s.store, authPayload, id, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "API key revoked",
+	})
+}
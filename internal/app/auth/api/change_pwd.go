@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 	"github.com/steve-mir/bukka_backend/token"
 )
 
@@ -14,13 +15,13 @@ func (s *Server) changePwd(ctx *gin.Context) {
 
 	var req services.ChangePwdReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
 	err := services.ChangeUserPwd(ctx, req.OldPassword, req.NewPassword, s.store, authPayload.Subject)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type configDumpRes struct {
+	Environment string         `json:"environment"`
+	Config      map[string]any `json:"config"`
+}
+
+// dumpConfig shows super admins the config the process runs with, reloads included and secrets redacted.
+func (s *Server) dumpConfig(ctx *gin.Context) {
+	config := s.configWatcher.Current()
+	ctx.JSON(http.StatusOK, configDumpRes{
+		Environment: config.Environment,
+		Config:      config.Dump(),
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) currentPolicies(ctx *gin.Context) {
+	docs, err := s.consentService.CurrentDocuments(ctx)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	res := make([]services.PolicyDocumentRes, 0, len(docs))
+	for _, doc := range docs {
+		res = append(res, services.NewPolicyDocumentRes(doc))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (s *Server) acceptConsent(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.AcceptConsentReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	err := s.consentService.Accept(ctx, authPayload.Subject, req, clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Consent recorded",
+	})
+}
+
+func (s *Server) listConsents(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	consents, err := s.consentService.UserConsents(ctx, authPayload.Subject)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, consents)
+}
+
+func (s *Server) publishPolicy(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.PublishPolicyReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	doc, err := s.consentService.Publish(ctx, req, authPayload.Subject)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.NewPolicyDocumentRes(doc))
+}
+
+func (s *Server) consentReport(ctx *gin.Context) {
+	report, err := s.consentService.Report(ctx)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) requestDataExport(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	request, err := services.RequestDataExport(ctx, s.store, s.config, authPayload.Subject, clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, services.DataExportRes{
+		RequestID: request.ID,
+		Status:    request.Status,
+		Msg:       "Your data export is being prepared. A download link will be sent to your email",
+	})
+}
+
+func (s *Server) downloadDataExport(ctx *gin.Context) {
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	reader, request, err := services.OpenDataExport(ctx, s.store, s.storage, ctx.Query("token"), clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+	defer reader.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, "application/zip", reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="bukka-data-export-%s.zip"`, request.ID),
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 	"github.com/steve-mir/bukka_backend/token"
 )
 
@@ -14,13 +15,13 @@ func (s *Server) deleteAccount(ctx *gin.Context) {
 
 	var req services.DeleteAccountReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
 	err := services.DeleteAccountRequest(ctx, req.Password, s.store, authPayload.Subject)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
@@ -33,13 +34,13 @@ func (s *Server) deleteAccount(ctx *gin.Context) {
 func (s *Server) requestAccountRecovery(ctx *gin.Context) {
 	var req services.AccountRecoveryReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
-	err := services.AccRecoveryRequest(ctx, s.store, s.taskDistributor, req.Email)
+	err := services.AccRecoveryRequest(ctx, s.store, req.Email)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
@@ -51,9 +52,9 @@ func (s *Server) requestAccountRecovery(ctx *gin.Context) {
 
 func (s *Server) completeAccountRecovery(ctx *gin.Context) {
 	token := ctx.Query("token")
-	err := services.AccountRecovery(ctx, s.db, s.store, token)
+	err := services.AccountRecovery(ctx, s.store, token)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
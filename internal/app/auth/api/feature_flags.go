@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) createFeatureFlag(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.CreateFeatureFlagReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	flag, err := services.CreateFeatureFlag(ctx, s.store, authPayload, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, flag)
+}
+
+func (s *Server) listFeatureFlags(ctx *gin.Context) {
+	flags, err := services.ListFeatureFlags(ctx, s.store)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flags)
+}
+
+func (s *Server) getFeatureFlag(ctx *gin.Context) {
+	flag, err := services.GetFeatureFlag(ctx, s.store, ctx.Param("key"))
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+func (s *Server) updateFeatureFlag(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.UpdateFeatureFlagReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	flag, err := services.UpdateFeatureFlag(ctx, s.store, s.flags, authPayload, ctx.Param("key"), req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+func (s *Server) deleteFeatureFlag(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	if err := services.DeleteFeatureFlag(ctx, s.store, s.flags, authPayload, ctx.Param("key"), ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Feature flag deleted",
+	})
+}
@@ -5,21 +5,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/constants"
-	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 )
 
 func (s *Server) forgotPwd(ctx *gin.Context) {
 
 	var req services.AccountRecoveryReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
-	err := services.RequestPwdReset(ctx, req.Email, s.store, s.taskDistributor)
+	err := services.RequestPwdReset(ctx, req.Email, s.store)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
@@ -33,24 +33,13 @@ func (s *Server) resetPwd(ctx *gin.Context) {
 
 	var req services.ResetPwdReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
-	// Begin transaction
-	tx, err := s.db.Begin()
-
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-	defer tx.Rollback()
-
-	qtx := sqlc.New(tx)
-
-	err = services.ResetPassword(ctx, qtx, tx, s.store, req.Token, req.NewPassword)
+	err := services.ResetPassword(ctx, s.store, req.Token, req.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
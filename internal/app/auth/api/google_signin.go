@@ -1,24 +1,28 @@
 package api
 
 import (
-	"database/sql"
 	"encoding/json"
-	"errors"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
 	"golang.org/x/oauth2"
 )
 
+// errGoogleExchange answers callbacks whose code Google would not exchange for a token
+var errGoogleExchange = apperr.New(apperr.CodeInvalidArgument, "auth.google.exchange_failed", "could not sign in with google")
+
 // https://localhost:8080/v1/auth/google/callback
 func (server *Server) googleLogin(c *gin.Context) {
 	fcmToken := c.Query("fcmToken")
 	// url := server.oauthConfig.AuthCodeURL("state") + "&fcmToken=" + url.QueryEscape(fcmToken)
 	url := server.oauthConfig.AuthCodeURL("state", oauth2.SetAuthURLParam("fcmToken", fcmToken))
-	log.Println("Auth url", url)
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
@@ -31,14 +35,14 @@ func (server *Server) googleCallback(c *gin.Context) {
 	fcmToken := c.Query("fcmToken")
 	token, err := server.oauthConfig.Exchange(c, code)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(c, errGoogleExchange.WithCause(err))
 		return
 	}
 
 	client := server.oauthConfig.Client(c, token)
 	userInfo, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(c, err)
 		return
 	}
 	defer userInfo.Body.Close()
@@ -48,55 +52,50 @@ func (server *Server) googleCallback(c *gin.Context) {
 		Name  string `json:"name"`
 	}
 	if err := json.NewDecoder(userInfo.Body).Decode(&googleUser); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(c, err)
 		return
 	}
 
 	// Check if the user exists
-	user, err := server.store.GetUserAndRoleByIdentifier(c, sql.NullString{String: googleUser.Email, Valid: true})
-	if err != nil && err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+	user, err := server.store.GetUserAndRoleByIdentifier(c, pgtype.Text{String: googleUser.Email, Valid: true})
+	if err != nil && err != pgx.ErrNoRows {
+		httpserver.Error(c, err)
 		return
 	}
 
 	var userData services.UserAuthRes
-	if err == sql.ErrNoRows {
+	if err == pgx.ErrNoRows {
 		// User doesn't exist, create a new account
-		tx, err := server.db.Begin()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		defer tx.Rollback()
-
-		qtx := sqlc.New(tx)
-
 		// Generate a random password for the user
 		// randomPassword, _ := utils.GenerateUniqueToken(16) //utils.GenerateRandomString(16)
 		_, uid, err := services.PrepareUserData("")
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			httpserver.Error(c, err)
 			return
 		}
 
-		// Create the user
-		sqlcUser, err := services.CreateUserConcurrent(c, qtx, uid, googleUser.Email, googleUser.Name, "", true, true)
+		req := services.RegisterReq{Email: googleUser.Email, Username: googleUser.Name, FcmToken: fcmToken}
+		var sqlcUser sqlc.Authentication
+		err = server.store.ExecTx(c, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+			// Create the user
+			var err error
+			sqlcUser, err = services.CreateUserConcurrent(c, qtx, uid, googleUser.Email, googleUser.Name, "", true, true)
+			if err != nil {
+				return err
+			}
+
+			return services.RunUserCreationTasks(c, qtx, req, uid, true)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			httpserver.Error(c, err)
 			return
 		}
 
-		// Run concurrent operations (you may need to modify this to fit Google sign-in specifics)
 		clientIP := c.ClientIP()
 		agent := c.Request.UserAgent()
-		accessToken, accessExp, err := services.RunConcurrentUserCreationTasks(c, server.tokenMaker, qtx, tx, server.config, server.taskDistributor, services.RegisterReq{Email: googleUser.Email, Username: googleUser.Name, FcmToken: fcmToken}, uid, clientIP, agent, true)
+		accessToken, accessExp, err := services.CreateRegistrationAccessToken(server.tokenMaker, server.config, req, uid, clientIP, agent)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-
-		if err := tx.Commit(); err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			httpserver.Error(c, err)
 			return
 		}
 
@@ -114,7 +113,7 @@ func (server *Server) googleCallback(c *gin.Context) {
 	} else {
 		// Check if the user is an OAuth user
 		if !user.IsOauthUser.Bool {
-			c.JSON(http.StatusInternalServerError, errorResponse(errors.New("this account is not linked with OAuth")))
+			httpserver.Error(c, services.ErrNotOAuthAccount)
 		}
 
 		// User exists, log them in
@@ -123,8 +122,9 @@ func (server *Server) googleCallback(c *gin.Context) {
 		var err error
 		// TODO: Get fcm token
 		userData, err = services.LogOAuthUserIn(services.LoginReq{Identifier: googleUser.Email, FcmToken: fcmToken}, *server.tokenService, server.store, c, server.config, clientIP, agent)
+		metrics.Login("google", err)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, errorResponse(err))
+			httpserver.Error(c, err)
 			return
 		}
 	}
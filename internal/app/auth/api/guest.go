@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+)
+
+func (s *Server) createGuestSession(ctx *gin.Context) {
+	var req services.GuestSessionReq
+	// The body is optional, guests may not send any device details
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpserver.BindingError(ctx, err)
+			return
+		}
+	}
+
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	guest, err := services.CreateGuestSession(ctx, s.store, s.tokenService, s.config, req, clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, guest)
+}
+
+func (s *Server) rotateGuestToken(ctx *gin.Context) {
+	var req services.RotateTokenReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	authToken, err := services.RotateGuestToken(ctx, req, s.store, s.tokenService, s.tokenMaker, s.cache, s.config, clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authToken)
+}
+
+// upgradeGuest merges a guest session into the account that just registered or logged in.
+// The account is already usable at this point so failures are only logged.
+func (s *Server) upgradeGuest(ctx *gin.Context, guestToken string, uid uuid.UUID) {
+	if guestToken == "" {
+		return
+	}
+
+	if err := services.UpgradeGuestSession(ctx, s.store, s.tokenMaker, s.cache, guestToken, uid); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("user_id", uid.String()).Msg("could not upgrade guest session")
+	}
+}
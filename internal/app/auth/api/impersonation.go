@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) startImpersonation(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.StartImpersonationReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	clientIP := ctx.ClientIP()
+	agent := ctx.Request.UserAgent()
+
+	res, err := services.StartImpersonation(ctx, s.store, s.tokenService, s.cache, s.config, authPayload, req, clientIP, agent)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (s *Server) endImpersonation(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	err := services.EndImpersonation(ctx, s.store, s.cache, authPayload, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Impersonation ended",
+	})
+}
+
+func (s *Server) listImpersonations(ctx *gin.Context) {
+	sessions, err := services.ListActiveImpersonations(ctx, s.store)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+func (s *Server) revokeImpersonation(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		httpserver.Error(ctx, errInvalidID)
+		return
+	}
+
+	err = services.RevokeImpersonation(ctx, s.store, s.cache, sessionID, authPayload.Subject, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Impersonation revoked",
+	})
+}
@@ -5,12 +5,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
 )
 
 func (s *Server) login(ctx *gin.Context) {
 	var req services.LoginReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
@@ -18,11 +20,14 @@ func (s *Server) login(ctx *gin.Context) {
 	agent := ctx.Request.UserAgent()
 
 	userData, err := services.LogUserIn(req, *s.tokenService, s.store, ctx, s.config, clientIP, agent)
+	metrics.Login("password", err)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
+	s.upgradeGuest(ctx, req.GuestToken, userData.Uid)
+
 	ctx.JSON(http.StatusOK, userData)
 
 }
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 	"github.com/steve-mir/bukka_backend/token"
 )
 
@@ -14,20 +16,26 @@ func (s *Server) logout(ctx *gin.Context) {
 
 	authorizationHeader := ctx.GetHeader(middlewares.AuthorizationHeaderKey)
 	if len(authorizationHeader) == 0 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is not provided"})
+		httpserver.Error(ctx, middlewares.ErrMissingAuthHeader)
 		return
 	}
 
 	fields := strings.Fields(authorizationHeader)
 	if len(fields) < 2 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+		httpserver.Error(ctx, middlewares.ErrInvalidAuthHeader)
+		return
+	}
+
+	// Logging out of an impersonation closes the impersonation session as well
+	if authPayload.IsImpersonated() {
+		s.endImpersonation(ctx)
 		return
 	}
 
 	accessToken := fields[1]
-	err := s.tokenMaker.RevokeTokenAccessToken(accessToken, ctx, s.store, *s.cache)
+	err := s.tokenMaker.RevokeTokenAccessToken(accessToken, ctx, s.store, s.cache)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Error revoking token" + err.Error()})
+		httpserver.Error(ctx, fmt.Errorf("failed to revoke token: %w", err))
 		return
 	}
 
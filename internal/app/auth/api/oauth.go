@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) openIDConfiguration(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, s.oauthService.Discovery("/"+baseURL))
+}
+
+func (s *Server) jwks(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.JSON(http.StatusOK, s.oauthService.JWKS())
+}
+
+// authorize validates the request the consent screen was opened with and tells it what to show.
+func (s *Server) authorize(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.AuthorizeReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	res, err := s.oauthService.Authorize(ctx, authPayload, req)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (s *Server) authorizeDecision(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.AuthorizeDecisionReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	res, err := s.oauthService.Decide(ctx, authPayload, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (s *Server) oauthToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req services.TokenReq
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	basicID, basicSecret, _ := ctx.Request.BasicAuth()
+	res, err := s.oauthService.Exchange(ctx, req, basicID, basicSecret, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (s *Server) userInfo(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	claims, err := s.oauthService.UserInfo(ctx, authPayload)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, claims)
+}
+
+func (s *Server) listOAuthConsents(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	consents, err := s.oauthService.ListConsents(ctx, authPayload.Subject)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, consents)
+}
+
+func (s *Server) revokeOAuthConsent(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	err := s.oauthService.RevokeConsent(ctx, authPayload.Subject, ctx.Param("client_id"), ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "App access revoked",
+	})
+}
+
+func (s *Server) registerOAuthClient(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	var req services.RegisterOAuthClientReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	client, err := s.oauthService.RegisterClient(ctx, authPayload, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, client)
+}
+
+func (s *Server) listOAuthClients(ctx *gin.Context) {
+	clients, err := s.oauthService.ListClients(ctx)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clients)
+}
+
+func (s *Server) disableOAuthClient(ctx *gin.Context) {
+	if err := s.oauthService.DisableClient(ctx, ctx.Param("id")); err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.GenericRes{
+		Msg: "Client disabled",
+	})
+}
+
+// oauthErrorResponse writes OAuth errors in the RFC 6749 format and anything else as an internal error.
+func oauthErrorResponse(ctx *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if errors.As(err, &oauthErr) {
+		ctx.JSON(oauthErr.Status, oauthErr)
+		return
+	}
+	logging.FromContext(ctx).Error().Err(err).Str("path", ctx.FullPath()).Msg("oauth request failed")
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": "internal server error"})
+}
@@ -1,21 +1,27 @@
 package api
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 	"github.com/steve-mir/bukka_backend/token"
 )
 
 func (s *Server) viewProfile(ctx *gin.Context) {
 	authPayload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
 
-	user, err := s.store.GetUserProfile(ctx, sql.NullString{String: authPayload.Subject.String(), Valid: true})
+	user, err := s.store.GetUserProfile(ctx, pgtype.Text{String: authPayload.Subject.String(), Valid: true})
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = services.ErrUserNotFound
+		}
+		httpserver.Error(ctx, err)
 		return
 	}
 
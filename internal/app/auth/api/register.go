@@ -4,73 +4,69 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
 )
 
 func (s *Server) register(ctx *gin.Context) {
 	var req services.RegisterReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
 	clientIP := ctx.ClientIP()
 	agent := ctx.Request.UserAgent()
 
-	// Begin transaction
-	tx, err := s.db.Begin()
-
+	// hash pwd and generate uuid
+	hashedPwd, uid, err := services.PrepareUserData(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
-	defer tx.Rollback()
 
-	qtx := sqlc.New(tx)
+	var sqlcUser sqlc.Authentication
+	err = s.store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		// Check db if user exists
+		if err := services.CheckUserExists(ctx, qtx, req.Email, req.Username); err != nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("failed to check whether the email or username is taken")
+			return err
+		}
 
-	// Check db if user exists
-	if err := services.CheckUserExists(ctx, qtx, req.Email, req.Username); err != nil {
-		// return nil, status.Errorf(codes.AlreadyExists, err.Error())
-		log.Err(err).Msg("Error2")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
+		var err error
+		sqlcUser, err = services.CreateUserConcurrent(ctx, qtx, uid, req.Email, req.Username, hashedPwd, false, false)
+		if err != nil {
+			logging.FromContext(ctx).Error().Err(err).Str("user_id", uid.String()).Msg("failed to create user")
+			return err
+		}
 
-	// hash pwd and generate uuid
-	hashedPwd, uid, err := services.PrepareUserData(req.Password)
-	if err != nil {
-		// return nil, status.Errorf(codes.Internal, err.Error())
-		log.Err(err).Msg("Error3")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
+		// Record the accepted terms and privacy policy versions
+		if err := services.RecordRegistrationConsent(ctx, qtx, uid, req.TermsVersion, req.PrivacyVersion, clientIP, agent); err != nil {
+			logging.FromContext(ctx).Error().Err(err).Str("user_id", uid.String()).Msg("failed to record registration consent")
+			return err
+		}
 
-	sqlcUser, err := services.CreateUserConcurrent(ctx, qtx /*tx,*/, uid, req.Email, req.Username, hashedPwd, false, false)
+		// Also queues the verification email, sent once the account committed
+		err = services.RunUserCreationTasks(ctx, qtx, req, uid, false)
+		if err != nil {
+			logging.FromContext(ctx).Error().Err(err).Str("user_id", uid.String()).Msg("failed to run user creation tasks")
+		}
+		return err
+	})
 	if err != nil {
-		// return nil, status.Errorf(codes.Internal, "error while creating user with email and password %s", err)
-		log.Err(err).Msg("Error4")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
-	// Run concurrent operations
-	accessToken, accessExp, err := services.RunConcurrentUserCreationTasks(ctx, s.tokenMaker, qtx, tx, s.config, s.taskDistributor, req, uid, clientIP, agent, false)
+	accessToken, accessExp, err := services.CreateRegistrationAccessToken(s.tokenMaker, s.config, req, uid, clientIP, agent)
 	if err != nil {
-		// return nil, status.Errorf(codes.Internal, "error creating details: %s", err.Error())
-		log.Err(err).Msg("Error5")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
-	// Only commit the transaction if all previous steps were successful
-	if err := tx.Commit(); err != nil {
-		// return nil, status.Errorf(codes.Internal, "an unexpected error occurred during transaction commit: %s", err)
-		log.Err(err).Msg("Error6")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
+	s.upgradeGuest(ctx, req.GuestToken, uid)
 
 	ctx.JSON(http.StatusOK, services.UserAuthRes{
 		Uid:             sqlcUser.ID,
@@ -5,21 +5,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 )
 
 func (s *Server) rotateToken(ctx *gin.Context) {
 	var req services.RotateTokenReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.BindingError(ctx, err)
 		return
 	}
 
 	clientIP := ctx.ClientIP()
 	agent := ctx.Request.UserAgent()
 
-	userData, err := services.RotateUserToken(req, s.tokenService, *s.cache, s.store, s.tokenMaker, ctx, s.config, clientIP, agent)
+	userData, err := services.RotateUserToken(req, s.tokenService, s.cache, s.store, s.tokenMaker, ctx, s.config, clientIP, agent)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		httpserver.Error(ctx, err)
 		return
 	}
 
@@ -1,10 +1,6 @@
 package api
 
 import (
-	"database/sql"
-	"fmt"
-	"time"
-
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -13,48 +9,61 @@ import (
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
 	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/oidc"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/storage"
 	"github.com/steve-mir/bukka_backend/token"
 	"github.com/steve-mir/bukka_backend/utils"
 	"github.com/steve-mir/bukka_backend/worker"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
-	"golang.org/x/time/rate"
 )
 
 const (
 	baseURL = "v1/auth"
 )
 
-type RouteConfig struct {
-	Method      string
-	Path        string
-	Handler     gin.HandlerFunc
-	Middlewares []gin.HandlerFunc
-	Roles       []int8
-	RateLimit   middlewares.RateLimitConfig
-}
-
 type Server struct {
 	store           db.Store
 	Router          *gin.Engine
-	db              *sql.DB
 	config          utils.Config
+	configWatcher   *utils.ConfigWatcher
 	taskDistributor worker.TaskDistributor
 	tokenService    *services.TokenService
+	consentService  *services.ConsentService
+	apiKeyService   *services.APIKeyService
+	oauthService    *services.OAuthService
 	oauthConfig     *oauth2.Config
 	tokenMaker      token.Maker
-	cache           *cache.Cache
+	cache           cache.Cache
+	storage         storage.Storage
+	flags           *featureflag.Client
 }
 
-func NewServer(store db.Store, db *sql.DB, config utils.Config, td worker.TaskDistributor) *Server {
+// NewServer builds the service on resources shared with the other services of the process. Reloads of
+// configWatcher apply to its rate limits.
+func NewServer(store db.Store, configWatcher *utils.ConfigWatcher, td worker.TaskDistributor, cache cache.Cache) *Server {
+	config := configWatcher.Current()
+
 	tokenMaker, err := token.NewPasetoMaker(config.AccessTokenSymmetricKey, config.RefreshTokenSymmetricKey)
 	if err != nil {
 		panic(err)
 	}
 
-	cache := cache.NewCache(config.RedisAddress, config.RedisUsername, config.RedisPwd, 0)
 	tokenService := services.NewTokenService(config, cache, tokenMaker)
 
+	exportStorage, err := storage.NewLocalStorage(config.ExportStorageDir)
+	if err != nil {
+		panic(err)
+	}
+
+	oauthService, err := services.NewOAuthService(store, tokenService, tokenMaker, cache, config)
+	if err != nil {
+		panic(err)
+	}
+
 	oauthConfig := &oauth2.Config{
 		ClientID:     config.GoogleOauthClientId,
 		ClientSecret: config.GoogleOauthClientSecret,
@@ -65,13 +74,18 @@ func NewServer(store db.Store, db *sql.DB, config utils.Config, td worker.TaskDi
 
 	server := &Server{
 		store:           store,
-		db:              db,
 		config:          config,
+		configWatcher:   configWatcher,
 		taskDistributor: td,
 		tokenService:    tokenService,
+		consentService:  services.NewConsentService(store, cache),
+		apiKeyService:   services.NewAPIKeyService(store),
+		oauthService:    oauthService,
 		oauthConfig:     oauthConfig,
 		tokenMaker:      tokenMaker,
 		cache:           cache,
+		storage:         exportStorage,
+		flags:           featureflag.NewClient(store, cache),
 	}
 
 	server.setupValidator()
@@ -87,64 +101,94 @@ func (server *Server) setupValidator() {
 		v.RegisterValidation("passwordValidator", utils.ValidPassword)
 		v.RegisterValidation("usernameValidator", utils.ValidUsername)
 	}
+	apperr.RegisterRule("emailValidator", "validation.email", constants.InvalidEmail)
+	apperr.RegisterRule("phoneValidator", "validation.phone", constants.InvalidPhone)
+	apperr.RegisterRule("passwordValidator", "validation.password", constants.InvalidPassword)
+	apperr.RegisterRule("usernameValidator", "validation.username", constants.InvalidUsername)
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
-	rl := setupRateLimiter()
-	router.Use(middlewares.RateLimit(rl))
-
-	routes := []RouteConfig{
-		{Method: "POST", Path: "register", Handler: server.register, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(10 * time.Second), Burst: 2}},
-		{Method: "POST", Path: "login", Handler: server.login, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(5 * time.Second), Burst: 3}},
-		{Method: "GET", Path: "google/login", Handler: server.googleLogin, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(5 * time.Second), Burst: 3}},
+	routes := []httpserver.Route{
+		// OpenID Connect discovery lives at the root so clients can find it from the issuer url
+		{Method: "GET", Path: oidc.DiscoveryPath, Handler: server.openIDConfiguration},
+		{Method: "GET", Path: oidc.JWKSPath, Handler: server.jwks},
+		{Method: "POST", Path: "register", Handler: server.register},
+		{Method: "POST", Path: "login", Handler: server.login},
+		{Method: "GET", Path: "google/login", Handler: server.googleLogin},
 		{Method: "GET", Path: "google/callback", Handler: server.googleCallback},
-		{Method: "POST", Path: "rotate_token", Handler: server.rotateToken, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "POST", Path: "verify_email", Handler: server.verifyEmail, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "GET", Path: "resend_verification", Handler: server.resendVerificationEmail, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "DELETE", Path: "delete_account", Handler: server.deleteAccount, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "POST", Path: "request_account_recovery", Handler: server.requestAccountRecovery, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "GET", Path: "recover_account", Handler: server.completeAccountRecovery, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "POST", Path: "change_password", Handler: server.changePwd, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "POST", Path: "forgot_password", Handler: server.forgotPwd, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "POST", Path: "reset_password", Handler: server.resetPwd, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(1 * time.Minute), Burst: 1}},
-		{Method: "GET", Path: "profile", Handler: server.viewProfile, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
-		{Method: "GET", Path: "logout", Handler: server.logout, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(5 * time.Second), Burst: 3}},
+		{Method: "POST", Path: "rotate_token", Handler: server.rotateToken},
+		{Method: "POST", Path: "verify_email", Handler: server.verifyEmail},
+		{Method: "GET", Path: "data_export/download", Handler: server.downloadDataExport},
+		{Method: "GET", Path: "policies", Handler: server.currentPolicies},
+		{Method: "POST", Path: "guest", Handler: server.createGuestSession},
+		{Method: "POST", Path: "guest/rotate_token", Handler: server.rotateGuestToken},
+		{Method: "POST", Path: "oauth/token", Handler: server.oauthToken},
+		{Method: "GET", Path: "resend_verification", Handler: server.resendVerificationEmail, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "DELETE", Path: "delete_account", Handler: server.deleteAccount, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "POST", Path: "request_account_recovery", Handler: server.requestAccountRecovery},
+		{Method: "GET", Path: "recover_account", Handler: server.completeAccountRecovery},
+		{Method: "POST", Path: "change_password", Handler: server.changePwd, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "POST", Path: "forgot_password", Handler: server.forgotPwd},
+		{Method: "POST", Path: "reset_password", Handler: server.resetPwd},
+		{Method: "GET", Path: "profile", Handler: server.viewProfile, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, Scope: constants.ScopeProfileRead},
+		{Method: "GET", Path: "logout", Handler: server.logout, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "POST", Path: "data_export", Handler: server.requestDataExport, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "POST", Path: "consents", Handler: server.acceptConsent, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "GET", Path: "consents", Handler: server.listConsents, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "POST", Path: "admin/policies", Handler: server.publishPolicy, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/consents/report", Handler: server.consentReport, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "POST", Path: "admin/impersonations", Handler: server.startImpersonation, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin}},
+		{Method: "GET", Path: "admin/impersonations", Handler: server.listImpersonations, Roles: []int8{constants.SuperAdmin}},
+		{Method: "DELETE", Path: "admin/impersonations/:id", Handler: server.revokeImpersonation, Roles: []int8{constants.SuperAdmin}},
+		{Method: "POST", Path: "impersonation/end", Handler: server.endImpersonation, Roles: []int8{constants.AppAdmin, constants.RegularUsers}, SkipConsent: true},
+		{Method: "POST", Path: "admin/service_accounts", Handler: server.createServiceAccount, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/service_accounts", Handler: server.listServiceAccounts, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "DELETE", Path: "admin/service_accounts/:id", Handler: server.disableServiceAccount, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "POST", Path: "api_keys", Handler: server.createAPIKey, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "GET", Path: "api_keys", Handler: server.listAPIKeys, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "POST", Path: "api_keys/:id/rotate", Handler: server.rotateAPIKey, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "DELETE", Path: "api_keys/:id", Handler: server.revokeAPIKey, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "GET", Path: "oauth/authorize", Handler: server.authorize, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "POST", Path: "oauth/authorize", Handler: server.authorizeDecision, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "GET", Path: "oauth/userinfo", Handler: server.userInfo, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, SkipConsent: true, Scope: constants.ScopeOpenID},
+		{Method: "GET", Path: "oauth/consents", Handler: server.listOAuthConsents, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "DELETE", Path: "oauth/consents/:client_id", Handler: server.revokeOAuthConsent, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}},
+		{Method: "POST", Path: "admin/oauth_clients", Handler: server.registerOAuthClient, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/oauth_clients", Handler: server.listOAuthClients, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "DELETE", Path: "admin/oauth_clients/:id", Handler: server.disableOAuthClient, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "POST", Path: "admin/feature_flags", Handler: server.createFeatureFlag, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/feature_flags", Handler: server.listFeatureFlags, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/feature_flags/:key", Handler: server.getFeatureFlag, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "PUT", Path: "admin/feature_flags/:key", Handler: server.updateFeatureFlag, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "DELETE", Path: "admin/feature_flags/:key", Handler: server.deleteFeatureFlag, Middlewares: []gin.HandlerFunc{middlewares.BlockImpersonation()}, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}},
+		{Method: "GET", Path: "admin/config", Handler: server.dumpConfig, Roles: []int8{constants.SuperAdmin}},
 		{Method: "GET", Path: "home", Handler: server.home},
 	}
 
-	accessibleRoles := make(map[string][]int8)
-
-	for _, route := range routes {
-		fullPath := fmt.Sprintf("%s /%s/%s", route.Method, baseURL, route.Path)
-		handlers := []gin.HandlerFunc{route.Handler}
-
-		if len(route.Roles) > 0 {
-			accessibleRoles[fullPath] = route.Roles
-			router.Use(middlewares.AuthMiddleWare(server.config, server.tokenMaker, *server.cache, accessibleRoles))
-		}
-
-		if route.RateLimit.Rate != 0 {
-			rl.SetRateLimitConfig("/"+route.Path, route.RateLimit)
-		}
-
-		router.Handle(route.Method, "/"+baseURL+"/"+route.Path, handlers...)
+	opts, err := middlewares.RouterOptions("auth", baseURL, middlewares.RouteOptions{
+		Config:          server.config,
+		ConfigWatcher:   server.configWatcher,
+		TokenMaker:      server.tokenMaker,
+		Cache:           server.cache,
+		RateLimitPrefix: "ratelimit:auth",
+		Consent:         server.consentService,
+		APIKeys:         server.apiKeyService,
+	})
+	if err != nil {
+		panic(err)
 	}
 
-	// Apply the auth middleware to all routes
-	// router.Use(middlewares.AuthMiddleWare(server.config, server.tokenMaker, *server.cache, accessibleRoles))
+	router, err := httpserver.NewRouter(opts, routes)
+	if err != nil {
+		panic(err)
+	}
 
 	server.Router = router
 }
 
-func setupRateLimiter() *middlewares.RateLimiter {
-	return middlewares.NewRateLimiter()
-}
-
 func (server *Server) Start(address string) error {
 	return server.Router.Run(address)
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
+// errInvalidID answers ids in the path that are not UUIDs
+var errInvalidID = apperr.InvalidField("id", "validation.uuid", "must be a valid id")
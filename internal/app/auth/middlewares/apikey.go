@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+// APIKeyVerifier resolves a raw API key into the payload of the user or service account that owns it.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, rawKey string) (*token.Payload, error)
+}
+
+// APIKeyPolicy plugs API key authentication into AuthMiddleWare. Only routes listed in Scopes
+// (indexed by full method, like accessibleRoles) accept API keys, and the key must carry that scope.
+// The same scopes apply to access tokens issued to oauth clients.
+type APIKeyPolicy struct {
+	Verifier APIKeyVerifier
+	Scopes   map[string]string
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
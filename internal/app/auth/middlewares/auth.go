@@ -3,11 +3,16 @@ package middlewares
 import (
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
 	"github.com/steve-mir/bukka_backend/token"
 	"github.com/steve-mir/bukka_backend/utils"
 )
@@ -15,70 +20,215 @@ import (
 const (
 	AuthorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
+	APIKeyHeaderKey         = "X-API-Key"
+	ImpersonatedByHeaderKey = "X-Impersonated-By"
 )
 
 var (
 	AuthorizationPayloadKey = "authorization_payload"
 )
 
+var (
+	// ErrConsentRequired rejects users that have not accepted the current terms of service and privacy policy
+	ErrConsentRequired = apperr.New(apperr.CodeConsentRequired, "auth.consent.required", "please accept the latest terms of service and privacy policy")
+	// ErrImpersonationForbidden rejects impersonation tokens on routes guarded by BlockImpersonation
+	ErrImpersonationForbidden = apperr.New(apperr.CodeImpersonationForbidden, "auth.impersonation.forbidden", "this action is not allowed while impersonating a user")
+
+	// ErrRateLimited answers requests over one of their rate limits
+	ErrRateLimited = apperr.New(apperr.CodeResourceExhausted, "error.rate_limited", "rate limit exceeded")
+
+	// ErrMissingAuthHeader and ErrInvalidAuthHeader reject requests without a usable Authorization header
+	ErrMissingAuthHeader = apperr.New(apperr.CodeUnauthenticated, "auth.header.missing", "authorization header is not provided")
+	ErrInvalidAuthHeader = apperr.New(apperr.CodeUnauthenticated, "auth.header.invalid", "invalid authorization header format")
+
+	errNotAuthorized       = apperr.New(apperr.CodeUnauthenticated, "auth.route.unauthorized", "you are not allowed to access this route")
+	errUnsupportedAuthType = apperr.New(apperr.CodeUnauthenticated, "auth.header.unsupported_type", "unsupported authorization type")
+	errInvalidToken        = apperr.New(apperr.CodeUnauthenticated, "auth.token.invalid", "invalid token")
+	errExpiredToken        = apperr.New(apperr.CodeUnauthenticated, "auth.token.expired", "access token has expired")
+	errInvalidAPIKey       = apperr.New(apperr.CodeUnauthenticated, "auth.api_key.invalid", "invalid api key")
+	errAPIKeyNotAccepted   = apperr.New(apperr.CodeUnauthenticated, "auth.api_key.not_accepted", "api keys are not accepted for this route")
+	errScopedNotAccepted   = apperr.New(apperr.CodeUnauthenticated, "auth.scope.not_accepted", "scoped credentials are not accepted for this route")
+	errMissingScope        = apperr.New(apperr.CodePermissionDenied, "auth.scope.missing", "the credential is missing a required scope")
+	errEmailNotVerified    = apperr.New(apperr.CodeUnauthenticated, "auth.email.not_verified", "account not verified")
+	errForbiddenRole       = apperr.New(apperr.CodePermissionDenied, "auth.role.forbidden", "you don't have permission to access this resource")
+)
+
 // I want to add authorization to this auth middleware. The idea is that endpoint will have the permissions assigned to it. EG
 // get_menu endpoint might have only 1,2 roles. And will be the accessibleRoles. Refactor this to be able to fit the narrative
 // Also note the full method should be used to index it so as to get the permissions(roles)
-func AuthMiddleWare(config utils.Config, tokenMaker token.Maker, cache cache.Cache, accessibleRoles map[string][]int8) gin.HandlerFunc {
+// If consent is not nil, users that haven't accepted the current policy documents are rejected with ErrConsentRequired.
+// If apiKeys is not nil, routes with a scope also accept an API key through the X-API-Key header or the ApiKey scheme,
+// and tokens issued to oauth clients with that scope.
+func AuthMiddleWare(config utils.Config, tokenMaker token.Maker, cache cache.Cache, accessibleRoles map[string][]int8, consent *ConsentPolicy, apiKeys *APIKeyPolicy) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		fullMethod := fmt.Sprintf("%s %s", ctx.Request.Method, ctx.FullPath())
 		roles, ok := accessibleRoles[fullMethod]
 		if !ok {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "sorry you do not have enough permissions to access: " + fullMethod})
+			ctx.AbortWithStatusJSON(apperr.Response(errNotAuthorized))
 			return
 		}
 
-		authorizationHeader := ctx.GetHeader(AuthorizationHeaderKey)
-		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		authorizationType, credential, err := extractCredential(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(apperr.Response(err))
 			return
 		}
 
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
+		var payload *token.Payload
+		switch authorizationType {
+		case authorizationTypeBearer:
+			payload, err = tokenMaker.VerifyToken(ctx, cache, credential, token.AccessToken) // Decrypts the access token and returns the data stored in it
+			if err != nil {
+				ctx.AbortWithStatusJSON(apperr.Response(tokenError(err)))
+				return
+			}
 
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case authorizationTypeAPIKey:
+			if apiKeys == nil {
+				ctx.AbortWithStatusJSON(apperr.Response(errAPIKeyNotAccepted))
+				return
+			}
+
+			payload, err = apiKeys.Verifier.VerifyAPIKey(ctx, credential)
+			if err != nil {
+				ctx.AbortWithStatusJSON(apperr.Response(apiKeyError(err)))
+				return
+			}
+
+		default:
+			ctx.AbortWithStatusJSON(apperr.Response(errUnsupportedAuthType))
 			return
 		}
 
-		accessToken := fields[1]
+		// API keys and tokens issued to oauth clients can only reach routes with a scope they were granted
+		if payload.AuthMethod == token.AuthAPIKey || payload.AuthMethod == token.AuthOAuth {
+			var scope string
+			if apiKeys != nil {
+				scope, ok = apiKeys.Scopes[fullMethod]
+			}
+			if !ok || scope == "" {
+				ctx.AbortWithStatusJSON(apperr.Response(errScopedNotAccepted))
+				return
+			}
 
-		payload, err := tokenMaker.VerifyToken(ctx, cache, accessToken, token.AccessToken) // Decrypts the access token and returns the data stored in it
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
+			if !hasScope(payload.Scopes, scope) {
+				ctx.AbortWithStatusJSON(apperr.Response(errMissingScope.WithFields(apperr.FieldError{
+					Field: "scope", Rule: "scope", Key: "auth.scope.missing", Message: "is required", Param: scope,
+				})))
+				return
+			}
 		}
 
+		// Guests have no account so there is no email to verify or policy consent to check.
+		// API keys are used by integrations, consent is given by the user that created the key
+		isGuest := payload.Role == constants.Guests
+		isAPIKey := payload.AuthMethod == token.AuthAPIKey
+
 		// Check if email is verified (remove if it is optional to verify email)
-		if !payload.EmailVerified {
-			fmt.Println("Error: Please verify your account")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "account not verified"})
+		if !payload.EmailVerified && !isGuest && !isAPIKey {
+			ctx.AbortWithStatusJSON(apperr.Response(errEmailNotVerified))
 			return
 		}
 
 		if !isAuthorized(payload.Role, roles) {
-			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "you don't have permission to access this resource"})
+			ctx.AbortWithStatusJSON(apperr.Response(errForbiddenRole))
 			return
 		}
 
+		// Flag every response so clients can show that an admin is acting as the user
+		if payload.IsImpersonated() {
+			ctx.Header(ImpersonatedByHeaderKey, payload.ImpersonatorID.String())
+		}
+
+		if consent != nil && !isGuest && !isAPIKey && !payload.IsImpersonated() && !consent.Exempt[fullMethod] {
+			accepted, err := consent.Checker.HasCurrentConsent(ctx, payload.Subject)
+			if err != nil {
+				logging.FromContext(ctx).Error().Err(err).Msg("could not verify policy consent")
+				ctx.AbortWithStatusJSON(apperr.Response(apperr.Internal(err)))
+				return
+			}
+
+			if !accepted {
+				ctx.AbortWithStatusJSON(apperr.Response(ErrConsentRequired))
+				return
+			}
+		}
+
 		ctx.Set(AuthorizationPayloadKey, payload)
+		tagSubject(ctx, payload)
 		ctx.Next()
 	}
 }
 
+// tagSubject tags the request with the caller so the store keeps their reads on the primary right after they wrote,
+// so its logs name them and so feature flags are evaluated for them.
+func tagSubject(ctx *gin.Context, payload *token.Payload) {
+	reqCtx := sqlc.WithSubject(ctx.Request.Context(), payload.Subject.String())
+	reqCtx = featureflag.WithSubject(reqCtx, featureflag.SubjectFromPayload(payload.PayloadData))
+	sessionID := ""
+	if payload.SessionID != uuid.Nil {
+		sessionID = payload.SessionID.String()
+	}
+	ctx.Request = ctx.Request.WithContext(logging.WithUser(reqCtx, payload.Subject.String(), sessionID))
+}
+
+// extractCredential returns the authorization scheme and credential of the request.
+// The X-API-Key header takes precedence over the authorization header.
+func extractCredential(ctx *gin.Context) (string, string, error) {
+	if apiKey := ctx.GetHeader(APIKeyHeaderKey); apiKey != "" {
+		return authorizationTypeAPIKey, apiKey, nil
+	}
+
+	authorizationHeader := ctx.GetHeader(AuthorizationHeaderKey)
+	if len(authorizationHeader) == 0 {
+		return "", "", ErrMissingAuthHeader
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return "", "", ErrInvalidAuthHeader
+	}
+
+	return strings.ToLower(fields[0]), fields[1], nil
+}
+
+// BlockImpersonation rejects requests made with impersonation tokens. It is used on routes that change
+// credentials or account settings which an admin must never do on the user's behalf.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, exists := ctx.Get(AuthorizationPayloadKey)
+		if !exists {
+			ctx.Next()
+			return
+		}
+
+		if payload, ok := value.(*token.Payload); ok && payload.IsImpersonated() {
+			ctx.AbortWithStatusJSON(apperr.Response(ErrImpersonationForbidden))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// tokenError tells clients whether to refresh an expired token, other failures stay vague.
+func tokenError(err error) error {
+	if errors.Is(err, token.ErrExpiredToken) {
+		return errExpiredToken.WithCause(err)
+	}
+	return errInvalidToken.WithCause(err)
+}
+
+// apiKeyError keeps the errors the verifier meant for clients and hides the rest.
+func apiKeyError(err error) error {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return errInvalidAPIKey.WithCause(err)
+}
+
 func isAuthorized(userRole int8, allowedRoles []int8) bool {
 	for _, role := range allowedRoles {
 		if userRole == role {
@@ -0,0 +1,161 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/internal/apikey"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAPIKeyVerifier map[string]*token.Payload
+
+func (f fakeAPIKeyVerifier) VerifyAPIKey(ctx context.Context, rawKey string) (*token.Payload, error) {
+	payload, ok := f[rawKey]
+	if !ok {
+		return nil, apikey.ErrMalformedKey
+	}
+	return payload, nil
+}
+
+func TestAuthMiddleWareAPIKey(t *testing.T) {
+	verifier := fakeAPIKeyVerifier{
+		"reader": {PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New(), AuthMethod: token.AuthAPIKey, Scopes: []string{constants.ScopeCartRead}}},
+	}
+	roles := map[string][]int8{
+		"GET /cart":    {constants.RegularUsers},
+		"PUT /cart":    {constants.RegularUsers},
+		"GET /profile": {constants.RegularUsers},
+	}
+	apiKeys := &APIKeyPolicy{Verifier: verifier, Scopes: map[string]string{
+		"GET /cart": constants.ScopeCartRead,
+		"PUT /cart": constants.ScopeCartWrite,
+	}}
+
+	router := gin.New()
+	router.Use(AuthMiddleWare(utils.Config{}, nil, cache.NewMemory(), roles, nil, apiKeys))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	router.GET("/cart", ok)
+	router.PUT("/cart", ok)
+	router.GET("/profile", ok)
+
+	send := func(method, path string, headers map[string]string) int {
+		req, _ := http.NewRequest(method, path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("GET", "/cart", map[string]string{APIKeyHeaderKey: "reader"}))
+	assert.Equal(t, http.StatusOK, send("GET", "/cart", map[string]string{AuthorizationHeaderKey: "ApiKey reader"}))
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/cart", map[string]string{APIKeyHeaderKey: "unknown"}))
+	assert.Equal(t, http.StatusForbidden, send("PUT", "/cart", map[string]string{APIKeyHeaderKey: "reader"}))
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/profile", map[string]string{APIKeyHeaderKey: "reader"}))
+}
+
+func TestBlockImpersonation(t *testing.T) {
+	user := &token.Payload{PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New()}}
+	impersonated := &token.Payload{PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New(), ImpersonatorID: uuid.New()}}
+
+	var current *token.Payload
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(AuthorizationPayloadKey, current)
+		c.Next()
+	})
+	router.POST("/change_password", BlockImpersonation(), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	current = user
+	assert.Equal(t, http.StatusOK, performRequest(router, "POST", "/change_password").Code)
+
+	current = impersonated
+	w := performRequest(router, "POST", "/change_password")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), string(apperr.CodeImpersonationForbidden))
+}
+
+// fakeTokenMaker verifies the tokens it was given the payloads of.
+type fakeTokenMaker struct {
+	token.Maker
+	payloads map[string]*token.Payload
+}
+
+func (f fakeTokenMaker) VerifyToken(ctx context.Context, sessions cache.Cache, raw string, tokenType token.TokenType) (*token.Payload, error) {
+	payload, ok := f.payloads[raw]
+	if !ok {
+		return nil, token.ErrInvalidToken
+	}
+	return payload, nil
+}
+
+// fakeConsent has the users of accepted accept the current policy documents.
+type fakeConsent struct {
+	accepted map[uuid.UUID]bool
+	checked  int
+}
+
+func (f *fakeConsent) HasCurrentConsent(ctx context.Context, userID uuid.UUID) (bool, error) {
+	f.checked++
+	return f.accepted[userID], nil
+}
+
+func TestAuthMiddleWareConsent(t *testing.T) {
+	accepted, outdated, guest := uuid.New(), uuid.New(), uuid.New()
+	maker := fakeTokenMaker{payloads: map[string]*token.Payload{
+		"accepted": {PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: accepted, EmailVerified: true}},
+		"outdated": {PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: outdated, EmailVerified: true}},
+		"impersonated": {PayloadData: token.PayloadData{
+			Role: constants.RegularUsers, Subject: outdated, EmailVerified: true, ImpersonatorID: uuid.New(),
+		}},
+		"guest": {PayloadData: token.PayloadData{Role: constants.Guests, Subject: guest}},
+	}}
+	roles := map[string][]int8{
+		"GET /profile":  {constants.RegularUsers, constants.Guests},
+		"POST /consent": {constants.RegularUsers},
+	}
+	checker := &fakeConsent{accepted: map[uuid.UUID]bool{accepted: true}}
+	consent := &ConsentPolicy{Checker: checker, Exempt: map[string]bool{"POST /consent": true}}
+
+	router := gin.New()
+	router.Use(AuthMiddleWare(utils.Config{}, maker, cache.NewMemory(), roles, consent, nil))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	router.GET("/profile", ok)
+	router.POST("/consent", ok)
+
+	send := func(method, path, bearer string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set(AuthorizationHeaderKey, "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("GET", "/profile", "accepted").Code)
+
+	w := send("GET", "/profile", "outdated")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), string(apperr.CodeConsentRequired))
+
+	// Users with outdated consent can still reach the routes that let them accept it
+	assert.Equal(t, http.StatusOK, send("POST", "/consent", "outdated").Code)
+
+	// Guests have nothing to accept and admins acting as a user can't accept for them
+	checked := checker.checked
+	assert.Equal(t, http.StatusOK, send("GET", "/profile", "guest").Code)
+	assert.Equal(t, http.StatusOK, send("GET", "/profile", "impersonated").Code)
+	assert.Equal(t, checked, checker.checked)
+}
@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ConsentChecker reports whether a user has accepted the current terms and privacy policy.
+type ConsentChecker interface {
+	HasCurrentConsent(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// ConsentPolicy plugs consent enforcement into AuthMiddleWare. Routes listed in Exempt
+// (indexed by full method, like accessibleRoles) can still be used with outdated consent.
+type ConsentPolicy struct {
+	Checker ConsentChecker
+	Exempt  map[string]bool
+}
@@ -1,12 +1,12 @@
 package middlewares
 
 import (
-	"log"
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
 )
 
 // ab -n 20 -c 5 -r -s 1  http://localhost:6000/v1/auth/test
@@ -67,15 +67,10 @@ func (lb *LeakyBucket) Allow() bool {
 		lb.tokens = lb.capacity
 	}
 
-	log.Printf("Elapsed: %f, Tokens: %d", elapsed, lb.tokens)
-
 	if lb.tokens > 0 {
 		lb.tokens--
-		log.Printf("Allowing request. Tokens left: %d", lb.tokens)
 		return true
 	}
-
-	log.Println("Rejecting request. No tokens left.")
 	return false
 }
 
@@ -86,19 +81,17 @@ func RateLimitMiddleware(rl *RateLimiterLb) gin.HandlerFunc {
 		if bucket.Allow() {
 			c.Next()
 		} else {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
-			c.Abort()
+			metrics.RateLimited(c.Request.Method, c.FullPath(), string(RateLimitByIP))
+			c.AbortWithStatusJSON(apperr.Response(ErrRateLimited))
 		}
 	}
 }
 
 func (rl *RateLimiterLb) CleanupOldBuckets(interval time.Duration) {
-	log.Println("Cleaning")
 	for {
 		time.Sleep(interval)
 		rl.mu.Lock()
 		for ip, bucket := range rl.buckets {
-			log.Println("Wiping", ip, bucket)
 			if time.Since(bucket.lastUpdate) > interval {
 				delete(rl.buckets, ip)
 			}
@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"golang.org/x/time/rate"
+)
+
+// Limiter decides whether a request identified by key may go through under the given limit.
+// Implementations use GCRA so a limit behaves like a token bucket of size Burst refilled at Rate.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit RateLimitConfig) (RateLimitResult, error)
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed, zero when allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// emissionInterval is the time it takes to earn back one request, zero means unlimited.
+func emissionInterval(limit RateLimitConfig) time.Duration {
+	if limit.Rate == rate.Inf || limit.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / float64(limit.Rate))
+}
+
+// gcra applies a request arriving at now to the bucket whose theoretical arrival time is tat and returns the
+// result with the tat to store, which is unchanged when the request is rejected.
+func gcra(now, tat time.Time, limit RateLimitConfig) (RateLimitResult, time.Time) {
+	interval := emissionInterval(limit)
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	if interval == 0 {
+		return RateLimitResult{Allowed: true, Limit: burst, Remaining: burst}, tat
+	}
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-time.Duration(burst) * interval)
+	if now.Before(allowAt) {
+		return RateLimitResult{
+			Limit:      burst,
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}, tat
+	}
+
+	return RateLimitResult{
+		Allowed:    true,
+		Limit:      burst,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTat.Sub(now),
+	}, newTat
+}
+
+// MemoryLimiter keeps buckets in process. Buckets that have refilled are dropped by a periodic sweep so the
+// map only holds clients that were recently limited.
+type MemoryLimiter struct {
+	buckets   map[string]time.Time
+	mu        sync.Mutex
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+const memoryLimiterSweepInterval = time.Minute
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit RateLimitConfig) (RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= memoryLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	res, tat := gcra(now, l.buckets[key], limit)
+	if tat.After(now) {
+		l.buckets[key] = tat
+	}
+	return res, nil
+}
+
+// sweep removes buckets whose theoretical arrival time has passed, they are indistinguishable from new ones.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, tat := range l.buckets {
+		if !tat.After(now) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func (l *MemoryLimiter) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// FallbackLimiter uses primary and switches to fallback for requests where primary fails, e.g. when Redis
+// is unreachable. Limits are then enforced per instance instead of failing open or closed.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+func NewFallbackLimiter(primary, fallback Limiter) *FallbackLimiter {
+	return &FallbackLimiter{primary: primary, fallback: fallback}
+}
+
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, limit RateLimitConfig) (RateLimitResult, error) {
+	res, err := l.primary.Allow(ctx, key, limit)
+	if err == nil {
+		return res, nil
+	}
+
+	logging.FromContext(ctx).Warn().Err(err).Msg("rate limiter unavailable, using in memory limits")
+	return l.fallback.Allow(ctx, key, limit)
+}
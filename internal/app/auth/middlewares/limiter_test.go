@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// Test the bucket refills at the configured rate
+func TestMemoryLimiterRefill(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := RateLimitConfig{Rate: rate.Every(time.Second), Burst: 2}
+
+	res, err := limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, _ = limiter.Allow(context.Background(), "key", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = limiter.Allow(context.Background(), "key", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 2*time.Second, res.ResetAfter)
+
+	now = now.Add(time.Second)
+	res, _ = limiter.Allow(context.Background(), "key", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	// Other keys have their own bucket
+	res, _ = limiter.Allow(context.Background(), "other", limit)
+	assert.True(t, res.Allowed)
+}
+
+// Test refilled buckets are dropped so the map doesn't grow forever
+func TestMemoryLimiterSweep(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	now := limiter.lastSweep
+	limiter.now = func() time.Time { return now }
+	limit := RateLimitConfig{Rate: rate.Every(time.Second), Burst: 1}
+
+	for _, key := range []string{"a", "b", "c"} {
+		limiter.Allow(context.Background(), key, limit)
+	}
+	assert.Equal(t, 3, limiter.size())
+
+	now = now.Add(memoryLimiterSweepInterval)
+	limiter.Allow(context.Background(), "d", limit)
+	assert.Equal(t, 1, limiter.size())
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit RateLimitConfig) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("connection refused")
+}
+
+// Test limits are still enforced when the primary limiter is down
+func TestFallbackLimiter(t *testing.T) {
+	rl := NewRateLimiterWithLimiter(NewFallbackLimiter(failingLimiter{}, NewMemoryLimiter()))
+	rl.SetRateLimitConfig("/home", RateLimitConfig{Rate: rate.Every(time.Minute), Burst: 1})
+
+	router := gin.New()
+	router.Use(RateLimit(rl))
+	router.GET("/home", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/home").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRequest(router, "GET", "/home").Code)
+}
+
+// Test the RateLimit and Retry-After headers
+func TestRateLimitHeaders(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimitConfig("/home", RateLimitConfig{Rate: rate.Every(time.Minute), Burst: 2})
+
+	router := gin.New()
+	router.Use(RateLimit(rl))
+	router.GET("/home", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	w := performRequest(router, "GET", "/home")
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	performRequest(router, "GET", "/home")
+	w = performRequest(router, "GET", "/home")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
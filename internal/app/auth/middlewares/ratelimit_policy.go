@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitPolicy is the set of limits of a service, usually parsed from config.
+type RateLimitPolicy struct {
+	Default   []RateLimitConfig
+	Endpoints map[string][]RateLimitConfig
+}
+
+// ParseRateLimitPolicy parses the default limits and the per endpoint limits. Endpoints are separated by ";"
+// and written as "METHOD /full/path=limits", e.g.
+//
+//	POST /v1/auth/login=ip:3/5s,identifier:5/3m;POST /v1/auth/register=ip:2/10s
+//
+// See ParseRateLimits for the format of the limits.
+func ParseRateLimitPolicy(defaults, endpoints string) (RateLimitPolicy, error) {
+	policy := RateLimitPolicy{Endpoints: make(map[string][]RateLimitConfig)}
+
+	var err error
+	if strings.TrimSpace(defaults) != "" {
+		if policy.Default, err = ParseRateLimits(defaults); err != nil {
+			return RateLimitPolicy{}, fmt.Errorf("default rate limit: %w", err)
+		}
+	}
+
+	for _, entry := range strings.Split(endpoints, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		endpoint, spec, found := strings.Cut(entry, "=")
+		endpoint = strings.Join(strings.Fields(endpoint), " ")
+		if !found || endpoint == "" {
+			return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q, expected METHOD /path=limits", entry)
+		}
+
+		limits, err := ParseRateLimits(spec)
+		if err != nil {
+			return RateLimitPolicy{}, fmt.Errorf("rate limit of %s: %w", endpoint, err)
+		}
+		policy.Endpoints[endpoint] = limits
+	}
+
+	return policy, nil
+}
+
+// ParseRateLimits parses comma separated limits written as "key:burst/every". "ip:3/5s" lets an ip make 3
+// requests at once and then one more every 5 seconds. See RateLimitKey for the keys.
+func ParseRateLimits(spec string) ([]RateLimitConfig, error) {
+	var limits []RateLimitConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(part, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid limit %q, expected key:burst/every", part)
+		}
+
+		switch RateLimitKey(key) {
+		case RateLimitByIP, RateLimitByUser, RateLimitByGuest, RateLimitByIdentifier, RateLimitByDevice:
+		default:
+			return nil, fmt.Errorf("unknown rate limit key %q", key)
+		}
+
+		burstStr, everyStr, found := strings.Cut(value, "/")
+		if !found {
+			return nil, fmt.Errorf("invalid limit %q, expected key:burst/every", part)
+		}
+
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid burst in %q", part)
+		}
+
+		every, err := time.ParseDuration(everyStr)
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("invalid interval in %q", part)
+		}
+
+		limits = append(limits, RateLimitConfig{Key: RateLimitKey(key), Rate: rate.Every(every), Burst: burst})
+	}
+
+	if len(limits) == 0 {
+		return nil, fmt.Errorf("no limits in %q", spec)
+	}
+	return limits, nil
+}
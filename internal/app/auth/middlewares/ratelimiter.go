@@ -1,71 +1,290 @@
 package middlewares
 
 import (
-	"net/http"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/token"
 	"golang.org/x/time/rate"
 )
 
+// RateLimitKey is what a limit counts requests by.
+type RateLimitKey string
+
+const (
+	// RateLimitByIP counts requests per client ip, it is the default
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitByUser counts requests per authenticated subject, the route must require auth
+	RateLimitByUser RateLimitKey = "user"
+	// RateLimitByGuest counts requests per guest session, registered users are not limited by it
+	RateLimitByGuest RateLimitKey = "guest"
+	// RateLimitByIdentifier counts requests per login identifier or email found in the JSON body
+	RateLimitByIdentifier RateLimitKey = "identifier"
+	// RateLimitByDevice counts requests per device id, from the X-Device-ID header or the token
+	RateLimitByDevice RateLimitKey = "device"
+)
+
+const DeviceIDHeader = "X-Device-ID"
+
+// maxIdentifierBodySize caps how much of the body is read to find the identifier
+const maxIdentifierBodySize = 1 << 16
+
 type RateLimitConfig struct {
+	Key   RateLimitKey
 	Rate  rate.Limit
 	Burst int
 }
 
+// RateLimiter holds the limits configured per endpoint and enforces them with a Limiter.
 type RateLimiter struct {
-	limiters map[string]map[string]*rate.Limiter
-	configs  map[string]RateLimitConfig
-	mu       sync.Mutex
+	limiter  Limiter
+	configs  map[string][]RateLimitConfig
+	defaults []RateLimitConfig
+	mu       sync.RWMutex
 }
 
+var defaultRateLimit = RateLimitConfig{Key: RateLimitByIP, Rate: rate.Every(time.Second), Burst: 5}
+
+// NewRateLimiter enforces limits in memory, each instance of the service keeps its own counts.
 func NewRateLimiter() *RateLimiter {
+	return NewRateLimiterWithLimiter(NewMemoryLimiter())
+}
+
+// NewDistributedRateLimiter shares limits between instances through Redis. prefix keeps the keys of different
+// services apart. If Redis is unavailable the limits are enforced in memory until it is back.
+func NewDistributedRateLimiter(redis cache.Scripter, prefix string) *RateLimiter {
+	return NewRateLimiterWithLimiter(NewFallbackLimiter(NewRedisLimiter(redis, prefix), NewMemoryLimiter()))
+}
+
+func NewRateLimiterWithLimiter(limiter Limiter) *RateLimiter {
 	return &RateLimiter{
-		limiters: make(map[string]map[string]*rate.Limiter),
-		configs:  make(map[string]RateLimitConfig),
+		limiter:  limiter,
+		configs:  make(map[string][]RateLimitConfig),
+		defaults: []RateLimitConfig{defaultRateLimit},
 	}
 }
 
+// SetRateLimitConfig replaces the limits of an endpoint with a single limit.
 func (rl *RateLimiter) SetRateLimitConfig(endpoint string, rateLimit RateLimitConfig) {
+	rl.SetRateLimits(endpoint, []RateLimitConfig{rateLimit})
+}
+
+// SetRateLimits replaces the limits of an endpoint, a request must pass all of them. The endpoint is either
+// "METHOD /full/path" or "/full/path" for every method.
+func (rl *RateLimiter) SetRateLimits(endpoint string, limits []RateLimitConfig) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	rl.configs[endpoint] = rateLimit
+	rl.configs[endpoint] = limits
 }
 
-func (rl *RateLimiter) getLimiter(ip, endpoint string) *rate.Limiter {
+// SetDefaultRateLimits sets the limits of endpoints that have none configured.
+func (rl *RateLimiter) SetDefaultRateLimits(limits []RateLimitConfig) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	rl.defaults = limits
+}
 
-	if _, exists := rl.limiters[endpoint]; !exists {
-		rl.limiters[endpoint] = make(map[string]*rate.Limiter)
+// ApplyPolicy configures every endpoint of the policy, see ParseRateLimitPolicy.
+func (rl *RateLimiter) ApplyPolicy(policy RateLimitPolicy) {
+	for endpoint, limits := range policy.Endpoints {
+		rl.SetRateLimits(endpoint, limits)
+	}
+	if len(policy.Default) > 0 {
+		rl.SetDefaultRateLimits(policy.Default)
 	}
+}
 
-	if limiter, exists := rl.limiters[endpoint][ip]; exists {
-		return limiter
+// ReplacePolicy swaps every limit for those of policy at once, endpoints it doesn't list go back to the defaults.
+// It is how reloaded config applies.
+func (rl *RateLimiter) ReplacePolicy(policy RateLimitPolicy) {
+	configs := make(map[string][]RateLimitConfig, len(policy.Endpoints))
+	for endpoint, limits := range policy.Endpoints {
+		configs[endpoint] = limits
+	}
+	defaults := policy.Default
+	if len(defaults) == 0 {
+		defaults = []RateLimitConfig{defaultRateLimit}
 	}
 
-	config, exists := rl.configs[endpoint]
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.configs = configs
+	rl.defaults = defaults
+}
+
+// getLimits returns the limits set for "METHOD /path", then for "/path" alone, then the defaults.
+func (rl *RateLimiter) getLimits(method, path string) []RateLimitConfig {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	if limits, exists := rl.configs[method+" "+path]; exists {
+		return limits
+	}
+	if limits, exists := rl.configs[path]; exists {
+		return limits
+	}
+	return rl.defaults
+}
+
+// allow checks the request against limits, sets the RateLimit headers for the most restrictive one and aborts
+// the request when any is exceeded. It returns whether the request may continue.
+func (rl *RateLimiter) allow(c *gin.Context, endpoint string, limits []RateLimitConfig) bool {
+	var tightest *RateLimitResult
+	var tightestKey RateLimitKey
+	for _, limit := range limits {
+		subject, ok := rateLimitSubject(c, limit.Key)
+		if !ok {
+			continue
+		}
+
+		res, err := rl.limiter.Allow(c, endpoint+"|"+string(limit.Key)+":"+subject, limit)
+		if err != nil {
+			// Don't lock everyone out because the limiter is down
+			logging.FromContext(c).Error().Err(err).Msg("rate limiter failed")
+			continue
+		}
+
+		if tightest == nil || moreRestrictive(res, *tightest) {
+			tightest = &res
+			tightestKey = limit.Key
+		}
+	}
+
+	if tightest == nil {
+		return true
+	}
+
+	setRateLimitHeaders(c, *tightest)
+	if !tightest.Allowed {
+		metrics.RateLimited(c.Request.Method, c.FullPath(), string(tightestKey))
+		c.AbortWithStatusJSON(apperr.Response(ErrRateLimited))
+		return false
+	}
+	return true
+}
+
+func moreRestrictive(a, b RateLimitResult) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+// rateLimitSubject returns who the request is counted against for key, false when key does not apply to it.
+func rateLimitSubject(c *gin.Context, key RateLimitKey) (string, bool) {
+	var subject string
+	switch key {
+	case RateLimitByIP, "":
+		return c.ClientIP(), true
+	case RateLimitByUser:
+		if payload := requestPayload(c); payload != nil {
+			subject = payload.Subject.String()
+		}
+	case RateLimitByGuest:
+		if payload := requestPayload(c); payload != nil && payload.Role == constants.Guests {
+			subject = payload.Subject.String()
+		}
+	case RateLimitByDevice:
+		subject = c.GetHeader(DeviceIDHeader)
+		if payload := requestPayload(c); subject == "" && payload != nil {
+			subject = payload.DeviceID
+		}
+	case RateLimitByIdentifier:
+		subject = requestIdentifier(c)
+	}
+	return subject, subject != ""
+}
+
+func requestPayload(c *gin.Context) *token.Payload {
+	value, exists := c.Get(AuthorizationPayloadKey)
 	if !exists {
-		config = RateLimitConfig{Rate: rate.Every(time.Second), Burst: 5}
+		return nil
+	}
+	payload, _ := value.(*token.Payload)
+	return payload
+}
+
+// requestIdentifier reads the identifier or email from a JSON body and puts the body back for the handler.
+func requestIdentifier(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdentifierBodySize))
+	if err != nil {
+		return ""
 	}
-	limiter := rate.NewLimiter(config.Rate, config.Burst)
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
 
-	rl.limiters[endpoint][ip] = limiter
-	return limiter
+	var fields struct {
+		Identifier string `json:"identifier"`
+		Email      string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+
+	identifier := fields.Identifier
+	if identifier == "" {
+		identifier = fields.Email
+	}
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// setRateLimitHeaders follows the IETF RateLimit header fields draft, Retry-After is only set on rejection.
+func setRateLimitHeaders(c *gin.Context, res RateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
 }
 
+// RateLimit enforces the limits configured for the route. Limits keyed by user need the auth payload, so on
+// authenticated routes it must run after AuthMiddleWare.
 func RateLimit(rl *RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		endpoint := c.FullPath()
-		limiter := rl.getLimiter(ip, endpoint)
+		endpoint := c.Request.Method + " " + c.FullPath()
+		if rl.allow(c, endpoint, rl.getLimits(c.Request.Method, c.FullPath())) {
+			c.Next()
+		}
+	}
+}
+
+// GuestRateLimit limits requests made with guest tokens, keyed by the guest session rather than the client ip.
+// It must run after AuthMiddleWare, requests from registered users pass through untouched.
+func GuestRateLimit(rl *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		endpoint := c.Request.Method + " " + c.FullPath()
+		limits := rl.getLimits(c.Request.Method, c.FullPath())
+
+		guestLimits := make([]RateLimitConfig, len(limits))
+		for i, limit := range limits {
+			limit.Key = RateLimitByGuest
+			guestLimits[i] = limit
+		}
 
-		if limiter.Allow() {
+		if rl.allow(c, endpoint, guestLimits) {
 			c.Next()
-		} else {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
-			c.Abort()
 		}
 	}
 }
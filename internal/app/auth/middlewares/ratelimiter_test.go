@@ -3,12 +3,17 @@ package middlewares
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/token"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 )
 
@@ -122,3 +127,152 @@ func performRequest(router *gin.Engine, method, path string) *httptest.ResponseR
 	router.ServeHTTP(w, req)
 	return w
 }
+
+// Test guest limits are tracked per guest session and skip registered users
+func TestGuestRateLimit(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimitConfig("/cart", RateLimitConfig{Rate: rate.Every(time.Minute), Burst: 1})
+
+	guestA := &token.Payload{PayloadData: token.PayloadData{Role: constants.Guests, Subject: uuid.New()}}
+	guestB := &token.Payload{PayloadData: token.PayloadData{Role: constants.Guests, Subject: uuid.New()}}
+	user := &token.Payload{PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New()}}
+
+	var current *token.Payload
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(AuthorizationPayloadKey, current)
+		c.Next()
+	})
+	router.Use(GuestRateLimit(rl))
+	router.GET("/cart", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	current = guestA
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/cart").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRequest(router, "GET", "/cart").Code)
+
+	current = guestB
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/cart").Code)
+
+	current = user
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/cart").Code)
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/cart").Code)
+}
+
+// Test limits configured for the full route path and method are applied
+func TestRateLimitByMethodAndFullPath(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimitConfig("POST /v1/auth/items/:id", RateLimitConfig{Rate: rate.Every(time.Minute), Burst: 1})
+
+	router := gin.New()
+	router.Use(RateLimit(rl))
+	router.POST("/v1/auth/items/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	router.GET("/v1/auth/items/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	assert.Equal(t, http.StatusOK, performRequest(router, "POST", "/v1/auth/items/1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRequest(router, "POST", "/v1/auth/items/2").Code)
+
+	// Other methods on the same path use the default limit
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/v1/auth/items/1").Code)
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/v1/auth/items/1").Code)
+}
+
+// Test identifier limits count attempts per account across ips and leave the body readable
+func TestRateLimitByIdentifier(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimits("POST /login", []RateLimitConfig{
+		{Key: RateLimitByIP, Rate: rate.Every(time.Minute), Burst: 10},
+		{Key: RateLimitByIdentifier, Rate: rate.Every(time.Minute), Burst: 1},
+	})
+
+	router := gin.New()
+	router.Use(RateLimit(rl))
+	router.POST("/login", func(c *gin.Context) {
+		var req struct {
+			Identifier string `json:"identifier"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, req.Identifier)
+	})
+
+	login := func(ip, identifier string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"identifier":"`+identifier+`"}`))
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := login("10.0.0.1", "Jane@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Jane@example.com", w.Body.String())
+
+	assert.Equal(t, http.StatusTooManyRequests, login("10.0.0.2", "jane@example.com").Code)
+	assert.Equal(t, http.StatusOK, login("10.0.0.1", "john@example.com").Code)
+}
+
+// Test user limits follow the authenticated subject
+func TestRateLimitByUser(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimits("GET /profile", []RateLimitConfig{{Key: RateLimitByUser, Rate: rate.Every(time.Minute), Burst: 1}})
+
+	userA := &token.Payload{PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New()}}
+	userB := &token.Payload{PayloadData: token.PayloadData{Role: constants.RegularUsers, Subject: uuid.New()}}
+
+	var current *token.Payload
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(AuthorizationPayloadKey, current)
+		c.Next()
+	})
+	router.GET("/profile", RateLimit(rl), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	current = userA
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/profile").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRequest(router, "GET", "/profile").Code)
+
+	current = userB
+	assert.Equal(t, http.StatusOK, performRequest(router, "GET", "/profile").Code)
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := ParseRateLimitPolicy("ip:5/1s", "POST  /v1/auth/login=ip:3/5s, identifier:5/3m ; GET /v1/menu/cart=guest:5/1s")
+	require.NoError(t, err)
+
+	assert.Equal(t, []RateLimitConfig{{Key: RateLimitByIP, Rate: rate.Every(time.Second), Burst: 5}}, policy.Default)
+	assert.Equal(t, []RateLimitConfig{
+		{Key: RateLimitByIP, Rate: rate.Every(5 * time.Second), Burst: 3},
+		{Key: RateLimitByIdentifier, Rate: rate.Every(3 * time.Minute), Burst: 5},
+	}, policy.Endpoints["POST /v1/auth/login"])
+	assert.Len(t, policy.Endpoints["GET /v1/menu/cart"], 1)
+
+	for _, spec := range []string{"POST /login", "POST /login=email:1/1s", "POST /login=ip:0/1s", "POST /login=ip:1/soon", "POST /login=ip:1"} {
+		_, err := ParseRateLimitPolicy("", spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestReplacePolicy(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.SetRateLimitConfig("/home", RateLimitConfig{Rate: rate.Every(time.Minute), Burst: 1})
+
+	policy, err := ParseRateLimitPolicy("ip:1/1m", "GET /other=ip:2/1m")
+	require.NoError(t, err)
+	rl.ReplacePolicy(policy)
+
+	assert.Equal(t, policy.Default, rl.getLimits("GET", "/home"), "endpoints left out fall back to the defaults")
+	assert.Equal(t, policy.Endpoints["GET /other"], rl.getLimits("GET", "/other"))
+
+	rl.ReplacePolicy(RateLimitPolicy{})
+	assert.Equal(t, []RateLimitConfig{defaultRateLimit}, rl.getLimits("GET", "/other"))
+}
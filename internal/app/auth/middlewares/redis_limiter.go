@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+)
+
+// gcraScript is the Redis side of gcra. It uses the Redis clock so every replica agrees on the time.
+// All times are in microseconds.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - burst * interval
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}
+`)
+
+// RedisLimiter shares limits between all instances of a service.
+type RedisLimiter struct {
+	redis  cache.Scripter
+	prefix string
+}
+
+func NewRedisLimiter(redis cache.Scripter, prefix string) *RedisLimiter {
+	return &RedisLimiter{redis: redis, prefix: prefix}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit RateLimitConfig) (RateLimitResult, error) {
+	interval := emissionInterval(limit)
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	if interval == 0 {
+		return RateLimitResult{Allowed: true, Limit: burst, Remaining: burst}, nil
+	}
+
+	result, err := l.redis.RunScript(ctx, gcraScript, []string{l.prefix + ":" + key}, interval.Microseconds(), burst)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 4 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result %v", result)
+	}
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		if ints[i], ok = v.(int64); !ok {
+			return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result %v", result)
+		}
+	}
+
+	return RateLimitResult{
+		Allowed:    ints[0] == 1,
+		Limit:      burst,
+		Remaining:  int(ints[1]),
+		RetryAfter: time.Duration(ints[2]) * time.Microsecond,
+		ResetAfter: time.Duration(ints[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+// RouteOptions is what the auth and rate limits of the routes of a service need.
+type RouteOptions struct {
+	Config     utils.Config
+	TokenMaker token.Maker
+	Cache      cache.Cache
+	// RateLimitPrefix keeps the rate limit keys of each service apart in Redis
+	RateLimitPrefix string
+	// Consent enforces the current policy documents on authenticated routes when set
+	Consent ConsentChecker
+	// APIKeys lets routes with a Scope accept API keys when set
+	APIKeys APIKeyVerifier
+	// ConfigWatcher applies reloaded rate limits when set
+	ConfigWatcher *utils.ConfigWatcher
+}
+
+// RouterOptions returns the httpserver options of a service authenticating its routes with AuthMiddleWare and
+// limiting them with the limits read from RATE_LIMIT_DEFAULT and RATE_LIMITS, see ParseRateLimitPolicy. The limits
+// follow the config reloads of opts.ConfigWatcher.
+func RouterOptions(service, basePath string, opts RouteOptions) (httpserver.Options, error) {
+	rateLimit, err := routeRateLimit(service, opts)
+	if err != nil {
+		return httpserver.Options{}, err
+	}
+
+	return httpserver.Options{
+		Service:   service,
+		BasePath:  basePath,
+		Auth:      routeAuth(opts),
+		RateLimit: rateLimit,
+	}, nil
+}
+
+func routeAuth(opts RouteOptions) func(guards map[string]httpserver.Guard) gin.HandlerFunc {
+	return func(guards map[string]httpserver.Guard) gin.HandlerFunc {
+		accessibleRoles := make(map[string][]int8, len(guards))
+		var consent *ConsentPolicy
+		if opts.Consent != nil {
+			consent = &ConsentPolicy{Checker: opts.Consent, Exempt: make(map[string]bool)}
+		}
+		var apiKeys *APIKeyPolicy
+		if opts.APIKeys != nil {
+			apiKeys = &APIKeyPolicy{Verifier: opts.APIKeys, Scopes: make(map[string]string)}
+		}
+
+		for fullMethod, guard := range guards {
+			accessibleRoles[fullMethod] = guard.Roles
+			if consent != nil && guard.SkipConsent {
+				consent.Exempt[fullMethod] = true
+			}
+			if apiKeys != nil && guard.Scope != "" {
+				apiKeys.Scopes[fullMethod] = guard.Scope
+			}
+		}
+
+		return AuthMiddleWare(opts.Config, opts.TokenMaker, opts.Cache, accessibleRoles, consent, apiKeys)
+	}
+}
+
+func routeRateLimit(service string, opts RouteOptions) (gin.HandlerFunc, error) {
+	policy, err := ParseRateLimitPolicy(opts.Config.RateLimitDefault, opts.Config.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	// Caches that can't run the limiter scripts, such as the in-memory one of tests, limit each instance alone
+	rl := NewRateLimiter()
+	if redis, ok := opts.Cache.(cache.Scripter); ok {
+		rl = NewDistributedRateLimiter(redis, opts.RateLimitPrefix)
+	}
+	rl.ApplyPolicy(policy)
+	if opts.ConfigWatcher != nil {
+		opts.ConfigWatcher.OnReload(func(config utils.Config) {
+			policy, err := ParseRateLimitPolicy(config.RateLimitDefault, config.RateLimits)
+			if err != nil {
+				log.Error().Err(err).Str("service", service).Msg("keeping the current rate limits")
+				return
+			}
+			rl.ReplacePolicy(policy)
+		})
+	}
+
+	return RateLimit(rl), nil
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/apikey"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+const defaultAPIKeyLifetimeDays = 90
+
+var (
+	ErrInvalidAPIKey          = apperr.New(apperr.CodeUnauthenticated, "auth.api_key.invalid", "invalid api key")
+	ErrAPIKeyExpired          = apperr.New(apperr.CodeUnauthenticated, "auth.api_key.expired", "api key has expired")
+	ErrAPIKeyNotFound         = apperr.New(apperr.CodeNotFound, "auth.api_key.not_found", "api key not found")
+	ErrAPIKeyForbidden        = apperr.New(apperr.CodePermissionDenied, "auth.api_key.forbidden", "you don't have permission to manage this api key")
+	ErrAPIKeyNotActive        = apperr.New(apperr.CodeFailedPrecondition, "auth.api_key.not_active", "only active api keys can be rotated")
+	ErrAPIKeyRevoked          = apperr.New(apperr.CodeFailedPrecondition, "auth.api_key.revoked", "api key already revoked")
+	ErrServiceAccountNotFound = apperr.New(apperr.CodeNotFound, "auth.service_account.not_found", "service account not found")
+	ErrServiceAccountDisabled = apperr.New(apperr.CodeFailedPrecondition, "auth.service_account.disabled", "service account is disabled")
+)
+
+type CreateServiceAccountReq struct {
+	Name        string `json:"name" binding:"required,min=3,max=64"`
+	Description string `json:"description" binding:"max=500"`
+	Role        int8   `json:"role" binding:"required,oneof=2 3"`
+}
+
+type ServiceAccountRes struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Role        int32      `json:"role"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+}
+
+type CreateAPIKeyReq struct {
+	Name          string   `json:"name" binding:"required,min=3,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=365"`
+	// When set the key is created for the service account instead of the caller
+	ServiceAccountID uuid.NullUUID `json:"service_account_id"`
+}
+
+type APIKeyRes struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Prefix string    `json:"prefix"`
+	// Key is only returned when the key is created or rotated, it can't be recovered afterwards
+	Key              string        `json:"key,omitempty"`
+	Scopes           []string      `json:"scopes"`
+	UserID           uuid.NullUUID `json:"user_id"`
+	ServiceAccountID uuid.NullUUID `json:"service_account_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	LastUsedAt       *time.Time    `json:"last_used_at,omitempty"`
+	RevokedAt        *time.Time    `json:"revoked_at,omitempty"`
+}
+
+// APIKeyService authenticates API keys for AuthMiddleWare.
+type APIKeyService struct {
+	store sqlc.Store
+}
+
+func NewAPIKeyService(store sqlc.Store) *APIKeyService {
+	return &APIKeyService{store: store}
+}
+
+// VerifyAPIKey resolves the key to a payload for its owner. The key id is used as the session id.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, rawKey string) (*token.Payload, error) {
+	prefix, err := apikey.Parse(rawKey)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.store.GetApiKeyForAuth(ctx, prefix)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if !apikey.Matches(rawKey, key.KeyHash) || key.RevokedAt.Valid {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if time.Now().After(key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	payloadData := token.PayloadData{
+		SessionID:  key.ID,
+		AuthMethod: token.AuthAPIKey,
+		Scopes:     key.Scopes,
+		TokenType:  token.AccessToken,
+	}
+
+	if key.ServiceAccountID.Valid {
+		if key.ServiceAccountDisabledAt.Valid {
+			return nil, ErrInvalidAPIKey
+		}
+		payloadData.Subject = key.ServiceAccountID.UUID
+		payloadData.Role = int8(key.ServiceAccountRoleID.Int32)
+	} else {
+		if key.UserSuspended.Bool || key.UserDeleted.Bool {
+			return nil, ErrInvalidAPIKey
+		}
+		payloadData.Subject = key.UserID.UUID
+		payloadData.Role = int8(key.UserRoleID.Int32)
+	}
+
+	if err := s.store.TouchApiKey(ctx, key.ID); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("api_key_id", key.ID.String()).Msg("failed to update api key last used time")
+	}
+
+	return &token.Payload{
+		PayloadData: payloadData,
+		IssuedAt:    key.CreatedAt,
+		Expires:     key.ExpiresAt,
+	}, nil
+}
+
+func CreateServiceAccount(ctx context.Context, store sqlc.Store, admin *token.Payload, req CreateServiceAccountReq, clientIP, agent string) (ServiceAccountRes, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return ServiceAccountRes{}, apperr.Internal(err)
+	}
+
+	account, err := store.CreateServiceAccount(ctx, sqlc.CreateServiceAccountParams{
+		ID:          id,
+		Name:        req.Name,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		RoleID:      int32(req.Role),
+		CreatedBy:   admin.Subject,
+	})
+	if err != nil {
+		return ServiceAccountRes{}, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditServiceAccountCreated,
+		Metadata:  map[string]interface{}{"service_account_id": account.ID, "name": account.Name},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	return newServiceAccountRes(account), nil
+}
+
+func ListServiceAccounts(ctx context.Context, store sqlc.Store) ([]ServiceAccountRes, error) {
+	accounts, err := store.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]ServiceAccountRes, 0, len(accounts))
+	for _, account := range accounts {
+		res = append(res, newServiceAccountRes(account))
+	}
+	return res, nil
+}
+
+// DisableServiceAccount disables the account and revokes all of its keys.
+func DisableServiceAccount(ctx context.Context, store sqlc.Store, admin *token.Payload, id uuid.UUID, clientIP, agent string) error {
+	if _, err := store.GetServiceAccount(ctx, id); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrServiceAccountNotFound
+		}
+		return apperr.Internal(err)
+	}
+
+	if err := store.DisableServiceAccount(ctx, id); err != nil {
+		return apperr.Internal(err)
+	}
+
+	if err := store.RevokeServiceAccountApiKeys(ctx, uuid.NullUUID{UUID: id, Valid: true}); err != nil {
+		return apperr.Internal(err)
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditServiceAccountDisabled,
+		Metadata:  map[string]interface{}{"service_account_id": id},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return nil
+}
+
+// CreateAPIKey creates a personal key for the caller, or a key for a service account when requested by an admin.
+func CreateAPIKey(ctx context.Context, store sqlc.Store, caller *token.Payload, req CreateAPIKeyReq, clientIP, agent string) (APIKeyRes, error) {
+	if err := validateScopes(req.Scopes); err != nil {
+		return APIKeyRes{}, err
+	}
+
+	owner := sqlc.ApiKey{ServiceAccountID: req.ServiceAccountID}
+	if !req.ServiceAccountID.Valid {
+		owner.UserID = uuid.NullUUID{UUID: caller.Subject, Valid: true}
+	}
+
+	if !canManageAPIKey(caller, owner) {
+		return APIKeyRes{}, ErrAPIKeyForbidden
+	}
+
+	if req.ServiceAccountID.Valid {
+		account, err := store.GetServiceAccount(ctx, req.ServiceAccountID.UUID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return APIKeyRes{}, ErrServiceAccountNotFound
+			}
+			return APIKeyRes{}, apperr.Internal(err)
+		}
+		if account.DisabledAt.Valid {
+			return APIKeyRes{}, ErrServiceAccountDisabled
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyLifetimeDays
+	}
+
+	return issueAPIKey(ctx, store, caller, sqlc.CreateApiKeyParams{
+		Name:             req.Name,
+		Scopes:           req.Scopes,
+		UserID:           owner.UserID,
+		ServiceAccountID: owner.ServiceAccountID,
+		ExpiresAt:        time.Now().AddDate(0, 0, days),
+	}, constants.AuditAPIKeyCreated, clientIP, agent)
+}
+
+// ListAPIKeys lists the caller's personal keys, or the keys of a service account for admins.
+func ListAPIKeys(ctx context.Context, store sqlc.Store, caller *token.Payload, serviceAccountID uuid.NullUUID) ([]APIKeyRes, error) {
+	var keys []sqlc.ApiKey
+	var err error
+	if serviceAccountID.Valid {
+		if !canManageAPIKey(caller, sqlc.ApiKey{ServiceAccountID: serviceAccountID}) {
+			return nil, ErrAPIKeyForbidden
+		}
+		keys, err = store.ListServiceAccountApiKeys(ctx, serviceAccountID)
+	} else {
+		keys, err = store.ListUserApiKeys(ctx, uuid.NullUUID{UUID: caller.Subject, Valid: true})
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]APIKeyRes, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, newAPIKeyRes(key, ""))
+	}
+	return res, nil
+}
+
+// RotateAPIKey issues a replacement key with the same name, scopes and expiry and revokes the old one.
+func RotateAPIKey(ctx context.Context, store sqlc.Store, caller *token.Payload, id uuid.UUID, clientIP, agent string) (APIKeyRes, error) {
+	key, err := getManagedAPIKey(ctx, store, caller, id)
+	if err != nil {
+		return APIKeyRes{}, err
+	}
+
+	if key.RevokedAt.Valid || time.Now().After(key.ExpiresAt) {
+		return APIKeyRes{}, ErrAPIKeyNotActive
+	}
+
+	// The old key is only revoked along with its replacement being created, and only one of concurrent
+	// rotations revokes it
+	var replacement sqlc.ApiKey
+	var raw string
+	err = store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		rows, err := qtx.RevokeApiKey(ctx, key.ID)
+		if err != nil {
+			return apperr.Internal(err)
+		}
+		if rows == 0 {
+			return ErrAPIKeyNotActive
+		}
+
+		replacement, raw, err = createAPIKey(ctx, qtx, caller, sqlc.CreateApiKeyParams{
+			Name:             key.Name,
+			Scopes:           key.Scopes,
+			UserID:           key.UserID,
+			ServiceAccountID: key.ServiceAccountID,
+			ExpiresAt:        key.ExpiresAt,
+			RotatedFrom:      uuid.NullUUID{UUID: key.ID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return APIKeyRes{}, err
+	}
+
+	recordAPIKeyAudit(ctx, store, caller, replacement, constants.AuditAPIKeyRotated, clientIP, agent)
+	return newAPIKeyRes(replacement, raw), nil
+}
+
+func RevokeAPIKey(ctx context.Context, store sqlc.Store, caller *token.Payload, id uuid.UUID, clientIP, agent string) error {
+	key, err := getManagedAPIKey(ctx, store, caller, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := store.RevokeApiKey(ctx, key.ID)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if rows == 0 {
+		return ErrAPIKeyRevoked
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    key.UserID.UUID,
+		ActorID:   caller.Subject,
+		Action:    constants.AuditAPIKeyRevoked,
+		Metadata:  apiKeyAuditMetadata(key),
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return nil
+}
+
+func issueAPIKey(ctx context.Context, store sqlc.Store, caller *token.Payload, params sqlc.CreateApiKeyParams, action, clientIP, agent string) (APIKeyRes, error) {
+	key, raw, err := createAPIKey(ctx, store, caller, params)
+	if err != nil {
+		return APIKeyRes{}, err
+	}
+
+	recordAPIKeyAudit(ctx, store, caller, key, action, clientIP, agent)
+	return newAPIKeyRes(key, raw), nil
+}
+
+// createAPIKey generates a key and stores it with params, q may belong to a transaction. It returns the stored key
+// and the raw key, which is never stored.
+func createAPIKey(ctx context.Context, q sqlc.Querier, caller *token.Payload, params sqlc.CreateApiKeyParams) (sqlc.ApiKey, string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return sqlc.ApiKey{}, "", apperr.Internal(err)
+	}
+
+	generated, err := apikey.Generate()
+	if err != nil {
+		return sqlc.ApiKey{}, "", apperr.Internal(err)
+	}
+
+	params.ID = id
+	params.Prefix = generated.Prefix
+	params.KeyHash = generated.Hash
+	params.CreatedBy = caller.Subject
+
+	key, err := q.CreateApiKey(ctx, params)
+	if err != nil {
+		return sqlc.ApiKey{}, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+	return key, generated.Raw, nil
+}
+
+func recordAPIKeyAudit(ctx context.Context, store sqlc.Store, caller *token.Payload, key sqlc.ApiKey, action, clientIP, agent string) {
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    key.UserID.UUID,
+		ActorID:   caller.Subject,
+		Action:    action,
+		Metadata:  apiKeyAuditMetadata(key),
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+}
+
+func getManagedAPIKey(ctx context.Context, store sqlc.Store, caller *token.Payload, id uuid.UUID) (sqlc.ApiKey, error) {
+	key, err := store.GetApiKeyByID(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return sqlc.ApiKey{}, ErrAPIKeyNotFound
+		}
+		return sqlc.ApiKey{}, apperr.Internal(err)
+	}
+
+	// Don't reveal keys that belong to someone else
+	if !canManageAPIKey(caller, key) {
+		return sqlc.ApiKey{}, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// canManageAPIKey allows users to manage their own keys, admins to manage service account keys
+// and super admins to manage every key.
+func canManageAPIKey(caller *token.Payload, key sqlc.ApiKey) bool {
+	switch {
+	case caller.Role == constants.SuperAdmin:
+		return true
+	case key.ServiceAccountID.Valid:
+		return caller.Role == constants.AppAdmin
+	default:
+		return key.UserID.Valid && key.UserID.UUID == caller.Subject
+	}
+}
+
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		known := false
+		for _, s := range constants.APIKeyScopes {
+			if scope == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return unknownScope(scope)
+		}
+	}
+	return nil
+}
+
+func apiKeyAuditMetadata(key sqlc.ApiKey) map[string]interface{} {
+	metadata := map[string]interface{}{"api_key_id": key.ID, "prefix": key.Prefix, "scopes": key.Scopes}
+	if key.ServiceAccountID.Valid {
+		metadata["service_account_id"] = key.ServiceAccountID.UUID
+	}
+	return metadata
+}
+
+func newServiceAccountRes(account sqlc.ServiceAccount) ServiceAccountRes {
+	res := ServiceAccountRes{
+		ID:          account.ID,
+		Name:        account.Name,
+		Description: account.Description.String,
+		Role:        account.RoleID,
+		CreatedBy:   account.CreatedBy,
+		CreatedAt:   account.CreatedAt,
+	}
+	if account.DisabledAt.Valid {
+		res.DisabledAt = &account.DisabledAt.Time
+	}
+	return res
+}
+
+func newAPIKeyRes(key sqlc.ApiKey, raw string) APIKeyRes {
+	res := APIKeyRes{
+		ID:               key.ID,
+		Name:             key.Name,
+		Prefix:           key.Prefix,
+		Key:              raw,
+		Scopes:           key.Scopes,
+		UserID:           key.UserID,
+		ServiceAccountID: key.ServiceAccountID,
+		CreatedAt:        key.CreatedAt,
+		ExpiresAt:        key.ExpiresAt,
+	}
+	if key.LastUsedAt.Valid {
+		res.LastUsedAt = &key.LastUsedAt.Time
+	}
+	if key.RevokedAt.Valid {
+		res.RevokedAt = &key.RevokedAt.Time
+	}
+	return res
+}
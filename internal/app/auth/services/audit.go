@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+type AuditEntry struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Action    string
+	Metadata  map[string]interface{}
+	ClientIP  string
+	UserAgent string
+}
+
+// RecordAudit writes an entry to the audit log. Failures are logged but never returned so that
+// auditing can not break the operation being audited.
+func RecordAudit(ctx context.Context, store sqlc.Store, entry AuditEntry) {
+	var metadata []byte
+	if len(entry.Metadata) > 0 {
+		data, err := json.Marshal(entry.Metadata)
+		if err == nil {
+			metadata = data
+		}
+	}
+
+	err := store.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		UserID:    uuid.NullUUID{UUID: entry.UserID, Valid: entry.UserID != uuid.Nil},
+		ActorID:   uuid.NullUUID{UUID: entry.ActorID, Valid: entry.ActorID != uuid.Nil},
+		Action:    entry.Action,
+		Metadata:  metadata,
+		IpAddress: utils.GetIpAddr(entry.ClientIP),
+		UserAgent: pgtype.Text{String: entry.UserAgent, Valid: entry.UserAgent != ""},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("action", entry.Action).Msg("failed to record audit log")
+	}
+}
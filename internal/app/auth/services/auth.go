@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"errors"
 
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 )
@@ -16,7 +15,7 @@ func CheckIfUsernameExists(ctx context.Context, qtx *sqlc.Queries, username stri
 
 	// If count is greater than 0 it means a username variant was found irrespective of the case
 	if c > 0 {
-		return errors.New("username taken")
+		return ErrUsernameTaken
 	}
 
 	// If none of the conditions are met, it means no user exists with that username and there were no errors.
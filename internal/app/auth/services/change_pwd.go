@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -15,23 +14,23 @@ func ChangeUserPwd(ctx context.Context, oldPassword, newPassword string, store s
 
 	user, err := store.GetUserByID(ctx, uid)
 	if err != nil {
-		return fmt.Errorf("couldn't verify old password %s", err)
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	err = utils.CheckPassword(oldPassword, user.PasswordHash)
 	if err != nil {
-		return fmt.Errorf("old password incorrect %s", err)
+		return ErrPasswordIncorrect.WithCause(err)
 	}
 
 	// Hash password
 	pwdHash, err := utils.HashPassword(newPassword)
 	if err != nil {
-		return fmt.Errorf("couldn't verify new password %s", err)
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Check if new password is the same as the old password
 	if oldPassword == newPassword {
-		return errors.New("new password cannot be the same as the old password")
+		return ErrPasswordReused
 	}
 
 	// Update password
@@ -40,7 +39,7 @@ func ChangeUserPwd(ctx context.Context, oldPassword, newPassword string, store s
 		PasswordHash: pwdHash,
 	})
 	if err != nil {
-		return fmt.Errorf("couldn't update password password %s", err)
+		return fmt.Errorf("failed to update password: %w", err)
 	}
 
 	// TODO: Close all the users session after changing password
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+const (
+	PolicyTerms   = "terms"
+	PolicyPrivacy = "privacy"
+
+	currentPoliciesCacheKey = "consent:current_documents"
+	currentPoliciesTTL      = 5 * time.Minute
+	userConsentTTL          = time.Hour
+)
+
+var (
+	ErrConsentVersionMismatch = apperr.New(apperr.CodeFailedPrecondition, "auth.consent.version_mismatch", "the accepted policy version is not the current version, please review the latest terms and privacy policy")
+	ErrPolicyVersionExists    = apperr.New(apperr.CodeAlreadyExists, "auth.policy.version_exists", "this version of the policy already exists")
+)
+
+type PublishPolicyReq struct {
+	Kind        string    `json:"kind" binding:"required,oneof=terms privacy"`
+	Version     string    `json:"version" binding:"required"`
+	Url         string    `json:"url" binding:"required,url"`
+	Summary     string    `json:"summary"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type AcceptConsentReq struct {
+	TermsVersion   string `json:"terms_version"`
+	PrivacyVersion string `json:"privacy_version"`
+}
+
+type PolicyDocumentRes struct {
+	ID          int32     `json:"id"`
+	Kind        string    `json:"kind"`
+	Version     string    `json:"version"`
+	Url         string    `json:"url"`
+	Summary     string    `json:"summary,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type UserConsentRes struct {
+	Kind       string    `json:"kind"`
+	Version    string    `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+	IpAddress  string    `json:"ip_address,omitempty"`
+}
+
+type ConsentReportRes struct {
+	DocumentID     int32     `json:"document_id"`
+	Kind           string    `json:"kind"`
+	Version        string    `json:"version"`
+	PublishedAt    time.Time `json:"published_at"`
+	AcceptedCount  int64     `json:"accepted_count"`
+	TotalUsers     int64     `json:"total_users"`
+	AcceptanceRate float64   `json:"acceptance_rate"`
+}
+
+// ConsentService tracks which versions of the terms and privacy policy each user accepted.
+// Current documents and per user results are cached in redis so it can be checked on every request.
+type ConsentService struct {
+	store sqlc.Store
+	cache cache.Cache
+}
+
+func NewConsentService(store sqlc.Store, cache cache.Cache) *ConsentService {
+	return &ConsentService{
+		store: store,
+		cache: cache,
+	}
+}
+
+// CurrentDocuments returns the latest published version of each policy document.
+func (s *ConsentService) CurrentDocuments(ctx context.Context) ([]sqlc.PolicyDocument, error) {
+	return cache.GetOrLoad(ctx, s.cache, currentPoliciesCacheKey, currentPoliciesTTL, s.store.GetCurrentPolicyDocuments)
+}
+
+// HasCurrentConsent reports whether the user has accepted the current version of every policy document.
+func (s *ConsentService) HasCurrentConsent(ctx context.Context, userID uuid.UUID) (bool, error) {
+	docs, err := s.CurrentDocuments(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if len(docs) == 0 {
+		return true, nil
+	}
+
+	key := userConsentCacheKey(userID, docs)
+	if cached, err := s.cache.GetKey(ctx, key); err == nil && cached == "1" {
+		return true, nil
+	}
+
+	missing, err := s.store.CountMissingConsents(ctx, sqlc.CountMissingConsentsParams{
+		DocumentIds: documentIDs(docs),
+		UserID:      userID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if missing > 0 {
+		return false, nil
+	}
+
+	s.cache.SetKey(ctx, key, "1", userConsentTTL)
+	return true, nil
+}
+
+// Accept records the user's acceptance of the current policy documents. The versions sent by the client
+// must match the current versions so users can't accept a document they haven't been shown.
+func (s *ConsentService) Accept(ctx context.Context, userID uuid.UUID, req AcceptConsentReq, clientIP, agent string) error {
+	docs, err := s.CurrentDocuments(ctx)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	if err := recordConsents(ctx, s.store, docs, userID, req.TermsVersion, req.PrivacyVersion, clientIP, agent); err != nil {
+		return err
+	}
+
+	s.cache.SetKey(ctx, userConsentCacheKey(userID, docs), "1", userConsentTTL)
+	return nil
+}
+
+func (s *ConsentService) UserConsents(ctx context.Context, userID uuid.UUID) ([]UserConsentRes, error) {
+	consents, err := s.store.GetUserConsents(ctx, userID)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]UserConsentRes, 0, len(consents))
+	for _, c := range consents {
+		ip := ""
+		if c.IpAddress != nil {
+			ip = c.IpAddress.String()
+		}
+		res = append(res, UserConsentRes{
+			Kind:       c.Kind,
+			Version:    c.Version,
+			AcceptedAt: c.AcceptedAt,
+			IpAddress:  ip,
+		})
+	}
+	return res, nil
+}
+
+// Publish creates a new policy document version. Once it is published every user has to accept it again.
+func (s *ConsentService) Publish(ctx context.Context, req PublishPolicyReq, publishedBy uuid.UUID) (sqlc.PolicyDocument, error) {
+	_, err := s.store.GetPolicyDocumentByVersion(ctx, sqlc.GetPolicyDocumentByVersionParams{
+		Kind:    req.Kind,
+		Version: req.Version,
+	})
+	if err == nil {
+		return sqlc.PolicyDocument{}, ErrPolicyVersionExists
+	}
+	if err != pgx.ErrNoRows {
+		return sqlc.PolicyDocument{}, apperr.Internal(err)
+	}
+
+	publishedAt := req.PublishedAt
+	if publishedAt.IsZero() {
+		publishedAt = time.Now()
+	}
+
+	doc, err := s.store.CreatePolicyDocument(ctx, sqlc.CreatePolicyDocumentParams{
+		Kind:        req.Kind,
+		Version:     req.Version,
+		Url:         req.Url,
+		Summary:     pgtype.Text{String: req.Summary, Valid: req.Summary != ""},
+		PublishedAt: publishedAt,
+		CreatedBy:   uuid.NullUUID{UUID: publishedBy, Valid: true},
+	})
+	if err != nil {
+		return sqlc.PolicyDocument{}, apperr.Internal(err)
+	}
+
+	// Drop the cached current versions so every user is asked to accept the new document
+	s.cache.DeleteKey(ctx, currentPoliciesCacheKey)
+	return doc, nil
+}
+
+func (s *ConsentService) Report(ctx context.Context) ([]ConsentReportRes, error) {
+	rows, err := s.store.GetConsentAcceptanceReport(ctx)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]ConsentReportRes, 0, len(rows))
+	for _, row := range rows {
+		var rate float64
+		if row.TotalUsers > 0 {
+			rate = float64(row.AcceptedCount) / float64(row.TotalUsers)
+		}
+		res = append(res, ConsentReportRes{
+			DocumentID:     row.ID,
+			Kind:           row.Kind,
+			Version:        row.Version,
+			PublishedAt:    row.PublishedAt,
+			AcceptedCount:  row.AcceptedCount,
+			TotalUsers:     row.TotalUsers,
+			AcceptanceRate: rate,
+		})
+	}
+	return res, nil
+}
+
+// RecordRegistrationConsent stores the consent given at registration inside the registration transaction.
+func RecordRegistrationConsent(ctx context.Context, qtx *sqlc.Queries, uid uuid.UUID, termsVersion, privacyVersion, clientIP, agent string) error {
+	docs, err := qtx.GetCurrentPolicyDocuments(ctx)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	return recordConsents(ctx, qtx, docs, uid, termsVersion, privacyVersion, clientIP, agent)
+}
+
+func recordConsents(ctx context.Context, q sqlc.Querier, docs []sqlc.PolicyDocument, uid uuid.UUID, termsVersion, privacyVersion, clientIP, agent string) error {
+	accepted := map[string]string{
+		PolicyTerms:   termsVersion,
+		PolicyPrivacy: privacyVersion,
+	}
+
+	for _, doc := range docs {
+		if accepted[doc.Kind] != doc.Version {
+			return ErrConsentVersionMismatch
+		}
+	}
+
+	ip := utils.GetIpAddr(clientIP)
+	for _, doc := range docs {
+		err := q.CreateUserConsent(ctx, sqlc.CreateUserConsentParams{
+			UserID:     uid,
+			DocumentID: doc.ID,
+			IpAddress:  ip,
+			UserAgent:  pgtype.Text{String: agent, Valid: agent != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record consent: %w", err)
+		}
+	}
+	return nil
+}
+
+func userConsentCacheKey(userID uuid.UUID, docs []sqlc.PolicyDocument) string {
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, fmt.Sprint(doc.ID))
+	}
+	return fmt.Sprintf("consent:%s:%s", userID, strings.Join(ids, "-"))
+}
+
+func documentIDs(docs []sqlc.PolicyDocument) []int32 {
+	ids := make([]int32, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+func NewPolicyDocumentRes(doc sqlc.PolicyDocument) PolicyDocumentRes {
+	return PolicyDocumentRes{
+		ID:          doc.ID,
+		Kind:        doc.Kind,
+		Version:     doc.Version,
+		Url:         doc.Url,
+		Summary:     doc.Summary.String,
+		PublishedAt: doc.PublishedAt,
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/stretchr/testify/require"
+)
+
+// consentStore keeps policy documents and consents in memory, the other queries panic.
+type consentStore struct {
+	sqlc.Store
+	docs          []sqlc.PolicyDocument
+	consents      map[uuid.UUID]map[int32]bool
+	missingChecks int
+}
+
+func (s *consentStore) GetCurrentPolicyDocuments(ctx context.Context) ([]sqlc.PolicyDocument, error) {
+	// Documents are published in order, the last one of each kind is current
+	latest := map[string]sqlc.PolicyDocument{}
+	for _, doc := range s.docs {
+		latest[doc.Kind] = doc
+	}
+	docs := make([]sqlc.PolicyDocument, 0, len(latest))
+	for _, doc := range latest {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Kind < docs[j].Kind })
+	return docs, nil
+}
+
+func (s *consentStore) GetPolicyDocumentByVersion(ctx context.Context, arg sqlc.GetPolicyDocumentByVersionParams) (sqlc.PolicyDocument, error) {
+	for _, doc := range s.docs {
+		if doc.Kind == arg.Kind && doc.Version == arg.Version {
+			return doc, nil
+		}
+	}
+	return sqlc.PolicyDocument{}, pgx.ErrNoRows
+}
+
+func (s *consentStore) CreatePolicyDocument(ctx context.Context, arg sqlc.CreatePolicyDocumentParams) (sqlc.PolicyDocument, error) {
+	doc := sqlc.PolicyDocument{
+		ID:          int32(len(s.docs) + 1),
+		Kind:        arg.Kind,
+		Version:     arg.Version,
+		Url:         arg.Url,
+		PublishedAt: arg.PublishedAt,
+	}
+	s.docs = append(s.docs, doc)
+	return doc, nil
+}
+
+func (s *consentStore) CountMissingConsents(ctx context.Context, arg sqlc.CountMissingConsentsParams) (int64, error) {
+	s.missingChecks++
+	var missing int64
+	for _, id := range arg.DocumentIds {
+		if !s.consents[arg.UserID][id] {
+			missing++
+		}
+	}
+	return missing, nil
+}
+
+func (s *consentStore) CreateUserConsent(ctx context.Context, arg sqlc.CreateUserConsentParams) error {
+	if s.consents[arg.UserID] == nil {
+		s.consents[arg.UserID] = map[int32]bool{}
+	}
+	s.consents[arg.UserID][arg.DocumentID] = true
+	return nil
+}
+
+func TestConsentReacceptance(t *testing.T) {
+	ctx := context.Background()
+	store := &consentStore{consents: map[uuid.UUID]map[int32]bool{}}
+	svc := NewConsentService(store, cache.NewMemory())
+	uid, admin := uuid.New(), uuid.New()
+
+	has, err := svc.HasCurrentConsent(ctx, uid)
+	require.NoError(t, err)
+	require.True(t, has, "there is nothing to accept before a document is published")
+
+	publish := func(kind, version string) error {
+		_, err := svc.Publish(ctx, PublishPolicyReq{Kind: kind, Version: version, Url: "https://bukka.test/" + kind}, admin)
+		return err
+	}
+	require.NoError(t, publish(PolicyTerms, "1.0"))
+	require.NoError(t, publish(PolicyPrivacy, "1.0"))
+
+	has, err = svc.HasCurrentConsent(ctx, uid)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.ErrorIs(t, svc.Accept(ctx, uid, AcceptConsentReq{TermsVersion: "0.9", PrivacyVersion: "1.0"}, "127.0.0.1", "test"), ErrConsentVersionMismatch)
+	require.NoError(t, svc.Accept(ctx, uid, AcceptConsentReq{TermsVersion: "1.0", PrivacyVersion: "1.0"}, "127.0.0.1", "test"))
+
+	checks := store.missingChecks
+	has, err = svc.HasCurrentConsent(ctx, uid)
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Equal(t, checks, store.missingChecks, "accepting caches the result for the current documents")
+
+	// A new version blocks the user again, although their consent to the previous documents is cached
+	oldDocs, err := svc.CurrentDocuments(ctx)
+	require.NoError(t, err)
+	require.NoError(t, publish(PolicyTerms, "2.0"))
+	require.ErrorIs(t, publish(PolicyTerms, "2.0"), ErrPolicyVersionExists)
+	newDocs, err := svc.CurrentDocuments(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, userConsentCacheKey(uid, oldDocs), userConsentCacheKey(uid, newDocs), "the cached result is tied to the documents")
+
+	has, err = svc.HasCurrentConsent(ctx, uid)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.ErrorIs(t, svc.Accept(ctx, uid, AcceptConsentReq{TermsVersion: "1.0", PrivacyVersion: "1.0"}, "127.0.0.1", "test"), ErrConsentVersionMismatch)
+	require.NoError(t, svc.Accept(ctx, uid, AcceptConsentReq{TermsVersion: "2.0", PrivacyVersion: "1.0"}, "127.0.0.1", "test"))
+	has, err = svc.HasCurrentConsent(ctx, uid)
+	require.NoError(t, err)
+	require.True(t, has)
+}
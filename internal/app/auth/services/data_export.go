@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/storage"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/steve-mir/bukka_backend/worker"
+)
+
+var (
+	ErrDataExportTooSoon   = apperr.New(apperr.CodeResourceExhausted, "auth.data_export.too_soon", "a data export was requested recently, please try again later")
+	ErrInvalidDownloadLink = apperr.New(apperr.CodeNotFound, "auth.data_export.invalid_link", "invalid download link")
+	ErrDownloadLinkExpired = apperr.New(apperr.CodeFailedPrecondition, "auth.data_export.link_expired", "download link has expired")
+)
+
+type DataExportRes struct {
+	RequestID uuid.UUID `json:"request_id"`
+	Status    string    `json:"status"`
+	Msg       string    `json:"msg"`
+}
+
+// RequestDataExport records a data export request for the user and queues the worker task that builds the archive.
+// Only one export is allowed per config.DataExportCooldown unless the previous one failed.
+func RequestDataExport(ctx context.Context, store sqlc.Store, config utils.Config, uid uuid.UUID, clientIP, agent string) (sqlc.DataExportRequest, error) {
+	latest, err := store.GetLatestDataExportRequest(ctx, uid)
+	if err != nil && err != pgx.ErrNoRows {
+		return sqlc.DataExportRequest{}, apperr.Internal(err)
+	}
+
+	if err == nil && latest.Status != constants.DataExportFailed && time.Since(latest.CreatedAt) < config.DataExportCooldown {
+		return sqlc.DataExportRequest{}, ErrDataExportTooSoon
+	}
+
+	requestID, err := uuid.NewRandom()
+	if err != nil {
+		return sqlc.DataExportRequest{}, apperr.Internal(err)
+	}
+
+	var request sqlc.DataExportRequest
+	err = store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		var err error
+		request, err = qtx.CreateDataExportRequest(ctx, sqlc.CreateDataExportRequestParams{
+			ID:     requestID,
+			UserID: uid,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create data export request: %w", err)
+		}
+
+		err = outbox.AddTask(ctx, qtx, outbox.UserAggregate(uid), worker.TaskExportUserData, worker.PayloadExportUserData{
+			RequestID: request.ID,
+			UserID:    uid,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to queue data export task: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return sqlc.DataExportRequest{}, err
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    uid,
+		ActorID:   uid,
+		Action:    constants.AuditDataExportRequested,
+		Metadata:  map[string]interface{}{"request_id": request.ID},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	return request, nil
+}
+
+// OpenDataExport validates a download token and opens the stored archive. The caller must close the reader.
+func OpenDataExport(ctx context.Context, store sqlc.Store, exportStorage storage.Storage, downloadToken, clientIP, agent string) (io.ReadCloser, sqlc.DataExportRequest, error) {
+	if downloadToken == "" {
+		return nil, sqlc.DataExportRequest{}, ErrInvalidDownloadLink
+	}
+
+	request, err := store.GetDataExportRequestByTokenHash(ctx, pgtype.Text{String: utils.HashToken(downloadToken), Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, sqlc.DataExportRequest{}, ErrInvalidDownloadLink
+		}
+		return nil, sqlc.DataExportRequest{}, apperr.Internal(err)
+	}
+
+	if request.Status != constants.DataExportCompleted || !request.StorageKey.Valid {
+		return nil, sqlc.DataExportRequest{}, ErrInvalidDownloadLink
+	}
+
+	if !request.ExpiresAt.Valid || request.ExpiresAt.Time.Before(time.Now()) {
+		return nil, sqlc.DataExportRequest{}, ErrDownloadLinkExpired
+	}
+
+	reader, err := exportStorage.Get(ctx, request.StorageKey.String)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			return nil, sqlc.DataExportRequest{}, ErrDownloadLinkExpired
+		}
+		return nil, sqlc.DataExportRequest{}, apperr.Internal(err)
+	}
+
+	if err := store.MarkDataExportDownloaded(ctx, request.ID); err != nil {
+		reader.Close()
+		return nil, sqlc.DataExportRequest{}, apperr.Internal(err)
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    request.UserID,
+		Action:    constants.AuditDataExportDownloaded,
+		Metadata:  map[string]interface{}{"request_id": request.ID},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	return reader, request, nil
+}
@@ -2,83 +2,76 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
 	"github.com/steve-mir/bukka_backend/utils"
 	"github.com/steve-mir/bukka_backend/worker"
 )
 
 const (
-	codeLength    = int64(1000000)
-	length        = 6
-	ResetMsg      = "if an account exists a password reset email will be sent to you"
-	UnexpectedErr = "an unexpected error occurred"
+	codeLength = int64(1000000)
+	length     = 6
+	ResetMsg   = "if an account exists a password reset email will be sent to you"
 )
 
-func SendVerificationEmail(qtx *sqlc.Queries, ctx context.Context, td worker.TaskDistributor, userId uuid.UUID, email string) error {
+// SendVerificationEmail stores a new verification code for the user and queues the email with it, both in the
+// transaction of q so the code is only sent when it committed.
+func SendVerificationEmail(ctx context.Context, q sqlc.Querier, userId uuid.UUID, email string) error {
+	code, err := CreateVerificationCode(ctx, q, userId, email)
+	if err != nil {
+		return err
+	}
+	return QueueVerificationCode(ctx, q, userId, email, code)
+}
 
+// CreateVerificationCode stores a new verification code for the user and returns it, q may belong to a transaction.
+func CreateVerificationCode(ctx context.Context, q sqlc.Querier, userId uuid.UUID, email string) (string, error) {
 	verificationCode, err := utils.GenerateSecureRandomNumber(codeLength)
 	if err != nil {
-		return fmt.Errorf("failed to generate secure random number %v", err)
+		return "", fmt.Errorf("failed to generate secure random number: %w", err)
 	}
 	code := fmt.Sprintf("%06d", verificationCode)
-	content := fmt.Sprintf("Use this to verify you email. code %s", code)
-
-	// Use a WaitGroup to wait for both goroutines to complete
-	var wg sync.WaitGroup
-	wg.Add(2) // We have two goroutines
-
-	// Error channel with buffer for two errors
-	errChan := make(chan error, 2)
-
-	go func() {
-		defer wg.Done() // Notify the WaitGroup that this goroutine is done
-		// Send email here.
-		if err := SendEmail(td, ctx, email, content); err != nil {
-			errChan <- fmt.Errorf("failed to send verification email %v", err)
-		}
-	}()
-
-	go func() {
-		defer wg.Done() // Notify the WaitGroup that this goroutine is done
-		// Add link to db
-		if err := qtx.CreateEmailVerificationRequest(ctx, sqlc.CreateEmailVerificationRequestParams{
-			UserID:    userId,
-			Email:     email,
-			Token:     code,
-			ExpiresAt: time.Now().Add(time.Minute * 15),
-		}); err != nil {
-			errChan <- fmt.Errorf("failed to create email verification request %v", err)
-		}
-	}()
-
-	// Wait for both goroutines to complete
-	wg.Wait()
-	close(errChan) // Close the channel so that the range loop can finish
 
-	// Collect errors from the error channel
-	for err := range errChan {
-		if err != nil {
-			return err // Return the first error encountered
-		}
+	// TODO: If there is any other active code that hasn't expired invalidate all before creating another
+	if err := q.CreateEmailVerificationRequest(ctx, sqlc.CreateEmailVerificationRequestParams{
+		UserID:    userId,
+		Email:     email,
+		Token:     code,
+		ExpiresAt: time.Now().Add(time.Minute * 15),
+	}); err != nil {
+		return "", fmt.Errorf("failed to create email verification request: %w", err)
 	}
+	return code, nil
+}
 
+// QueueVerificationCode queues the email of a code stored by CreateVerificationCode in the outbox of q.
+func QueueVerificationCode(ctx context.Context, q sqlc.Querier, userId uuid.UUID, email, code string) error {
+	err := outbox.AddTask(ctx, q, outbox.UserAggregate(userId), worker.TaskVerifyEmail, worker.PayloadVerifyEmail{
+		UserID: userId,
+		Email:  email,
+		Code:   code,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
 	return nil
-
 }
 
-func ReSendVerificationEmail(store sqlc.Store, ctx context.Context, td worker.TaskDistributor, userId uuid.UUID, email string) error {
+func ReSendVerificationEmail(store sqlc.Store, ctx context.Context, userId uuid.UUID, email string) error {
 
 	// Check if identifier exists
 	usr, err := store.GetUserByID(ctx, userId)
 	if err != nil {
-		return fmt.Errorf("failed to get user by identifier %v", ResetMsg)
+		if err == pgx.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// check account status
@@ -87,55 +80,9 @@ func ReSendVerificationEmail(store sqlc.Store, ctx context.Context, td worker.Ta
 		return err
 	}
 
-	verificationCode, err := utils.GenerateSecureRandomNumber(codeLength)
-	if err != nil {
-		return errors.New("failed to generate secure random number")
-	}
-	code := fmt.Sprintf("%06d", verificationCode)
-	content := fmt.Sprintf("Use this to verify you email. code %s", code)
-
-	// Use a WaitGroup to wait for both goroutines to complete
-	var wg sync.WaitGroup
-	wg.Add(2) // We have two goroutines
-
-	// Error channel with buffer for two errors
-	errChan := make(chan error, 2)
-
-	go func() {
-		defer wg.Done() // Notify the WaitGroup that this goroutine is done
-		// Send email here.
-		if err := SendEmail(td, ctx, email, content); err != nil {
-			errChan <- errors.New("failed to send verification email")
-		}
-	}()
-
-	go func() {
-		defer wg.Done() // Notify the WaitGroup that this goroutine is done
-		// Add link to db
-		// TODO: If there is any other active code that hasn't expired invalidate all before creating another
-		if err := store.CreateEmailVerificationRequest(ctx, sqlc.CreateEmailVerificationRequestParams{
-			UserID:    userId,
-			Email:     email,
-			Token:     code,
-			ExpiresAt: time.Now().Add(time.Minute * 15),
-		}); err != nil {
-			errChan <- errors.New("failed to create email verification request")
-		}
-	}()
-
-	// Wait for both goroutines to complete
-	wg.Wait()
-	close(errChan) // Close the channel so that the range loop can finish
-
-	// Collect errors from the error channel
-	for err := range errChan {
-		if err != nil {
-			return err // Return the first error encountered
-		}
-	}
-
-	return nil
-
+	return store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		return SendVerificationEmail(ctx, qtx, userId, email)
+	})
 }
 
 func VerifyEmail(ctx context.Context, store sqlc.Store, code string) error {
@@ -143,38 +90,41 @@ func VerifyEmail(ctx context.Context, store sqlc.Store, code string) error {
 	defer cancel()
 
 	if len(code) != length {
-		return errors.New("invalid token")
+		return ErrInvalidToken
 	}
 
 	linkData, err := store.GetEmailVerificationRequestByToken(context.Background(), code)
 	if err != nil {
-		return err
+		if err == pgx.ErrNoRows {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get verification request: %w", err)
 	}
 
 	if condition := linkData.ExpiresAt.Before(time.Now()); condition {
-		return fmt.Errorf("token expired")
+		return ErrTokenExpired
 	}
 
 	if condition := linkData.IsVerified.Bool; condition {
-		return fmt.Errorf("token already verified")
+		return ErrTokenUsed
 	}
 
 	// Update token to used
 	usr, err := store.UpdateEmailVerificationRequest(context.Background(), sqlc.UpdateEmailVerificationRequestParams{
 		Token:      code,
-		IsVerified: sql.NullBool{Bool: true, Valid: true},
+		IsVerified: pgtype.Bool{Bool: true, Valid: true},
 	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update verification request: %w", err)
 	}
 
 	// Verify user in "users" db
 	_, err = store.UpdateUser(ctx, sqlc.UpdateUserParams{
 		ID:              usr.UserID,
-		IsEmailVerified: sql.NullBool{Bool: true, Valid: true},
+		IsEmailVerified: pgtype.Bool{Bool: true, Valid: true},
 	})
 	if err != nil {
-		return fmt.Errorf("error updating profile %s", err)
+		return fmt.Errorf("error updating profile: %w", err)
 	}
 
 	// TODO: Generate access token with "verified" as true
@@ -185,15 +135,15 @@ func VerifyEmail(ctx context.Context, store sqlc.Store, code string) error {
 
 func checkAccountStatusForEmail(usr sqlc.Authentication) error {
 	if usr.IsSuspended.Bool {
-		return errors.New("account suspended")
+		return ErrAccountSuspended
 	}
 
 	if usr.IsDeleted.Bool {
-		return errors.New("account deleted")
+		return ErrAccountDeleted
 	}
 
 	if usr.IsEmailVerified.Bool {
-		return errors.New("email already verified")
+		return ErrEmailAlreadyVerified
 	}
 	return nil
 }
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+)
+
+// Errors shown to clients, failures they can do nothing about are returned as plain errors and answered with
+// apperr.Internal. Errors that belong to a single feature live next to it.
+var (
+	ErrLoginMismatch   = apperr.New(apperr.CodeUnauthenticated, "auth.login.mismatch", "id or password mismatch")
+	ErrWrongAuthMethod = apperr.New(apperr.CodeFailedPrecondition, "auth.login.wrong_method", "wrong authentication method")
+	ErrNotOAuthAccount = apperr.New(apperr.CodeFailedPrecondition, "auth.login.not_oauth", "this account is not linked with OAuth")
+
+	ErrEmailTaken         = apperr.New(apperr.CodeAlreadyExists, "auth.register.email_taken", "email already exists")
+	ErrUsernameTaken      = apperr.New(apperr.CodeAlreadyExists, "auth.register.username_taken", "username taken")
+	ErrAccountRecoverable = apperr.New(apperr.CodeFailedPrecondition, "auth.register.account_recoverable", "account is deleted but can be recovered, please follow the account recovery process")
+
+	ErrUserNotFound     = apperr.New(apperr.CodeNotFound, "auth.user.not_found", "user not found")
+	ErrAccountSuspended = apperr.New(apperr.CodePermissionDenied, "auth.account.suspended", "account suspended")
+	ErrAccountDeleted   = apperr.New(apperr.CodeFailedPrecondition, "auth.account.deleted", "account deleted")
+
+	ErrPasswordIncorrect = apperr.New(apperr.CodeInvalidArgument, "auth.password.incorrect", "password incorrect")
+	ErrPasswordReused    = apperr.New(apperr.CodeInvalidArgument, "auth.password.reused", "new password cannot be the same as the old password")
+
+	// Codes and links sent by email for verification, password resets and account recovery
+	ErrInvalidToken         = apperr.New(apperr.CodeInvalidArgument, "auth.token.invalid", "invalid token")
+	ErrTokenExpired         = apperr.New(apperr.CodeFailedPrecondition, "auth.token.expired", "token expired")
+	ErrTokenUsed            = apperr.New(apperr.CodeFailedPrecondition, "auth.token.used", "token already used")
+	ErrEmailAlreadyVerified = apperr.New(apperr.CodeFailedPrecondition, "auth.email.already_verified", "email already verified")
+
+	// Refresh tokens of user and guest sessions
+	ErrInvalidRefreshToken = apperr.New(apperr.CodeUnauthenticated, "auth.session.invalid_token", "invalid refresh token")
+	ErrSuspiciousActivity  = apperr.New(apperr.CodeUnauthenticated, "auth.session.suspicious", "suspicious activity detected")
+	ErrSessionBlocked      = apperr.New(apperr.CodeUnauthenticated, "auth.session.blocked", "session blocked")
+	ErrSessionEnded        = apperr.New(apperr.CodeUnauthenticated, "auth.session.ended", "user not logged in")
+)
+
+// invalidIdentifier rejects a login identifier that looks like an email, phone or username but isn't valid.
+func invalidIdentifier(key, message string) *apperr.Error {
+	return apperr.InvalidField("identifier", key, message)
+}
+
+var (
+	errInvalidEmailIdentifier    = invalidIdentifier("validation.email", constants.InvalidEmail)
+	errInvalidPhoneIdentifier    = invalidIdentifier("validation.phone", constants.InvalidPhone)
+	errInvalidUsernameIdentifier = invalidIdentifier("validation.username", constants.InvalidUsername)
+)
+
+// unknownScope rejects a scope that is not in the list the request is checked against.
+func unknownScope(scope string) *apperr.Error {
+	return apperr.Validation(apperr.FieldError{
+		Field:   "scopes",
+		Rule:    "scope",
+		Key:     "validation.scope",
+		Message: "unknown scope " + scope,
+		Param:   scope,
+	})
+}
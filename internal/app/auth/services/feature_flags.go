@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+var (
+	ErrFeatureFlagNotFound = apperr.New(apperr.CodeNotFound, "auth.feature_flag.not_found", "feature flag not found")
+	ErrFeatureFlagExists   = apperr.New(apperr.CodeAlreadyExists, "auth.feature_flag.exists", "a feature flag with this key already exists")
+	ErrFeatureFlagChanged  = apperr.New(apperr.CodeFailedPrecondition, "auth.feature_flag.changed", "the feature flag was changed since you read it, reload it and try again")
+)
+
+// FeatureFlagDefinition is how a flag serves its variants, see featureflag.Flag.
+type FeatureFlagDefinition struct {
+	Description string             `json:"description" binding:"max=500"`
+	Enabled     bool               `json:"enabled"`
+	Variants    []string           `json:"variants" binding:"required"`
+	OffVariant  string             `json:"off_variant" binding:"required"`
+	Rules       []featureflag.Rule `json:"rules"`
+	Default     featureflag.Serve  `json:"default"`
+}
+
+type CreateFeatureFlagReq struct {
+	Key  string           `json:"key" binding:"required"`
+	Kind featureflag.Kind `json:"kind" binding:"required,oneof=boolean variant"`
+	FeatureFlagDefinition
+}
+
+type UpdateFeatureFlagReq struct {
+	// Version is the version the change was made on, it fails when someone else changed the flag since
+	Version int32 `json:"version" binding:"required,min=1"`
+	FeatureFlagDefinition
+}
+
+type FeatureFlagRes struct {
+	Key         string             `json:"key"`
+	Description string             `json:"description,omitempty"`
+	Kind        featureflag.Kind   `json:"kind"`
+	Enabled     bool               `json:"enabled"`
+	Variants    []string           `json:"variants"`
+	OffVariant  string             `json:"off_variant"`
+	Rules       []featureflag.Rule `json:"rules"`
+	Default     featureflag.Serve  `json:"default"`
+	Version     int32              `json:"version"`
+	CreatedBy   uuid.UUID          `json:"created_by"`
+	UpdatedBy   uuid.UUID          `json:"updated_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+func newFeatureFlagRes(row sqlc.FeatureFlag) (FeatureFlagRes, error) {
+	flag, err := featureflag.FromRow(row)
+	if err != nil {
+		return FeatureFlagRes{}, err
+	}
+	rules := flag.Rules
+	if rules == nil {
+		rules = []featureflag.Rule{}
+	}
+	return FeatureFlagRes{
+		Key:         row.Key,
+		Description: row.Description.String,
+		Kind:        flag.Kind,
+		Enabled:     flag.Enabled,
+		Variants:    flag.Variants,
+		OffVariant:  flag.OffVariant,
+		Rules:       rules,
+		Default:     flag.Default,
+		Version:     row.Version,
+		CreatedBy:   row.CreatedBy,
+		UpdatedBy:   row.UpdatedBy,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+// validateFeatureFlag checks the flag can be evaluated and returns its rules and default as stored.
+func validateFeatureFlag(key string, kind featureflag.Kind, def FeatureFlagDefinition) (rules, defaultRule []byte, err error) {
+	flag := featureflag.Flag{
+		Key:        key,
+		Kind:       kind,
+		Variants:   def.Variants,
+		OffVariant: def.OffVariant,
+		Rules:      def.Rules,
+		Default:    def.Default,
+	}
+	if err := flag.Validate(); err != nil {
+		return nil, nil, apperr.InvalidField("definition", "validation.feature_flag", err.Error())
+	}
+
+	if def.Rules == nil {
+		def.Rules = []featureflag.Rule{}
+	}
+	if rules, err = json.Marshal(def.Rules); err != nil {
+		return nil, nil, apperr.Internal(err)
+	}
+	if defaultRule, err = json.Marshal(def.Default); err != nil {
+		return nil, nil, apperr.Internal(err)
+	}
+	return rules, defaultRule, nil
+}
+
+func CreateFeatureFlag(ctx context.Context, store sqlc.Store, admin *token.Payload, req CreateFeatureFlagReq, clientIP, agent string) (FeatureFlagRes, error) {
+	rules, defaultRule, err := validateFeatureFlag(req.Key, req.Kind, req.FeatureFlagDefinition)
+	if err != nil {
+		return FeatureFlagRes{}, err
+	}
+
+	if _, err := store.GetFeatureFlag(ctx, req.Key); err == nil {
+		return FeatureFlagRes{}, ErrFeatureFlagExists
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return FeatureFlagRes{}, apperr.Internal(err)
+	}
+
+	row, err := store.CreateFeatureFlag(ctx, sqlc.CreateFeatureFlagParams{
+		Key:         req.Key,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Kind:        string(req.Kind),
+		Enabled:     req.Enabled,
+		Variants:    req.Variants,
+		OffVariant:  req.OffVariant,
+		Rules:       rules,
+		DefaultRule: defaultRule,
+		CreatedBy:   admin.Subject,
+	})
+	if err != nil {
+		return FeatureFlagRes{}, apperr.Internal(err)
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditFeatureFlagCreated,
+		Metadata:  map[string]interface{}{"key": row.Key, "enabled": row.Enabled},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return newFeatureFlagRes(row)
+}
+
+func ListFeatureFlags(ctx context.Context, store sqlc.Store) ([]FeatureFlagRes, error) {
+	rows, err := store.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]FeatureFlagRes, 0, len(rows))
+	for _, row := range rows {
+		flag, err := newFeatureFlagRes(row)
+		if err != nil {
+			return nil, apperr.Internal(err)
+		}
+		res = append(res, flag)
+	}
+	return res, nil
+}
+
+func GetFeatureFlag(ctx context.Context, store sqlc.Store, key string) (FeatureFlagRes, error) {
+	row, err := store.GetFeatureFlag(ctx, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FeatureFlagRes{}, ErrFeatureFlagNotFound
+		}
+		return FeatureFlagRes{}, apperr.Internal(err)
+	}
+	return newFeatureFlagRes(row)
+}
+
+// UpdateFeatureFlag replaces how the flag serves its variants, its kind can't change. Every instance sees the
+// change on its next evaluation.
+func UpdateFeatureFlag(ctx context.Context, store sqlc.Store, flags *featureflag.Client, admin *token.Payload, key string, req UpdateFeatureFlagReq, clientIP, agent string) (FeatureFlagRes, error) {
+	current, err := store.GetFeatureFlag(ctx, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FeatureFlagRes{}, ErrFeatureFlagNotFound
+		}
+		return FeatureFlagRes{}, apperr.Internal(err)
+	}
+
+	rules, defaultRule, err := validateFeatureFlag(key, featureflag.Kind(current.Kind), req.FeatureFlagDefinition)
+	if err != nil {
+		return FeatureFlagRes{}, err
+	}
+
+	row, err := store.UpdateFeatureFlag(ctx, sqlc.UpdateFeatureFlagParams{
+		Key:         key,
+		Version:     req.Version,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Enabled:     req.Enabled,
+		Variants:    req.Variants,
+		OffVariant:  req.OffVariant,
+		Rules:       rules,
+		DefaultRule: defaultRule,
+		UpdatedBy:   admin.Subject,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FeatureFlagRes{}, ErrFeatureFlagChanged
+		}
+		return FeatureFlagRes{}, apperr.Internal(err)
+	}
+	flags.Invalidate(ctx, key)
+
+	RecordAudit(ctx, store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditFeatureFlagUpdated,
+		Metadata:  map[string]interface{}{"key": key, "enabled": row.Enabled, "version": row.Version},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return newFeatureFlagRes(row)
+}
+
+// DeleteFeatureFlag removes the flag, callers get the fallback of the SDK from then on.
+func DeleteFeatureFlag(ctx context.Context, store sqlc.Store, flags *featureflag.Client, admin *token.Payload, key, clientIP, agent string) error {
+	deleted, err := store.DeleteFeatureFlag(ctx, key)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if deleted == 0 {
+		return ErrFeatureFlagNotFound
+	}
+	flags.Invalidate(ctx, key)
+
+	RecordAudit(ctx, store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditFeatureFlagDeleted,
+		Metadata:  map[string]interface{}{"key": key},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return nil
+}
@@ -2,29 +2,32 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
 	"github.com/steve-mir/bukka_backend/utils"
 	"github.com/steve-mir/bukka_backend/worker"
-	"golang.org/x/sync/errgroup"
 )
 
-func RequestPwdReset(ctx context.Context, email string, store sqlc.Store, td worker.TaskDistributor) error {
+func RequestPwdReset(ctx context.Context, email string, store sqlc.Store) error {
 
 	usr, pwdResetCodeStr, err := initChangeRequest(ctx, store, email)
 	if err != nil {
-		return errors.New(ResetMsg)
+		// Unknown and inactive accounts get the same answer as everyone else so accounts can't be probed
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, ErrAccountSuspended) || errors.Is(err, ErrAccountDeleted) {
+			return nil
+		}
+		return err
 	}
-	msg := fmt.Sprintf("Below is code to reset your password: %s.\nPlease do not share this with anyone", pwdResetCodeStr)
-	var eg errgroup.Group
 
-	// Add link to db
-	eg.Go(func() error {
-		if err := store.CreatePasswordResetRequest(ctx, sqlc.CreatePasswordResetRequestParams{
+	// The code is only emailed once it is stored
+	err = store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		if err := qtx.CreatePasswordResetRequest(ctx, sqlc.CreatePasswordResetRequestParams{
 			UserID:    usr.ID,
 			Email:     usr.Email,
 			Token:     pwdResetCodeStr,
@@ -32,45 +35,42 @@ func RequestPwdReset(ctx context.Context, email string, store sqlc.Store, td wor
 		}); err != nil {
 			return err
 		}
-		return nil
+		return outbox.AddTask(ctx, qtx, outbox.UserAggregate(usr.ID), worker.TaskPasswordResetEmail, worker.PayloadPasswordResetEmail{
+			UserID: usr.ID,
+			Email:  usr.Email,
+			Code:   pwdResetCodeStr,
+		})
 	})
-
-	// Send email
-	eg.Go(func() error {
-		if err := SendEmail(td, ctx, email, msg); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	// Wait for both goroutines to complete
-	if err := eg.Wait(); err != nil {
-		return fmt.Errorf("an unexpected error occurred: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to request password reset: %w", err)
 	}
 
 	return nil
 }
 
-func ResetPassword(ctx context.Context, qtx *sqlc.Queries, tx *sql.Tx, store sqlc.Store, code, pwd string) error {
+func ResetPassword(ctx context.Context, store sqlc.Store, code, pwd string) error {
 	// Create a context with a timeout for the transaction
 	ctx, cancel := context.WithTimeout(ctx, time.Second*10) // Adjust the timeout as needed
 	defer cancel()
 
 	if len(code) != length {
-		return errors.New("invalid token")
+		return ErrInvalidToken
 	}
 
 	tokenData, err := store.GetPasswordResetRequestByToken(ctx, code)
 	if err != nil {
-		return err
+		if err == pgx.ErrNoRows {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get password reset request: %w", err)
 	}
 
 	if tokenData.ExpiresAt.Before(time.Now()) {
-		return fmt.Errorf("token expired")
+		return ErrTokenExpired
 	}
 
 	if tokenData.Used.Bool {
-		return fmt.Errorf("token already used")
+		return ErrTokenUsed
 	}
 
 	// ! 1 Get User
@@ -86,60 +86,51 @@ func ResetPassword(ctx context.Context, qtx *sqlc.Queries, tx *sql.Tx, store sql
 
 	// ! 2 Check old password
 	if err = utils.CheckPassword(pwd, user.PasswordHash); err == nil {
-		return errors.New("cannot use old password")
+		return ErrPasswordReused
 	}
 
 	// ! 3 Hash password
 	hashedPwd, err := utils.HashPassword(pwd)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	var eg errgroup.Group
-
-	// Goroutine for updating token status
-	eg.Go(func() error {
-		// Update password_request table
-		err := updateResetPwdTokenStatus(ctx, qtx, code)
+	// Repeatable read makes a second reset racing on the same code fail to serialize, its retry then sees the code used
+	err = store.ExecTx(ctx, sqlc.TxOptions{Isolation: pgx.RepeatableRead}, func(qtx *sqlc.Queries) error {
+		tokenData, err := qtx.GetPasswordResetRequestByToken(ctx, code)
 		if err != nil {
 			return err
 		}
-		return nil
-	})
 
-	// Goroutine for updating user password
-	eg.Go(func() error {
+		if tokenData.Used.Bool {
+			return ErrTokenUsed
+		}
 
-		// Update users table
-		err := updateUserPassword(ctx, qtx, tokenData.Email, hashedPwd)
-		if err != nil {
+		// Update password_request table
+		if err := updateResetPwdTokenStatus(ctx, qtx, code); err != nil {
 			return err
 		}
-		return nil
-	})
-
-	// Wait for both goroutines to complete
-	if err := eg.Wait(); err != nil {
-		tx.Rollback()
-		return errors.New("error resetting password " + err.Error())
-	}
 
-	// Commit the transaction if all updates were successful
-	err = tx.Commit()
+		// Update users table
+		return updateUserPassword(ctx, qtx, tokenData.Email, hashedPwd)
+	})
 	if err != nil {
-		return err
+		if errors.Is(err, ErrTokenUsed) {
+			return err
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
 	}
 
 	return nil
-
 }
+
 func getUser(ctx context.Context, store sqlc.Store, email string) (sqlc.Authentication, error) {
 	user, err := store.GetUserByIdentifier(ctx, email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return sqlc.Authentication{}, errors.New("1. email not found")
+		if err == pgx.ErrNoRows {
+			return sqlc.Authentication{}, ErrUserNotFound
 		}
-		return sqlc.Authentication{}, errors.New("email not found")
+		return sqlc.Authentication{}, fmt.Errorf("failed to get user: %w", err)
 	}
 	return user, nil
 }
@@ -147,7 +138,7 @@ func getUser(ctx context.Context, store sqlc.Store, email string) (sqlc.Authenti
 func updateResetPwdTokenStatus(ctx context.Context, qtx *sqlc.Queries, token string) error {
 	err := qtx.UpdatePasswordResetRequestByToken(ctx, sqlc.UpdatePasswordResetRequestByTokenParams{
 		Token: token,
-		Used:  sql.NullBool{Bool: true, Valid: true},
+		Used:  pgtype.Bool{Bool: true, Valid: true},
 	})
 	return err
 }
@@ -164,13 +155,13 @@ func updateUserPassword(ctx context.Context, qtx *sqlc.Queries, email, hashedPwd
 func initChangeRequest(ctx context.Context, store sqlc.Store, id string) (sqlc.Authentication, string, error) {
 	emailResetCode, err := utils.GenerateSecureRandomNumber(codeLength)
 	if err != nil {
-		return sqlc.Authentication{}, "", fmt.Errorf("failed to generate secure random number %v", err)
+		return sqlc.Authentication{}, "", fmt.Errorf("failed to generate secure random number: %w", err)
 	}
 
 	// Check if identifier exists
 	usr, err := store.GetUserByIdentifier(ctx, id)
 	if err != nil {
-		return sqlc.Authentication{}, "", fmt.Errorf("failed to get user by identifier, %s. %v", ResetMsg, err)
+		return sqlc.Authentication{}, "", fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// check account status
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+var (
+	ErrGuestSessionClosed = apperr.New(apperr.CodeFailedPrecondition, "auth.guest.closed", "guest session is no longer active")
+	ErrNotGuestToken      = apperr.New(apperr.CodeInvalidArgument, "auth.guest.invalid_token", "token does not belong to a guest session")
+)
+
+type GuestSessionReq struct {
+	DeviceID   string `json:"device_id"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+}
+
+type GuestSessionRes struct {
+	GuestID   uuid.UUID `json:"guest_id"`
+	CreatedAt time.Time `json:"created_at"`
+	AuthToken AuthToken `json:"auth_token"`
+}
+
+// CreateGuestSession starts an anonymous session. The guest id doubles as the token subject and session id so that
+// the guest's data (cart etc) can be keyed on it and later moved over to a real account.
+func CreateGuestSession(ctx context.Context, store sqlc.Store, tokenService *TokenService, config utils.Config, req GuestSessionReq, clientIP, agent string) (GuestSessionRes, error) {
+	guestID, err := uuid.NewRandom()
+	if err != nil {
+		return GuestSessionRes{}, apperr.Internal(err)
+	}
+
+	authToken, err := tokenService.CreateGuestTokenPair(ctx, newGuestPayload(config, guestID, req.DeviceID, req.Platform, req.AppVersion, clientIP, agent))
+	if err != nil {
+		return GuestSessionRes{}, fmt.Errorf("error creating guest token: %w", err)
+	}
+
+	session, err := store.CreateGuestSession(ctx, sqlc.CreateGuestSessionParams{
+		ID:              guestID,
+		RefreshToken:    authToken.RefreshToken,
+		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
+		DeviceID:        pgtype.Text{String: req.DeviceID, Valid: req.DeviceID != ""},
+		Platform:        pgtype.Text{String: req.Platform, Valid: req.Platform != ""},
+		UserAgent:       agent,
+		IpAddress:       utils.GetIpAddrOrUnspecified(clientIP),
+	})
+	if err != nil {
+		return GuestSessionRes{}, fmt.Errorf("error creating guest session: %w", err)
+	}
+
+	return GuestSessionRes{
+		GuestID:   session.ID,
+		CreatedAt: session.CreatedAt.Time,
+		AuthToken: authToken,
+	}, nil
+}
+
+// RotateGuestToken exchanges a guest refresh token for a new guest token pair.
+// Guest sessions that have been upgraded to an account can no longer be rotated.
+func RotateGuestToken(ctx context.Context, req RotateTokenReq, store sqlc.Store, tokenService *TokenService, tokenMaker token.Maker, cache cache.Cache, config utils.Config, clientIP, agent string) (AuthToken, error) {
+	payload, err := tokenMaker.VerifyToken(ctx, cache, req.RefreshToken, token.RefreshToken)
+	if err != nil {
+		return AuthToken{}, ErrInvalidRefreshToken.WithCause(err)
+	}
+
+	session, err := store.GetGuestSessionByRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return AuthToken{}, ErrGuestSessionClosed
+		}
+		return AuthToken{}, fmt.Errorf("failed to get guest session: %w", err)
+	}
+
+	if session.ID != payload.Subject || session.RetiredAt.Valid {
+		return AuthToken{}, ErrGuestSessionClosed
+	}
+
+	authToken, err := tokenService.CreateGuestTokenPair(ctx, newGuestPayload(config, session.ID, session.DeviceID.String, session.Platform.String, payload.AppVersion, clientIP, agent))
+	if err != nil {
+		return AuthToken{}, fmt.Errorf("could not rotate token: %w", err)
+	}
+
+	err = store.RotateGuestSessionToken(ctx, sqlc.RotateGuestSessionTokenParams{
+		ID:              session.ID,
+		RefreshToken:    authToken.RefreshToken,
+		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
+	})
+	if err != nil {
+		return AuthToken{}, fmt.Errorf("could not rotate token: %w", err)
+	}
+
+	return authToken, nil
+}
+
+// UpgradeGuestSession moves everything the guest built up into the user's account and retires the guest session.
+// guestToken is the guest's access token, it is revoked once the merge is committed.
+func UpgradeGuestSession(ctx context.Context, store sqlc.Store, tokenMaker token.Maker, cache cache.Cache, guestToken string, uid uuid.UUID) error {
+	payload, err := tokenMaker.VerifyToken(ctx, cache, guestToken, token.AccessToken)
+	if err != nil {
+		return ErrNotGuestToken.WithCause(err)
+	}
+
+	if payload.Role != constants.Guests {
+		return ErrNotGuestToken
+	}
+
+	err = store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		session, err := qtx.GetGuestSessionByID(ctx, payload.Subject)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrGuestSessionClosed
+			}
+			return fmt.Errorf("failed to get guest session: %w", err)
+		}
+
+		if session.RetiredAt.Valid {
+			return ErrGuestSessionClosed
+		}
+
+		if err = qtx.MergeCart(ctx, sqlc.MergeCartParams{ToOwner: uid, FromOwner: session.ID}); err != nil {
+			return fmt.Errorf("failed to merge guest cart: %w", err)
+		}
+
+		if err = qtx.ClearCart(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to clear guest cart: %w", err)
+		}
+
+		err = qtx.RetireGuestSession(ctx, sqlc.RetireGuestSessionParams{
+			ID:         session.ID,
+			UpgradedTo: uuid.NullUUID{UUID: uid, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to retire guest session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return cache.DeleteKey(ctx, guestToken)
+}
+
+func newGuestPayload(config utils.Config, guestID uuid.UUID, deviceID, platform, appVersion, clientIP, agent string) token.PayloadData {
+	return token.PayloadData{
+		Role:       constants.Guests,
+		Subject:    guestID,
+		SessionID:  guestID,
+		Issuer:     config.AppName,
+		Audience:   "website users",
+		IP:         clientIP,
+		UserAgent:  agent,
+		DeviceID:   deviceID,
+		Platform:   platform,
+		AppVersion: appVersion,
+		TokenType:  token.AccessToken,
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+var (
+	ErrImpersonationNotFound = apperr.New(apperr.CodeNotFound, "auth.impersonation.not_found", "impersonation session not found or already ended")
+	ErrNotImpersonating      = apperr.New(apperr.CodeFailedPrecondition, "auth.impersonation.not_impersonating", "this token is not an impersonation token")
+	ErrNestedImpersonation   = apperr.New(apperr.CodeFailedPrecondition, "auth.impersonation.nested", "can not start an impersonation while impersonating")
+	ErrSelfImpersonation     = apperr.New(apperr.CodeInvalidArgument, "auth.impersonation.self", "you can not impersonate yourself")
+	ErrImpersonateSuperAdmin = apperr.New(apperr.CodePermissionDenied, "auth.impersonation.super_admin", "super admins can not be impersonated")
+)
+
+type StartImpersonationReq struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Reason string    `json:"reason" binding:"required,min=5,max=500"`
+}
+
+type ImpersonationRes struct {
+	SessionID            uuid.UUID `json:"session_id"`
+	TargetUserID         uuid.UUID `json:"target_user_id"`
+	ImpersonatorID       uuid.UUID `json:"impersonator_id"`
+	AccessToken          string    `json:"access_token,omitempty"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	StartedAt            time.Time `json:"started_at"`
+}
+
+// StartImpersonation lets a super admin act as another user. The issued access token carries the target as the
+// subject and the admin as the impersonator, it is short lived and has no refresh token.
+func StartImpersonation(ctx context.Context, store sqlc.Store, tokenService *TokenService, cache cache.Cache, config utils.Config, admin *token.Payload, req StartImpersonationReq, clientIP, agent string) (ImpersonationRes, error) {
+	if admin.IsImpersonated() {
+		return ImpersonationRes{}, ErrNestedImpersonation
+	}
+
+	if req.UserID == admin.Subject {
+		return ImpersonationRes{}, ErrSelfImpersonation
+	}
+
+	user, err := store.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ImpersonationRes{}, ErrUserNotFound
+		}
+		return ImpersonationRes{}, apperr.Internal(err)
+	}
+
+	if user.IsDeleted.Bool {
+		return ImpersonationRes{}, ErrUserNotFound
+	}
+
+	roles, err := store.GetUserRolesByUserID(ctx, user.ID)
+	if err != nil {
+		return ImpersonationRes{}, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	if len(roles) == 0 {
+		return ImpersonationRes{}, fmt.Errorf("user %s has no role", user.ID)
+	}
+
+	role := int8(roles[0].RoleID)
+	if role == constants.SuperAdmin {
+		return ImpersonationRes{}, ErrImpersonateSuperAdmin
+	}
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return ImpersonationRes{}, apperr.Internal(err)
+	}
+
+	accessToken, accessPayload, err := tokenService.CreateImpersonationToken(ctx, token.PayloadData{
+		Role:           role,
+		Subject:        user.ID,
+		Username:       user.Username.String,
+		Email:          user.Email,
+		Phone:          user.Phone.String,
+		EmailVerified:  user.IsEmailVerified.Bool,
+		Issuer:         config.AppName,
+		IP:             clientIP,
+		UserAgent:      agent,
+		MfaPassed:      true,
+		SessionID:      sessionID,
+		ImpersonatorID: admin.Subject,
+	})
+	if err != nil {
+		return ImpersonationRes{}, fmt.Errorf("error creating impersonation token: %w", err)
+	}
+
+	session, err := store.CreateImpersonationSession(ctx, sqlc.CreateImpersonationSessionParams{
+		ID:             sessionID,
+		ImpersonatorID: admin.Subject,
+		TargetUserID:   user.ID,
+		Reason:         req.Reason,
+		IpAddress:      utils.GetIpAddr(clientIP),
+		UserAgent:      pgtype.Text{String: agent, Valid: agent != ""},
+		ExpiresAt:      accessPayload.Expires,
+	})
+	if err != nil {
+		cache.DeleteKey(ctx, accessToken)
+		return ImpersonationRes{}, fmt.Errorf("error creating impersonation session: %w", err)
+	}
+
+	// Keep a handle on the token so the session can be revoked by id
+	err = cache.SetKey(ctx, impersonationCacheKey(sessionID), accessToken, config.ImpersonationDuration)
+	if err != nil {
+		return ImpersonationRes{}, err
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    user.ID,
+		ActorID:   admin.Subject,
+		Action:    constants.AuditImpersonationStarted,
+		Metadata:  map[string]interface{}{"session_id": sessionID, "reason": req.Reason},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	return ImpersonationRes{
+		SessionID:            session.ID,
+		TargetUserID:         session.TargetUserID,
+		ImpersonatorID:       session.ImpersonatorID,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.Expires,
+		StartedAt:            session.StartedAt,
+	}, nil
+}
+
+// EndImpersonation closes the impersonation session the token belongs to.
+func EndImpersonation(ctx context.Context, store sqlc.Store, cache cache.Cache, payload *token.Payload, clientIP, agent string) error {
+	if !payload.IsImpersonated() {
+		return ErrNotImpersonating
+	}
+
+	return RevokeImpersonation(ctx, store, cache, payload.SessionID, payload.ImpersonatorID, clientIP, agent)
+}
+
+// RevokeImpersonation ends an impersonation session and invalidates its access token. endedBy is the admin
+// that ended it, which may be someone other than the impersonator.
+func RevokeImpersonation(ctx context.Context, store sqlc.Store, cache cache.Cache, sessionID, endedBy uuid.UUID, clientIP, agent string) error {
+	session, err := store.GetImpersonationSession(ctx, sessionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrImpersonationNotFound
+		}
+		return apperr.Internal(err)
+	}
+
+	rows, err := store.EndImpersonationSession(ctx, sqlc.EndImpersonationSessionParams{
+		ID:      session.ID,
+		EndedBy: uuid.NullUUID{UUID: endedBy, Valid: true},
+	})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if rows == 0 {
+		return ErrImpersonationNotFound
+	}
+
+	key := impersonationCacheKey(session.ID)
+	if accessToken, err := cache.GetKey(ctx, key); err == nil && accessToken != "" {
+		if err := cache.DeleteKey(ctx, accessToken); err != nil {
+			return err
+		}
+	}
+	if err := cache.DeleteKey(ctx, key); err != nil {
+		return err
+	}
+
+	RecordAudit(ctx, store, AuditEntry{
+		UserID:    session.TargetUserID,
+		ActorID:   endedBy,
+		Action:    constants.AuditImpersonationEnded,
+		Metadata:  map[string]interface{}{"session_id": session.ID, "impersonator_id": session.ImpersonatorID},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	return nil
+}
+
+func ListActiveImpersonations(ctx context.Context, store sqlc.Store) ([]ImpersonationRes, error) {
+	sessions, err := store.ListActiveImpersonationSessions(ctx)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]ImpersonationRes, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, ImpersonationRes{
+			SessionID:            session.ID,
+			TargetUserID:         session.TargetUserID,
+			ImpersonatorID:       session.ImpersonatorID,
+			AccessTokenExpiresAt: session.ExpiresAt,
+			StartedAt:            session.StartedAt,
+		})
+	}
+	return res, nil
+}
+
+func impersonationCacheKey(sessionID uuid.UUID) string {
+	return "impersonation:" + sessionID.String()
+}
@@ -2,14 +2,12 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
-	"log"
+	"net/netip"
 
 	"github.com/google/uuid"
-	"github.com/sqlc-dev/pqtype"
-	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/token"
 	"github.com/steve-mir/bukka_backend/utils"
@@ -22,29 +20,29 @@ func LogUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, ctx co
 
 	sessionID, err := uuid.NewRandom()
 	if err != nil {
-		return UserAuthRes{}, fmt.Errorf("error creating uid %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating uid: %w", err)
 	}
 
-	user, err := store.GetUserAndRoleByIdentifier(ctx, sql.NullString{String: req.Identifier, Valid: true})
+	user, err := store.GetUserAndRoleByIdentifier(ctx, pgtype.Text{String: req.Identifier, Valid: true})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return UserAuthRes{}, errors.New(constants.LoginError)
+		if err == pgx.ErrNoRows {
+			return UserAuthRes{}, ErrLoginMismatch
 		}
-		return UserAuthRes{}, errors.New(constants.LoginError)
+		return UserAuthRes{}, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user.IsOauthUser.Bool {
-		return UserAuthRes{}, errors.New("wrong authentication method")
+		return UserAuthRes{}, ErrWrongAuthMethod
 	}
 
 	err = utils.CheckPassword(req.Password, user.PasswordHash)
 	if err != nil {
-		return UserAuthRes{}, errors.New(constants.LoginError)
+		return UserAuthRes{}, ErrLoginMismatch
 	}
 
 	// Check if user should gain access
 	err = checkAccountStat(user.IsSuspended.Bool, user.IsDeleted.Bool)
 	if err != nil {
-		return UserAuthRes{}, errors.New(constants.LoginError)
+		return UserAuthRes{}, err
 	}
 
 	var mfaPassed bool
@@ -71,8 +69,7 @@ func LogUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, ctx co
 		TokenType:     token.TokenType(token.AccessToken),
 	})
 	if err != nil {
-		log.Println("Session ID Error", err)
-		return UserAuthRes{}, fmt.Errorf("error creating session id %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating tokens: %w", err)
 	}
 
 	ip := utils.GetIpAddr(clientIP)
@@ -83,18 +80,18 @@ func LogUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, ctx co
 		RefreshToken:    authToken.RefreshToken,
 		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
 		UserAgent:       agent,
-		IpAddress:       ip,
-		FcmToken:        sql.NullString{String: req.FcmToken, Valid: true},
+		IpAddress:       utils.GetIpAddrOrUnspecified(clientIP),
+		FcmToken:        pgtype.Text{String: req.FcmToken, Valid: true},
 	})
 
 	if err != nil {
-		return UserAuthRes{}, fmt.Errorf("error creating session id %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating session: %w", err)
 	}
 
 	//! 3 User logged in successfully. Record it
 	err = recordLoginSuccess(ctx, store, user.ID, agent, ip)
 	if err != nil {
-		return UserAuthRes{}, fmt.Errorf("error creating login record %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating login record: %w", err)
 	}
 
 	// return resp
@@ -119,20 +116,23 @@ func LogUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, ctx co
 }
 
 func LogOAuthUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, ctx context.Context, config utils.Config, clientIP, agent string) (UserAuthRes, error) {
-	user, err := store.GetUserAndRoleByIdentifier(ctx, sql.NullString{String: req.Identifier, Valid: true})
+	user, err := store.GetUserAndRoleByIdentifier(ctx, pgtype.Text{String: req.Identifier, Valid: true})
 	if err != nil {
-		return UserAuthRes{}, errors.New(constants.LoginError)
+		if err == pgx.ErrNoRows {
+			return UserAuthRes{}, ErrLoginMismatch
+		}
+		return UserAuthRes{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Check if the user is an OAuth user
 	if !user.IsOauthUser.Bool {
-		return UserAuthRes{}, errors.New("this account is not linked with OAuth")
+		return UserAuthRes{}, ErrNotOAuthAccount
 	}
 
 	// Check if user should gain access
 	err = checkAccountStat(user.IsSuspended.Bool, user.IsDeleted.Bool)
 	if err != nil {
-		return UserAuthRes{}, errors.New(constants.LoginError)
+		return UserAuthRes{}, err
 	}
 
 	// For OAuth users, we assume MFA is passed (you might want to handle this differently)
@@ -141,7 +141,7 @@ func LogOAuthUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, c
 	// Create session ID
 	sessionID, err := uuid.NewRandom()
 	if err != nil {
-		return UserAuthRes{}, fmt.Errorf("error creating session id: %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating session id: %w", err)
 	}
 
 	// Create access token and Refresh token
@@ -161,8 +161,7 @@ func LogOAuthUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, c
 		TokenType:     token.TokenType(token.AccessToken),
 	})
 	if err != nil {
-		log.Println("Session ID Error", err)
-		return UserAuthRes{}, fmt.Errorf("error creating session id %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating tokens: %w", err)
 	}
 
 	ip := utils.GetIpAddr(clientIP)
@@ -173,19 +172,18 @@ func LogOAuthUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, c
 		RefreshToken:    authToken.RefreshToken,
 		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
 		UserAgent:       agent,
-		IpAddress:       ip,
-		FcmToken:        sql.NullString{String: req.FcmToken, Valid: true},
+		IpAddress:       utils.GetIpAddrOrUnspecified(clientIP),
+		FcmToken:        pgtype.Text{String: req.FcmToken, Valid: true},
 	})
 
 	if err != nil {
-		log.Println("Session ID Error", err)
-		return UserAuthRes{}, fmt.Errorf("error creating session id %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating session: %w", err)
 	}
 
 	//! 3 User logged in successfully. Record it
 	err = recordLoginSuccess(ctx, store, user.ID, agent, ip)
 	if err != nil {
-		return UserAuthRes{}, fmt.Errorf("error creating login record %s", err)
+		return UserAuthRes{}, fmt.Errorf("error creating login record: %w", err)
 	}
 
 	// return resp
@@ -212,15 +210,15 @@ func LogOAuthUserIn(req LoginReq, tokenService TokenService, store sqlc.Store, c
 func validateLoginUserRequest(identifier string) error {
 	if utils.IsEmailFormat(identifier) { // Assuming there's a function to check if the format is an email
 		if ok := utils.ValidateEmail(identifier); !ok {
-			return errors.New(constants.InvalidEmail)
+			return errInvalidEmailIdentifier
 		}
 	} else if utils.IsPhoneFormat(identifier) {
 		if !utils.ValidatePhone(identifier) {
-			return errors.New(constants.InvalidPhone)
+			return errInvalidPhoneIdentifier
 		}
 	} else { // Default to username validation if it's not email or phone
 		if !utils.ValidateUsername(identifier) {
-			return errors.New(constants.InvalidUsername)
+			return errInvalidUsernameIdentifier
 		}
 	}
 
@@ -228,25 +226,22 @@ func validateLoginUserRequest(identifier string) error {
 }
 
 func checkAccountStat(isSuspended bool, isDeleted bool) error {
-	fmt.Printf("Is Suspended %v is deleted %v", isSuspended, isDeleted)
 	if isSuspended {
-		log.Println("Account deleted: ", isSuspended)
-		return errors.New("account suspended")
+		return ErrAccountSuspended
 	}
 
-	// Check if user should gain access
+	// Deleted accounts look like unknown ones
 	if isDeleted {
-		log.Println("Account deleted: ", isDeleted)
-		return errors.New(constants.LoginError)
+		return ErrLoginMismatch
 	}
 	return nil
 }
 
-func recordLoginSuccess(ctx context.Context, dbStore sqlc.Store, userId uuid.UUID, userAgent string, ipAddrs pqtype.Inet) error {
+func recordLoginSuccess(ctx context.Context, dbStore sqlc.Store, userId uuid.UUID, userAgent string, ipAddrs *netip.Addr) error {
 
 	_, err := dbStore.CreateUserLogin(ctx, sqlc.CreateUserLoginParams{
 		UserID: userId,
-		UserAgent: sql.NullString{
+		UserAgent: pgtype.Text{
 			String: userAgent,
 			Valid:  true,
 		},
@@ -11,6 +11,9 @@ type LoginReq struct {
 	Identifier string `json:"identifier" binding:"required"`
 	FcmToken   string `json:"fcm_token"`
 	Password   string `json:"password" binding:"required,passwordValidator"`
+
+	// Access token of the guest session to merge into the account, if any
+	GuestToken string `json:"guest_token"`
 }
 
 type VerifyEmailReq struct {
@@ -0,0 +1,752 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/oidc"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+var (
+	ErrOAuthClientNotFound  = apperr.New(apperr.CodeNotFound, "auth.oauth.client_not_found", "client not found or already disabled")
+	ErrOAuthConsentNotFound = apperr.New(apperr.CodeNotFound, "auth.oauth.consent_not_found", "no consent found for this app")
+)
+
+// OAuthError is an error response as defined by RFC 6749.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Status      int    `json:"-"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) *OAuthError {
+	status := http.StatusBadRequest
+	if code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	return &OAuthError{Code: code, Description: description, Status: status}
+}
+
+type AuthorizeReq struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	Scope               string `form:"scope" json:"scope" binding:"required"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	Prompt              string `form:"prompt" json:"prompt"`
+}
+
+// AuthorizeDecisionReq is sent by the consent screen once the user approved or denied the request.
+type AuthorizeDecisionReq struct {
+	AuthorizeReq
+	Approve bool `json:"approve"`
+}
+
+type AuthorizeRes struct {
+	Client          OAuthClientRes `json:"client"`
+	Scopes          []string       `json:"scopes"`
+	ConsentRequired bool           `json:"consent_required"`
+	// RedirectTo is set once the request is decided, the client app should navigate to it
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+type TokenReq struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type TokenRes struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+type RegisterOAuthClientReq struct {
+	Name         string   `json:"name" binding:"required,min=3,max=64"`
+	LogoURL      string   `json:"logo_url" binding:"omitempty,url"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	Scopes       []string `json:"scopes" binding:"required,min=1"`
+	Confidential bool     `json:"confidential"`
+}
+
+type OAuthClientRes struct {
+	ClientID       string     `json:"client_id"`
+	Name           string     `json:"name"`
+	LogoURL        string     `json:"logo_url,omitempty"`
+	RedirectURIs   []string   `json:"redirect_uris,omitempty"`
+	AllowedScopes  []string   `json:"allowed_scopes,omitempty"`
+	IsConfidential bool       `json:"is_confidential"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	// ClientSecret is only returned when a confidential client is registered
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type OAuthConsentRes struct {
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	LogoURL    string    `json:"logo_url,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	GrantedAt  time.Time `json:"granted_at"`
+}
+
+// OAuthService implements the OpenID Connect provider on top of TokenService and the sessions table.
+type OAuthService struct {
+	store        sqlc.Store
+	tokenService *TokenService
+	tokenMaker   token.Maker
+	cache        cache.Cache
+	signer       *oidc.Signer
+	config       utils.Config
+}
+
+// NewOAuthService loads the ID token signing key from config.OIDCSigningKeyFile. Without one an ephemeral key
+// is generated, which is refused in production since ID tokens would stop verifying after a restart.
+func NewOAuthService(store sqlc.Store, tokenService *TokenService, tokenMaker token.Maker, cache cache.Cache, config utils.Config) (*OAuthService, error) {
+	var signer *oidc.Signer
+	var err error
+	if config.OIDCSigningKeyFile != "" {
+		signer, err = oidc.NewSigner(config.OIDCSigningKeyFile)
+	} else if config.Production() {
+		err = errors.New("OIDC_SIGNING_KEY_FILE is required in production")
+	} else {
+		log.Warn().Msg("OIDC_SIGNING_KEY_FILE not set, using an ephemeral ID token signing key")
+		signer, err = oidc.NewEphemeralSigner()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthService{
+		store:        store,
+		tokenService: tokenService,
+		tokenMaker:   tokenMaker,
+		cache:        cache,
+		signer:       signer,
+		config:       config,
+	}, nil
+}
+
+func (s *OAuthService) Discovery(basePath string) oidc.Discovery {
+	return oidc.NewDiscovery(s.config.OIDCIssuer, basePath, constants.OAuthScopes)
+}
+
+func (s *OAuthService) JWKS() oidc.JSONWebKeySet {
+	return s.signer.JWKS()
+}
+
+// Authorize validates an authorization request for the consent screen. If the user already consented to the
+// requested scopes the code is issued straight away and RedirectTo is set.
+func (s *OAuthService) Authorize(ctx context.Context, user *token.Payload, req AuthorizeReq) (AuthorizeRes, error) {
+	client, scopes, err := s.validateAuthorizeReq(ctx, user, req)
+	if err != nil {
+		return s.authorizeError(client, req, err)
+	}
+
+	res := AuthorizeRes{Client: newOAuthClientRes(client, false), Scopes: scopes, ConsentRequired: true}
+
+	consent, err := s.store.GetOAuthConsent(ctx, sqlc.GetOAuthConsentParams{UserID: user.Subject, ClientID: client.ID})
+	if err != nil && err != pgx.ErrNoRows {
+		return AuthorizeRes{}, apperr.Internal(err)
+	}
+
+	if err == nil && oidc.ContainsAll(consent.Scopes, scopes) && req.Prompt != "consent" {
+		res.ConsentRequired = false
+		res.RedirectTo, err = s.issueCode(ctx, user, client, scopes, req)
+		if err != nil {
+			return AuthorizeRes{}, err
+		}
+	}
+
+	return res, nil
+}
+
+// Decide records the user's answer on the consent screen and returns where to send them back to.
+func (s *OAuthService) Decide(ctx context.Context, user *token.Payload, req AuthorizeDecisionReq, clientIP, agent string) (AuthorizeRes, error) {
+	client, scopes, err := s.validateAuthorizeReq(ctx, user, req.AuthorizeReq)
+	if err != nil {
+		return s.authorizeError(client, req.AuthorizeReq, err)
+	}
+
+	res := AuthorizeRes{Client: newOAuthClientRes(client, false), Scopes: scopes}
+
+	if !req.Approve {
+		res.RedirectTo = errorRedirect(req.RedirectURI, req.State, newOAuthError("access_denied", "the user denied the request"))
+		return res, nil
+	}
+
+	// Keep scopes granted earlier so approving a smaller request doesn't narrow the consent
+	granted := scopes
+	if existing, err := s.store.GetOAuthConsent(ctx, sqlc.GetOAuthConsentParams{UserID: user.Subject, ClientID: client.ID}); err == nil {
+		for _, scope := range existing.Scopes {
+			if !oidc.HasScope(granted, scope) {
+				granted = append(granted, scope)
+			}
+		}
+	}
+
+	_, err = s.store.UpsertOAuthConsent(ctx, sqlc.UpsertOAuthConsentParams{UserID: user.Subject, ClientID: client.ID, Scopes: granted})
+	if err != nil {
+		return AuthorizeRes{}, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	RecordAudit(ctx, s.store, AuditEntry{
+		UserID:    user.Subject,
+		ActorID:   user.Subject,
+		Action:    constants.AuditOAuthConsentGranted,
+		Metadata:  map[string]interface{}{"client_id": client.ID, "scopes": scopes},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	res.RedirectTo, err = s.issueCode(ctx, user, client, scopes, req.AuthorizeReq)
+	if err != nil {
+		return AuthorizeRes{}, err
+	}
+	return res, nil
+}
+
+// Exchange implements the token endpoint. basicID and basicSecret come from HTTP basic auth, if used.
+func (s *OAuthService) Exchange(ctx context.Context, req TokenReq, basicID, basicSecret, clientIP, agent string) (TokenRes, error) {
+	client, err := s.authenticateClient(ctx, req, basicID, basicSecret)
+	if err != nil {
+		return TokenRes{}, err
+	}
+
+	switch req.GrantType {
+	case oidc.GrantAuthorizationCode:
+		return s.exchangeCode(ctx, client, req, clientIP, agent)
+	case oidc.GrantRefreshToken:
+		return s.refresh(ctx, client, req)
+	default:
+		return TokenRes{}, newOAuthError("unsupported_grant_type", "")
+	}
+}
+
+// UserInfo returns the claims of the token's subject allowed by its scopes.
+func (s *OAuthService) UserInfo(ctx context.Context, payload *token.Payload) (map[string]interface{}, error) {
+	user, err := s.store.GetUserByID(ctx, payload.Subject)
+	if err != nil {
+		return nil, newOAuthError("invalid_token", "user not found")
+	}
+
+	claims := map[string]interface{}{"sub": user.ID.String()}
+	if oidc.HasScope(payload.Scopes, constants.ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.IsEmailVerified.Bool
+	}
+	if oidc.HasScope(payload.Scopes, constants.ScopeProfile) {
+		claims["preferred_username"] = user.Username.String
+	}
+	if oidc.HasScope(payload.Scopes, constants.ScopeRoles) {
+		claims["role"] = payload.Role
+	}
+	return claims, nil
+}
+
+func (s *OAuthService) RegisterClient(ctx context.Context, admin *token.Payload, req RegisterOAuthClientReq, clientIP, agent string) (OAuthClientRes, error) {
+	for _, scope := range req.Scopes {
+		if !oidc.HasScope(constants.OAuthScopes, scope) {
+			return OAuthClientRes{}, unknownScope(scope)
+		}
+	}
+
+	clientID, err := utils.GenerateUniqueToken(16)
+	if err != nil {
+		return OAuthClientRes{}, apperr.Internal(err)
+	}
+	clientID = strings.TrimRight(clientID, "=")
+
+	var secret string
+	var secretHash pgtype.Text
+	if req.Confidential {
+		if secret, err = utils.GenerateUniqueToken(32); err != nil {
+			return OAuthClientRes{}, apperr.Internal(err)
+		}
+		hash, err := utils.HashPassword(secret)
+		if err != nil {
+			return OAuthClientRes{}, apperr.Internal(err)
+		}
+		secretHash = pgtype.Text{String: hash, Valid: true}
+	}
+
+	client, err := s.store.CreateOAuthClient(ctx, sqlc.CreateOAuthClientParams{
+		ID:             clientID,
+		SecretHash:     secretHash,
+		Name:           req.Name,
+		LogoUrl:        pgtype.Text{String: req.LogoURL, Valid: req.LogoURL != ""},
+		RedirectUris:   req.RedirectURIs,
+		AllowedScopes:  req.Scopes,
+		IsConfidential: req.Confidential,
+		CreatedBy:      admin.Subject,
+	})
+	if err != nil {
+		return OAuthClientRes{}, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	RecordAudit(ctx, s.store, AuditEntry{
+		ActorID:   admin.Subject,
+		Action:    constants.AuditOAuthClientCreated,
+		Metadata:  map[string]interface{}{"client_id": client.ID, "name": client.Name},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+
+	res := newOAuthClientRes(client, true)
+	res.ClientSecret = secret
+	return res, nil
+}
+
+func (s *OAuthService) ListClients(ctx context.Context) ([]OAuthClientRes, error) {
+	clients, err := s.store.ListOAuthClients(ctx)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]OAuthClientRes, 0, len(clients))
+	for _, client := range clients {
+		res = append(res, newOAuthClientRes(client, true))
+	}
+	return res, nil
+}
+
+// DisableClient stops the client from authorizing users or using its refresh tokens.
+func (s *OAuthService) DisableClient(ctx context.Context, clientID string) error {
+	rows, err := s.store.DisableOAuthClient(ctx, clientID)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if rows == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+func (s *OAuthService) ListConsents(ctx context.Context, uid uuid.UUID) ([]OAuthConsentRes, error) {
+	consents, err := s.store.ListUserOAuthConsents(ctx, uid)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	res := make([]OAuthConsentRes, 0, len(consents))
+	for _, consent := range consents {
+		res = append(res, OAuthConsentRes{
+			ClientID:   consent.ClientID,
+			ClientName: consent.ClientName,
+			LogoURL:    consent.ClientLogoUrl.String,
+			Scopes:     consent.Scopes,
+			GrantedAt:  consent.GrantedAt,
+		})
+	}
+	return res, nil
+}
+
+// RevokeConsent removes the client's access to the user's account and ends the client's sessions.
+func (s *OAuthService) RevokeConsent(ctx context.Context, uid uuid.UUID, clientID, clientIP, agent string) error {
+	rows, err := s.store.RevokeOAuthConsent(ctx, sqlc.RevokeOAuthConsentParams{UserID: uid, ClientID: clientID})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if rows == 0 {
+		return ErrOAuthConsentNotFound
+	}
+
+	err = s.store.RevokeClientSessions(ctx, sqlc.RevokeClientSessionsParams{
+		UserID:   uid,
+		ClientID: pgtype.Text{String: clientID, Valid: true},
+	})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	RecordAudit(ctx, s.store, AuditEntry{
+		UserID:    uid,
+		ActorID:   uid,
+		Action:    constants.AuditOAuthConsentRevoked,
+		Metadata:  map[string]interface{}{"client_id": clientID},
+		ClientIP:  clientIP,
+		UserAgent: agent,
+	})
+	return nil
+}
+
+// validateAuthorizeReq returns the client even on error when the redirect uri was verified, so the error can be
+// sent back to the client. Otherwise the client is empty and the error must be shown to the user.
+func (s *OAuthService) validateAuthorizeReq(ctx context.Context, user *token.Payload, req AuthorizeReq) (sqlc.OauthClient, []string, error) {
+	client, err := s.store.GetOAuthClient(ctx, req.ClientID)
+	if err != nil || client.DisabledAt.Valid {
+		return sqlc.OauthClient{}, nil, newOAuthError("invalid_client", "unknown client")
+	}
+
+	if !oidc.ValidRedirectURI(client.RedirectUris, req.RedirectURI) {
+		return sqlc.OauthClient{}, nil, newOAuthError("invalid_request", "redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != oidc.ResponseTypeCode {
+		return client, nil, newOAuthError("unsupported_response_type", "")
+	}
+
+	if err := oidc.ValidateChallenge(req.CodeChallenge, req.CodeChallengeMethod); err != nil {
+		return client, nil, newOAuthError("invalid_request", err.Error())
+	}
+
+	if user.IsImpersonated() {
+		return client, nil, newOAuthError("access_denied", "apps can't be authorized while impersonating")
+	}
+
+	scopes := oidc.ParseScopes(req.Scope)
+	roleScopes := constants.RoleScopes[user.Role]
+	for _, scope := range scopes {
+		if !oidc.HasScope(client.AllowedScopes, scope) || !oidc.HasScope(roleScopes, scope) {
+			return client, nil, newOAuthError("invalid_scope", "scope "+scope+" is not allowed")
+		}
+	}
+	if len(scopes) == 0 {
+		return client, nil, newOAuthError("invalid_scope", "no scope requested")
+	}
+
+	return client, scopes, nil
+}
+
+func (s *OAuthService) authorizeError(client sqlc.OauthClient, req AuthorizeReq, err error) (AuthorizeRes, error) {
+	var oauthErr *OAuthError
+	if client.ID == "" || !errors.As(err, &oauthErr) {
+		return AuthorizeRes{}, err
+	}
+
+	return AuthorizeRes{
+		Client:     newOAuthClientRes(client, false),
+		RedirectTo: errorRedirect(req.RedirectURI, req.State, oauthErr),
+	}, nil
+}
+
+func (s *OAuthService) issueCode(ctx context.Context, user *token.Payload, client sqlc.OauthClient, scopes []string, req AuthorizeReq) (string, error) {
+	code, err := utils.GenerateUniqueToken(32)
+	if err != nil {
+		return "", apperr.Internal(err)
+	}
+
+	err = s.store.CreateOAuthAuthorizationCode(ctx, sqlc.CreateOAuthAuthorizationCodeParams{
+		CodeHash:            hashOAuthCode(code),
+		ClientID:            client.ID,
+		UserID:              user.Subject,
+		RedirectUri:         req.RedirectURI,
+		Scopes:              scopes,
+		Nonce:               pgtype.Text{String: req.Nonce, Valid: req.Nonce != ""},
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            user.IssuedAt,
+		ExpiresAt:           time.Now().Add(s.config.OAuthCodeDuration),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create authorization code: %w", err)
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+func (s *OAuthService) authenticateClient(ctx context.Context, req TokenReq, basicID, basicSecret string) (sqlc.OauthClient, error) {
+	clientID, secret := req.ClientID, req.ClientSecret
+	if basicID != "" {
+		clientID, secret = basicID, basicSecret
+	}
+
+	client, err := s.store.GetOAuthClient(ctx, clientID)
+	if err != nil || client.DisabledAt.Valid {
+		return sqlc.OauthClient{}, newOAuthError("invalid_client", "")
+	}
+
+	if client.IsConfidential {
+		if secret == "" || utils.CheckPassword(secret, client.SecretHash.String) != nil {
+			return sqlc.OauthClient{}, newOAuthError("invalid_client", "")
+		}
+	}
+
+	return client, nil
+}
+
+func (s *OAuthService) exchangeCode(ctx context.Context, client sqlc.OauthClient, req TokenReq, clientIP, agent string) (TokenRes, error) {
+	hash := hashOAuthCode(req.Code)
+	code, err := s.store.ConsumeOAuthAuthorizationCode(ctx, sqlc.ConsumeOAuthAuthorizationCodeParams{
+		CodeHash:    hash,
+		ClientID:    client.ID,
+		RedirectUri: req.RedirectURI,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.revokeReplayedCode(ctx, hash, client.ID)
+			return TokenRes{}, newOAuthError("invalid_grant", "invalid authorization code")
+		}
+		return TokenRes{}, apperr.Internal(err)
+	}
+
+	if err := oidc.VerifyPKCE(req.CodeVerifier, code.CodeChallenge, code.CodeChallengeMethod); err != nil {
+		return TokenRes{}, newOAuthError("invalid_grant", err.Error())
+	}
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return TokenRes{}, apperr.Internal(err)
+	}
+
+	user, role, err := s.activeUser(ctx, code.UserID, client.ID)
+	if err != nil {
+		return TokenRes{}, err
+	}
+
+	authToken, err := s.tokenService.CreateTokenPair(ctx, s.clientPayload(user, role, client.ID, sessionID, code.Scopes, clientIP, agent))
+	if err != nil {
+		return TokenRes{}, apperr.Internal(err)
+	}
+
+	_, err = s.store.CreateClientSession(ctx, sqlc.CreateClientSessionParams{
+		ID:              sessionID,
+		UserID:          user.ID,
+		RefreshToken:    authToken.RefreshToken,
+		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
+		UserAgent:       agent,
+		IpAddress:       utils.GetIpAddrOrUnspecified(clientIP),
+		ClientID:        pgtype.Text{String: client.ID, Valid: true},
+		Scopes:          code.Scopes,
+	})
+	if err != nil {
+		return TokenRes{}, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	// Remember the session so it can be revoked if the code is ever replayed
+	if err := s.store.SetOAuthAuthorizationCodeSession(ctx, sqlc.SetOAuthAuthorizationCodeSessionParams{
+		CodeHash:  hash,
+		SessionID: uuid.NullUUID{UUID: sessionID, Valid: true},
+	}); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("failed to link authorization code to session")
+	}
+
+	return s.tokenResponse(user, role, client.ID, authToken, code.Scopes, code.Nonce.String, code.AuthTime)
+}
+
+func (s *OAuthService) refresh(ctx context.Context, client sqlc.OauthClient, req TokenReq) (TokenRes, error) {
+	payload, err := s.tokenMaker.VerifyToken(ctx, s.cache, req.RefreshToken, token.RefreshToken)
+	if err != nil {
+		return TokenRes{}, newOAuthError("invalid_grant", err.Error())
+	}
+
+	session, err := s.store.GetSessionAndUserByRefreshToken(ctx, req.RefreshToken)
+	if err != nil || session.ClientID.String != client.ID || session.ID != payload.SessionID {
+		return TokenRes{}, newOAuthError("invalid_grant", "invalid refresh token")
+	}
+
+	if session.BlockedAt.Valid || session.InvalidatedAt.Valid || !oidc.HasScope(session.Scopes, constants.ScopeOfflineAccess) {
+		return TokenRes{}, newOAuthError("invalid_grant", "invalid refresh token")
+	}
+
+	user, role, err := s.activeUser(ctx, session.UserID, client.ID)
+	if err != nil {
+		return TokenRes{}, err
+	}
+
+	authToken, err := s.tokenService.CreateTokenPair(ctx, s.clientPayload(user, role, client.ID, session.ID, session.Scopes, payload.IP, payload.UserAgent))
+	if err != nil {
+		return TokenRes{}, apperr.Internal(err)
+	}
+
+	err = s.store.RotateSessionTokens(ctx, sqlc.RotateSessionTokensParams{
+		ID:              session.ID,
+		RefreshToken:    authToken.RefreshToken,
+		RefreshTokenExp: authToken.RefreshTokenExpiresAt,
+	})
+	if err != nil {
+		return TokenRes{}, apperr.Internal(err)
+	}
+
+	return s.tokenResponse(user, role, client.ID, authToken, session.Scopes, "", session.CreatedAt.Time)
+}
+
+// activeUser loads the user a token is being issued for and makes sure they still allow the client.
+func (s *OAuthService) activeUser(ctx context.Context, uid uuid.UUID, clientID string) (sqlc.Authentication, int8, error) {
+	user, err := s.store.GetUserByID(ctx, uid)
+	if err != nil || user.IsDeleted.Bool || user.IsSuspended.Bool {
+		return sqlc.Authentication{}, 0, newOAuthError("invalid_grant", "user is not active")
+	}
+
+	if _, err := s.store.GetOAuthConsent(ctx, sqlc.GetOAuthConsentParams{UserID: uid, ClientID: clientID}); err != nil {
+		return sqlc.Authentication{}, 0, newOAuthError("invalid_grant", "consent has been revoked")
+	}
+
+	roles, err := s.store.GetUserRolesByUserID(ctx, uid)
+	if err != nil || len(roles) == 0 {
+		return sqlc.Authentication{}, 0, apperr.Internal(err)
+	}
+
+	return user, int8(roles[0].RoleID), nil
+}
+
+func (s *OAuthService) clientPayload(user sqlc.Authentication, role int8, clientID string, sessionID uuid.UUID, scopes []string, clientIP, agent string) token.PayloadData {
+	return token.PayloadData{
+		Role:          role,
+		Subject:       user.ID,
+		Username:      user.Username.String,
+		Email:         user.Email,
+		Phone:         user.Phone.String,
+		EmailVerified: user.IsEmailVerified.Bool,
+		Issuer:        s.config.AppName,
+		Audience:      clientID,
+		IP:            clientIP,
+		UserAgent:     agent,
+		MfaPassed:     true,
+		SessionID:     sessionID,
+		AuthMethod:    token.AuthOAuth,
+		Scopes:        scopes,
+		TokenType:     token.AccessToken,
+	}
+}
+
+func (s *OAuthService) tokenResponse(user sqlc.Authentication, role int8, clientID string, authToken AuthToken, scopes []string, nonce string, authTime time.Time) (TokenRes, error) {
+	res := TokenRes{
+		AccessToken: authToken.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(authToken.AccessTokenExpiresAt).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if oidc.HasScope(scopes, constants.ScopeOfflineAccess) {
+		res.RefreshToken = authToken.RefreshToken
+	}
+
+	if oidc.HasScope(scopes, constants.ScopeOpenID) {
+		now := time.Now()
+		claims := oidc.IDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    strings.TrimSuffix(s.config.OIDCIssuer, "/"),
+				Subject:   user.ID.String(),
+				Audience:  jwt.ClaimStrings{clientID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(s.config.IDTokenDuration)),
+			},
+			Nonce:    nonce,
+			AuthTime: jwt.NewNumericDate(authTime),
+		}
+		if oidc.HasScope(scopes, constants.ScopeEmail) {
+			verified := user.IsEmailVerified.Bool
+			claims.Email = user.Email
+			claims.EmailVerified = &verified
+		}
+		if oidc.HasScope(scopes, constants.ScopeProfile) {
+			claims.PreferredUsername = user.Username.String
+		}
+		if oidc.HasScope(scopes, constants.ScopeRoles) {
+			claims.Role = role
+		}
+
+		idToken, err := s.signer.Sign(claims)
+		if err != nil {
+			return TokenRes{}, apperr.Internal(err)
+		}
+		res.IDToken = idToken
+	}
+
+	return res, nil
+}
+
+// revokeReplayedCode ends the session created with a code that is presented a second time (RFC 6749 section 4.1.2).
+func (s *OAuthService) revokeReplayedCode(ctx context.Context, hash, clientID string) {
+	// Another client holding a leaked code can't end the session of the client it was issued to
+	code, err := s.store.GetOAuthAuthorizationCode(ctx, hash)
+	if err != nil || code.ClientID != clientID || !code.UsedAt.Valid || !code.SessionID.Valid {
+		return
+	}
+
+	logging.FromContext(ctx).Warn().Str("client_id", code.ClientID).Msg("authorization code replayed, revoking session")
+	if err := s.store.RevokeSessionById(ctx, code.SessionID.UUID); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("failed to revoke session of replayed authorization code")
+	}
+}
+
+func hashOAuthCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func errorRedirect(redirectURI, state string, err *OAuthError) string {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+func appendQuery(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func newOAuthClientRes(client sqlc.OauthClient, admin bool) OAuthClientRes {
+	res := OAuthClientRes{
+		ClientID:       client.ID,
+		Name:           client.Name,
+		LogoURL:        client.LogoUrl.String,
+		IsConfidential: client.IsConfidential,
+		CreatedAt:      client.CreatedAt,
+	}
+	// Only admins need to see how the client is configured
+	if admin {
+		res.RedirectURIs = client.RedirectUris
+		res.AllowedScopes = client.AllowedScopes
+		if client.DisabledAt.Valid {
+			res.DisabledAt = &client.DisabledAt.Time
+		}
+	}
+	return res
+}
@@ -2,18 +2,18 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/steve-mir/bukka_backend/constants"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/events"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
 	"github.com/steve-mir/bukka_backend/token"
 	"github.com/steve-mir/bukka_backend/utils"
-	"github.com/steve-mir/bukka_backend/worker"
-	"golang.org/x/sync/errgroup"
 )
 
 type RegisterReq struct {
@@ -23,6 +23,13 @@ type RegisterReq struct {
 	Phone    string `json:"phone" binding:"phoneValidator"`
 	Password string `json:"password" binding:"required,passwordValidator"`
 	FcmToken string `json:"fcm_token"`
+
+	// Versions of the policy documents shown to the user, they must match the current versions
+	TermsVersion   string `json:"terms_version"`
+	PrivacyVersion string `json:"privacy_version"`
+
+	// Access token of the guest session to merge into the new account, if any
+	GuestToken string `json:"guest_token"`
 }
 
 type UserResult struct {
@@ -39,13 +46,11 @@ type AccessTokenResult struct {
 func CheckUserExists(ctx context.Context, qtx *sqlc.Queries, email, username string) error {
 	// Check db if email exists
 	if err := CheckEmailExistsError(ctx, qtx, email); err != nil {
-		fmt.Println("Error checking email", email)
 		return err
 	}
 
 	// Check db if username exists
 	if err := CheckIfUsernameExists(ctx, qtx, username); err != nil {
-		fmt.Println("Error checking username", username)
 		return err
 	}
 	return nil
@@ -54,13 +59,13 @@ func CheckUserExists(ctx context.Context, qtx *sqlc.Queries, email, username str
 func PrepareUserData(pwd string) (string, uuid.UUID, error) {
 	hashedPwd, err := utils.HashPassword(pwd)
 	if err != nil {
-		return "", uuid.UUID{}, errors.New("error processing data")
+		return "", uuid.UUID{}, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Generate UUID in advance
 	uid, err := uuid.NewRandom()
 	if err != nil {
-		return "", uuid.UUID{}, errors.New("an unexpected error occurred")
+		return "", uuid.UUID{}, fmt.Errorf("failed to generate user id: %w", err)
 	}
 
 	return hashedPwd, uid, nil
@@ -70,13 +75,13 @@ func CreateUserConcurrent(ctx context.Context, qtx *sqlc.Queries /*tx *sql.Tx,*/
 	params := sqlc.CreateUserParams{
 		ID:           uid,
 		Email:        email,
-		Username:     sql.NullString{String: username, Valid: true},
+		Username:     pgtype.Text{String: username, Valid: true},
 		PasswordHash: pwd,
-		IsEmailVerified: sql.NullBool{
+		IsEmailVerified: pgtype.Bool{
 			Bool:  isEmailVerified,
 			Valid: true,
 		},
-		IsOauthUser: sql.NullBool{
+		IsOauthUser: pgtype.Bool{
 			Bool:  isOauthUser,
 			Valid: true,
 		},
@@ -84,7 +89,7 @@ func CreateUserConcurrent(ctx context.Context, qtx *sqlc.Queries /*tx *sql.Tx,*/
 
 	userData, err := qtx.CreateUser(ctx, params)
 	if err != nil {
-		return sqlc.Authentication{}, errors.New("error creating user: " + err.Error())
+		return sqlc.Authentication{}, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	return sqlc.Authentication{
@@ -96,93 +101,72 @@ func CreateUserConcurrent(ctx context.Context, qtx *sqlc.Queries /*tx *sql.Tx,*/
 	}, nil
 }
 
-func RunConcurrentUserCreationTasks(ctx context.Context, tokenMaker token.Maker, qtx *sqlc.Queries, tx *sql.Tx, config utils.Config, td worker.TaskDistributor,
-	req RegisterReq, uid uuid.UUID, clientIP string, agent string, isEmailVerified bool) (string, time.Time, error) {
-
-	var accessToken string
-	var accessPayload *token.Payload
-	var err error
-
-	var eg errgroup.Group
+// CreateRegistrationAccessToken issues the access token returned to a user who just signed up.
+func CreateRegistrationAccessToken(tokenMaker token.Maker, config utils.Config, req RegisterReq, uid uuid.UUID, clientIP string, agent string) (string, time.Time, error) {
+	accessToken, accessPayload, err := tokenMaker.CreateToken(
+		token.PayloadData{
+			Role:          constants.RegularUsers,
+			Subject:       uid,
+			Username:      req.Username,
+			Email:         req.Email,
+			EmailVerified: false,
+			Issuer:        config.AppName,
+			Audience:      "website users",
+			IP:            clientIP,
+			UserAgent:     agent,
+			MfaPassed:     true,
+			TokenType:     token.TokenType(token.AccessToken),
+		}, config.AccessTokenDuration, token.TokenType(token.AccessToken),
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
 
-	// Create access token
-	eg.Go(func() error {
-		accessToken, accessPayload, err = tokenMaker.CreateToken(
-			token.PayloadData{
-				Role:          constants.RegularUsers,
-				Subject:       uid,
-				Username:      req.Username,
-				Email:         req.Email,
-				EmailVerified: false,
-				Issuer:        config.AppName,
-				Audience:      "website users",
-				IP:            clientIP,
-				UserAgent:     agent,
-				MfaPassed:     true,
-				TokenType:     token.TokenType(token.AccessToken),
-			}, config.AccessTokenDuration, token.TokenType(token.AccessToken),
-		)
-		if err != nil {
-			return err
-		}
+	return accessToken, accessPayload.Expires, nil
+}
 
-		return nil
+// RunUserCreationTasks creates the profile and role of a new user inside the registration transaction, and writes
+// the user.registered event to the outbox. Unless the email is already verified it also stores a verification code
+// and queues the email with it, so nothing is sent if the transaction rolls back.
+func RunUserCreationTasks(ctx context.Context, qtx *sqlc.Queries, req RegisterReq, uid uuid.UUID, isEmailVerified bool) error {
+	err := qtx.CreateUserProfile(ctx, sqlc.CreateUserProfileParams{
+		UserID:    uid,
+		FirstName: pgtype.Text{String: req.FullName, Valid: true},
+		LastName:  pgtype.Text{String: req.FullName, Valid: true},
 	})
+	if err != nil {
+		return fmt.Errorf("failed to create user profile: %w", err)
+	}
 
-	// Create user profile
-	eg.Go(func() error {
-		err = qtx.CreateUserProfile(ctx, sqlc.CreateUserProfileParams{
-			UserID:    uid,
-			FirstName: sql.NullString{String: req.FullName, Valid: true},
-			LastName:  sql.NullString{String: req.FullName, Valid: true},
-		})
-		if err != nil {
-			return err
-		}
-
-		return nil
+	_, err = qtx.CreateUserRole(ctx, sqlc.CreateUserRoleParams{
+		UserID: uid,
+		RoleID: constants.RegularUsers,
 	})
+	if err != nil {
+		return fmt.Errorf("failed to create user role: %w", err)
+	}
 
-	// Create user role
-	eg.Go(func() error {
-		_, err = qtx.CreateUserRole(ctx, sqlc.CreateUserRoleParams{
-			UserID: uid,
-			RoleID: constants.RegularUsers,
-		})
-		if err != nil {
-			return err
-		}
-
-		return nil
+	err = outbox.AddEvent(ctx, qtx, outbox.UserAggregate(uid), events.StreamUsers, events.UserRegistered, events.UserRegisteredPayload{
+		UserID:        uid,
+		Email:         req.Email,
+		Username:      req.Username,
+		EmailVerified: isEmailVerified,
 	})
-
-	// Send verification email concurrently (does not need to be within transaction)
-	if !isEmailVerified {
-
-		eg.Go(func() error {
-			err = SendVerificationEmail(qtx, ctx, td, uid, req.Email)
-			if err != nil {
-				return err
-			}
-
-			return nil
-		})
+	if err != nil {
+		return err
 	}
 
-	// Wait for both goroutines to complete
-	if err := eg.Wait(); err != nil {
-		tx.Rollback()
-		return "", time.Time{}, errors.New("error registering " + err.Error())
+	if isEmailVerified {
+		return nil
 	}
-
-	return accessToken, accessPayload.Expires, nil
+	return SendVerificationEmail(ctx, qtx, uid, req.Email)
 }
 
 // ?----------------
 func CheckEmailExistsError(ctx context.Context, qtx *sqlc.Queries, email string) error {
 	// Check duplicate emails
 	user, err := qtx.GetUserByIdentifier(ctx, email)
-	if err != nil && err != sql.ErrNoRows {
+	if err != nil && err != pgx.ErrNoRows {
 		// An error occurred that isn't simply indicating no rows were found
 		return err
 	}
@@ -193,17 +177,17 @@ func CheckEmailExistsError(ctx context.Context, qtx *sqlc.Queries, email string)
 			// Check if the account is within the recovery period
 			if time.Since(user.DeletedAt.Time) <= MaxAccountRecoveryDuration {
 				// Account is within the recovery period and can be recovered
-				return errors.New("account is deleted but can be recovered, please follow the account recovery process")
+				return ErrAccountRecoverable
 			} else {
 				// Account is beyond the recovery period, append timestamp to the email to make it unique
 				err = appendTimestampToEmail(ctx, qtx, user.Email, user.DeletedAt.Time)
 				if err != nil {
-					return fmt.Errorf("failed to update email for user with ID %s: %v", user.ID, err)
+					return fmt.Errorf("failed to update email for user with ID %s: %w", user.ID, err)
 				}
 			}
 		} else {
 			// Account exists and is not marked as deleted
-			return errors.New("email already exists")
+			return ErrEmailTaken
 		}
 	}
 	return nil
@@ -218,7 +202,7 @@ func appendTimestampToEmail(ctx context.Context, qtx *sqlc.Queries, email string
 	newEmail := addDeleteTimeToEmail(email, deletedAt)
 
 	_, err := qtx.UpdateUser(ctx, sqlc.UpdateUserParams{
-		Email: sql.NullString{String: newEmail, Valid: true},
+		Email: pgtype.Text{String: newEmail, Valid: true},
 	})
 	if err != nil {
 		return err
@@ -259,8 +243,8 @@ func RunSyncUserCreationTasks(ctx context.Context, qtx *sqlc.Queries, tx *sql.Tx
 	go func() {
 		profileErr := qtx.CreateUserProfile(ctx, sqlc.CreateUserProfileParams{
 			UserID:    uid,
-			FirstName: sql.NullString{String: req.FullName, Valid: true},
-			LastName:  sql.NullString{String: req.FullName, Valid: true},
+			FirstName: pgtype.Text{String: req.FullName, Valid: true},
+			LastName:  pgtype.Text{String: req.FullName, Valid: true},
 		})
 		profileCh <- result{err: profileErr}
 		close(profileCh)
@@ -2,12 +2,11 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/cache"
 	"github.com/steve-mir/bukka_backend/token"
@@ -22,7 +21,7 @@ func RotateUserToken(req RotateTokenReq, ts *TokenService, cache cache.Cache, st
 
 	payload, err := tokenMaker.VerifyToken(ctx, cache, req.RefreshToken, token.RefreshToken)
 	if err != nil {
-		return AuthToken{}, fmt.Errorf("token verification failed: %v", err)
+		return AuthToken{}, ErrInvalidRefreshToken.WithCause(err)
 	}
 
 	err = checkUserStatus(ctx, store, payload.Subject)
@@ -32,26 +31,31 @@ func RotateUserToken(req RotateTokenReq, ts *TokenService, cache cache.Cache, st
 
 	session, err := store.GetSessionAndUserByRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			if blockErr := blockUser(ctx, store, payload.Subject); blockErr != nil {
 				return AuthToken{}, blockErr
 			}
-			return AuthToken{}, errors.New("suspicious activity detected")
+			return AuthToken{}, ErrSuspiciousActivity
 		}
-		return AuthToken{}, fmt.Errorf("failed to get session: %v", err)
+		return AuthToken{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// OAuth clients refresh their tokens at the token endpoint, which keeps their scopes
+	if session.ClientID.Valid {
+		return AuthToken{}, ErrInvalidRefreshToken
 	}
 
 	if !session.BlockedAt.Time.IsZero() {
-		return AuthToken{}, errors.New("session blocked")
+		return AuthToken{}, ErrSessionBlocked
 	}
 	if !session.InvalidatedAt.Time.IsZero() {
-		return AuthToken{}, errors.New("user not logged in")
+		return AuthToken{}, ErrSessionEnded
 	}
 
 	authToken, err := ts.RotateTokens(ctx, req.RefreshToken, store, cache)
 
 	if err != nil {
-		return AuthToken{}, fmt.Errorf("could not rotate token: %v", err)
+		return AuthToken{}, fmt.Errorf("could not rotate token: %w", err)
 	}
 
 	return AuthToken{
@@ -65,18 +69,18 @@ func RotateUserToken(req RotateTokenReq, ts *TokenService, cache cache.Cache, st
 func checkUserStatus(ctx context.Context, store sqlc.Store, uid uuid.UUID) error {
 	user, err := store.GetUserByID(ctx, uid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("user not found")
+		if err == pgx.ErrNoRows {
+			return ErrUserNotFound
 		}
-		return fmt.Errorf("failed to get user: %v", err)
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if user.IsDeleted.Bool {
-		return errors.New("account not found")
+		return ErrUserNotFound
 	}
 
 	if user.IsSuspended.Bool {
-		return errors.New("account suspended")
+		return ErrAccountSuspended
 	}
 	return nil
 }
@@ -109,7 +113,7 @@ func blockUser(ctx context.Context, store sqlc.Store, userId uuid.UUID) error {
 	// Check for errors.
 	for err := range errCh {
 		if err != nil {
-			return fmt.Errorf("block operation error: %v", err)
+			return fmt.Errorf("block operation error: %w", err)
 		}
 	}
 
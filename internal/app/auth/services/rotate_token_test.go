@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// rotateStore keeps a single user and session, the other queries panic.
+type rotateStore struct {
+	sqlc.Store
+	user    sqlc.Authentication
+	session sqlc.GetSessionAndUserByRefreshTokenRow
+}
+
+func (s *rotateStore) GetUserByID(ctx context.Context, id uuid.UUID) (sqlc.Authentication, error) {
+	return s.user, nil
+}
+
+func (s *rotateStore) GetSessionAndUserByRefreshToken(ctx context.Context, refreshToken string) (sqlc.GetSessionAndUserByRefreshTokenRow, error) {
+	return s.session, nil
+}
+
+// refreshMaker accepts any refresh token as one of payload, its other methods panic.
+type refreshMaker struct {
+	token.Maker
+	payload token.Payload
+}
+
+func (m *refreshMaker) VerifyToken(ctx context.Context, sessions cache.Cache, tok string, tokenType token.TokenType) (*token.Payload, error) {
+	return &m.payload, nil
+}
+
+func TestRotateUserTokenRejectsOAuthSession(t *testing.T) {
+	ctx := context.Background()
+	user := sqlc.Authentication{ID: uuid.New(), Email: "ada@example.com"}
+	sessionID := uuid.New()
+	maker := &refreshMaker{payload: token.Payload{PayloadData: token.PayloadData{Subject: user.ID, SessionID: sessionID, TokenType: token.RefreshToken}}}
+	refreshToken := "oauth-refresh-token"
+
+	store := &rotateStore{user: user, session: sqlc.GetSessionAndUserByRefreshTokenRow{
+		ID:           sessionID,
+		UserID:       user.ID,
+		RefreshToken: refreshToken,
+		ClientID:     pgtype.Text{String: "third-party", Valid: true},
+		Scopes:       []string{"profile"},
+	}}
+
+	// A nil TokenService would panic if the session was rotated
+	_, err := RotateUserToken(RotateTokenReq{RefreshToken: refreshToken}, nil, cache.NewMemory(), store, maker, ctx, utils.Config{}, "127.0.0.1", "test")
+	require.ErrorIs(t, err, ErrInvalidRefreshToken, "OAuth refresh tokens are only exchanged at the token endpoint")
+}
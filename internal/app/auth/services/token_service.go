@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"time"
 
+	"github.com/steve-mir/bukka_backend/constants"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/cache"
 	"github.com/steve-mir/bukka_backend/token"
@@ -17,21 +19,57 @@ type TokenService struct {
 	// other dependencies as needed
 }
 
-func NewTokenService(config utils.Config, cache *cache.Cache, tokenMaker token.Maker) *TokenService {
+func NewTokenService(config utils.Config, cache cache.Cache, tokenMaker token.Maker) *TokenService {
 	return &TokenService{
 		config:     config,
-		cache:      *cache,
+		cache:      cache,
 		tokenMaker: tokenMaker,
 	}
 }
 
 func (s *TokenService) CreateTokenPair(ctx context.Context, payloadData token.PayloadData) (AuthToken, error) {
+	return s.createTokenPair(ctx, payloadData, s.config.AccessTokenDuration, s.config.RefreshTokenDuration)
+}
+
+// CreateGuestTokenPair issues a token pair for a guest session using the guest token lifetimes.
+func (s *TokenService) CreateGuestTokenPair(ctx context.Context, payloadData token.PayloadData) (AuthToken, error) {
+	payloadData.Role = constants.Guests
+	return s.createTokenPair(ctx, payloadData, s.config.GuestAccessTokenDuration, s.config.GuestRefreshTokenDuration)
+}
+
+// CreateImpersonationToken issues a short lived access token for an impersonation session.
+// No refresh token is created so the session can't outlive config.ImpersonationDuration.
+func (s *TokenService) CreateImpersonationToken(ctx context.Context, payloadData token.PayloadData) (string, *token.Payload, error) {
+	payloadData.Audience = "website users"
+	payloadData.TokenType = token.AccessToken
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(payloadData, s.config.ImpersonationDuration, token.AccessToken)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = s.cache.SetKey(ctx, accessToken, payloadData.SessionID.String(), s.config.ImpersonationDuration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return accessToken, accessPayload, nil
+}
+
+func (s *TokenService) createTokenPair(ctx context.Context, payloadData token.PayloadData, accessDuration, refreshDuration time.Duration) (AuthToken, error) {
+	// Tokens issued to oauth clients are scoped to the client, everything else is for our own apps
+	audience := payloadData.Audience
+	if audience == "" {
+		audience = "website users"
+	}
+
+	// Each goroutine keeps its own error, eg.Wait returns the first
 	var eg errgroup.Group
-	var err error
 	var accessToken, refreshToken string
 	var accessPayload, refreshPayload *token.Payload
 
 	eg.Go(func() error {
+		var err error
 		accessToken, accessPayload, err = s.tokenMaker.CreateToken(token.PayloadData{
 			Role:          payloadData.Role,
 			Subject:       payloadData.Subject,
@@ -40,14 +78,16 @@ func (s *TokenService) CreateTokenPair(ctx context.Context, payloadData token.Pa
 			Phone:         payloadData.Phone,
 			EmailVerified: payloadData.EmailVerified,
 			Issuer:        payloadData.Issuer,
-			Audience:      "website users",
+			Audience:      audience,
 			IP:            payloadData.IP,
 			UserAgent:     payloadData.UserAgent,
 			MfaPassed:     payloadData.MfaPassed,
 			SessionID:     payloadData.SessionID,
+			AuthMethod:    payloadData.AuthMethod,
+			Scopes:        payloadData.Scopes,
 			TokenType:     token.TokenType(token.AccessToken),
 		},
-			s.config.AccessTokenDuration, token.TokenType(token.AccessToken))
+			accessDuration, token.TokenType(token.AccessToken))
 		if err != nil {
 			return err
 		}
@@ -56,17 +96,18 @@ func (s *TokenService) CreateTokenPair(ctx context.Context, payloadData token.Pa
 	})
 
 	eg.Go(func() error {
+		var err error
 		refreshToken, refreshPayload, err = s.tokenMaker.CreateToken(
 			token.PayloadData{
 				Subject:   payloadData.Subject,
 				SessionID: payloadData.SessionID,
 				Issuer:    payloadData.Issuer,
-				Audience:  "website users",
+				Audience:  audience,
 				IP:        payloadData.IP,
 				UserAgent: payloadData.UserAgent,
 				TokenType: token.TokenType(token.RefreshToken),
 			},
-			s.config.RefreshTokenDuration, token.TokenType(token.RefreshToken))
+			refreshDuration, token.TokenType(token.RefreshToken))
 
 		if err != nil {
 			return err
@@ -80,7 +121,7 @@ func (s *TokenService) CreateTokenPair(ctx context.Context, payloadData token.Pa
 		return AuthToken{}, err
 	}
 
-	err = s.cache.SetKey(ctx, accessToken, payloadData.SessionID.String(), s.config.AccessTokenDuration)
+	err := s.cache.SetKey(ctx, accessToken, payloadData.SessionID.String(), accessDuration)
 	if err != nil {
 		return AuthToken{}, err
 	}
@@ -1 +1,36 @@
 package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/token"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Test both tokens of a pair are created at once and the access token is tied to its session. Run with -race.
+func TestCreateTokenPair(t *testing.T) {
+	ctx := context.Background()
+	maker, err := token.NewPasetoMaker("a_very_secret_key_with_sufficient_length", "a_very_secret_key_with_sufficient_length")
+	require.NoError(t, err)
+	sessions := cache.NewMemory()
+	ts := NewTokenService(utils.Config{TokenConfig: utils.TokenConfig{
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}}, sessions, maker)
+
+	sessionID := uuid.New()
+	pair, err := ts.CreateTokenPair(ctx, token.PayloadData{Subject: uuid.New(), SessionID: sessionID})
+	require.NoError(t, err)
+	require.NotEmpty(t, pair.AccessToken)
+	require.NotEmpty(t, pair.RefreshToken)
+	require.True(t, pair.RefreshTokenExpiresAt.After(pair.AccessTokenExpiresAt))
+
+	cached, err := sessions.GetKey(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, sessionID.String(), cached)
+}
@@ -2,20 +2,18 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
-
-	// "github.com/jackc/pgx/v5/pgtype"
-	// "github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
 	"github.com/steve-mir/bukka_backend/utils"
 	"github.com/steve-mir/bukka_backend/worker"
-	"golang.org/x/sync/errgroup"
 )
 
 // MaxAccountRecoveryDuration is the duration for which an account can be recovered after deletion.
@@ -24,21 +22,27 @@ const (
 	recoveryTokenLength        = 39
 )
 
+var (
+	ErrRecoveryNotAllowed   = apperr.New(apperr.CodeFailedPrecondition, "auth.recovery.not_allowed", "cannot process account recovery")
+	ErrRecoveryPeriodOver   = apperr.New(apperr.CodeFailedPrecondition, "auth.recovery.period_over", "the account recovery period has expired")
+	ErrInvalidRecoveryToken = apperr.New(apperr.CodeInvalidArgument, "auth.recovery.invalid_token", "account recovery request is invalid or has expired")
+)
+
 func DeleteAccountRequest(ctx context.Context, password string, store sqlc.Store, uid uuid.UUID) error {
 
 	// Check if the user exists and is not already marked as deleted
 	user, err := store.GetUserByID(ctx, uid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("user with ID %s not found", uid.String())
+		if err == pgx.ErrNoRows {
+			return ErrUserNotFound
 		}
-		return err
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Check password
 	err = utils.CheckPassword(password, user.PasswordHash)
 	if err != nil {
-		return errors.New("password incorrect")
+		return ErrPasswordIncorrect
 	}
 
 	err = checkAccountStatus(user)
@@ -52,39 +56,39 @@ func DeleteAccountRequest(ctx context.Context, password string, store sqlc.Store
 	// Proceed with soft deletion if all checks pass
 	_, err = store.UpdateUser(ctx, sqlc.UpdateUserParams{
 		ID:        uid,
-		IsDeleted: sql.NullBool{Bool: true, Valid: true},
-		DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		IsDeleted: pgtype.Bool{Bool: true, Valid: true},
+		DeletedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	return nil
 }
 
-func AccRecoveryRequest(ctx context.Context, store sqlc.Store, td worker.TaskDistributor, email string) error {
+func AccRecoveryRequest(ctx context.Context, store sqlc.Store, email string) error {
 	// Check if the email is valid.
 	if !utils.IsEmailFormat(email) {
-		return fmt.Errorf("invalid email format: %s", email)
+		return apperr.InvalidField("email", "validation.email", constants.InvalidEmail)
 	}
 
 	// Retrieve the user associated with the email address.
 	user, err := store.GetUserByIdentifier(ctx, email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("an error occurred while processing the recovery request")
+		if err == pgx.ErrNoRows {
+			return ErrRecoveryNotAllowed
 		}
-		return err
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Check if the user's account is already marked as deleted.
 	if !user.IsDeleted.Bool { //!user.DeletedAt.Valid
-		return errors.New("cannot process account recovery")
+		return ErrRecoveryNotAllowed
 	}
 
 	// Check if the account is within the recovery period.
 	if time.Since(user.DeletedAt.Time) > MaxAccountRecoveryDuration {
-		return fmt.Errorf("the account recovery period has expired for email: %s", email)
+		return ErrRecoveryPeriodOver
 	}
 
 	// Generate a recovery token and send an email to the user with the recovery instructions.
@@ -92,105 +96,71 @@ func AccRecoveryRequest(ctx context.Context, store sqlc.Store, td worker.TaskDis
 	// recoveryToken, err := generateSecureRecoveryToken()
 	recoveryToken, err := utils.GenerateUniqueToken(recoveryTokenLength)
 	if err != nil {
-		return err
-	}
-
-	err = store.CreateUserDeleteRequest(ctx, sqlc.CreateUserDeleteRequestParams{
-		UserID:        user.ID,
-		Email:         user.Email,
-		RecoveryToken: recoveryToken,
-		ExpiresAt:     time.Now().Add(time.Minute * 15),
-	})
-	if err != nil {
-		return err
+		return fmt.Errorf("failed to generate recovery token: %w", err)
 	}
 
-	err = SendEmail(td, ctx, email, recoveryToken)
-	if err != nil {
-		return err
-	}
+	return store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		err := qtx.CreateUserDeleteRequest(ctx, sqlc.CreateUserDeleteRequestParams{
+			UserID:        user.ID,
+			Email:         user.Email,
+			RecoveryToken: recoveryToken,
+			ExpiresAt:     time.Now().Add(time.Minute * 15),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create delete request: %w", err)
+		}
 
-	return nil
+		return outbox.AddTask(ctx, qtx, outbox.UserAggregate(user.ID), worker.TaskAccountRecoveryEmail, worker.PayloadAccountRecoveryEmail{
+			UserID: user.ID,
+			Email:  user.Email,
+			Token:  recoveryToken,
+		})
+	})
 }
 
-func AccountRecovery(ctx context.Context, db *sql.DB, store sqlc.Store, recoveryToken string) error {
+func AccountRecovery(ctx context.Context, store sqlc.Store, recoveryToken string) error {
 
 	// Retrieve the user and recovery token information from the database
 	usr, err := store.GetUserFromDeleteReqByToken(ctx, recoveryToken)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("account recovery request is invalid or has expired")
+		if err == pgx.ErrNoRows {
+			return ErrInvalidRecoveryToken
 		}
-		return err
+		return fmt.Errorf("failed to get delete request: %w", err)
 	}
 
 	if usr.Used.Bool {
-		return errors.New("request token has been used or has expired")
+		return ErrTokenUsed
 	}
 
 	if usr.ExpiresAt.Before(time.Now()) {
-		return errors.New("account recovery request is invalid or has expired")
+		return ErrInvalidRecoveryToken
 	}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err = store.ExecTx(ctx, sqlc.TxOptions{Isolation: pgx.RepeatableRead}, func(qtx *sqlc.Queries) error {
+		// Check again inside the transaction, a concurrent recovery with the same token makes this one retry
+		req, err := qtx.GetUserFromDeleteReqByToken(ctx, recoveryToken)
+		if err != nil {
+			return err
+		}
+		if req.Used.Bool {
+			return ErrTokenUsed
 		}
-	}()
-
-	qtx := sqlc.New(tx)
-	// Use a wait group to wait for both updates to complete
-	var eg errgroup.Group
-	// var err error
 
-	// Goroutine for updating token status
-	eg.Go(func() error {
+		if err := qtx.MarkDeleteAsUsedByToken(ctx, recoveryToken); err != nil {
+			return fmt.Errorf("failed to mark delete request as used: %w", err)
+		}
 
 		// Assuming the recovery token is valid, proceed to unmark the account as deleted
 		_, err = qtx.UpdateUser(ctx, sqlc.UpdateUserParams{
 			ID:        usr.UserID,
-			IsDeleted: sql.NullBool{Bool: false, Valid: true},
-			DeletedAt: sql.NullTime{Time: time.Time{}, Valid: true}, // TODO: Set the null time
+			IsDeleted: pgtype.Bool{Bool: false, Valid: true},
+			DeletedAt: pgtype.Timestamptz{Time: time.Time{}, Valid: true}, // TODO: Set the null time
 		})
 		if err != nil {
-			log.Println("Error 1", err.Error())
-			return err
+			return fmt.Errorf("failed to restore user: %w", err)
 		}
 		return nil
 	})
-
-	// Goroutine for updating user account
-	eg.Go(func() error {
-		// defer wg.Done()
-
-		// Optionally, you may want to invalidate the recovery token after successful account recovery
-		err = qtx.MarkDeleteAsUsedByToken(ctx, recoveryToken)
-		if err != nil {
-			// Rollback the transaction in case of an error
-			// tx.Rollback(ctx)
-			log.Println("Error 2", err.Error())
-			return err
-		}
-		return nil
-	})
-
-	// Wait for both goroutines to complete
-	// wg.Wait()
-	if err := eg.Wait(); err != nil {
-		tx.Rollback()
-		log.Println("Error 3", err.Error())
-		return err
-	}
-
-	// Commit the transaction if all updates were successful
-	err = tx.Commit()
-	if err != nil {
-		log.Println("Error 4", err.Error())
-		return err
-	}
-	return nil
+	return err
 }
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/menu/services"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+func (s *Server) getCart(ctx *gin.Context) {
+	payload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	cart, err := services.GetCart(ctx, s.store, payload.Subject)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (s *Server) setCartItem(ctx *gin.Context) {
+	var req services.CartItemReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpserver.BindingError(ctx, err)
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	item, err := services.SetCartItem(ctx, s.store, payload.Subject, req)
+	if err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func (s *Server) removeCartItem(ctx *gin.Context) {
+	payload := ctx.MustGet(middlewares.AuthorizationPayloadKey).(*token.Payload)
+
+	if err := services.RemoveCartItem(ctx, s.store, payload.Subject, ctx.Param("item_id")); err != nil {
+		httpserver.Error(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
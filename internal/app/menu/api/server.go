@@ -1,13 +1,14 @@
 package api
 
 import (
-	"database/sql"
-	"fmt"
-
 	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/constants"
 	db "github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/app/auth/middlewares"
+	"github.com/steve-mir/bukka_backend/internal/app/auth/services"
 	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
 	"github.com/steve-mir/bukka_backend/token"
 	"github.com/steve-mir/bukka_backend/utils"
 	"github.com/steve-mir/bukka_backend/worker"
@@ -17,40 +18,35 @@ const (
 	baseURL = "v1/menu"
 )
 
-type RouteConfig struct {
-	Method      string
-	Path        string
-	Handler     gin.HandlerFunc
-	Middlewares []gin.HandlerFunc
-	Roles       []int8
-	RateLimit   middlewares.RateLimitConfig
-}
-
 type Server struct {
 	store           db.Store
 	Router          *gin.Engine
-	db              *sql.DB
 	config          utils.Config
+	configWatcher   *utils.ConfigWatcher
 	taskDistributor worker.TaskDistributor
 	tokenMaker      token.Maker
-	cache           *cache.Cache
+	cache           cache.Cache
+	flags           *featureflag.Client
 }
 
-func NewServer(store db.Store, db *sql.DB, config utils.Config, td worker.TaskDistributor) *Server {
+// NewServer builds the service on resources shared with the other services of the process. Reloads of
+// configWatcher apply to its rate limits.
+func NewServer(store db.Store, configWatcher *utils.ConfigWatcher, td worker.TaskDistributor, cache cache.Cache) *Server {
+	config := configWatcher.Current()
+
 	tokenMaker, err := token.NewPasetoMaker(config.AccessTokenSymmetricKey, config.RefreshTokenSymmetricKey)
 	if err != nil {
 		panic(err)
 	}
 
-	cache := cache.NewCache(config.RedisAddress, config.RedisUsername, config.RedisPwd, 0)
-
 	server := &Server{
 		store:           store,
-		db:              db,
 		config:          config,
+		configWatcher:   configWatcher,
 		taskDistributor: td,
 		tokenMaker:      tokenMaker,
 		cache:           cache,
+		flags:           featureflag.NewClient(store, cache),
 	}
 
 	// server.setupValidator()
@@ -69,12 +65,11 @@ func NewServer(store db.Store, db *sql.DB, config utils.Config, td worker.TaskDi
 // }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
-	rl := setupRateLimiter()
-	router.Use(middlewares.RateLimit(rl))
-
-	routes := []RouteConfig{
+	routes := []httpserver.Route{
 		{Method: "GET", Path: "home", Handler: server.home},
+		{Method: "GET", Path: "cart", Handler: server.getCart, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers, constants.Guests}, Scope: constants.ScopeCartRead},
+		{Method: "PUT", Path: "cart", Handler: server.setCartItem, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers, constants.Guests}, Scope: constants.ScopeCartWrite},
+		{Method: "DELETE", Path: "cart/:item_id", Handler: server.removeCartItem, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers, constants.Guests}, Scope: constants.ScopeCartWrite},
 		// {Method: "POST", Path: "categories", Handler: server.register, Roles: []int8{constants.SuperAdmin, constants.AppAdmin}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(10 * time.Second), Burst: 2}},
 		// {Method: "GET", Path: "categories", Handler: server.login, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(5 * time.Second), Burst: 3}},
 		// {Method: "GET", Path: "dishes", Handler: server.googleLogin, Roles: []int8{constants.SuperAdmin, constants.AppAdmin, constants.RegularUsers}, RateLimit: middlewares.RateLimitConfig{Rate: rate.Every(5 * time.Second), Burst: 3}},
@@ -83,34 +78,27 @@ func (server *Server) setupRouter() {
 
 	}
 
-	accessibleRoles := make(map[string][]int8)
-
-	for _, route := range routes {
-		fullPath := fmt.Sprintf("%s /%s/%s", route.Method, baseURL, route.Path)
-		handlers := []gin.HandlerFunc{route.Handler}
-
-		if len(route.Roles) > 0 {
-			accessibleRoles[fullPath] = route.Roles
-			router.Use(middlewares.AuthMiddleWare(server.config, server.tokenMaker, *server.cache, accessibleRoles))
-		}
-
-		if route.RateLimit.Rate != 0 {
-			rl.SetRateLimitConfig("/"+route.Path, route.RateLimit)
-		}
-
-		router.Handle(route.Method, "/"+baseURL+"/"+route.Path, handlers...)
+	opts, err := middlewares.RouterOptions("menu", baseURL, middlewares.RouteOptions{
+		Config:          server.config,
+		ConfigWatcher:   server.configWatcher,
+		TokenMaker:      server.tokenMaker,
+		Cache:           server.cache,
+		RateLimitPrefix: "ratelimit:menu",
+		Consent:         services.NewConsentService(server.store, server.cache),
+		APIKeys:         services.NewAPIKeyService(server.store),
+	})
+	if err != nil {
+		panic(err)
 	}
 
-	// Apply the auth middleware to all routes
-	// router.Use(middlewares.AuthMiddleWare(server.config, server.tokenMaker, *server.cache, accessibleRoles))
+	router, err := httpserver.NewRouter(opts, routes)
+	if err != nil {
+		panic(err)
+	}
 
 	server.Router = router
 }
 
-func setupRateLimiter() *middlewares.RateLimiter {
-	return middlewares.NewRateLimiter()
-}
-
 func (server *Server) Start(address string) error {
 	return server.Router.Run(address)
 }
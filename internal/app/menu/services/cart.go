@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+)
+
+type CartItemReq struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int32  `json:"quantity" binding:"required,min=1,max=100"`
+	Notes    string `json:"notes" binding:"max=500"`
+}
+
+type CartItemRes struct {
+	ItemID    string    `json:"item_id"`
+	Quantity  int32     `json:"quantity"`
+	Notes     string    `json:"notes,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CartRes struct {
+	Items []CartItemRes `json:"items"`
+}
+
+// GetCart returns the cart for the owner, the owner can be either a user or a guest session.
+func GetCart(ctx context.Context, store sqlc.Store, ownerID uuid.UUID) (CartRes, error) {
+	items, err := store.GetCartItems(ctx, ownerID)
+	if err != nil {
+		return CartRes{}, fmt.Errorf("failed to get cart %s", err)
+	}
+
+	res := CartRes{Items: make([]CartItemRes, 0, len(items))}
+	for _, item := range items {
+		res.Items = append(res.Items, newCartItemRes(item))
+	}
+	return res, nil
+}
+
+// SetCartItem adds the item to the cart or replaces its quantity and notes if it is already there.
+func SetCartItem(ctx context.Context, store sqlc.Store, ownerID uuid.UUID, req CartItemReq) (CartItemRes, error) {
+	item, err := store.UpsertCartItem(ctx, sqlc.UpsertCartItemParams{
+		OwnerID:  ownerID,
+		ItemID:   req.ItemID,
+		Quantity: req.Quantity,
+		Notes:    pgtype.Text{String: req.Notes, Valid: req.Notes != ""},
+	})
+	if err != nil {
+		return CartItemRes{}, fmt.Errorf("failed to update cart %s", err)
+	}
+	return newCartItemRes(item), nil
+}
+
+func RemoveCartItem(ctx context.Context, store sqlc.Store, ownerID uuid.UUID, itemID string) error {
+	err := store.DeleteCartItem(ctx, sqlc.DeleteCartItemParams{OwnerID: ownerID, ItemID: itemID})
+	if err != nil {
+		return fmt.Errorf("failed to update cart %s", err)
+	}
+	return nil
+}
+
+func newCartItemRes(item sqlc.CartItem) CartItemRes {
+	return CartItemRes{
+		ItemID:    item.ItemID,
+		Quantity:  item.Quantity,
+		Notes:     item.Notes.String,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
@@ -0,0 +1,66 @@
+// Package cache stores short lived values shared by the instances of every service: sessions, rate limits and
+// cached reads. Redis backs it in production, optionally behind a local tier, and Memory stands in for it in tests.
+// GetOrLoad reads typed values through it, loading them once however many callers miss at the same time.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMiss is returned when a key doesn't exist or has expired
+	ErrMiss = errors.New("cache: miss")
+	// ErrLocked is returned when someone else holds the lock
+	ErrLocked = errors.New("cache: locked")
+	// ErrLockLost is returned when releasing or extending a lock that expired and may have been taken since
+	ErrLockLost = errors.New("cache: lock lost")
+)
+
+// Cache is what the services need from the shared cache.
+type Cache interface {
+	// GetKey returns the value of key, ErrMiss when it is not set.
+	GetKey(ctx context.Context, key string) (string, error)
+	// SetKey sets key to value, a string, []byte or number, for ttl. A ttl of 0 keeps it until deleted.
+	SetKey(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	DeleteKey(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+
+	SAdd(ctx context.Context, setKey string, member interface{}) error
+	SRem(ctx context.Context, setKey string, member interface{}) error
+	SIsMember(ctx context.Context, setKey string, member interface{}) (bool, error)
+
+	// Tag files key under tags for at least ttl so InvalidateTags can delete it with everything else tagged alike.
+	Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error
+	// InvalidateTags deletes every key filed under tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+
+	// Lock takes the lock key for ttl unless someone else holds it, in which case it returns ErrLocked. Locks
+	// expire so a crashed holder doesn't keep them forever, holders working longer than ttl must Extend them.
+	Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+
+	// Publish sends message to every subscriber of channel, on any instance.
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe returns the messages published to channel until ctx is done. Messages published while the
+	// subscriber is disconnected are lost.
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+
+	// Ping reports whether the cache answers.
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Lock is a lock taken with Cache.Lock.
+type Lock interface {
+	// Extend keeps the lock for ttl from now, ErrLockLost when it expired in the meantime.
+	Extend(ctx context.Context, ttl time.Duration) error
+	// Unlock releases the lock, ErrLockLost when it expired in the meantime.
+	Unlock(ctx context.Context) error
+}
+
+const (
+	tagPrefix  = "cachetag:"
+	lockPrefix = "lock:"
+)
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// Codec encodes the values GetOrLoad caches.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+var (
+	// JSON is the default codec, readable with redis-cli
+	JSON Codec = jsonCodec{}
+	// Msgpack is smaller and faster for large values. Fields are named as in Go unless tagged `msgpack:"..."`.
+	Msgpack Codec = msgpackCodec{}
+)
+
+type loadOptions struct {
+	codec Codec
+	tags  []string
+}
+
+// LoadOption configures GetOrLoad.
+type LoadOption func(*loadOptions)
+
+// WithCodec encodes the value with codec instead of JSON.
+func WithCodec(codec Codec) LoadOption {
+	return func(o *loadOptions) { o.codec = codec }
+}
+
+// WithTags files the loaded value under tags, see Cache.InvalidateTags.
+func WithTags(tags ...string) LoadOption {
+	return func(o *loadOptions) { o.tags = append(o.tags, tags...) }
+}
+
+// loads shares the load of a key between the callers of a process missing it at the same time.
+var loads singleflight.Group
+
+// GetOrLoad returns the value cached under key, otherwise loads it, caches it for ttl and returns it. Callers of
+// the process missing the same key at the same time share a single load, run with the context of the first one.
+// The cache failing only costs a load: values that can't be read or decoded are loaded again and values that
+// can't be stored are still returned.
+func GetOrLoad[T any](ctx context.Context, c Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error), opts ...LoadOption) (T, error) {
+	options := loadOptions{codec: JSON}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var value T
+	cached, err := c.GetKey(ctx, key)
+	if err == nil {
+		if err := options.codec.Unmarshal([]byte(cached), &value); err == nil {
+			return value, nil
+		}
+		logging.FromContext(ctx).Warn().Str("key", key).Msg("cannot decode cached value, loading it again")
+	} else if !errors.Is(err, ErrMiss) {
+		logging.FromContext(ctx).Error().Err(err).Str("key", key).Msg("cannot read cache")
+	}
+
+	// Every caller decodes its own copy so none can change the value another one got
+	data, err, _ := loads.Do(fmt.Sprintf("%p/%s", c, key), func() (interface{}, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := options.codec.Marshal(loaded)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %s: %w", key, err)
+		}
+
+		if err := c.SetKey(ctx, key, data, ttl); err != nil {
+			logging.FromContext(ctx).Error().Err(err).Str("key", key).Msg("cannot cache value")
+		} else if err := c.Tag(ctx, key, ttl, options.tags...); err != nil {
+			// An untagged value would outlive the invalidation of its tags
+			logging.FromContext(ctx).Error().Err(err).Str("key", key).Msg("cannot tag cached value")
+			c.DeleteKey(ctx, key)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	if err := options.codec.Unmarshal(data.([]byte), &value); err != nil {
+		return value, fmt.Errorf("cannot decode %s: %w", key, err)
+	}
+	return value, nil
+}
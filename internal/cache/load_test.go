@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type menu struct {
+	ID    int      `json:"id" msgpack:"id"`
+	Items []string `json:"items" msgpack:"items"`
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	for name, codec := range map[string]Codec{"json": JSON, "msgpack": Msgpack} {
+		t.Run(name, func(t *testing.T) {
+			c := NewMemory()
+			var loads int
+			load := func(ctx context.Context) (menu, error) {
+				loads++
+				return menu{ID: 1, Items: []string{"jollof"}}, nil
+			}
+
+			for i := 0; i < 2; i++ {
+				value, err := GetOrLoad(ctx, c, "menu:1", time.Minute, load, WithCodec(codec))
+				require.NoError(t, err)
+				require.Equal(t, menu{ID: 1, Items: []string{"jollof"}}, value)
+			}
+			require.Equal(t, 1, loads)
+		})
+	}
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	errDown := errors.New("database down")
+	_, err := GetOrLoad(ctx, c, "menu:1", time.Minute, func(ctx context.Context) (menu, error) {
+		return menu{}, errDown
+	})
+	require.ErrorIs(t, err, errDown)
+	_, err = c.GetKey(ctx, "menu:1")
+	require.ErrorIs(t, err, ErrMiss, "failed loads are not cached")
+
+	require.NoError(t, c.SetKey(ctx, "menu:1", "not json", time.Minute))
+	value, err := GetOrLoad(ctx, c, "menu:1", time.Minute, func(ctx context.Context) (menu, error) {
+		return menu{ID: 1}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, value.ID, "values that can't be decoded are loaded again")
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	var loads int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (menu, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return menu{ID: 1, Items: []string{"jollof"}}, nil
+	}
+
+	const callers = 10
+	values := make([]menu, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := GetOrLoad(ctx, c, "menu:1", time.Minute, load)
+			require.NoError(t, err)
+			values[i] = value
+		}(i)
+	}
+	// Let every caller miss before the load completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&loads))
+	values[0].Items[0] = "changed"
+	require.Equal(t, "jollof", values[1].Items[0], "callers don't share the value")
+}
+
+func TestGetOrLoadTags(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	var loads int
+	load := func(ctx context.Context) (int, error) {
+		loads++
+		return loads, nil
+	}
+
+	_, err := GetOrLoad(ctx, c, "menu:1", time.Minute, load, WithTags("restaurant:1"))
+	require.NoError(t, err)
+	require.NoError(t, c.InvalidateTags(ctx, "restaurant:1"))
+	value, err := GetOrLoad(ctx, c, "menu:1", time.Minute, load, WithTags("restaurant:1"))
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+}
@@ -0,0 +1,357 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var errWrongType = errors.New("cache: operation against a key holding the wrong kind of value")
+
+type memoryEntry struct {
+	value   string
+	set     map[string]bool
+	expires time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Memory is a Cache kept in the process. It stands in for Redis in tests and keeps the local tier of Tiered, it
+// is never shared between instances.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	// maxEntries bounds the entries when positive, the ones expiring first are evicted to make room
+	maxEntries  int
+	subscribers map[string][]chan string
+	now         func() time.Time
+}
+
+func NewMemory() *Memory {
+	return newMemory(0)
+}
+
+func newMemory(maxEntries int) *Memory {
+	return &Memory{
+		entries:     make(map[string]*memoryEntry),
+		maxEntries:  maxEntries,
+		subscribers: make(map[string][]chan string),
+		now:         time.Now,
+	}
+}
+
+// get returns the live entry of key, nil when there is none. c.mu must be held.
+func (c *Memory) get(key string) *memoryEntry {
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.expired(c.now()) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// put stores entry under key, expiring after ttl when positive. c.mu must be held.
+func (c *Memory) put(key string, entry *memoryEntry, ttl time.Duration) {
+	if ttl > 0 {
+		entry.expires = c.now().Add(ttl)
+	}
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = entry
+}
+
+// evict drops the expired entries, or the one expiring first when none has. c.mu must be held.
+func (c *Memory) evict() {
+	now := c.now()
+	var victim string
+	var victimExpires time.Time
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+			continue
+		}
+		if victim == "" || (!entry.expires.IsZero() && (victimExpires.IsZero() || entry.expires.Before(victimExpires))) {
+			victim, victimExpires = key, entry.expires
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, victim)
+	}
+}
+
+// flush drops every entry.
+func (c *Memory) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*memoryEntry)
+}
+
+func (c *Memory) GetKey(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.get(key)
+	if entry == nil {
+		return "", ErrMiss
+	}
+	if entry.set != nil {
+		return "", errWrongType
+	}
+	return entry.value, nil
+}
+
+func (c *Memory) SetKey(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(key, &memoryEntry{value: toString(value)}, ttl)
+	return nil
+}
+
+// toString formats value the way Redis stores it.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Duration:
+		return strconv.FormatInt(int64(v), 10)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c *Memory) DeleteKey(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *Memory) Incr(ctx context.Context, key string) (int64, error) {
+	return c.incrBy(key, 1)
+}
+
+func (c *Memory) Decr(ctx context.Context, key string) (int64, error) {
+	return c.incrBy(key, -1)
+}
+
+func (c *Memory) incrBy(key string, delta int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.get(key)
+	if entry == nil {
+		entry = &memoryEntry{value: "0"}
+		c.put(key, entry, 0)
+	}
+	if entry.set != nil {
+		return 0, errWrongType
+	}
+	n, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, errors.New("cache: value is not an integer")
+	}
+	n += delta
+	entry.value = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// set returns the set of setKey, creating it when create is set. c.mu must be held.
+func (c *Memory) set(setKey string, create bool) (map[string]bool, error) {
+	entry := c.get(setKey)
+	if entry == nil {
+		if !create {
+			return nil, nil
+		}
+		entry = &memoryEntry{set: make(map[string]bool)}
+		c.put(setKey, entry, 0)
+	}
+	if entry.set == nil {
+		return nil, errWrongType
+	}
+	return entry.set, nil
+}
+
+func (c *Memory) SAdd(ctx context.Context, setKey string, member interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set, err := c.set(setKey, true)
+	if err != nil {
+		return err
+	}
+	set[toString(member)] = true
+	return nil
+}
+
+func (c *Memory) SRem(ctx context.Context, setKey string, member interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set, err := c.set(setKey, false)
+	if err != nil || set == nil {
+		return err
+	}
+	delete(set, toString(member))
+	if len(set) == 0 {
+		delete(c.entries, setKey)
+	}
+	return nil
+}
+
+func (c *Memory) SIsMember(ctx context.Context, setKey string, member interface{}) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set, err := c.set(setKey, false)
+	if err != nil {
+		return false, err
+	}
+	return set[toString(member)], nil
+}
+
+func (c *Memory) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tagKeys(tags) {
+		set, err := c.set(tag, true)
+		if err != nil {
+			return err
+		}
+		set[key] = true
+
+		// The tag lives as long as its longest lived key
+		entry := c.entries[tag]
+		if ttl == 0 {
+			entry.expires = time.Time{}
+		} else if expires := c.now().Add(ttl); len(set) == 1 || (!entry.expires.IsZero() && entry.expires.Before(expires)) {
+			entry.expires = expires
+		}
+	}
+	return nil
+}
+
+func (c *Memory) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tagKeys(tags) {
+		set, err := c.set(tag, false)
+		if err != nil {
+			return err
+		}
+		for key := range set {
+			delete(c.entries, key)
+		}
+		delete(c.entries, tag)
+	}
+	return nil
+}
+
+func (c *Memory) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key = lockPrefix + key
+	if c.get(key) != nil {
+		return nil, ErrLocked
+	}
+	c.put(key, &memoryEntry{value: token}, ttl)
+	return &memoryLock{cache: c, key: key, token: token}, nil
+}
+
+type memoryLock struct {
+	cache *Memory
+	key   string
+	token string
+}
+
+// held returns the entry of the lock while it is held with its token. l.cache.mu must be held.
+func (l *memoryLock) held() *memoryEntry {
+	entry := l.cache.get(l.key)
+	if entry == nil || entry.value != l.token {
+		return nil
+	}
+	return entry
+}
+
+func (l *memoryLock) Extend(ctx context.Context, ttl time.Duration) error {
+	l.cache.mu.Lock()
+	defer l.cache.mu.Unlock()
+	entry := l.held()
+	if entry == nil {
+		return ErrLockLost
+	}
+	entry.expires = l.cache.now().Add(ttl)
+	return nil
+}
+
+func (l *memoryLock) Unlock(ctx context.Context) error {
+	l.cache.mu.Lock()
+	defer l.cache.mu.Unlock()
+	if l.held() == nil {
+		return ErrLockLost
+	}
+	delete(l.cache.entries, l.key)
+	return nil
+}
+
+// Publish hands message to the subscribers of channel in this process, dropping it for those not keeping up.
+func (c *Memory) Publish(ctx context.Context, channel, message string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, subscriber := range c.subscribers[channel] {
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (c *Memory) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	messages := make(chan string, 64)
+	c.mu.Lock()
+	c.subscribers[channel] = append(c.subscribers[channel], messages)
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		subscribers := c.subscribers[channel]
+		for i, subscriber := range subscribers {
+			if subscriber == messages {
+				c.subscribers[channel] = append(subscribers[:i:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		close(messages)
+	}()
+	return messages, nil
+}
+
+func (c *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *Memory) Close() error {
+	return nil
+}
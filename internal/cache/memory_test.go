@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock lets tests move the time of a Memory forward.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMemory(maxEntries int) (*Memory, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	c := newMemory(maxEntries)
+	c.now = func() time.Time { return clock.now }
+	return c, clock
+}
+
+func TestMemoryKeys(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestMemory(0)
+
+	_, err := c.GetKey(ctx, "missing")
+	require.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, c.SetKey(ctx, "session", "abc", time.Minute))
+	require.NoError(t, c.SetKey(ctx, "verified", true, 0))
+	value, err := c.GetKey(ctx, "verified")
+	require.NoError(t, err)
+	require.Equal(t, "1", value, "values are stored the way Redis formats them")
+
+	clock.advance(time.Minute)
+	_, err = c.GetKey(ctx, "session")
+	require.ErrorIs(t, err, ErrMiss)
+
+	n, err := c.Incr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	n, err = c.Decr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+	_, err = c.Incr(ctx, "verified")
+	require.NoError(t, err)
+
+	require.NoError(t, c.SAdd(ctx, "set", "a"))
+	member, err := c.SIsMember(ctx, "set", "a")
+	require.NoError(t, err)
+	require.True(t, member)
+	_, err = c.GetKey(ctx, "set")
+	require.Error(t, err)
+	require.NoError(t, c.SRem(ctx, "set", "a"))
+	member, err = c.SIsMember(ctx, "set", "a")
+	require.NoError(t, err)
+	require.False(t, member)
+
+	require.NoError(t, c.DeleteKey(ctx, "verified", "counter"))
+	_, err = c.GetKey(ctx, "verified")
+	require.ErrorIs(t, err, ErrMiss)
+}
+
+func TestMemoryEvicts(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestMemory(2)
+
+	require.NoError(t, c.SetKey(ctx, "forever", "1", 0))
+	require.NoError(t, c.SetKey(ctx, "soon", "1", time.Second))
+	require.NoError(t, c.SetKey(ctx, "later", "1", time.Hour))
+
+	_, err := c.GetKey(ctx, "soon")
+	require.ErrorIs(t, err, ErrMiss, "the entry expiring first makes room")
+	for _, key := range []string{"forever", "later"} {
+		_, err := c.GetKey(ctx, key)
+		require.NoError(t, err, key)
+	}
+}
+
+func TestMemoryTags(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestMemory(0)
+
+	require.NoError(t, c.SetKey(ctx, "menu:1", "a", time.Hour))
+	require.NoError(t, c.Tag(ctx, "menu:1", time.Hour, "menu", "restaurant:1"))
+	require.NoError(t, c.SetKey(ctx, "menu:2", "b", time.Minute))
+	require.NoError(t, c.Tag(ctx, "menu:2", time.Minute, "menu"))
+	require.NoError(t, c.SetKey(ctx, "other", "c", 0))
+
+	clock.advance(30 * time.Minute)
+	require.NoError(t, c.InvalidateTags(ctx, "menu"))
+	_, err := c.GetKey(ctx, "menu:1")
+	require.ErrorIs(t, err, ErrMiss, "the tag lives as long as its longest lived key")
+	_, err = c.GetKey(ctx, "other")
+	require.NoError(t, err)
+
+	require.NoError(t, c.InvalidateTags(ctx, "unknown"))
+}
+
+func TestMemoryLock(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestMemory(0)
+
+	lock, err := c.Lock(ctx, "export", time.Minute)
+	require.NoError(t, err)
+	_, err = c.Lock(ctx, "export", time.Minute)
+	require.ErrorIs(t, err, ErrLocked)
+
+	clock.advance(50 * time.Second)
+	require.NoError(t, lock.Extend(ctx, time.Minute))
+	clock.advance(50 * time.Second)
+	_, err = c.Lock(ctx, "export", time.Minute)
+	require.ErrorIs(t, err, ErrLocked, "extending keeps the lock")
+
+	require.NoError(t, lock.Unlock(ctx))
+	require.ErrorIs(t, lock.Unlock(ctx), ErrLockLost)
+
+	lock, err = c.Lock(ctx, "export", time.Minute)
+	require.NoError(t, err)
+	clock.advance(time.Minute)
+	other, err := c.Lock(ctx, "export", time.Minute)
+	require.NoError(t, err, "expired locks can be taken")
+	require.ErrorIs(t, lock.Unlock(ctx), ErrLockLost, "the previous holder can't release the new one")
+	require.NoError(t, other.Unlock(ctx))
+}
+
+func TestMemoryPubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewMemory()
+
+	messages, err := c.Subscribe(ctx, "events")
+	require.NoError(t, err)
+	require.NoError(t, c.Publish(ctx, "events", "hello"))
+	require.NoError(t, c.Publish(ctx, "others", "ignored"))
+	require.Equal(t, "hello", <-messages)
+
+	cancel()
+	_, ok := <-messages
+	require.False(t, ok, "the subscription ends with its context")
+}
@@ -0,0 +1,335 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+)
+
+// tagScript files ARGV[1] under the tag sets in KEYS. A tag set lives as long as the longest lived key in it,
+// ARGV[2] milliseconds or forever when 0.
+var tagScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+for _, tag in ipairs(KEYS) do
+	local current = redis.call('PTTL', tag)
+	redis.call('SADD', tag, ARGV[1])
+	if ttl == 0 then
+		redis.call('PERSIST', tag)
+	elseif current == -2 or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', tag, ttl)
+	end
+end
+return 0
+`)
+
+// invalidateTagsScript deletes the keys in the tag sets of KEYS and the sets themselves.
+var invalidateTagsScript = redis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	local keys = redis.call('SMEMBERS', tag)
+	for i = 1, #keys, 500 do
+		redis.call('DEL', unpack(keys, i, math.min(i + 499, #keys)))
+	end
+	redis.call('DEL', tag)
+end
+return 0
+`)
+
+// unlockScript deletes the lock KEYS[1] if it is still held with the token ARGV[1].
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendScript sets the lock KEYS[1] to expire in ARGV[2] milliseconds if it is still held with the token ARGV[1].
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Scripter runs Lua scripts, which only Redis can.
+type Scripter interface {
+	RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Redis is the Cache every instance of the services shares.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(addr, username, password string, db int) *Redis {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+		Username: username,
+	})
+	client.AddHook(metrics.RedisHook{})
+	client.AddHook(tracing.RedisHook{})
+	return &Redis{client: client}
+}
+
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
+
+// Ping reports whether Redis answers.
+func (c *Redis) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// XAdd appends values to stream under an ID Redis generates. The stream is trimmed to about maxLen entries when
+// positive.
+func (c *Redis) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	args := &redis.XAddArgs{
+		Stream: stream,
+		ID:     "*",
+		Values: values,
+		MaxLen: maxLen,
+		Approx: true, // Trimming whole nodes only is much cheaper
+	}
+	return c.client.XAdd(ctx, args).Result()
+}
+
+func (c *Redis) SetKey(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return c.client.Set(ctx, key, value, expiration).Err()
+}
+
+func (c *Redis) XRead(ctx context.Context, streams []string, count int64, block time.Duration) ([]redis.XStream, error) {
+	return c.client.XRead(ctx, &redis.XReadArgs{
+		Streams: streams,
+		Count:   count,
+		Block:   block,
+	}).Result()
+}
+
+func (c *Redis) GetKey(ctx context.Context, key string) (string, error) {
+	value, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrMiss
+	}
+	return value, err
+}
+
+func (c *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+func (c *Redis) Decr(ctx context.Context, key string) (int64, error) {
+	return c.client.Decr(ctx, key).Result()
+}
+
+// XGroupCreate creates group on stream, and the stream when missing. It succeeds when the group already exists.
+func (c *Redis) XGroupCreate(ctx context.Context, stream, group, start string) error {
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup reads up to count entries of streams never delivered to group, waiting up to block for some.
+func (c *Redis) XReadGroup(ctx context.Context, group, consumer string, streams []string, count int64, block time.Duration) ([]redis.XStream, error) {
+	ids := make([]string, len(streams))
+	for i := range ids {
+		ids[i] = ">"
+	}
+	result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  append(append([]string(nil), streams...), ids...),
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return result, err
+}
+
+// XAutoClaim moves up to count entries of stream pending in group for longer than minIdle to consumer. It returns
+// them and the ID to start the next call from, "0-0" once the whole pending list was scanned.
+func (c *Redis) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]redis.XMessage, string, error) {
+	return c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    start,
+		Count:    count,
+	}).Result()
+}
+
+// XDeliveries returns how many times the pending entry id of stream was delivered to group, 0 once acknowledged.
+func (c *Redis) XDeliveries(ctx context.Context, stream, group, id string) (int64, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	return pending[0].RetryCount, nil
+}
+
+func (c *Redis) XAck(ctx context.Context, stream, group, id string) (int64, error) {
+	return c.client.XAck(ctx, stream, group, id).Result()
+}
+
+func (c *Redis) DeleteKey(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// SIsMember checks if a member is in a Redis set.
+func (c *Redis) SIsMember(ctx context.Context, setKey string, member interface{}) (bool, error) {
+	cmd := c.client.SIsMember(ctx, setKey, member)
+	return cmd.Result()
+}
+
+// SAdd adds a member to a Redis set.
+func (c *Redis) SAdd(ctx context.Context, setKey string, member interface{}) error {
+	cmd := c.client.SAdd(ctx, setKey, member)
+	_, err := cmd.Result()
+	return err
+}
+
+// SRem removes a member from a Redis set.
+func (c *Redis) SRem(ctx context.Context, setKey string, member interface{}) error {
+	cmd := c.client.SRem(ctx, setKey, member)
+	_, err := cmd.Result()
+	return err
+}
+
+// RunScript runs a Lua script, loading it into Redis the first time it is used.
+func (c *Redis) RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.Run(ctx, c.client, keys, args...).Result()
+}
+
+func (c *Redis) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return tagScript.Run(ctx, c.client, tagKeys(tags), key, ttl.Milliseconds()).Err()
+}
+
+func (c *Redis) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return invalidateTagsScript.Run(ctx, c.client, tagKeys(tags)).Err()
+}
+
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagPrefix + tag
+	}
+	return keys
+}
+
+func (c *Redis) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key = lockPrefix + key
+	ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLocked
+	}
+	return &redisLock{client: c.client, key: key, token: token}, nil
+}
+
+// lockToken tells the holder of a lock apart from whoever takes it after it expired.
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+func (l *redisLock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *redisLock) Unlock(ctx context.Context) error {
+	deleted, err := unlockScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (c *Redis) Publish(ctx context.Context, channel, message string) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe returns the messages of channel. The subscription reconnects by itself when Redis goes away.
+func (c *Redis) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	pubsub := c.client.Subscribe(ctx, channel)
+	// Wait for the confirmation so messages published once Subscribe returns are received
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		received := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-received:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
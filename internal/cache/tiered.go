@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// invalidationChannel carries the keys written through any instance so the others drop their local copy
+	invalidationChannel = "cache:invalidate"
+	// flushLocal asks every instance to drop its whole local tier
+	flushLocal = "*"
+	// resubscribeDelay paces the attempts to subscribe again after losing the invalidations
+	resubscribeDelay = time.Second
+)
+
+// Tiered keeps what GetKey reads from a shared cache in a small local tier for localTTL, sparing the round trip for
+// hot keys. Writes go to the shared cache and are broadcast so every instance drops its copy. A copy can still be
+// served for up to localTTL when an invalidation is missed, so keep localTTL to what a stale read costs.
+type Tiered struct {
+	Cache
+	local    *Memory
+	localTTL time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewTiered puts a local tier of at most maxEntries in front of remote. It subscribes to the invalidations before
+// returning so none is missed, unless remote is down in which case it keeps trying in the background.
+func NewTiered(remote Cache, localTTL time.Duration, maxEntries int) *Tiered {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Tiered{
+		Cache:    remote,
+		local:    newMemory(maxEntries),
+		localTTL: localTTL,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go c.listen(ctx, c.subscribe(ctx))
+	return c
+}
+
+func (c *Tiered) subscribe(ctx context.Context) <-chan string {
+	messages, err := c.Cache.Subscribe(ctx, invalidationChannel)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot subscribe to cache invalidations")
+		return nil
+	}
+	return messages
+}
+
+// listen drops the local copies of the keys written through other instances until ctx is done.
+func (c *Tiered) listen(ctx context.Context, messages <-chan string) {
+	defer close(c.done)
+	for {
+		if messages != nil {
+			for key := range messages {
+				if key == flushLocal {
+					c.local.flush()
+				} else {
+					c.local.DeleteKey(ctx, key)
+				}
+			}
+		}
+
+		// Invalidations may have been missed while not subscribed
+		c.local.flush()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+		messages = c.subscribe(ctx)
+	}
+}
+
+// invalidate drops the local copies of keys on every instance.
+func (c *Tiered) invalidate(ctx context.Context, keys ...string) {
+	c.local.DeleteKey(ctx, keys...)
+	c.broadcast(ctx, keys...)
+}
+
+func (c *Tiered) broadcast(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := c.Cache.Publish(ctx, invalidationChannel, key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("cannot broadcast cache invalidation")
+		}
+	}
+}
+
+func (c *Tiered) GetKey(ctx context.Context, key string) (string, error) {
+	if value, err := c.local.GetKey(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, err := c.Cache.GetKey(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	c.local.SetKey(ctx, key, value, c.localTTL)
+	return value, nil
+}
+
+func (c *Tiered) SetKey(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	err := c.Cache.SetKey(ctx, key, value, ttl)
+	c.invalidate(ctx, key)
+	return err
+}
+
+func (c *Tiered) DeleteKey(ctx context.Context, keys ...string) error {
+	err := c.Cache.DeleteKey(ctx, keys...)
+	c.invalidate(ctx, keys...)
+	return err
+}
+
+func (c *Tiered) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := c.Cache.Incr(ctx, key)
+	c.invalidate(ctx, key)
+	return n, err
+}
+
+func (c *Tiered) Decr(ctx context.Context, key string) (int64, error) {
+	n, err := c.Cache.Decr(ctx, key)
+	c.invalidate(ctx, key)
+	return n, err
+}
+
+// InvalidateTags drops the whole local tier of every instance, which doesn't know the keys of the tags.
+func (c *Tiered) InvalidateTags(ctx context.Context, tags ...string) error {
+	err := c.Cache.InvalidateTags(ctx, tags...)
+	c.local.flush()
+	c.broadcast(ctx, flushLocal)
+	return err
+}
+
+// RunScript runs script on the shared cache.
+func (c *Tiered) RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	scripter, ok := c.Cache.(Scripter)
+	if !ok {
+		return nil, errors.New("cache: the shared cache can't run scripts")
+	}
+	return scripter.RunScript(ctx, script, keys, args...)
+}
+
+// Close stops listening for invalidations and closes the shared cache.
+func (c *Tiered) Close() error {
+	c.cancel()
+	<-c.done
+	return c.Cache.Close()
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredInvalidatesEveryInstance(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemory()
+	a := NewTiered(remote, time.Minute, 100)
+	b := NewTiered(remote, time.Minute, 100)
+	defer a.Close()
+	defer b.Close()
+
+	require.NoError(t, a.SetKey(ctx, "menu:1", "v1", time.Hour))
+	value, err := b.GetKey(ctx, "menu:1")
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+
+	// Writing behind the tiers' back leaves b on its local copy
+	require.NoError(t, remote.SetKey(ctx, "menu:1", "v2", time.Hour))
+	value, err = b.GetKey(ctx, "menu:1")
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+
+	require.NoError(t, a.SetKey(ctx, "menu:1", "v3", time.Hour))
+	require.Eventually(t, func() bool {
+		value, err := b.GetKey(ctx, "menu:1")
+		return err == nil && value == "v3"
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.Tag(ctx, "menu:1", time.Hour, "restaurant:1"))
+	require.NoError(t, a.InvalidateTags(ctx, "restaurant:1"))
+	require.Eventually(t, func() bool {
+		_, err := b.GetKey(ctx, "menu:1")
+		return err == ErrMiss
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/events"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/outbox"
+	"github.com/steve-mir/bukka_backend/internal/platform/health"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/internal/platform/migrations"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"github.com/steve-mir/bukka_backend/internal/rabbitmq"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/steve-mir/bukka_backend/worker"
+)
+
+// deps are the connections shared by everything running in the process.
+type deps struct {
+	config          utils.Config
+	configWatcher   *utils.ConfigWatcher
+	connPool        *pgxpool.Pool
+	replicaPools    []*pgxpool.Pool
+	router          *sqlc.Router
+	store           sqlc.Store
+	cache           cache.Cache
+	publisher       *events.Publisher
+	rabbitmq        *rabbitmq.Publisher
+	redisOpt        asynq.RedisClientOpt
+	taskDistributor worker.TaskDistributor
+	inspector       *asynq.Inspector
+	poolCollector   *metrics.PoolCollector
+	health          *health.Checker
+}
+
+func newDeps(config utils.Config, configWatcher *utils.ConfigWatcher) (*deps, error) {
+	connPool, err := sqlc.CreateDbPool(config)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaPools, err := sqlc.CreateReplicaPools(config)
+	if err != nil {
+		connPool.Close()
+		return nil, err
+	}
+
+	var router *sqlc.Router
+	store := sqlc.NewStore(connPool)
+	if len(replicaPools) > 0 {
+		router = sqlc.NewRouter(connPool, replicaPools, sqlc.ReplicaOptions{
+			MaxLag:               config.DBReplicaMaxLag,
+			CheckInterval:        config.DBReplicaCheckInterval,
+			ReadYourWritesWindow: config.DBReadYourWritesWindow,
+		})
+		store = sqlc.NewRoutedStore(router)
+	}
+
+	redisOpt := asynq.RedisClientOpt{
+		Addr:     config.RedisAddress,
+		Username: config.RedisUsername,
+		Password: config.RedisPwd,
+	}
+
+	redis := cache.NewRedis(config.RedisAddress, config.RedisUsername, config.RedisPwd, 0)
+
+	// Scrapes read the pool counters, DB_STATS_INTERVAL only adds them to the logs
+	poolCollector := metrics.NewPoolCollector(connPool, replicaPools)
+	metrics.Registry.MustRegister(poolCollector)
+
+	d := &deps{
+		config:          config,
+		configWatcher:   configWatcher,
+		connPool:        connPool,
+		replicaPools:    replicaPools,
+		router:          router,
+		store:           store,
+		cache:           newCache(config, redis),
+		publisher:       events.NewPublisher(redis, config.EventsStreamMaxLen),
+		redisOpt:        redisOpt,
+		taskDistributor: worker.NewRedisTaskDistributor(redisOpt),
+		inspector:       asynq.NewInspector(redisOpt),
+		poolCollector:   poolCollector,
+		health:          health.New(config.HealthCheckTimeout),
+	}
+
+	if config.RabbitMQURL != "" {
+		d.rabbitmq = rabbitmq.NewPublisher(config.RabbitMQURL)
+	}
+
+	d.health.Add("postgres", connPool.Ping)
+	// Reads fall back to the primary, a replica being down doesn't make the process unready
+	for i, pool := range replicaPools {
+		d.health.AddOptional(fmt.Sprintf("postgres_replica_%d", i), pool.Ping)
+	}
+	d.health.Add("redis", d.cache.Ping)
+	return d, nil
+}
+
+// checkTasks makes readiness depend on the task processor of this process when local is set, on any running one
+// otherwise.
+func (d *deps) checkTasks(local bool) {
+	d.health.Add("asynq", worker.ServersCheck(d.inspector, local))
+}
+
+// drain returns a context done delay after ctx. Readiness fails as soon as ctx is done, so load balancers stop
+// sending requests while the servers still answer them.
+func (d *deps) drain(ctx context.Context, delay time.Duration) context.Context {
+	drained, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		d.health.Drain()
+		if delay > 0 {
+			log.Info().Msgf("draining for %s before shutting down", delay)
+			time.Sleep(delay)
+		}
+		cancel()
+	}()
+	return drained
+}
+
+// tracingFlushTimeout bounds how long exiting waits for the spans left to be exported.
+const tracingFlushTimeout = 5 * time.Second
+
+// startTracing installs tracing for the process as serviceName, the returned func flushes the spans left.
+func startTracing(ctx context.Context, config utils.Config, serviceName string) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, config, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up tracing: %w", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
+	}, nil
+}
+
+// prepareSchema migrates the database when AUTO_MIGRATE is set and refuses to go on when the schema is dirty or
+// newer than this build.
+func (d *deps) prepareSchema(ctx context.Context) error {
+	if d.config.AutoMigrate {
+		if err := migrations.AutoMigrate(ctx, d.connPool); err != nil {
+			return fmt.Errorf("cannot migrate database: %w", err)
+		}
+	}
+	if err := migrations.CheckSchema(d.connPool); err != nil {
+		return fmt.Errorf("refusing to start: %w", err)
+	}
+	return nil
+}
+
+// monitorDB watches replica lag and logs the db pool counters every DB_STATS_INTERVAL, when set, until ctx is done.
+func (d *deps) monitorDB(ctx context.Context) {
+	if d.router != nil {
+		go d.router.Monitor(ctx)
+	}
+	if d.config.DBStatsInterval > 0 {
+		go sqlc.ReportPoolStats(ctx, d.connPool, d.config.DBStatsInterval)
+	}
+}
+
+// watchConfig reloads the config when its files change until ctx is done.
+func (d *deps) watchConfig(ctx context.Context) {
+	if err := d.configWatcher.Run(ctx); err != nil {
+		log.Warn().Err(err).Msg("config changes will need a restart")
+	}
+}
+
+// newCache puts redis behind a local tier when CACHE_LOCAL_TTL is set.
+func newCache(config utils.Config, redis *cache.Redis) cache.Cache {
+	if config.CacheLocalTTL == 0 {
+		return redis
+	}
+	return cache.NewTiered(redis, config.CacheLocalTTL, config.CacheLocalMaxEntries)
+}
+
+func (d *deps) newTaskProcessor() worker.TaskProcessor {
+	return worker.NewRedisTaskProcessor(d.redisOpt, d.store, d.config, featureflag.NewClient(d.store, d.cache))
+}
+
+// runRelay sends the outbox to the task queues and event streams until ctx is done, the returned channel is closed
+// once the relay stopped. It runs wherever tasks are processed.
+func (d *deps) runRelay(ctx context.Context) <-chan struct{} {
+	var messages outbox.Messages
+	if d.rabbitmq != nil {
+		messages = d.rabbitmq
+	}
+	relay := outbox.NewRelay(d.store, d.taskDistributor, d.publisher, messages, outbox.RelayOptions{
+		PollInterval: d.config.OutboxPollInterval,
+		BatchSize:    d.config.OutboxBatchSize,
+		MaxAttempts:  d.config.OutboxMaxAttempts,
+		Retention:    d.config.OutboxRetention,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+	return done
+}
+
+// close releases the connections once nothing uses them anymore.
+func (d *deps) close() {
+	metrics.Registry.Unregister(d.poolCollector)
+	err := errors.Join(d.taskDistributor.Close(), d.inspector.Close(), d.cache.Close())
+	if d.rabbitmq != nil {
+		err = errors.Join(err, d.rabbitmq.Close())
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to close connections")
+	}
+	for _, pool := range d.replicaPools {
+		pool.Close()
+	}
+	d.connPool.Close()
+}
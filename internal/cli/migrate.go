@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/migrations"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+func newMigrateCommand(loadConfig func() (utils.Config, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema with the migrations built into the binary",
+	}
+
+	// withMigrator opens the database for one subcommand and closes it when run returns.
+	withMigrator := func(run func(m *migrations.Migrator) error) error {
+		config, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("cannot load config: %w", err)
+		}
+
+		connPool, err := sqlc.CreateDbPool(config)
+		if err != nil {
+			return err
+		}
+		defer connPool.Close()
+
+		m, err := migrations.New(connPool)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return run(m)
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withMigrator(func(m *migrations.Migrator) error {
+					if err := m.Up(); err != nil {
+						return fmt.Errorf("failed to run migrate up: %w", err)
+					}
+					log.Info().Msg("db migrated successfully")
+					return nil
+				})
+			},
+		},
+		newMigrateDownCommand(withMigrator),
+		&cobra.Command{
+			Use:   "to VERSION",
+			Short: "Migrate up or down to VERSION",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := strconv.ParseUint(args[0], 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				return withMigrator(func(m *migrations.Migrator) error {
+					if err := m.To(uint(version)); err != nil {
+						return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+					}
+					log.Info().Msgf("db migrated to version %d", version)
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Print the applied and latest schema versions",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withMigrator(func(m *migrations.Migrator) error {
+					status, err := m.Status()
+					if err != nil {
+						return err
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), status)
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "force VERSION",
+			Short: "Set the schema version and clear the dirty flag without running migrations",
+			Long: "Set the schema version and clear the dirty flag without running migrations. Use it after fixing a failed migration by hand, " +
+				"none means no version, as does -1 passed after --.",
+			Example: "  migrate force 11\n  migrate force none",
+			Args:    cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := parseForceVersion(args[0])
+				if err != nil {
+					return err
+				}
+				return withMigrator(func(m *migrations.Migrator) error {
+					if err := m.Force(version); err != nil {
+						return fmt.Errorf("failed to force version %d: %w", version, err)
+					}
+					log.Info().Msgf("db version forced to %d", version)
+					return nil
+				})
+			},
+		},
+	)
+	return cmd
+}
+
+// parseForceVersion reads the VERSION of migrate force, "none" and -1 mean no version.
+func parseForceVersion(arg string) (int, error) {
+	if arg == "none" {
+		return -1, nil
+	}
+	version, err := strconv.Atoi(arg)
+	if err != nil || version < -1 {
+		return 0, fmt.Errorf("invalid version %q", arg)
+	}
+	return version, nil
+}
+
+func newMigrateDownCommand(withMigrator func(run func(m *migrations.Migrator) error) error) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "down [N]",
+		Short: "Revert the last N migrations, one by default",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps := 1
+			if len(args) == 1 {
+				if all {
+					return fmt.Errorf("cannot combine --all with a number of steps")
+				}
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid number of steps %q", args[0])
+				}
+				steps = n
+			}
+			return withMigrator(func(m *migrations.Migrator) error {
+				if all {
+					if err := m.DownAll(); err != nil {
+						return fmt.Errorf("failed to run migrate down: %w", err)
+					}
+					log.Info().Msg("reverted all migrations")
+					return nil
+				}
+				if err := m.Down(steps); err != nil {
+					return fmt.Errorf("failed to run migrate down: %w", err)
+				}
+				log.Info().Msgf("reverted %d migration(s)", steps)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "revert every applied migration")
+	return cmd
+}
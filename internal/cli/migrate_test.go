@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateForceVersion(t *testing.T) {
+	errNoConfig := errors.New("no config")
+	force := func(args ...string) error {
+		cmd := newMigrateCommand(func() (utils.Config, error) { return utils.Config{}, errNoConfig })
+		cmd.SetArgs(append([]string{"force"}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		return cmd.Execute()
+	}
+
+	// Versions that parse get as far as loading the config
+	assert.ErrorIs(t, force("11"), errNoConfig)
+	assert.ErrorIs(t, force("none"), errNoConfig)
+	assert.ErrorIs(t, force("--", "-1"), errNoConfig)
+
+	assert.EqualError(t, force("-2"), `unknown shorthand flag: '2' in -2`)
+	assert.EqualError(t, force("--", "-2"), `invalid version "-2"`)
+	assert.EqualError(t, force("latest"), `invalid version "latest"`)
+}
@@ -0,0 +1,54 @@
+// Package cli is the bukka binary. Every service, the task worker and the migrations run from it as
+// subcommands, so a deployment only ships one executable.
+package cli
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+// configSource is the directory the commands read app.env and its profiles from.
+type configSource struct {
+	path string
+}
+
+// load reads and validates the config, then sets up logging with it.
+func (s *configSource) load() (utils.Config, error) {
+	config, err := utils.LoadConfig(s.path)
+	if err != nil {
+		return config, err
+	}
+	return config, logging.Setup(config)
+}
+
+// watcher follows the changes to the config loaded as config. The log level applies as soon as it is reloaded.
+func (s *configSource) watcher(config utils.Config) *utils.ConfigWatcher {
+	watcher := utils.NewConfigWatcher(s.path, config)
+	watcher.OnReload(func(config utils.Config) {
+		if err := logging.SetLevel(config); err != nil {
+			log.Error().Err(err).Msg("keeping the current log level")
+		}
+	})
+	return watcher
+}
+
+func NewRootCommand() *cobra.Command {
+	source := &configSource{}
+
+	root := &cobra.Command{
+		Use:           "bukka",
+		Short:         "Bukka backend services",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&source.path, "config", ".", "directory containing app.env and its profiles")
+
+	root.AddCommand(
+		newServeCommand(source),
+		newWorkerCommand(source),
+		newMigrateCommand(source.load),
+	)
+	return root
+}
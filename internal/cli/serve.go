@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	authapi "github.com/steve-mir/bukka_backend/internal/app/auth/api"
+	menuapi "github.com/steve-mir/bukka_backend/internal/app/menu/api"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// service is an HTTP service that can be served by the binary.
+type service struct {
+	name    string
+	address func(config utils.Config) string
+	handler func(d *deps) http.Handler
+}
+
+var services = []service{
+	{
+		name:    "auth",
+		address: func(config utils.Config) string { return config.HTTPAuthServerAddress },
+		handler: func(d *deps) http.Handler {
+			return authapi.NewServer(d.store, d.configWatcher, d.taskDistributor, d.cache).Router
+		},
+	},
+	{
+		name:    "menu",
+		address: func(config utils.Config) string { return config.HTTPMenuServerAddress },
+		handler: func(d *deps) http.Handler {
+			return menuapi.NewServer(d.store, d.configWatcher, d.taskDistributor, d.cache).Router
+		},
+	},
+}
+
+func serviceNames() []string {
+	names := make([]string, 0, len(services))
+	for _, s := range services {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// selectServices resolves the names given to serve, "all" picks every service.
+func selectServices(names []string) ([]service, error) {
+	var selected []service
+	seen := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, s := range services {
+			if name == "all" || name == s.name {
+				found = true
+				if !seen[s.name] {
+					seen[s.name] = true
+					selected = append(selected, s)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown service %q, expected one of all, %s", name, strings.Join(serviceNames(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+func newServeCommand(source *configSource) *cobra.Command {
+	var withWorker bool
+
+	cmd := &cobra.Command{
+		Use:       "serve [all|" + strings.Join(serviceNames(), "|") + "]...",
+		Short:     "Serve HTTP services in this process",
+		Long:      "Serve one or more HTTP services. They share the database pool, the Redis client and, unless --worker=false, one task processor.",
+		Args:      cobra.MinimumNArgs(1),
+		ValidArgs: append([]string{"all"}, serviceNames()...),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selected, err := selectServices(args)
+			if err != nil {
+				return err
+			}
+
+			config, err := source.load()
+			if err != nil {
+				return fmt.Errorf("cannot load config: %w", err)
+			}
+
+			stopTracing, err := startTracing(cmd.Context(), config, "bukka-serve")
+			if err != nil {
+				return err
+			}
+			defer stopTracing()
+
+			d, err := newDeps(config, source.watcher(config))
+			if err != nil {
+				return err
+			}
+			defer d.close()
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := d.prepareSchema(ctx); err != nil {
+				return err
+			}
+			d.monitorDB(ctx)
+			go d.watchConfig(ctx)
+
+			return serve(ctx, d, selected, withWorker)
+		},
+	}
+	cmd.Flags().BoolVar(&withWorker, "worker", true, "also process background tasks in this process")
+	return cmd
+}
+
+// serve runs the services until ctx is done or one of them fails, which stops the others too.
+func serve(ctx context.Context, d *deps, selected []service, withWorker bool) error {
+	d.checkTasks(withWorker)
+	if withWorker {
+		processor := d.newTaskProcessor()
+		if err := processor.Start(); err != nil {
+			return fmt.Errorf("cannot start task processor: %w", err)
+		}
+		// Runs after the servers are done so tasks they enqueued while draining still get picked up
+		defer processor.Shutdown()
+		// Stopped when serve returns too, which is before ctx is done when a service failed
+		relayCtx, stopRelay := context.WithCancel(ctx)
+		relayStopped := d.runRelay(relayCtx)
+		defer func() {
+			stopRelay()
+			<-relayStopped
+		}()
+	}
+
+	timeouts := httpserver.TimeoutsFromConfig(d.config)
+	group, ctx := errgroup.WithContext(ctx)
+	serveCtx := d.drain(ctx, timeouts.Drain)
+	for _, s := range selected {
+		server := httpserver.NewServer(s.address(d.config), d.health.Wrap(s.handler(d)), timeouts)
+		name := s.name
+		group.Go(func() error {
+			log.Info().Msgf("starting %s service", name)
+			if err := httpserver.Run(serveCtx, server, timeouts.Shutdown); err != nil {
+				return fmt.Errorf("%s service: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	log.Info().Msg("all services stopped")
+	return err
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectServices(t *testing.T) {
+	names := func(selected []service) []string {
+		var out []string
+		for _, s := range selected {
+			out = append(out, s.name)
+		}
+		return out
+	}
+
+	selected, err := selectServices([]string{"menu"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"menu"}, names(selected))
+
+	selected, err = selectServices([]string{"auth", "all"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "menu"}, names(selected))
+
+	_, err = selectServices([]string{"orders"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/steve-mir/bukka_backend/internal/platform/health"
+	"github.com/steve-mir/bukka_backend/internal/platform/httpserver"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+func newWorkerCommand(source *configSource) *cobra.Command {
+	return &cobra.Command{
+		Use:   "worker",
+		Short: "Process background tasks without serving HTTP",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := source.load()
+			if err != nil {
+				return fmt.Errorf("cannot load config: %w", err)
+			}
+
+			stopTracing, err := startTracing(cmd.Context(), config, "bukka-worker")
+			if err != nil {
+				return err
+			}
+			defer stopTracing()
+
+			d, err := newDeps(config, source.watcher(config))
+			if err != nil {
+				return err
+			}
+			defer d.close()
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := d.prepareSchema(ctx); err != nil {
+				return err
+			}
+			d.monitorDB(ctx)
+			go d.watchConfig(ctx)
+			d.checkTasks(true)
+			if config.WorkerMetricsAddress != "" {
+				go serveWorkerMetrics(ctx, config, d.health)
+			}
+
+			processor := d.newTaskProcessor()
+			if err := processor.Start(); err != nil {
+				return fmt.Errorf("cannot start task processor: %w", err)
+			}
+			relayStopped := d.runRelay(ctx)
+
+			<-ctx.Done()
+			d.health.Drain()
+			log.Info().Msg("shutting down task processor")
+			<-relayStopped
+			processor.Shutdown()
+			return nil
+		},
+	}
+}
+
+// serveWorkerMetrics exposes the metrics and health probes of a worker process on WORKER_METRICS_ADDRESS until ctx
+// is done, the services serve theirs next to their routes.
+func serveWorkerMetrics(ctx context.Context, config utils.Config, checker *health.Checker) {
+	mux := http.NewServeMux()
+	mux.Handle(httpserver.MetricsPath, metrics.Handler())
+	mux.Handle(health.LivenessPath, checker.LivenessHandler())
+	mux.Handle(health.ReadinessPath, checker.ReadinessHandler())
+
+	timeouts := httpserver.TimeoutsFromConfig(config)
+	server := httpserver.NewServer(config.WorkerMetricsAddress, mux, timeouts)
+	if err := httpserver.Run(ctx, server, timeouts.Shutdown); err != nil {
+		log.Error().Err(err).Msg("worker metrics server failed")
+	}
+}
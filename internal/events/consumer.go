@@ -0,0 +1,329 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler handles an event, returning an error to have it retried.
+type Handler func(ctx context.Context, event Event) error
+
+// ConsumerOptions configures a Consumer, zero values take the defaults.
+type ConsumerOptions struct {
+	// Group is the consumer group, every instance of a service shares one so each event is handled once per service
+	Group string
+	// Name tells the consumers of the group apart, e.g. the host name
+	Name string
+	// Concurrency bounds the events handled at the same time, 10 by default
+	Concurrency int
+	// Block is how long a read waits for new events, and so how long stopping may wait for it, 2s by default
+	Block time.Duration
+	// ClaimIdle is how long an event stays unacknowledged before it is taken from the consumer it was delivered
+	// to, which must have stopped, 1m by default. It must exceed the time handling an event takes.
+	ClaimIdle time.Duration
+	// MaxAttempts is how many times a delivery is handled before the event is parked, 5 by default
+	MaxAttempts int
+	// MaxDeliveries parks events delivered that many times without being acknowledged, such as events crashing
+	// their consumer, 3 by default
+	MaxDeliveries int64
+	// Backoff is the wait before attempt+1, 100ms doubling up to 10s by default
+	Backoff func(attempt int) time.Duration
+}
+
+func (o *ConsumerOptions) setDefaults() {
+	if o.Concurrency <= 0 {
+		o.Concurrency = 10
+	}
+	if o.Block <= 0 {
+		o.Block = 2 * time.Second
+	}
+	if o.ClaimIdle <= 0 {
+		o.ClaimIdle = time.Minute
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.MaxDeliveries <= 0 {
+		o.MaxDeliveries = 3
+	}
+	if o.Backoff == nil {
+		o.Backoff = defaultBackoff
+	}
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	backoff := 100 * time.Millisecond
+	for i := 1; i < attempt && backoff < 10*time.Second; i++ {
+		backoff *= 2
+	}
+	if backoff > 10*time.Second {
+		backoff = 10 * time.Second
+	}
+	return backoff
+}
+
+// Consumer handles the events of the streams it has handlers for. Failed attempts are retried with backoff, then
+// the event is parked in the dead letter stream of its stream where it can be inspected and replayed.
+type Consumer struct {
+	streams  Streams
+	options  ConsumerOptions
+	handlers map[string]Handler
+	order    []string
+}
+
+func NewConsumer(streams Streams, options ConsumerOptions) *Consumer {
+	options.setDefaults()
+	return &Consumer{streams: streams, options: options, handlers: make(map[string]Handler)}
+}
+
+// Handle makes handler handle the events of stream. Call it before Run.
+func (c *Consumer) Handle(stream string, handler Handler) {
+	if _, ok := c.handlers[stream]; !ok {
+		c.order = append(c.order, stream)
+	}
+	c.handlers[stream] = handler
+}
+
+// Run handles events until ctx is done, then waits for the handlers running. Events they didn't get to acknowledge
+// are handled again once ClaimIdle has passed.
+func (c *Consumer) Run(ctx context.Context) error {
+	if len(c.handlers) == 0 {
+		return nil
+	}
+	for _, stream := range c.order {
+		// Starting from the beginning so events published before the group existed are handled too
+		if err := c.streams.XGroupCreate(ctx, stream, c.options.Group, "0"); err != nil {
+			return fmt.Errorf("cannot create group %s on %s: %w", c.options.Group, stream, err)
+		}
+	}
+
+	slots := make(chan struct{}, c.options.Concurrency)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	dispatch := func(stream string, msg redis.XMessage, deliveries int64) {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			defer func() { <-slots }()
+			c.process(ctx, stream, msg, deliveries)
+		}()
+	}
+
+	claimed := make(chan struct{})
+	go func() {
+		defer close(claimed)
+		c.reclaim(ctx, slots, dispatch)
+	}()
+	defer func() { <-claimed }()
+
+	for {
+		count, ok := acquire(ctx, slots)
+		if !ok {
+			return nil
+		}
+
+		result, err := c.streams.XReadGroup(ctx, c.options.Group, c.options.Name, c.order, int64(count), c.options.Block)
+		if err != nil {
+			release(slots, count)
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error().Err(err).Msg("cannot read events")
+			if !sleep(ctx, c.options.Block) {
+				return nil
+			}
+			continue
+		}
+
+		for _, stream := range result {
+			for _, msg := range stream.Messages {
+				dispatch(stream.Stream, msg, 1)
+				count--
+			}
+		}
+		release(slots, count)
+	}
+}
+
+// reclaim takes over the events left unacknowledged by consumers that stopped, every ClaimIdle until ctx is done.
+func (c *Consumer) reclaim(ctx context.Context, slots chan struct{}, dispatch func(string, redis.XMessage, int64)) {
+	for sleep(ctx, c.options.ClaimIdle/2) {
+		for _, stream := range c.order {
+			start := "0-0"
+			for {
+				count, ok := acquire(ctx, slots)
+				if !ok {
+					return
+				}
+				messages, next, err := c.streams.XAutoClaim(ctx, stream, c.options.Group, c.options.Name, c.options.ClaimIdle, start, int64(count))
+				if err != nil {
+					release(slots, count)
+					log.Error().Err(err).Str("stream", stream).Msg("cannot claim pending events")
+					break
+				}
+
+				for _, msg := range messages {
+					deliveries, err := c.streams.XDeliveries(ctx, stream, c.options.Group, msg.ID)
+					if err != nil {
+						log.Error().Err(err).Str("stream", stream).Msg("cannot count deliveries of event")
+					}
+					dispatch(stream, msg, deliveries)
+					count--
+				}
+				release(slots, count)
+				if next == "0-0" || next == "" {
+					break
+				}
+				start = next
+			}
+		}
+	}
+}
+
+// process handles msg until it succeeds or is parked. Stopping while waiting to retry leaves it to be reclaimed.
+func (c *Consumer) process(stopCtx context.Context, stream string, msg redis.XMessage, deliveries int64) {
+	// Handlers finish what they started even when stopping
+	ctx := context.Background()
+
+	event, err := parseEvent(stream, msg, deliveries)
+	if err != nil {
+		c.park(ctx, event, err)
+		return
+	}
+	if event.Deliveries > c.options.MaxDeliveries {
+		c.park(ctx, event, fmt.Errorf("delivered %d times without being acknowledged", event.Deliveries))
+		return
+	}
+
+	handler := c.handlers[stream]
+	for attempt := 1; ; attempt++ {
+		err = c.handle(ctx, handler, event, attempt)
+		if err == nil {
+			if _, err := c.streams.XAck(ctx, stream, c.options.Group, event.ID); err != nil {
+				logging.FromContext(ctx).Error().Err(err).Str("event_id", event.ID).Msg("cannot acknowledge event")
+			}
+			return
+		}
+		if attempt >= c.options.MaxAttempts {
+			c.park(ctx, event, err)
+			return
+		}
+		if !sleep(stopCtx, c.options.Backoff(attempt)) {
+			return
+		}
+	}
+}
+
+// handle runs handler on event in a consumer span continuing the trace of its publisher.
+func (c *Consumer) handle(ctx context.Context, handler Handler, event Event, attempt int) (err error) {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, event.trace), "process "+event.Stream,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingRedis, semconv.MessagingOperationDeliver, semconv.MessagingDestinationName(event.Stream), semconv.MessagingMessageID(event.ID)),
+	)
+	ctx = eventLogger(ctx, event)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			logging.FromContext(ctx).Error().Str("stack", string(debug.Stack())).Msg("event handler panicked")
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logging.FromContext(ctx).Warn().Err(err).Int("attempt", attempt).Msg("event handler failed")
+		}
+		metrics.ObserveEvent(event.Stream, err, time.Since(start))
+		span.End()
+	}()
+	return handler(ctx, event)
+}
+
+// park moves event to the dead letter stream of its stream with why it was given up on, then acknowledges it.
+// When that fails the event stays pending and is parked again once reclaimed.
+func (c *Consumer) park(ctx context.Context, event Event, cause error) {
+	ctx = eventLogger(ctx, event)
+	values := map[string]interface{}{
+		fieldType:      event.Type,
+		fieldPayload:   []byte(event.Payload),
+		fieldRequestID: event.requestID,
+		"event_id":     event.ID,
+		"group":        c.options.Group,
+		"error":        cause.Error(),
+		"deliveries":   event.Deliveries,
+		"parked_at":    time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if _, err := c.streams.XAdd(ctx, event.Stream+deadLetterSuffix, 0, values); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("cannot park event")
+		return
+	}
+	if _, err := c.streams.XAck(ctx, event.Stream, c.options.Group, event.ID); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("cannot acknowledge parked event")
+	}
+	metrics.EventParked(event.Stream)
+	logging.FromContext(ctx).Error().Err(cause).Msg("event parked")
+}
+
+// eventLogger returns ctx whose logger is tagged with event and the request that published it.
+func eventLogger(ctx context.Context, event Event) context.Context {
+	return logging.With(ctx, func(logCtx zerolog.Context) zerolog.Context {
+		logCtx = logCtx.Str("event_stream", event.Stream).Str("event_id", event.ID).Str("event_type", event.Type)
+		if event.requestID != "" {
+			logCtx = logCtx.Str("request_id", event.requestID)
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logCtx = logCtx.Str("trace_id", span.TraceID().String())
+		}
+		return logCtx
+	})
+}
+
+// acquire waits for a free slot then takes the other free ones, it returns how many it took.
+func acquire(ctx context.Context, slots chan struct{}) (int, bool) {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, false
+	}
+	count := 1
+	for count < cap(slots) {
+		select {
+		case slots <- struct{}{}:
+			count++
+		default:
+			return count, true
+		}
+	}
+	return count, true
+}
+
+func release(slots chan struct{}, count int) {
+	for i := 0; i < count; i++ {
+		<-slots
+	}
+}
+
+// sleep waits for d, it returns false when ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/stretchr/testify/require"
+)
+
+type userRegistered struct {
+	UserID string `json:"user_id"`
+}
+
+func newTestStreams(t *testing.T) (*cache.Redis, *redis.Client) {
+	server := miniredis.RunT(t)
+	streams := cache.NewRedis(server.Addr(), "", "", 0)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		streams.Close()
+		client.Close()
+	})
+	return streams, client
+}
+
+// run runs consumer until the test ends.
+func run(t *testing.T, consumer *Consumer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- consumer.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestConsumerHandlesEvents(t *testing.T) {
+	streams, client := newTestStreams(t)
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+
+	// Published before the group exists, still handled
+	_, err := NewPublisher(streams, 1000).Publish(ctx, StreamUsers, UserRegistered, userRegistered{UserID: "u1"})
+	require.NoError(t, err)
+
+	received := make(chan Event, 1)
+	consumer := NewConsumer(streams, ConsumerOptions{Group: "notifications", Name: "test", Block: 50 * time.Millisecond})
+	consumer.Handle(StreamUsers, func(ctx context.Context, event Event) error {
+		received <- event
+		return nil
+	})
+	run(t, consumer)
+
+	event := <-received
+	require.Equal(t, UserRegistered, event.Type)
+	require.Equal(t, "req-1", event.requestID)
+	require.Equal(t, int64(1), event.Deliveries)
+	var payload userRegistered
+	require.NoError(t, event.Decode(&payload))
+	require.Equal(t, "u1", payload.UserID)
+
+	require.Eventually(t, func() bool {
+		pending, err := client.XPending(context.Background(), StreamUsers, "notifications").Result()
+		return err == nil && pending.Count == 0
+	}, time.Second, 10*time.Millisecond, "handled events are acknowledged")
+}
+
+func TestConsumerRetriesThenParks(t *testing.T) {
+	streams, client := newTestStreams(t)
+	ctx := context.Background()
+
+	var attempts int32
+	consumer := NewConsumer(streams, ConsumerOptions{Group: "notifications", Name: "test", Block: 50 * time.Millisecond, MaxAttempts: 3, Backoff: noBackoff})
+	consumer.Handle(StreamUsers, func(ctx context.Context, event Event) error {
+		if atomic.AddInt32(&attempts, 1) == 2 {
+			panic("handler bug")
+		}
+		return errors.New("smtp down")
+	})
+	run(t, consumer)
+
+	publisher := NewPublisher(streams, 1000)
+	id, err := publisher.Publish(ctx, StreamUsers, UserRegistered, userRegistered{UserID: "u1"})
+	require.NoError(t, err)
+	// Malformed entries are parked without reaching the handler
+	_, err = client.XAdd(ctx, &redis.XAddArgs{Stream: StreamUsers, Values: map[string]interface{}{"payload": "{}"}}).Result()
+	require.NoError(t, err)
+
+	var parked []redis.XMessage
+	require.Eventually(t, func() bool {
+		parked, err = client.XRange(ctx, StreamUsers+deadLetterSuffix, "-", "+").Result()
+		return err == nil && len(parked) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Events are processed concurrently, the malformed one can be parked first
+	if parked[0].Values["event_id"] != id {
+		parked[0], parked[1] = parked[1], parked[0]
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	require.Equal(t, id, parked[0].Values["event_id"])
+	require.Equal(t, UserRegistered, parked[0].Values["type"])
+	require.Equal(t, `{"user_id":"u1"}`, parked[0].Values["payload"])
+	require.Equal(t, "smtp down", parked[0].Values["error"])
+	require.Equal(t, "event "+parked[1].Values["event_id"].(string)+" has no type", parked[1].Values["error"])
+
+	require.Eventually(t, func() bool {
+		pending, err := client.XPending(ctx, StreamUsers, "notifications").Result()
+		return err == nil && pending.Count == 0
+	}, time.Second, 10*time.Millisecond, "parked events are acknowledged")
+}
+
+func TestConsumerReclaimsEventsOfStoppedConsumers(t *testing.T) {
+	streams, client := newTestStreams(t)
+	ctx := context.Background()
+
+	publisher := NewPublisher(streams, 1000)
+	redelivered, err := publisher.Publish(ctx, StreamUsers, UserRegistered, userRegistered{UserID: "u1"})
+	require.NoError(t, err)
+	poison, err := publisher.Publish(ctx, StreamUsers, UserRegistered, userRegistered{UserID: "u2"})
+	require.NoError(t, err)
+
+	// Another consumer read both events then stopped, and u2 was already delivered too often
+	require.NoError(t, streams.XGroupCreate(ctx, StreamUsers, "notifications", "0"))
+	_, err = streams.XReadGroup(ctx, "notifications", "stopped", []string{StreamUsers}, 10, time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, client.XClaim(ctx, &redis.XClaimArgs{Stream: StreamUsers, Group: "notifications", Consumer: "stopped", Messages: []string{poison}}).Err())
+	require.NoError(t, client.XClaim(ctx, &redis.XClaimArgs{Stream: StreamUsers, Group: "notifications", Consumer: "stopped", Messages: []string{poison}}).Err())
+
+	received := make(chan Event, 2)
+	consumer := NewConsumer(streams, ConsumerOptions{Group: "notifications", Name: "test", Block: 50 * time.Millisecond, ClaimIdle: 100 * time.Millisecond})
+	consumer.Handle(StreamUsers, func(ctx context.Context, event Event) error {
+		received <- event
+		return nil
+	})
+	run(t, consumer)
+
+	select {
+	case event := <-received:
+		require.Equal(t, redelivered, event.ID)
+		require.Equal(t, int64(2), event.Deliveries)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the event was not reclaimed")
+	}
+
+	require.Eventually(t, func() bool {
+		parked, err := client.XRange(ctx, StreamUsers+deadLetterSuffix, "-", "+").Result()
+		return err == nil && len(parked) == 1 && parked[0].Values["event_id"] == poison
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Empty(t, received)
+}
+
+func TestConsumerStopsGracefully(t *testing.T) {
+	streams, client := newTestStreams(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	consumer := NewConsumer(streams, ConsumerOptions{Group: "notifications", Name: "test", Block: 50 * time.Millisecond})
+	consumer.Handle(StreamUsers, func(ctx context.Context, event Event) error {
+		close(started)
+		<-finish
+		return ctx.Err()
+	})
+	done := make(chan error)
+	go func() { done <- consumer.Run(ctx) }()
+
+	_, err := NewPublisher(streams, 1000).Publish(context.Background(), StreamUsers, UserRegistered, userRegistered{UserID: "u1"})
+	require.NoError(t, err)
+	<-started
+
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Run returned before the running handler finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(finish)
+	require.NoError(t, <-done)
+
+	pending, err := client.XPending(context.Background(), StreamUsers, "notifications").Result()
+	require.NoError(t, err)
+	require.Zero(t, pending.Count, "the handler finished with a live context and its event was acknowledged")
+}
@@ -0,0 +1,163 @@
+// Package events carries domain events such as user.registered between services over Redis Streams. A Publisher
+// appends events to a stream, a Consumer reads the streams it has handlers for as part of a consumer group so each
+// event is handled by one instance. Delivery is at least once: handlers must tolerate seeing an event twice.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Streams of the domain events, one per aggregate so the events of an aggregate keep their order.
+const (
+	StreamUsers  = "events:users"
+	StreamOrders = "events:orders"
+)
+
+// Types of the domain events.
+const (
+	UserRegistered = "user.registered"
+	OrderPlaced    = "order.placed"
+)
+
+// UserRegisteredPayload is the payload of UserRegistered events.
+type UserRegisteredPayload struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	// EmailVerified is set for accounts created by signing in with a provider such as Google
+	EmailVerified bool `json:"email_verified"`
+}
+
+// deadLetterSuffix names the stream events are parked in once given up on, e.g. "events:users:dead".
+const deadLetterSuffix = ":dead"
+
+// Fields of a stream entry.
+const (
+	fieldType        = "type"
+	fieldPayload     = "payload"
+	fieldPublishedAt = "published_at"
+	fieldTrace       = "trace"
+	fieldRequestID   = "request_id"
+)
+
+var messagingRedis = semconv.MessagingSystemKey.String("redis_streams")
+
+// Streams is the part of Redis the events need, implemented by *cache.Redis.
+type Streams interface {
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	XGroupCreate(ctx context.Context, stream, group, start string) error
+	XReadGroup(ctx context.Context, group, consumer string, streams []string, count int64, block time.Duration) ([]redis.XStream, error)
+	XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]redis.XMessage, string, error)
+	XDeliveries(ctx context.Context, stream, group, id string) (int64, error)
+	XAck(ctx context.Context, stream, group, id string) (int64, error)
+}
+
+// Event is an event read from a stream.
+type Event struct {
+	ID          string
+	Stream      string
+	Type        string
+	Payload     json.RawMessage
+	PublishedAt time.Time
+	// Deliveries counts the times the event was handed to the group, more than one after a consumer stopped
+	// without acknowledging it
+	Deliveries int64
+
+	trace     map[string]string
+	requestID string
+}
+
+// Decode unmarshals the payload of the event into v.
+func (e Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("cannot decode %s event %s: %w", e.Type, e.ID, err)
+	}
+	return nil
+}
+
+// Publisher appends events to streams.
+type Publisher struct {
+	streams Streams
+	maxLen  int64
+}
+
+// NewPublisher publishes to streams, keeping about maxLen events in each. Events trimmed before a group read them
+// are lost to it, so maxLen must cover the longest outage of the consumers.
+func NewPublisher(streams Streams, maxLen int64) *Publisher {
+	return &Publisher{streams: streams, maxLen: maxLen}
+}
+
+// Publish appends an event of eventType to stream with payload marshalled as JSON, and returns its ID. The event
+// continues the trace and keeps the request ID of ctx.
+func (p *Publisher) Publish(ctx context.Context, stream, eventType string, payload interface{}) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+stream,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingRedis, semconv.MessagingOperationPublish, semconv.MessagingDestinationName(stream)),
+	)
+	defer span.End()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal %s event: %w", eventType, err)
+	}
+	values := map[string]interface{}{
+		fieldType:        eventType,
+		fieldPayload:     data,
+		fieldPublishedAt: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if carrier := tracing.Inject(ctx); len(carrier) > 0 {
+		traceData, err := json.Marshal(carrier)
+		if err == nil {
+			values[fieldTrace] = traceData
+		}
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		values[fieldRequestID] = requestID
+	}
+
+	id, err := p.streams.XAdd(ctx, stream, p.maxLen, values)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", fmt.Errorf("cannot publish %s event: %w", eventType, err)
+	}
+	span.SetAttributes(semconv.MessagingMessageID(id))
+	return id, nil
+}
+
+// parseEvent reads the event of entry msg of stream.
+func parseEvent(stream string, msg redis.XMessage, deliveries int64) (Event, error) {
+	event := Event{ID: msg.ID, Stream: stream, Deliveries: deliveries}
+
+	eventType, ok := msg.Values[fieldType].(string)
+	if !ok || eventType == "" {
+		return event, fmt.Errorf("event %s has no type", msg.ID)
+	}
+	event.Type = eventType
+
+	payload, _ := msg.Values[fieldPayload].(string)
+	if !json.Valid([]byte(payload)) {
+		return event, fmt.Errorf("event %s has no valid payload", msg.ID)
+	}
+	event.Payload = json.RawMessage(payload)
+
+	if publishedAt, ok := msg.Values[fieldPublishedAt].(string); ok {
+		event.PublishedAt, _ = time.Parse(time.RFC3339Nano, publishedAt)
+	}
+	if traceData, ok := msg.Values[fieldTrace].(string); ok {
+		_ = json.Unmarshal([]byte(traceData), &event.trace)
+	}
+	event.requestID, _ = msg.Values[fieldRequestID].(string)
+	return event, nil
+}
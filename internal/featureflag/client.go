@@ -0,0 +1,113 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+)
+
+const (
+	cacheKeyPrefix = "featureflag:"
+	// flagTTL bounds how long an instance serves a flag changed without Invalidate, e.g. in SQL
+	flagTTL = time.Minute
+)
+
+type subjectKey struct{}
+
+// WithSubject returns ctx evaluating flags for subject. The HTTP services set it from the token of the request,
+// tasks set it themselves.
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFrom returns the subject of ctx, an anonymous one when it has none.
+func SubjectFrom(ctx context.Context) Subject {
+	subject, _ := ctx.Value(subjectKey{}).(Subject)
+	return subject
+}
+
+// Client evaluates flags for the callers of gin handlers and tasks. Flags are read through a Redis cache, a flag
+// that can't be read is treated as missing so a failure never breaks the caller.
+type Client struct {
+	store sqlc.Store
+	cache cache.Cache
+}
+
+func NewClient(store sqlc.Store, cache cache.Cache) *Client {
+	return &Client{store: store, cache: cache}
+}
+
+// Bool reports whether the boolean flag key is on for the subject of ctx. Missing flags are off.
+func (c *Client) Bool(ctx context.Context, key string) bool {
+	return c.Evaluate(ctx, key, SubjectFrom(ctx)).Enabled()
+}
+
+// Variant returns the variant of flag key served to the subject of ctx, fallback when the flag is missing.
+func (c *Client) Variant(ctx context.Context, key, fallback string) string {
+	evaluation := c.Evaluate(ctx, key, SubjectFrom(ctx))
+	if evaluation.Reason == ReasonNotFound {
+		return fallback
+	}
+	return evaluation.Variant
+}
+
+// Evaluate returns the variant of flag key served to subject and why, ReasonNotFound when the flag is missing or
+// can't be read.
+func (c *Client) Evaluate(ctx context.Context, key string, subject Subject) Evaluation {
+	flag, err := c.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("flag", key).Msg("failed to read feature flag")
+	}
+	if flag == nil {
+		return Evaluation{Key: key, Reason: ReasonNotFound}
+	}
+	return flag.Evaluate(subject)
+}
+
+// Get returns flag key, nil when it doesn't exist. Missing flags are cached too so checking them doesn't hit the
+// database.
+func (c *Client) Get(ctx context.Context, key string) (*Flag, error) {
+	return cache.GetOrLoad(ctx, c.cache, cacheKeyPrefix+key, flagTTL, func(ctx context.Context) (*Flag, error) {
+		row, err := c.store.GetFeatureFlag(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return FromRow(row)
+	})
+}
+
+// Invalidate drops flag key from the cache, call it after changing the flag.
+func (c *Client) Invalidate(ctx context.Context, key string) {
+	if err := c.cache.DeleteKey(ctx, cacheKeyPrefix+key); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("flag", key).Msg("failed to invalidate feature flag, it expires in " + flagTTL.String())
+	}
+}
+
+// FromRow decodes a flag read from the database.
+func FromRow(row sqlc.FeatureFlag) (*Flag, error) {
+	flag := &Flag{
+		Key:        row.Key,
+		Kind:       Kind(row.Kind),
+		Enabled:    row.Enabled,
+		Variants:   row.Variants,
+		OffVariant: row.OffVariant,
+		Version:    row.Version,
+	}
+	if err := json.Unmarshal(row.Rules, &flag.Rules); err != nil {
+		return nil, fmt.Errorf("invalid rules of flag %s: %w", row.Key, err)
+	}
+	if err := json.Unmarshal(row.DefaultRule, &flag.Default); err != nil {
+		return nil, fmt.Errorf("invalid default of flag %s: %w", row.Key, err)
+	}
+	return flag, nil
+}
@@ -0,0 +1,324 @@
+// Package featureflag decides which variant of a feature each caller gets. Flags are stored in Postgres, cached
+// in Redis and evaluated in process against a Subject: the first targeting rule matching the caller's user,
+// role, platform or app version serves its variant, otherwise the default rule does. Either can split callers
+// by percentage, a caller always lands in the same bucket of a flag.
+package featureflag
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/token"
+)
+
+// Kind is the type of a flag.
+type Kind string
+
+const (
+	// KindBoolean flags serve VariantOn or VariantOff
+	KindBoolean Kind = "boolean"
+	// KindVariant flags serve one of the variants they declare
+	KindVariant Kind = "variant"
+)
+
+// Variants of boolean flags.
+const (
+	VariantOn  = "on"
+	VariantOff = "off"
+)
+
+// Reasons an Evaluation gives for its variant.
+const (
+	ReasonNotFound = "not_found"
+	ReasonDisabled = "disabled"
+	ReasonRule     = "rule"
+	ReasonDefault  = "default"
+)
+
+var validKey = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// Subject is who a flag is evaluated for, usually built from the token of the request with SubjectFromPayload.
+type Subject struct {
+	UserID     uuid.UUID
+	Role       int8
+	Platform   string
+	AppVersion string
+	// DeviceID buckets callers without a user into rollouts
+	DeviceID string
+}
+
+// SubjectFromPayload returns the subject of the caller a token was issued to.
+func SubjectFromPayload(payload token.PayloadData) Subject {
+	return Subject{
+		UserID:     payload.Subject,
+		Role:       payload.Role,
+		Platform:   payload.Platform,
+		AppVersion: payload.AppVersion,
+		DeviceID:   payload.DeviceID,
+	}
+}
+
+// bucketKey returns what a subject is bucketed by in rollouts, empty when it can't be.
+func (s Subject) bucketKey() string {
+	if s.UserID != uuid.Nil {
+		return s.UserID.String()
+	}
+	return s.DeviceID
+}
+
+// Weight serves Variant to Percent of the callers.
+type Weight struct {
+	Variant string `json:"variant"`
+	Percent int    `json:"percent"`
+}
+
+// Serve is what a rule serves, either a single variant or a rollout whose weights add up to 100.
+type Serve struct {
+	Variant string   `json:"variant,omitempty"`
+	Rollout []Weight `json:"rollout,omitempty"`
+}
+
+// Rule matches callers on every condition it sets, conditions left empty match everyone.
+type Rule struct {
+	UserIDs   []uuid.UUID `json:"user_ids,omitempty"`
+	Roles     []int8      `json:"roles,omitempty"`
+	Platforms []string    `json:"platforms,omitempty"`
+	// MinAppVersion and MaxAppVersion bound the app version, both inclusive, e.g. "2.4.0"
+	MinAppVersion string `json:"min_app_version,omitempty"`
+	MaxAppVersion string `json:"max_app_version,omitempty"`
+	Serve
+}
+
+// Flag is a feature flag as it is cached and evaluated.
+type Flag struct {
+	Key        string   `json:"key"`
+	Kind       Kind     `json:"kind"`
+	Enabled    bool     `json:"enabled"`
+	Variants   []string `json:"variants"`
+	OffVariant string   `json:"off_variant"`
+	Rules      []Rule   `json:"rules"`
+	Default    Serve    `json:"default"`
+	Version    int32    `json:"version"`
+}
+
+// Evaluation is the variant a flag served a subject and why.
+type Evaluation struct {
+	Key     string `json:"key"`
+	Variant string `json:"variant"`
+	Reason  string `json:"reason"`
+	// Rule is the index of the matching rule when Reason is ReasonRule
+	Rule int `json:"rule,omitempty"`
+}
+
+// Enabled reports whether the evaluation turned a boolean flag on.
+func (e Evaluation) Enabled() bool {
+	return e.Variant == VariantOn
+}
+
+// Evaluate returns the variant flag serves subject. A disabled flag serves its off variant to everyone.
+func (f *Flag) Evaluate(subject Subject) Evaluation {
+	if !f.Enabled {
+		return Evaluation{Key: f.Key, Variant: f.OffVariant, Reason: ReasonDisabled}
+	}
+	for i, rule := range f.Rules {
+		if rule.matches(subject) {
+			return Evaluation{Key: f.Key, Variant: f.serve(rule.Serve, subject), Reason: ReasonRule, Rule: i}
+		}
+	}
+	return Evaluation{Key: f.Key, Variant: f.serve(f.Default, subject), Reason: ReasonDefault}
+}
+
+// serve picks the variant of s. Subjects that can't be bucketed are left out of rollouts and get the off variant.
+func (f *Flag) serve(s Serve, subject Subject) string {
+	if len(s.Rollout) == 0 {
+		return s.Variant
+	}
+	key := subject.bucketKey()
+	if key == "" {
+		return f.OffVariant
+	}
+
+	b := bucket(f.Key, key)
+	for _, w := range s.Rollout {
+		if b < w.Percent {
+			return w.Variant
+		}
+		b -= w.Percent
+	}
+	return f.OffVariant
+}
+
+// bucket places key in [0, 100) for the flag, the same key always gets the same bucket of a flag but unrelated
+// buckets across flags.
+func bucket(flagKey, key string) int {
+	sum := sha256.Sum256([]byte(flagKey + "/" + key))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+func (r Rule) matches(subject Subject) bool {
+	if len(r.UserIDs) > 0 && !contains(r.UserIDs, subject.UserID) {
+		return false
+	}
+	if len(r.Roles) > 0 && !contains(r.Roles, subject.Role) {
+		return false
+	}
+	if len(r.Platforms) > 0 && !containsFold(r.Platforms, subject.Platform) {
+		return false
+	}
+	if r.MinAppVersion != "" || r.MaxAppVersion != "" {
+		version, ok := parseVersion(subject.AppVersion)
+		if !ok {
+			return false
+		}
+		if min, _ := parseVersion(r.MinAppVersion); r.MinAppVersion != "" && compareVersions(version, min) < 0 {
+			return false
+		}
+		if max, _ := parseVersion(r.MaxAppVersion); r.MaxAppVersion != "" && compareVersions(version, max) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersion reads a dotted version such as "2.4.1", a leading "v" and a "-beta" like suffix are ignored.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "-")
+	if version == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// compareVersions compares versions part by part, missing parts count as 0 so "2.4" equals "2.4.0".
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Validate checks the flag can be evaluated: every variant it serves is declared and rollouts add up to 100.
+func (f *Flag) Validate() error {
+	if !validKey.MatchString(f.Key) {
+		return errors.New("key must be up to 64 lowercase letters, digits, '_', '.' or '-'")
+	}
+
+	switch f.Kind {
+	case KindBoolean:
+		if len(f.Variants) != 2 || !contains(f.Variants, VariantOn) || !contains(f.Variants, VariantOff) {
+			return fmt.Errorf("boolean flags have the variants %q and %q", VariantOn, VariantOff)
+		}
+	case KindVariant:
+		if len(f.Variants) < 2 {
+			return errors.New("variant flags need at least two variants")
+		}
+		seen := make(map[string]bool, len(f.Variants))
+		for _, v := range f.Variants {
+			if v == "" || seen[v] {
+				return fmt.Errorf("variant %q is empty or declared twice", v)
+			}
+			seen[v] = true
+		}
+	default:
+		return fmt.Errorf("kind must be %q or %q", KindBoolean, KindVariant)
+	}
+
+	if !contains(f.Variants, f.OffVariant) {
+		return fmt.Errorf("off variant %q is not declared", f.OffVariant)
+	}
+	for i, rule := range f.Rules {
+		if err := f.validateRule(rule); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	if err := f.validateServe(f.Default); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	return nil
+}
+
+func (f *Flag) validateRule(rule Rule) error {
+	if len(rule.UserIDs) == 0 && len(rule.Roles) == 0 && len(rule.Platforms) == 0 && rule.MinAppVersion == "" && rule.MaxAppVersion == "" {
+		return errors.New("has no condition, use the default instead")
+	}
+	for _, version := range []string{rule.MinAppVersion, rule.MaxAppVersion} {
+		if _, ok := parseVersion(version); version != "" && !ok {
+			return fmt.Errorf("app version %q is not a dotted version", version)
+		}
+	}
+	return f.validateServe(rule.Serve)
+}
+
+func (f *Flag) validateServe(s Serve) error {
+	if len(s.Rollout) == 0 {
+		if !contains(f.Variants, s.Variant) {
+			return fmt.Errorf("variant %q is not declared", s.Variant)
+		}
+		return nil
+	}
+	if s.Variant != "" {
+		return errors.New("set either a variant or a rollout")
+	}
+
+	total := 0
+	for _, w := range s.Rollout {
+		if !contains(f.Variants, w.Variant) {
+			return fmt.Errorf("variant %q is not declared", w.Variant)
+		}
+		if w.Percent < 0 {
+			return errors.New("percentages can't be negative")
+		}
+		total += w.Percent
+	}
+	if total != 100 {
+		return fmt.Errorf("rollout percentages add up to %d instead of 100", total)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package featureflag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func booleanFlag() *Flag {
+	return &Flag{
+		Key:        "menu.new_checkout",
+		Kind:       KindBoolean,
+		Enabled:    true,
+		Variants:   []string{VariantOn, VariantOff},
+		OffVariant: VariantOff,
+		Default:    Serve{Variant: VariantOff},
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	admin := uuid.New()
+	flag := booleanFlag()
+	flag.Rules = []Rule{
+		{UserIDs: []uuid.UUID{admin}, Serve: Serve{Variant: VariantOn}},
+		{Platforms: []string{"ios"}, MinAppVersion: "2.4", Serve: Serve{Variant: VariantOn}},
+	}
+
+	evaluation := flag.Evaluate(Subject{UserID: admin})
+	require.True(t, evaluation.Enabled())
+	require.Equal(t, ReasonRule, evaluation.Reason)
+	require.Equal(t, 0, evaluation.Rule)
+
+	evaluation = flag.Evaluate(Subject{UserID: uuid.New(), Platform: "iOS", AppVersion: "2.4.1"})
+	require.True(t, evaluation.Enabled())
+	require.Equal(t, 1, evaluation.Rule)
+
+	evaluation = flag.Evaluate(Subject{UserID: uuid.New(), Platform: "ios", AppVersion: "2.3.9"})
+	require.False(t, evaluation.Enabled())
+	require.Equal(t, ReasonDefault, evaluation.Reason)
+
+	require.False(t, flag.Evaluate(Subject{Platform: "android", AppVersion: "3.0"}).Enabled())
+
+	flag.Enabled = false
+	evaluation = flag.Evaluate(Subject{UserID: admin})
+	require.Equal(t, VariantOff, evaluation.Variant)
+	require.Equal(t, ReasonDisabled, evaluation.Reason)
+}
+
+func TestEvaluateRollout(t *testing.T) {
+	flag := booleanFlag()
+	flag.Default = Serve{Rollout: []Weight{{Variant: VariantOn, Percent: 20}, {Variant: VariantOff, Percent: 80}}}
+
+	on := 0
+	for i := 0; i < 10000; i++ {
+		subject := Subject{DeviceID: fmt.Sprintf("device-%d", i)}
+		variant := flag.Evaluate(subject).Variant
+		require.Equal(t, variant, flag.Evaluate(subject).Variant, "a subject always gets the same variant")
+		if variant == VariantOn {
+			on++
+		}
+	}
+	require.InDelta(t, 2000, on, 200)
+
+	require.Equal(t, VariantOff, flag.Evaluate(Subject{}).Variant, "subjects without a bucket key stay off")
+}
+
+func TestVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"2.4", "2.4.0", 0},
+		{"v2.10.0", "2.9.9", 1},
+		{"2.4.0-beta", "2.4.1", -1},
+	} {
+		a, ok := parseVersion(tc.a)
+		require.True(t, ok, tc.a)
+		b, ok := parseVersion(tc.b)
+		require.True(t, ok, tc.b)
+		require.Equal(t, tc.want, compareVersions(a, b), "%s vs %s", tc.a, tc.b)
+	}
+
+	for _, version := range []string{"", "two", "2.x", "2.-1"} {
+		_, ok := parseVersion(version)
+		require.False(t, ok, version)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, booleanFlag().Validate())
+
+	variant := &Flag{
+		Key:        "menu.layout",
+		Kind:       KindVariant,
+		Variants:   []string{"grid", "list", "carousel"},
+		OffVariant: "list",
+		Rules:      []Rule{{Roles: []int8{1}, Serve: Serve{Variant: "carousel"}}},
+		Default:    Serve{Rollout: []Weight{{Variant: "grid", Percent: 50}, {Variant: "list", Percent: 50}}},
+	}
+	require.NoError(t, variant.Validate())
+
+	for name, change := range map[string]func(f *Flag){
+		"key":             func(f *Flag) { f.Key = "Menu Layout" },
+		"boolean":         func(f *Flag) { f.Kind = KindBoolean },
+		"duplicate":       func(f *Flag) { f.Variants = []string{"grid", "grid"} },
+		"off variant":     func(f *Flag) { f.OffVariant = "table" },
+		"rule condition":  func(f *Flag) { f.Rules[0].Roles = nil },
+		"rule variant":    func(f *Flag) { f.Rules[0].Variant = "table" },
+		"rule version":    func(f *Flag) { f.Rules[0].MinAppVersion = "latest" },
+		"rollout total":   func(f *Flag) { f.Default.Rollout[0].Percent = 40 },
+		"rollout variant": func(f *Flag) { f.Default.Variant = "grid" },
+	} {
+		flag := *variant
+		flag.Rules = []Rule{variant.Rules[0]}
+		flag.Default.Rollout = append([]Weight(nil), variant.Default.Rollout...)
+		change(&flag)
+		require.Error(t, flag.Validate(), name)
+	}
+}
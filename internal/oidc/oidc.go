@@ -0,0 +1,107 @@
+// Package oidc holds the protocol pieces of the OpenID Connect provider: PKCE, ID token signing,
+// discovery metadata and scope handling. The flows themselves live in the auth service.
+package oidc
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ResponseTypeCode       = "code"
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/.well-known/jwks.json"
+)
+
+// IDTokenClaims are the claims of the ID token, optional ones are only set when their scope was granted.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	Email             string           `json:"email,omitempty"`
+	EmailVerified     *bool            `json:"email_verified,omitempty"`
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Role              int8             `json:"role,omitempty"`
+}
+
+// Discovery is the provider metadata served on DiscoveryPath.
+type Discovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// NewDiscovery builds the metadata for an issuer. basePath is where the oauth routes are mounted, eg "/v1/auth/oauth".
+func NewDiscovery(issuer, basePath string, scopes []string) Discovery {
+	issuer = strings.TrimSuffix(issuer, "/")
+	return Discovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + basePath + "/authorize",
+		TokenEndpoint:                     issuer + basePath + "/token",
+		UserinfoEndpoint:                  issuer + basePath + "/userinfo",
+		JWKSURI:                           issuer + JWKSPath,
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{ResponseTypeCode},
+		GrantTypesSupported:               []string{GrantAuthorizationCode, GrantRefreshToken},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{PKCEMethodS256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "preferred_username", "role"},
+	}
+}
+
+// ParseScopes splits a space separated scope parameter, dropping duplicates.
+func ParseScopes(scope string) []string {
+	seen := make(map[string]bool)
+	scopes := []string{}
+	for _, s := range strings.Fields(scope) {
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func HasScope(scopes []string, scope string) bool {
+	return contains(scopes, scope)
+}
+
+// ContainsAll reports whether every scope in subset is in scopes.
+func ContainsAll(scopes, subset []string) bool {
+	for _, s := range subset {
+		if !HasScope(scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidRedirectURI only accepts exact matches of a registered redirect uri.
+func ValidRedirectURI(registered []string, uri string) bool {
+	return uri != "" && contains(registered, uri)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
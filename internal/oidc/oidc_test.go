@@ -0,0 +1,134 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Local client fixtures, mirroring the two kinds of clients we register
+type fixtureClient struct {
+	id           string
+	redirectURIs []string
+	verifier     string
+}
+
+var fixtureClients = []fixtureClient{
+	{
+		// Public single page app, authenticates with PKCE only
+		id:           "partner-spa",
+		redirectURIs: []string{"http://localhost:3000/callback"},
+		verifier:     "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+	},
+	{
+		// Confidential restaurant dashboard backend
+		id:           "restaurant-dashboard",
+		redirectURIs: []string{"https://dashboard.bukka.test/oauth/callback", "http://localhost:4000/callback"},
+		verifier:     "M25iVXpKU3puUjFaYWg3T1NDTDQtcW1ROUY5YXlwalNoc0hhakxifmZHag",
+	},
+}
+
+func TestPKCE(t *testing.T) {
+	// Example from RFC 7636 appendix B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", S256Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	for _, client := range fixtureClients {
+		challenge := S256Challenge(client.verifier)
+		require.NoError(t, ValidateChallenge(challenge, PKCEMethodS256), client.id)
+		require.NoError(t, VerifyPKCE(client.verifier, challenge, PKCEMethodS256), client.id)
+		require.ErrorIs(t, VerifyPKCE(client.verifier+"x", challenge, PKCEMethodS256), ErrInvalidCodeVerifier, client.id)
+	}
+
+	require.ErrorIs(t, ValidateChallenge("verifier", "plain"), ErrUnsupportedChallengeMethod)
+	require.Error(t, ValidateChallenge("not-a-hash", PKCEMethodS256))
+	require.ErrorIs(t, VerifyPKCE("short", S256Challenge("short"), PKCEMethodS256), ErrInvalidCodeVerifier)
+}
+
+func TestRedirectURIs(t *testing.T) {
+	for _, client := range fixtureClients {
+		for _, uri := range client.redirectURIs {
+			require.True(t, ValidRedirectURI(client.redirectURIs, uri))
+		}
+		require.False(t, ValidRedirectURI(client.redirectURIs, client.redirectURIs[0]+"/evil"))
+		require.False(t, ValidRedirectURI(client.redirectURIs, ""))
+	}
+}
+
+func TestScopes(t *testing.T) {
+	scopes := ParseScopes("openid  profile openid email")
+	require.Equal(t, []string{"openid", "profile", "email"}, scopes)
+	require.True(t, ContainsAll([]string{"openid", "profile", "email", "offline_access"}, scopes))
+	require.False(t, ContainsAll([]string{"openid"}, scopes))
+	require.Empty(t, ParseScopes(""))
+}
+
+func TestIDTokenVerifiesWithJWKS(t *testing.T) {
+	signer, err := NewEphemeralSigner()
+	require.NoError(t, err)
+
+	client := fixtureClients[0]
+	now := time.Now()
+	verified := true
+	raw, err := signer.Sign(IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "http://localhost:8080",
+			Subject:   "5b0d6a4e-6f6e-4a2a-9d55-8c8a2b7c2f10",
+			Audience:  jwt.ClaimStrings{client.id},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Nonce:         "n-0S6_WzA2Mj",
+		Email:         "user@example.com",
+		EmailVerified: &verified,
+	})
+	require.NoError(t, err)
+
+	// Verify the way a client would, using only the published key set
+	jwks := signer.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	publicKey := publicKeyFromJWK(t, jwks.Keys[0])
+
+	claims := &IDTokenClaims{}
+	parsed, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		require.Equal(t, jwks.Keys[0].Kid, token.Header["kid"])
+		return publicKey, nil
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(client.id),
+		jwt.WithIssuer("http://localhost:8080"),
+	)
+	require.NoError(t, err)
+	require.True(t, parsed.Valid)
+	require.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	require.Equal(t, "user@example.com", claims.Email)
+
+	// A token signed by another provider must not verify
+	other, err := NewEphemeralSigner()
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(raw, &IDTokenClaims{}, func(*jwt.Token) (interface{}, error) {
+		return other.PublicKey(), nil
+	})
+	require.Error(t, err)
+}
+
+func TestDiscovery(t *testing.T) {
+	discovery := NewDiscovery("http://localhost:8080/", "/v1/auth/oauth", []string{"openid"})
+	require.Equal(t, "http://localhost:8080", discovery.Issuer)
+	require.Equal(t, "http://localhost:8080/v1/auth/oauth/authorize", discovery.AuthorizationEndpoint)
+	require.Equal(t, "http://localhost:8080/v1/auth/oauth/token", discovery.TokenEndpoint)
+	require.Equal(t, "http://localhost:8080"+JWKSPath, discovery.JWKSURI)
+	require.Equal(t, []string{PKCEMethodS256}, discovery.CodeChallengeMethodsSupported)
+}
+
+func publicKeyFromJWK(t *testing.T, key JSONWebKey) *rsa.PublicKey {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	require.NoError(t, err)
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+}
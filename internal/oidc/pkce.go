@@ -0,0 +1,51 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+)
+
+const PKCEMethodS256 = "S256"
+
+var (
+	ErrUnsupportedChallengeMethod = errors.New("only the S256 code challenge method is supported")
+	ErrInvalidCodeVerifier        = errors.New("invalid code verifier")
+)
+
+// S256Challenge derives the code challenge for a verifier as described in RFC 7636.
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ValidateChallenge checks the challenge sent on the authorization request. Plain challenges are rejected.
+func ValidateChallenge(challenge, method string) error {
+	if method != PKCEMethodS256 {
+		return ErrUnsupportedChallengeMethod
+	}
+
+	// A S256 challenge is always a base64url encoded sha256 hash
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	if err != nil || len(decoded) != sha256.Size {
+		return errors.New("invalid code challenge")
+	}
+	return nil
+}
+
+// VerifyPKCE checks the verifier sent to the token endpoint against the stored challenge.
+func VerifyPKCE(verifier, challenge, method string) error {
+	if method != PKCEMethodS256 {
+		return ErrUnsupportedChallengeMethod
+	}
+
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return ErrInvalidCodeVerifier
+	}
+
+	if subtle.ConstantTimeCompare([]byte(S256Challenge(verifier)), []byte(challenge)) != 1 {
+		return ErrInvalidCodeVerifier
+	}
+	return nil
+}
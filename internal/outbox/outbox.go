@@ -0,0 +1,119 @@
+// Package outbox makes the tasks, events and messages announcing a change commit or roll back with it. Services
+// write them with AddTask, AddEvent and AddMessage in the transaction of the change, then a Relay sends them to
+// asynq, Redis Streams or RabbitMQ once committed. Delivery is at least once, in the order messages were written within an aggregate such as a user.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"github.com/steve-mir/bukka_backend/worker"
+)
+
+// Kinds of message.
+const (
+	KindTask  = "task"
+	KindEvent = "event"
+	// KindAMQP messages are published to RabbitMQ
+	KindAMQP = "amqp"
+)
+
+// UserAggregate is the aggregate of the messages about the user id.
+func UserAggregate(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+// taskOptions are the options of a task of the worker catalogue stored with the message, its queue is the
+// destination.
+type taskOptions struct {
+	Queue     string        `json:"-"`
+	MaxRetry  int           `json:"max_retry"`
+	Timeout   time.Duration `json:"timeout,omitempty"`
+	TaskID    string        `json:"task_id,omitempty"`
+	UniqueFor time.Duration `json:"unique_for,omitempty"`
+}
+
+// asynqOptions returns the options worker.Task.Options returned when the message was written.
+func (o taskOptions) asynqOptions() []asynq.Option {
+	opts := []asynq.Option{asynq.Queue(o.Queue), asynq.MaxRetry(o.MaxRetry)}
+	if o.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(o.Timeout))
+	}
+	if o.TaskID != "" {
+		opts = append(opts, asynq.TaskID(o.TaskID), asynq.Retention(o.UniqueFor))
+	}
+	return opts
+}
+
+// AddTask writes a task of task with payload, to be enqueued once the transaction of q committed.
+func AddTask[T any](ctx context.Context, q sqlc.Querier, aggregate string, task worker.Task[T], payload T) error {
+	options, err := json.Marshal(taskOptions{
+		MaxRetry:  task.MaxRetry,
+		Timeout:   task.Timeout,
+		TaskID:    task.TaskID(payload),
+		UniqueFor: task.UniqueFor,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot marshal options of %s task: %w", task.Type, err)
+	}
+	return add(ctx, q, sqlc.CreateOutboxMessageParams{
+		Aggregate:   aggregate,
+		Kind:        KindTask,
+		Topic:       task.Type,
+		Destination: task.Queue,
+		Options:     options,
+	}, payload)
+}
+
+// AddEvent writes an event of eventType with payload marshalled as JSON, to be published to stream once the
+// transaction of q committed.
+func AddEvent(ctx context.Context, q sqlc.Querier, aggregate, stream, eventType string, payload interface{}) error {
+	return add(ctx, q, sqlc.CreateOutboxMessageParams{
+		Aggregate:   aggregate,
+		Kind:        KindEvent,
+		Topic:       eventType,
+		Destination: stream,
+		Options:     []byte("{}"),
+	}, payload)
+}
+
+// AddMessage writes a message with payload marshalled as JSON, to be published to exchange with routingKey once the
+// transaction of q committed.
+func AddMessage(ctx context.Context, q sqlc.Querier, aggregate, exchange, routingKey string, payload interface{}) error {
+	return add(ctx, q, sqlc.CreateOutboxMessageParams{
+		Aggregate:   aggregate,
+		Kind:        KindAMQP,
+		Topic:       routingKey,
+		Destination: exchange,
+		Options:     []byte("{}"),
+	}, payload)
+}
+
+// add writes the message of params, keeping the trace and request ID of ctx for the relay to continue.
+func add(ctx context.Context, q sqlc.Querier, params sqlc.CreateOutboxMessageParams, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal %s %s: %w", params.Topic, params.Kind, err)
+	}
+	params.Payload = data
+
+	params.Trace = []byte("{}")
+	if carrier := tracing.Inject(ctx); len(carrier) > 0 {
+		if params.Trace, err = json.Marshal(carrier); err != nil {
+			return fmt.Errorf("cannot marshal trace: %w", err)
+		}
+	}
+	params.RequestID = logging.RequestID(ctx)
+
+	if err := q.CreateOutboxMessage(ctx, params); err != nil {
+		return fmt.Errorf("cannot write %s %s to the outbox: %w", params.Topic, params.Kind, err)
+	}
+	return nil
+}
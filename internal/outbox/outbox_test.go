@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/worker"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueries records the outbox queries, the others panic.
+type fakeQueries struct {
+	sqlc.Querier
+	created   []sqlc.CreateOutboxMessageParams
+	delivered []int64
+	retried   []sqlc.RetryOutboxMessageParams
+	failed    []sqlc.FailOutboxMessageParams
+}
+
+func (q *fakeQueries) CreateOutboxMessage(ctx context.Context, arg sqlc.CreateOutboxMessageParams) error {
+	q.created = append(q.created, arg)
+	return nil
+}
+
+func (q *fakeQueries) MarkOutboxMessageDelivered(ctx context.Context, id int64) error {
+	q.delivered = append(q.delivered, id)
+	return nil
+}
+
+func (q *fakeQueries) RetryOutboxMessage(ctx context.Context, arg sqlc.RetryOutboxMessageParams) error {
+	q.retried = append(q.retried, arg)
+	return nil
+}
+
+func (q *fakeQueries) FailOutboxMessage(ctx context.Context, arg sqlc.FailOutboxMessageParams) error {
+	q.failed = append(q.failed, arg)
+	return nil
+}
+
+type fakeTasks struct {
+	taskType  string
+	payload   json.RawMessage
+	opts      []asynq.Option
+	requestID string
+	err       error
+}
+
+func (t *fakeTasks) DistributeTask(ctx context.Context, taskType string, payload json.RawMessage, opts ...asynq.Option) error {
+	t.taskType, t.payload, t.opts, t.requestID = taskType, payload, opts, logging.RequestID(ctx)
+	return t.err
+}
+
+type fakeMessages struct {
+	exchange, routingKey string
+	payload              json.RawMessage
+}
+
+func (m *fakeMessages) Publish(ctx context.Context, exchange, routingKey string, payload json.RawMessage) error {
+	m.exchange, m.routingKey, m.payload = exchange, routingKey, payload
+	return nil
+}
+
+type fakeEvents struct {
+	stream, eventType string
+	payload           interface{}
+}
+
+func (e *fakeEvents) Publish(ctx context.Context, stream, eventType string, payload interface{}) (string, error) {
+	e.stream, e.eventType, e.payload = stream, eventType, payload
+	return "1-0", nil
+}
+
+type sendEmail struct {
+	Username string `json:"username"`
+}
+
+var taskSendEmail = worker.Task[sendEmail]{
+	Type:      "task:send_email",
+	Queue:     "critical",
+	MaxRetry:  10,
+	Timeout:   30 * time.Second,
+	UniqueKey: func(p sendEmail) string { return p.Username },
+	UniqueFor: time.Hour,
+}
+
+func TestAdd(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	q := &fakeQueries{}
+	uid := uuid.New()
+
+	require.NoError(t, AddTask(ctx, q, UserAggregate(uid), taskSendEmail, sendEmail{Username: "ada@example.com"}))
+	require.NoError(t, AddEvent(ctx, q, UserAggregate(uid), "events:users", "user.registered", map[string]string{"user_id": uid.String()}))
+	require.NoError(t, AddMessage(ctx, q, UserAggregate(uid), "users", "user.registered", map[string]string{"user_id": uid.String()}))
+
+	require.Len(t, q.created, 3)
+	task := q.created[0]
+	require.Equal(t, "user:"+uid.String(), task.Aggregate)
+	require.Equal(t, KindTask, task.Kind)
+	require.Equal(t, "task:send_email", task.Topic)
+	require.Equal(t, "critical", task.Destination)
+	require.JSONEq(t, `{"username":"ada@example.com"}`, string(task.Payload))
+	require.JSONEq(t, `{"max_retry":10,"timeout":30000000000,"task_id":"task:send_email:ada@example.com","unique_for":3600000000000}`, string(task.Options))
+	require.Equal(t, "req-1", task.RequestID)
+
+	event := q.created[1]
+	require.Equal(t, KindEvent, event.Kind)
+	require.Equal(t, "user.registered", event.Topic)
+	require.Equal(t, "events:users", event.Destination)
+	require.JSONEq(t, `{}`, string(event.Options))
+
+	message := q.created[2]
+	require.Equal(t, KindAMQP, message.Kind)
+	require.Equal(t, "user.registered", message.Topic)
+	require.Equal(t, "users", message.Destination)
+}
+
+func TestRelayDeliver(t *testing.T) {
+	ctx := context.Background()
+	tasks, events, messages := &fakeTasks{}, &fakeEvents{}, &fakeMessages{}
+	relay := NewRelay(nil, tasks, events, messages, RelayOptions{})
+
+	err := relay.deliver(ctx, sqlc.Outbox{
+		Kind:        KindTask,
+		Topic:       "task:send_email",
+		Destination: "critical",
+		Payload:     []byte(`{"username":"ada@example.com"}`),
+		Options:     []byte(`{"max_retry":10}`),
+		Trace:       []byte(`{}`),
+		RequestID:   "req-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "task:send_email", tasks.taskType)
+	require.JSONEq(t, `{"username":"ada@example.com"}`, string(tasks.payload))
+	require.Equal(t, "req-1", tasks.requestID, "the task continues the request that wrote it")
+	require.Len(t, tasks.opts, 2)
+	require.Equal(t, asynq.Queue("critical").String(), tasks.opts[0].String())
+	require.Equal(t, asynq.MaxRetry(10).String(), tasks.opts[1].String())
+
+	// A message written by AddTask is enqueued with the options of its task
+	q := &fakeQueries{}
+	payload := sendEmail{Username: "ada@example.com"}
+	require.NoError(t, AddTask(ctx, q, "user:1", taskSendEmail, payload))
+	require.NoError(t, relay.deliver(ctx, sqlc.Outbox{
+		Kind:        KindTask,
+		Topic:       q.created[0].Topic,
+		Destination: q.created[0].Destination,
+		Payload:     q.created[0].Payload,
+		Options:     q.created[0].Options,
+		Trace:       q.created[0].Trace,
+	}))
+	want := taskSendEmail.Options(payload)
+	require.Len(t, tasks.opts, len(want))
+	for i := range want {
+		require.Equal(t, want[i].String(), tasks.opts[i].String())
+	}
+
+	err = relay.deliver(ctx, sqlc.Outbox{
+		Kind:        KindEvent,
+		Topic:       "user.registered",
+		Destination: "events:users",
+		Payload:     []byte(`{"user_id":"u1"}`),
+		Trace:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "events:users", events.stream)
+	require.Equal(t, "user.registered", events.eventType)
+	require.Equal(t, json.RawMessage(`{"user_id":"u1"}`), events.payload)
+
+	err = relay.deliver(ctx, sqlc.Outbox{
+		Kind:        KindAMQP,
+		Topic:       "user.registered",
+		Destination: "users",
+		Payload:     []byte(`{"user_id":"u1"}`),
+		Trace:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "users", messages.exchange)
+	require.Equal(t, "user.registered", messages.routingKey)
+	require.JSONEq(t, `{"user_id":"u1"}`, string(messages.payload))
+
+	require.Error(t, relay.deliver(ctx, sqlc.Outbox{Kind: "webhook", Trace: []byte(`{}`)}))
+
+	// Without RabbitMQ its messages fail like any undeliverable message
+	relay = NewRelay(nil, tasks, events, nil, RelayOptions{})
+	require.ErrorContains(t, relay.deliver(ctx, sqlc.Outbox{Kind: KindAMQP, Trace: []byte(`{}`)}), "not configured")
+}
+
+func TestRelayBatchTimeout(t *testing.T) {
+	for _, size := range []int{1, 100, 1000} {
+		relay := NewRelay(nil, &fakeTasks{}, &fakeEvents{}, nil, RelayOptions{BatchSize: size})
+		require.Greater(t, relay.batchTimeout(), time.Duration(size)*deliverTimeout, "every message of a batch can take its whole delivery timeout")
+	}
+}
+
+func TestRelaySettle(t *testing.T) {
+	ctx := context.Background()
+	q := &fakeQueries{}
+	relay := NewRelay(nil, &fakeTasks{}, &fakeEvents{}, nil, RelayOptions{
+		MaxAttempts: 3,
+		Backoff:     func(attempt int) time.Duration { return time.Duration(attempt) * time.Minute },
+	})
+
+	require.NoError(t, relay.settle(ctx, q, sqlc.Outbox{ID: 1, Kind: KindTask}, nil))
+	require.Equal(t, []int64{1}, q.delivered)
+
+	errDown := errors.New("redis down")
+	before := time.Now()
+	require.NoError(t, relay.settle(ctx, q, sqlc.Outbox{ID: 2, Kind: KindTask, Attempts: 1}, errDown))
+	require.Len(t, q.retried, 1)
+	require.Equal(t, int64(2), q.retried[0].ID)
+	require.Equal(t, "redis down", q.retried[0].LastError.String)
+	require.WithinDuration(t, before.Add(2*time.Minute), q.retried[0].AvailableAt, time.Second, "the second attempt backs off twice")
+
+	require.NoError(t, relay.settle(ctx, q, sqlc.Outbox{ID: 3, Kind: KindEvent, Attempts: 2}, errDown))
+	require.Len(t, q.failed, 1, "the last attempt marks the message failed, letting the next of its aggregate go")
+	require.Equal(t, int64(3), q.failed[0].ID)
+	require.Len(t, q.retried, 1)
+}
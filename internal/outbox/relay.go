@@ -0,0 +1,231 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+)
+
+const (
+	// queryTimeout bounds the queries of a batch on top of sending its messages, and the cleanup
+	queryTimeout = 30 * time.Second
+	// deliverTimeout bounds sending a message
+	deliverTimeout = 5 * time.Second
+	// cleanupInterval is how often delivered messages past their retention are deleted
+	cleanupInterval = 10 * time.Minute
+)
+
+// Tasks enqueues tasks, implemented by worker.TaskDistributor.
+type Tasks interface {
+	DistributeTask(ctx context.Context, taskType string, payload json.RawMessage, opts ...asynq.Option) error
+}
+
+// Events publishes events, implemented by *events.Publisher.
+type Events interface {
+	Publish(ctx context.Context, stream, eventType string, payload interface{}) (string, error)
+}
+
+// Messages publishes to RabbitMQ, implemented by *rabbitmq.Publisher.
+type Messages interface {
+	Publish(ctx context.Context, exchange, routingKey string, payload json.RawMessage) error
+}
+
+// RelayOptions configures a Relay, zero values take the defaults.
+type RelayOptions struct {
+	// PollInterval is how long the relay waits once there is nothing left to send, 1s by default
+	PollInterval time.Duration
+	// BatchSize is how many messages are sent per transaction, 100 by default
+	BatchSize int
+	// MaxAttempts is how many times a message is sent before it is marked failed, which lets the later messages
+	// of its aggregate go, 10 by default
+	MaxAttempts int
+	// Retention is how long delivered messages are kept, 24h by default
+	Retention time.Duration
+	// Backoff is the wait before attempt+1, 1s doubling up to 5m by default
+	Backoff func(attempt int) time.Duration
+}
+
+func (o *RelayOptions) setDefaults() {
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 10
+	}
+	if o.Retention <= 0 {
+		o.Retention = 24 * time.Hour
+	}
+	if o.Backoff == nil {
+		o.Backoff = defaultBackoff
+	}
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempt && backoff < 5*time.Minute; i++ {
+		backoff *= 2
+	}
+	if backoff > 5*time.Minute {
+		backoff = 5 * time.Minute
+	}
+	return backoff
+}
+
+// Relay sends the messages of the outbox. Any number of relays can run at once, each message is sent by one of
+// them, or again by another when a relay stopped between sending it and committing that it did.
+type Relay struct {
+	store  sqlc.Store
+	tasks  Tasks
+	events Events
+	// messages is nil when RabbitMQ isn't configured, messages for it are then retried until they fail
+	messages Messages
+	options  RelayOptions
+}
+
+func NewRelay(store sqlc.Store, tasks Tasks, events Events, messages Messages, options RelayOptions) *Relay {
+	options.setDefaults()
+	return &Relay{store: store, tasks: tasks, events: events, messages: messages, options: options}
+}
+
+// Run sends messages until ctx is done, letting the batch being sent finish.
+func (r *Relay) Run(ctx context.Context) {
+	var cleaned time.Time
+	for ctx.Err() == nil {
+		if time.Since(cleaned) >= cleanupInterval {
+			r.cleanup()
+			cleaned = time.Now()
+		}
+
+		relayed, err := r.relayBatch()
+		if err != nil {
+			log.Error().Err(err).Msg("cannot relay outbox messages")
+		}
+		// A full batch may have left more, and delivered messages may have let the next of their aggregate go
+		if relayed > 0 {
+			continue
+		}
+
+		timer := time.NewTimer(r.options.PollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+}
+
+// batchTimeout bounds a batch, which stopping lets finish so messages sent are marked as such. It lets every
+// message take its whole deliverTimeout, a batch cut short would roll back and send the messages it sent again.
+func (r *Relay) batchTimeout() time.Duration {
+	return time.Duration(r.options.BatchSize)*deliverTimeout + queryTimeout
+}
+
+// relayBatch sends a batch of messages in a transaction holding them, and returns how many it sent.
+func (r *Relay) relayBatch() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.batchTimeout())
+	defer cancel()
+
+	var relayed int
+	err := r.store.ExecTx(ctx, sqlc.TxOptions{}, func(qtx *sqlc.Queries) error {
+		messages, err := qtx.ClaimOutboxMessages(ctx, int32(r.options.BatchSize))
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := r.settle(ctx, qtx, message, r.deliver(ctx, message)); err != nil {
+				return err
+			}
+		}
+		relayed = len(messages)
+		return nil
+	})
+	return relayed, err
+}
+
+// deliver sends message with the trace and request ID of the request that wrote it.
+func (r *Relay) deliver(ctx context.Context, message sqlc.Outbox) error {
+	ctx, cancel := context.WithTimeout(ctx, deliverTimeout)
+	defer cancel()
+
+	var carrier map[string]string
+	_ = json.Unmarshal(message.Trace, &carrier)
+	ctx = tracing.Extract(ctx, carrier)
+	if message.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, message.RequestID)
+	}
+
+	switch message.Kind {
+	case KindTask:
+		var opts taskOptions
+		if err := json.Unmarshal(message.Options, &opts); err != nil {
+			return fmt.Errorf("invalid options: %w", err)
+		}
+		opts.Queue = message.Destination
+		return r.tasks.DistributeTask(ctx, message.Topic, message.Payload, opts.asynqOptions()...)
+	case KindEvent:
+		_, err := r.events.Publish(ctx, message.Destination, message.Topic, json.RawMessage(message.Payload))
+		return err
+	case KindAMQP:
+		if r.messages == nil {
+			return errors.New("rabbitmq is not configured")
+		}
+		return r.messages.Publish(ctx, message.Destination, message.Topic, message.Payload)
+	default:
+		return fmt.Errorf("unknown kind %q", message.Kind)
+	}
+}
+
+// settle records the outcome of sending message, scheduling a retry or marking it failed when sending failed.
+func (r *Relay) settle(ctx context.Context, q sqlc.Querier, message sqlc.Outbox, deliverErr error) error {
+	if deliverErr == nil {
+		metrics.OutboxRelayed(message.Kind, "delivered")
+		return q.MarkOutboxMessageDelivered(ctx, message.ID)
+	}
+
+	attempts := int(message.Attempts) + 1
+	logger := log.With().Int64("outbox_id", message.ID).Str("aggregate", message.Aggregate).
+		Str("kind", message.Kind).Str("topic", message.Topic).Int("attempts", attempts).Logger()
+	lastError := pgtype.Text{String: deliverErr.Error(), Valid: true}
+
+	if attempts >= r.options.MaxAttempts {
+		logger.Error().Err(deliverErr).Msg("outbox message failed, giving up")
+		metrics.OutboxRelayed(message.Kind, "failed")
+		return q.FailOutboxMessage(ctx, sqlc.FailOutboxMessageParams{ID: message.ID, LastError: lastError})
+	}
+
+	logger.Warn().Err(deliverErr).Msg("cannot relay outbox message, will retry")
+	metrics.OutboxRelayed(message.Kind, "retried")
+	return q.RetryOutboxMessage(ctx, sqlc.RetryOutboxMessageParams{
+		ID:          message.ID,
+		LastError:   lastError,
+		AvailableAt: time.Now().Add(r.options.Backoff(attempts)),
+	})
+}
+
+// cleanup deletes the messages delivered longer than Retention ago. Failed messages stay for inspection.
+func (r *Relay) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	deleted, err := r.store.DeleteDeliveredOutboxMessages(ctx, pgtype.Timestamptz{Time: time.Now().Add(-r.options.Retention), Valid: true})
+	if err != nil {
+		log.Error().Err(err).Msg("cannot clean up the outbox")
+		return
+	}
+	if deleted > 0 {
+		log.Info().Int64("deleted", deleted).Msg("cleaned up the outbox")
+	}
+}
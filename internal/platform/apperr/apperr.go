@@ -0,0 +1,120 @@
+// Package apperr is how services report errors to clients. An Error has a Code that decides the HTTP and gRPC
+// status, a Message and translation Key that are safe to show to anyone, field details for rejected input and an
+// internal cause that is only ever logged.
+package apperr
+
+import (
+	"context"
+	"errors"
+)
+
+// Code classifies an error, clients branch on it and the transports derive their status from it.
+type Code string
+
+const (
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodePermissionDenied   Code = "permission_denied"
+	CodeNotFound           Code = "not_found"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeFailedPrecondition Code = "failed_precondition"
+	CodeResourceExhausted  Code = "resource_exhausted"
+	CodeCanceled           Code = "canceled"
+	CodeDeadlineExceeded   Code = "deadline_exceeded"
+	CodeUnimplemented      Code = "unimplemented"
+	CodeUnavailable        Code = "unavailable"
+	CodeInternal           Code = "internal"
+
+	// CodeConsentRequired rejects users that did not accept the current policy documents, clients show them
+	CodeConsentRequired Code = "consent_required"
+	// CodeImpersonationForbidden rejects actions an admin must not take on a user's behalf
+	CodeImpersonationForbidden Code = "impersonation_forbidden"
+)
+
+// KeyInternal is the translation key of every error whose details must stay on the server.
+const KeyInternal = "error.internal"
+
+// Error is an error that can be shown to clients. Message and Key never contain internal details, the cause is
+// kept for logs and errors.Is/As only.
+type Error struct {
+	Code Code
+	// Message is the public, English message
+	Message string
+	// Key looks up the message in the clients' translations, e.g. "auth.login.mismatch"
+	Key    string
+	Fields []FieldError
+	cause  error
+}
+
+// New returns an error without a cause, it is typically kept in a package level variable.
+func New(code Code, key, message string) *Error {
+	return &Error{Code: code, Key: key, Message: message}
+}
+
+// Wrap returns an error showing message to clients and logging err.
+func Wrap(err error, code Code, key, message string) *Error {
+	return &Error{Code: code, Key: key, Message: message, cause: err}
+}
+
+// Internal hides err behind a generic message, for failures the client cannot do anything about.
+func Internal(err error) *Error {
+	return Wrap(err, CodeInternal, KeyInternal, "internal server error")
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is matches errors with the same code and key, so errors.Is finds a package level error after WithCause copied it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Key == e.Key
+}
+
+// WithCause returns a copy of e that logs err.
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.cause = err
+	return &c
+}
+
+// WithFields returns a copy of e with fields added to its details.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &c
+}
+
+// From returns err as an *Error. Errors that are not one are treated as internal, so nothing unexpected reaches
+// a client.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Wrap(err, CodeCanceled, "error.canceled", "the request was canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(err, CodeDeadlineExceeded, "error.timeout", "the request took too long, please try again")
+	}
+	return Internal(err)
+}
+
+// CodeOf returns the code of err, CodeInternal when it is not an *Error and empty when err is nil.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return From(err).Code
+}
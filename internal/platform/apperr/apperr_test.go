@@ -0,0 +1,160 @@
+package apperr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errNotFound = New(CodeNotFound, "test.not_found", "thing not found")
+
+func TestFrom(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	testCases := []struct {
+		name    string
+		err     error
+		code    Code
+		key     string
+		message string
+	}{
+		{name: "apperr", err: errNotFound, code: CodeNotFound, key: "test.not_found", message: "thing not found"},
+		{name: "wrapped apperr", err: fmt.Errorf("loading: %w", errNotFound), code: CodeNotFound, key: "test.not_found", message: "thing not found"},
+		{name: "plain error", err: cause, code: CodeInternal, key: KeyInternal, message: "internal server error"},
+		{name: "canceled", err: fmt.Errorf("query: %w", context.Canceled), code: CodeCanceled},
+		{name: "deadline", err: context.DeadlineExceeded, code: CodeDeadlineExceeded, key: "error.timeout"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := From(tc.err)
+			require.NotNil(t, e)
+			require.Equal(t, tc.code, e.Code)
+			if tc.key != "" {
+				require.Equal(t, tc.key, e.Key)
+			}
+			if tc.message != "" {
+				require.Equal(t, tc.message, e.Message)
+			}
+			require.NotContains(t, e.Message, "connection refused")
+		})
+	}
+
+	require.Nil(t, From(nil))
+	require.Equal(t, Code(""), CodeOf(nil))
+}
+
+func TestWithCause(t *testing.T) {
+	cause := errors.New("row locked")
+	err := errNotFound.WithCause(cause)
+
+	require.ErrorIs(t, err, errNotFound)
+	require.ErrorIs(t, err, cause)
+	require.Nil(t, errNotFound.Unwrap(), "the package level error must not change")
+	require.False(t, errors.Is(err, New(CodeNotFound, "test.other", "thing not found")))
+}
+
+func TestResponse(t *testing.T) {
+	status, body := Response(fmt.Errorf("saving: %w", errors.New("duplicate key")))
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, Body{Error: "internal server error", Code: CodeInternal, Key: KeyInternal}, body)
+
+	status, body = Response(errNotFound.WithCause(errors.New("no rows")))
+	require.Equal(t, http.StatusNotFound, status)
+	require.Equal(t, "thing not found", body.Error)
+
+	status, _ = Response(New(CodeConsentRequired, "test.consent", "accept the terms"))
+	require.Equal(t, http.StatusForbidden, status)
+	require.Equal(t, http.StatusInternalServerError, Code("unknown").HTTPStatus())
+}
+
+type bindingReq struct {
+	Email   string `json:"email" validate:"required,email"`
+	Age     int    `json:"age" validate:"gte=18"`
+	Comment string `form:"comment" validate:"max=3"`
+	Hidden  string `json:"-" validate:"required"`
+}
+
+func TestBinding(t *testing.T) {
+	v := validator.New()
+	v.RegisterTagNameFunc(JSONFieldName)
+
+	err := v.Struct(bindingReq{Email: "nope", Age: 12, Comment: "too long", Hidden: "x"})
+	require.Error(t, err)
+
+	e := Binding(err)
+	require.Equal(t, CodeInvalidArgument, e.Code)
+	require.Equal(t, KeyValidation, e.Key)
+	require.Equal(t, []FieldError{
+		{Field: "email", Rule: "email", Key: "validation.email", Message: "must be a valid email address"},
+		{Field: "age", Rule: "gte", Key: "validation.min", Message: "must be at least 18", Param: "18"},
+		{Field: "comment", Rule: "max", Key: "validation.max", Message: "must be at most 3", Param: "3"},
+	}, e.Fields)
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, e, &validationErrs)
+
+	var req bindingReq
+	typeErr := json.Unmarshal([]byte(`{"age":"old"}`), &req)
+	e = Binding(typeErr)
+	require.Len(t, e.Fields, 1)
+	require.Equal(t, "age", e.Fields[0].Field)
+	require.Equal(t, "type", e.Fields[0].Rule)
+
+	syntaxErr := json.Unmarshal([]byte(`{"age":`), &req)
+	require.Equal(t, "error.malformed_body", Binding(syntaxErr).Key)
+	require.Equal(t, "error.invalid_request", Binding(errors.New("unsupported content type")).Key)
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("evenTest", "validation.even", "must be even")
+
+	v := validator.New()
+	v.RegisterTagNameFunc(JSONFieldName)
+	require.NoError(t, v.RegisterValidation("evenTest", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+
+	err := v.Struct(struct {
+		N int `json:"n" validate:"evenTest"`
+	}{N: 3})
+	e := Binding(err)
+	require.Len(t, e.Fields, 1)
+	require.Equal(t, FieldError{Field: "n", Rule: "evenTest", Key: "validation.even", Message: "must be even"}, e.Fields[0])
+}
+
+func TestGRPCStatus(t *testing.T) {
+	err := ToGRPC(InvalidField("email", "validation.email", "invalid email format").WithCause(errors.New("secret")))
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, "some fields are invalid", st.Message())
+
+	details := st.Details()
+	require.Len(t, details, 2)
+
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, string(CodeInvalidArgument), info.Reason)
+	assert.Equal(t, KeyValidation, info.Metadata["key"])
+
+	badRequest, ok := details[1].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.FieldViolations, 1)
+	assert.Equal(t, "email", badRequest.FieldViolations[0].Field)
+
+	st, _ = status.FromError(ToGRPC(errors.New("secret")))
+	require.Equal(t, codes.Internal, st.Code())
+	require.NotContains(t, st.Message(), "secret")
+	require.NoError(t, ToGRPC(nil))
+}
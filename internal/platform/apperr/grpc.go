@@ -0,0 +1,74 @@
+package apperr
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain groups the reasons of ErrorInfo details, as gRPC clients expect
+const errorDomain = "bukka"
+
+var grpcCodes = map[Code]codes.Code{
+	CodeInvalidArgument:        codes.InvalidArgument,
+	CodeUnauthenticated:        codes.Unauthenticated,
+	CodePermissionDenied:       codes.PermissionDenied,
+	CodeNotFound:               codes.NotFound,
+	CodeAlreadyExists:          codes.AlreadyExists,
+	CodeFailedPrecondition:     codes.FailedPrecondition,
+	CodeResourceExhausted:      codes.ResourceExhausted,
+	CodeCanceled:               codes.Canceled,
+	CodeDeadlineExceeded:       codes.DeadlineExceeded,
+	CodeUnimplemented:          codes.Unimplemented,
+	CodeUnavailable:            codes.Unavailable,
+	CodeInternal:               codes.Internal,
+	CodeConsentRequired:        codes.PermissionDenied,
+	CodeImpersonationForbidden: codes.PermissionDenied,
+}
+
+// GRPCCode returns the gRPC status code of errors with code, Internal for unknown codes.
+func (code Code) GRPCCode() codes.Code {
+	if c, ok := grpcCodes[code]; ok {
+		return c
+	}
+	return codes.Internal
+}
+
+// GRPCStatus lets status.FromError and gRPC servers turn e into a status. The code and key travel as an ErrorInfo
+// and field errors as a BadRequest, the cause stays out.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.GRPCCode(), e.Message)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   string(e.Code),
+		Domain:   errorDomain,
+		Metadata: map[string]string{"key": e.Key},
+	}
+	details := []protoadapt.MessageV1{info}
+
+	if len(e.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, f := range e.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	// Only fails for codes.OK, which no Code maps to
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// ToGRPC returns the status error a gRPC handler returns for err.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	return From(err).GRPCStatus().Err()
+}
@@ -0,0 +1,50 @@
+package apperr
+
+import "net/http"
+
+// statusClientClosedRequest is the nginx status for requests the client gave up on, net/http has no name for it
+const statusClientClosedRequest = 499
+
+var httpStatuses = map[Code]int{
+	CodeInvalidArgument:        http.StatusBadRequest,
+	CodeUnauthenticated:        http.StatusUnauthorized,
+	CodePermissionDenied:       http.StatusForbidden,
+	CodeNotFound:               http.StatusNotFound,
+	CodeAlreadyExists:          http.StatusConflict,
+	CodeFailedPrecondition:     http.StatusBadRequest,
+	CodeResourceExhausted:      http.StatusTooManyRequests,
+	CodeCanceled:               statusClientClosedRequest,
+	CodeDeadlineExceeded:       http.StatusGatewayTimeout,
+	CodeUnimplemented:          http.StatusNotImplemented,
+	CodeUnavailable:            http.StatusServiceUnavailable,
+	CodeInternal:               http.StatusInternalServerError,
+	CodeConsentRequired:        http.StatusForbidden,
+	CodeImpersonationForbidden: http.StatusForbidden,
+}
+
+// HTTPStatus returns the status code answering errors with code, 500 for unknown codes.
+func (code Code) HTTPStatus() int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Body is the JSON body HTTP services answer errors with.
+type Body struct {
+	Error  string       `json:"error"`
+	Code   Code         `json:"code"`
+	Key    string       `json:"key,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// Response returns the status and body answering err, so handlers can write ctx.AbortWithStatusJSON(apperr.Response(err)).
+func Response(err error) (int, Body) {
+	e := From(err)
+	return e.Code.HTTPStatus(), Body{
+		Error:  e.Message,
+		Code:   e.Code,
+		Key:    e.Key,
+		Fields: e.Fields,
+	}
+}
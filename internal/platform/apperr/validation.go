@@ -0,0 +1,149 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// KeyValidation is the translation key of errors carrying field errors.
+const KeyValidation = "error.validation"
+
+// FieldError says why one field of a request was rejected.
+type FieldError struct {
+	// Field is the JSON name of the field, nested fields are joined with dots
+	Field string `json:"field"`
+	// Rule is the validation tag that failed, e.g. "required" or "max"
+	Rule    string `json:"rule"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	// Param is the rule's parameter, e.g. the limit of max, for translations to fill in
+	Param string `json:"param,omitempty"`
+}
+
+type rule struct {
+	key     string
+	message string
+}
+
+var (
+	rulesMu sync.RWMutex
+	// rules holds the message of each validation tag, messages may have a %s for the tag's parameter
+	rules = map[string]rule{
+		"required": {"validation.required", "is required"},
+		"min":      {"validation.min", "must be at least %s"},
+		"max":      {"validation.max", "must be at most %s"},
+		"gte":      {"validation.min", "must be at least %s"},
+		"lte":      {"validation.max", "must be at most %s"},
+		"len":      {"validation.len", "must have a length of %s"},
+		"oneof":    {"validation.oneof", "must be one of %s"},
+		"email":    {"validation.email", "must be a valid email address"},
+		"url":      {"validation.url", "must be a valid url"},
+		"uuid":     {"validation.uuid", "must be a valid id"},
+	}
+)
+
+// RegisterRule sets the key and message of fields failing a custom validation tag. message may contain a %s for
+// the tag's parameter.
+func RegisterRule(tag, key, message string) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[tag] = rule{key: key, message: message}
+}
+
+// Validation returns an invalid argument error listing fields.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Code:    CodeInvalidArgument,
+		Key:     KeyValidation,
+		Message: "some fields are invalid",
+		Fields:  fields,
+	}
+}
+
+// InvalidField returns a validation error for a single field, for checks made outside of binding tags.
+func InvalidField(field, key, message string) *Error {
+	return Validation(FieldError{Field: field, Rule: "invalid", Key: key, Message: message})
+}
+
+// Binding turns the error of binding a request into an invalid argument error with a field error for every
+// failed validation tag or mistyped JSON value.
+func Binding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, fieldError(fe))
+		}
+		return Validation(fields...).WithCause(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Validation(FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Key:     "validation.type",
+			Message: "must be a " + typeErr.Type.Kind().String(),
+			Param:   typeErr.Type.Kind().String(),
+		}).WithCause(err)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Wrap(err, CodeInvalidArgument, "error.malformed_body", "the request body is not valid JSON")
+	}
+	return Wrap(err, CodeInvalidArgument, "error.invalid_request", "the request is invalid")
+}
+
+func fieldError(fe validator.FieldError) FieldError {
+	rulesMu.RLock()
+	r, ok := rules[fe.Tag()]
+	rulesMu.RUnlock()
+	if !ok {
+		r = rule{key: "validation.invalid", message: "is invalid"}
+	}
+
+	message := r.message
+	if strings.Contains(message, "%s") {
+		message = fmt.Sprintf(message, fe.Param())
+	}
+
+	return FieldError{
+		Field:   fieldPath(fe.Namespace()),
+		Rule:    fe.Tag(),
+		Key:     r.key,
+		Message: message,
+		Param:   fe.Param(),
+	}
+}
+
+// fieldPath drops the struct name validator puts in front of the namespace, "RegisterReq.email" becomes "email".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// JSONFieldName names fields by their json or else form tag, register it with validator.RegisterTagNameFunc so
+// field errors use the names clients sent.
+func JSONFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name, _, _ = strings.Cut(field.Tag.Get("form"), ",")
+	}
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,201 @@
+// Package health serves the liveness and readiness probes of a process. Liveness only says the process is serving,
+// readiness runs a check against every dependency, each bounded by a timeout, and fails for good once the process
+// starts shutting down so load balancers stop routing to it before it closes its listeners.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Paths the probes are served on.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+	// StatusDraining is reported once the process is shutting down
+	StatusDraining = "draining"
+)
+
+// DefaultTimeout bounds each check when HEALTH_CHECK_TIMEOUT is not set.
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc reports whether a dependency can be used, it must give up when ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of one check. Errors are logged, only whether the check timed out reaches the response.
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Optional   bool   `json:"optional,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the body of ReadinessPath.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name     string
+	run      CheckFunc
+	optional bool
+}
+
+// Checker runs the readiness checks of a process.
+type Checker struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checks   []check
+	draining atomic.Bool
+}
+
+// New returns a Checker bounding each check by timeout, DefaultTimeout when it is not positive.
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Add makes readiness depend on check.
+func (c *Checker) Add(name string, run CheckFunc) {
+	c.add(check{name: name, run: run})
+}
+
+// AddOptional reports check without failing readiness when it is down, for dependencies the process can do without.
+func (c *Checker) AddOptional(name string, run CheckFunc) {
+	c.add(check{name: name, run: run, optional: true})
+}
+
+func (c *Checker) add(ch check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, ch)
+}
+
+// Drain makes readiness fail from now on, call it as soon as shutdown starts.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Draining reports whether Drain was called.
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Ready runs every check concurrently and reports the status of each. The process is ready when no required
+// check is down and it is not draining.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func(i int, ch check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, ch)
+		}(i, ch)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, ch := range checks {
+		report.Checks[ch.name] = results[i]
+		if results[i].Status == StatusDown && !ch.optional {
+			report.Status = StatusDown
+		}
+	}
+	if c.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, ch check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	// Checks that ignore ctx still can't hold the probe past the timeout
+	go func() { errCh <- ch.run(ctx) }()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Status: StatusUp, Optional: ch.optional, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = "unavailable"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "timeout"
+		}
+		log.Warn().Err(err).Str("check", ch.name).Bool("optional", ch.optional).Msg("health check failed")
+	}
+	return result
+}
+
+// LivenessHandler answers as long as the process can serve requests.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusUp})
+	})
+}
+
+// ReadinessHandler answers 200 when the process is ready and 503 with the failing checks otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Ready(r.Context())
+		status := http.StatusOK
+		if report.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// Wrap serves the probes in front of next, outside of its middlewares so probes are neither traced, logged nor
+// rate limited.
+func (c *Checker) Wrap(next http.Handler) http.Handler {
+	liveness, readiness := c.LivenessHandler(), c.ReadinessHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			switch r.URL.Path {
+			case LivenessPath:
+				liveness.ServeHTTP(w, r)
+				return
+			case ReadinessPath:
+				readiness.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("dial tcp 10.0.0.3:5432: connection refused") }
+
+func TestReady(t *testing.T) {
+	checker := New(50 * time.Millisecond)
+	checker.Add("postgres", up)
+	checker.AddOptional("replica", down)
+
+	report := checker.Ready(context.Background())
+	require.Equal(t, StatusUp, report.Status, "optional checks don't fail readiness")
+	require.Equal(t, StatusUp, report.Checks["postgres"].Status)
+	require.Equal(t, Result{Status: StatusDown, Error: "unavailable", Optional: true}, withoutDuration(report.Checks["replica"]))
+
+	checker.Add("redis", func(ctx context.Context) error {
+		// Ignores ctx, the checker still gives up on it
+		time.Sleep(time.Second)
+		return nil
+	})
+	report = checker.Ready(context.Background())
+	require.Equal(t, StatusDown, report.Status)
+	require.Equal(t, "timeout", report.Checks["redis"].Error)
+
+	checker.Drain()
+	require.Equal(t, StatusDraining, checker.Ready(context.Background()).Status)
+}
+
+func withoutDuration(result Result) Result {
+	result.DurationMs = 0
+	return result
+}
+
+func TestWrap(t *testing.T) {
+	checker := New(time.Second)
+	checker.Add("rabbitmq", down)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	handler := checker.Wrap(next)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, LivenessPath).Code)
+
+	w := serve(http.MethodGet, ReadinessPath)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, StatusDown, report.Checks["rabbitmq"].Status)
+	require.NotContains(t, w.Body.String(), "10.0.0.3", "dependency errors stay in the logs")
+
+	require.Equal(t, http.StatusTeapot, serve(http.MethodPost, ReadinessPath).Code)
+	require.Equal(t, http.StatusTeapot, serve(http.MethodGet, "/v1/auth/home").Code)
+}
@@ -0,0 +1,68 @@
+package httpserver
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+)
+
+var (
+	errRouteNotFound    = apperr.New(apperr.CodeNotFound, "error.route_not_found", "route not found")
+	errMethodNotAllowed = apperr.New(apperr.CodeInvalidArgument, "error.method_not_allowed", "method not allowed")
+
+	fieldNamesOnce sync.Once
+)
+
+// Error aborts the request with the status and body of err, see apperr.Response. Only the public message reaches
+// the client, server errors are logged with their cause.
+func Error(ctx *gin.Context, err error) {
+	status, body := apperr.Response(err)
+	if status >= http.StatusInternalServerError {
+		logging.FromContext(ctx.Request.Context()).Error().Err(err).
+			Str("method", ctx.Request.Method).Str("path", ctx.FullPath()).Int("status", status).Msg("request failed")
+	}
+	ctx.AbortWithStatusJSON(status, body)
+}
+
+// BindingError aborts the request with the field errors of a failed ShouldBind.
+func BindingError(ctx *gin.Context, err error) {
+	Error(ctx, apperr.Binding(err))
+}
+
+// Recovery turns panics in handlers into a JSON 500 instead of gin's empty response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(ctx *gin.Context, recovered any) {
+		logging.FromContext(ctx.Request.Context()).Error().Interface("panic", recovered).
+			Str("path", ctx.Request.URL.Path).Msg("recovered from panic")
+		ctx.AbortWithStatusJSON(apperr.Response(apperr.Internal(nil)))
+	})
+}
+
+func NotFound() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(apperr.Response(errRouteNotFound))
+	}
+}
+
+func MethodNotAllowed() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// No code maps to 405, it only exists at the HTTP level
+		_, body := apperr.Response(errMethodNotAllowed)
+		ctx.JSON(http.StatusMethodNotAllowed, body)
+	}
+}
+
+// useJSONFieldNames makes binding errors name fields like the JSON clients sent, gin's validator is global so it
+// is set up once for every service.
+func useJSONFieldNames() {
+	fieldNamesOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterTagNameFunc(apperr.JSONFieldName)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+)
+
+// logRequests gives every request an ID, taken from X-Request-ID when the client sent a usable one, and a logger
+// carrying it. The ID is echoed back so clients can quote it, and each request is logged once it is done.
+func logRequests(c *gin.Context) {
+	start := time.Now()
+	requestID := logging.ParseRequestID(c.GetHeader(logging.RequestIDHeader))
+	c.Header(logging.RequestIDHeader, requestID)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+	c.Next()
+
+	if c.FullPath() == MetricsPath {
+		return
+	}
+	status := c.Writer.Status()
+	// Handlers further down may have added the user to the logger
+	logger := logging.FromContext(c.Request.Context())
+	event := logger.Info()
+	switch {
+	case status >= http.StatusInternalServerError:
+		event = logger.Error()
+	case status >= http.StatusBadRequest:
+		event = logger.Warn()
+	}
+	event.Str("method", c.Request.Method).
+		Str("path", c.Request.URL.Path).
+		Str("route", c.FullPath()).
+		Int("status", status).
+		Dur("took", time.Since(start)).
+		Str("ip", c.ClientIP()).
+		Int("size", c.Writer.Size()).
+		Func(func(e *zerolog.Event) {
+			if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); errs != "" {
+				e.Str("errors", errs)
+			}
+		}).
+		Msg("request")
+}
@@ -0,0 +1,130 @@
+// Package httpserver holds what every Bukka HTTP service shares: declarative routes with auth, roles and rate
+// limits, JSON error rendering and a server that shuts down gracefully. A service only declares its routes and
+// passes in the middlewares enforcing their auth and rate limits.
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// MetricsPath serves the Prometheus metrics of the process on every service.
+const MetricsPath = "/metrics"
+
+// Route declares an endpoint. Paths starting with "/" are registered as is, others under Options.BasePath.
+type Route struct {
+	Method  string
+	Path    string
+	Handler gin.HandlerFunc
+	// Middlewares run after auth and rate limiting, right before Handler
+	Middlewares []gin.HandlerFunc
+	// Roles makes the route require a token of one of the roles, routes without roles are public
+	Roles       []int8
+	SkipConsent bool
+	// Scope lets API keys and oauth clients with that scope use the route
+	Scope string
+}
+
+// Guard is what the auth of a route with roles enforces, see Options.Auth.
+type Guard struct {
+	Roles       []int8
+	SkipConsent bool
+	Scope       string
+}
+
+type Options struct {
+	// Service names the service in metrics, e.g. "auth"
+	Service string
+	// BasePath is prefixed to relative route paths, e.g. "v1/auth"
+	BasePath string
+	// Auth builds the middleware run first on the routes with roles. guards holds the Guard of every such route,
+	// keyed by method and path such as "GET /v1/auth/profile". Routes with roles are refused when Auth is nil.
+	Auth func(guards map[string]Guard) gin.HandlerFunc
+	// RateLimit runs on every route when set, after Auth so limits can be keyed by user
+	RateLimit gin.HandlerFunc
+}
+
+// NewRouter builds the gin engine serving routes. Every route gets, in order: Options.Auth when it has roles,
+// Options.RateLimit, its own middlewares and the handler.
+func NewRouter(opts Options, routes []Route) (*gin.Engine, error) {
+	useJSONFieldNames()
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	// Lets the store see values handlers put on the request context, such as the db subject
+	router.ContextWithFallback = true
+	router.Use(otelgin.Middleware(opts.Service, otelgin.WithFilter(notMetrics)), logRequests, observe(opts.Service), Recovery())
+	router.NoRoute(NotFound())
+	router.NoMethod(MethodNotAllowed())
+	router.GET(MetricsPath, gin.WrapH(metrics.Handler()))
+
+	// Auth is given every guard at once, so the routes are resolved before any is registered
+	paths := make([]string, len(routes))
+	guards := make(map[string]Guard)
+	for i, route := range routes {
+		paths[i] = route.Path
+		if !strings.HasPrefix(paths[i], "/") {
+			paths[i] = path.Join("/", opts.BasePath, paths[i])
+		}
+
+		fullMethod := route.Method + " " + paths[i]
+		if _, ok := guards[fullMethod]; ok {
+			return nil, fmt.Errorf("route %s is declared twice", fullMethod)
+		}
+		guards[fullMethod] = Guard{Roles: route.Roles, SkipConsent: route.SkipConsent, Scope: route.Scope}
+	}
+
+	var auth gin.HandlerFunc
+	for fullMethod, guard := range guards {
+		if len(guard.Roles) == 0 {
+			delete(guards, fullMethod)
+		} else if opts.Auth == nil {
+			return nil, fmt.Errorf("route %s has roles but the router has no auth", fullMethod)
+		}
+	}
+	if len(guards) > 0 {
+		auth = opts.Auth(guards)
+	}
+
+	for i, route := range routes {
+		var handlers []gin.HandlerFunc
+		if len(route.Roles) > 0 {
+			handlers = append(handlers, auth)
+		}
+		if opts.RateLimit != nil {
+			handlers = append(handlers, opts.RateLimit)
+		}
+		handlers = append(handlers, route.Middlewares...)
+		handlers = append(handlers, route.Handler)
+
+		router.Handle(route.Method, paths[i], handlers...)
+	}
+
+	return router, nil
+}
+
+// observe records the status and latency of every request but the scrapes of MetricsPath.
+func observe(service string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == MetricsPath {
+			return
+		}
+		metrics.ObserveRequest(service, c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// notMetrics keeps Prometheus scrapes out of the traces.
+func notMetrics(r *http.Request) bool {
+	return r.URL.Path != MetricsPath
+}
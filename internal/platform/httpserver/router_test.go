@@ -0,0 +1,199 @@
+package httpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return Options{
+		BasePath: "v1/test",
+		// Requests never carry credentials in these tests
+		Auth: func(guards map[string]Guard) gin.HandlerFunc {
+			return func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+		},
+	}
+}
+
+func send(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Test auth only applies to routes with roles, wherever they are declared
+func TestNewRouterAuthPerRoute(t *testing.T) {
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	router, err := NewRouter(testOptions(), []Route{
+		{Method: "GET", Path: "public", Handler: ok},
+		{Method: "GET", Path: "private", Handler: ok, Roles: []int8{constants.RegularUsers}},
+		{Method: "GET", Path: "public_after", Handler: ok},
+		{Method: "GET", Path: "/.well-known/test", Handler: ok},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, send(router, "GET", "/v1/test/public").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(router, "GET", "/v1/test/private").Code)
+	assert.Equal(t, http.StatusOK, send(router, "GET", "/v1/test/public_after").Code)
+	assert.Equal(t, http.StatusOK, send(router, "GET", "/.well-known/test").Code)
+
+	w := send(router, "GET", "/v1/test/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"route not found","code":"not_found","key":"error.route_not_found"}`, w.Body.String())
+	assert.Equal(t, http.StatusMethodNotAllowed, send(router, "POST", "/v1/test/public").Code)
+}
+
+// Test auth is built from the guards of the routes with roles and runs before the rate limit
+func TestNewRouterGuards(t *testing.T) {
+	var guards map[string]Guard
+	var order []string
+	opts := testOptions()
+	opts.Auth = func(g map[string]Guard) gin.HandlerFunc {
+		guards = g
+		return func(c *gin.Context) { order = append(order, "auth") }
+	}
+	opts.RateLimit = func(c *gin.Context) { order = append(order, "rate_limit") }
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	router, err := NewRouter(opts, []Route{
+		{Method: "GET", Path: "public", Handler: ok},
+		{Method: "GET", Path: "private", Handler: ok, Roles: []int8{constants.RegularUsers}, SkipConsent: true},
+		{Method: "PUT", Path: "/v2/cart", Handler: ok, Roles: []int8{constants.Guests}, Scope: constants.ScopeCartWrite},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Guard{
+		"GET /v1/test/private": {Roles: []int8{constants.RegularUsers}, SkipConsent: true},
+		"PUT /v2/cart":         {Roles: []int8{constants.Guests}, Scope: constants.ScopeCartWrite},
+	}, guards)
+
+	assert.Equal(t, http.StatusOK, send(router, "GET", "/v1/test/private").Code)
+	assert.Equal(t, []string{"auth", "rate_limit"}, order)
+	order = nil
+	assert.Equal(t, http.StatusOK, send(router, "GET", "/v1/test/public").Code)
+	assert.Equal(t, []string{"rate_limit"}, order)
+
+	opts.Auth = nil
+	_, err = NewRouter(opts, []Route{{Method: "GET", Path: "private", Handler: ok, Roles: []int8{constants.RegularUsers}}})
+	assert.Error(t, err, "routes with roles are never served without auth")
+}
+
+func TestNewRouterRejectsDuplicates(t *testing.T) {
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	_, err := NewRouter(testOptions(), []Route{
+		{Method: "GET", Path: "home", Handler: ok},
+		{Method: "GET", Path: "/v1/test/home", Handler: ok},
+	})
+	assert.Error(t, err)
+}
+
+func TestRecovery(t *testing.T) {
+	router, err := NewRouter(testOptions(), []Route{
+		{Method: "GET", Path: "panic", Handler: func(c *gin.Context) { panic("boom") }},
+	})
+	require.NoError(t, err)
+
+	w := send(router, "GET", "/v1/test/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"internal server error","code":"internal","key":"error.internal"}`, w.Body.String())
+}
+
+// Test handlers see the request ID, taken from the client when usable, and it is echoed back
+func TestRequestID(t *testing.T) {
+	router, err := NewRouter(testOptions(), []Route{
+		{Method: "GET", Path: "id", Handler: func(c *gin.Context) { c.String(http.StatusOK, logging.RequestID(c)) }},
+	})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/v1/test/id", nil)
+	req.Header.Set(logging.RequestIDHeader, "client-id-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "client-id-1", w.Body.String())
+	assert.Equal(t, "client-id-1", w.Header().Get(logging.RequestIDHeader))
+
+	req.Header.Set(logging.RequestIDHeader, "bad id\n")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.NotEqual(t, "bad id\n", w.Body.String())
+	assert.Equal(t, w.Body.String(), w.Header().Get(logging.RequestIDHeader))
+	assert.NotEmpty(t, w.Body.String())
+}
+
+// Test requests are counted by route pattern and the metrics are served on every router
+func TestMetrics(t *testing.T) {
+	opts := testOptions()
+	opts.Service = "metrics_test"
+	router, err := NewRouter(opts, []Route{
+		{Method: "GET", Path: "items/:id", Handler: func(c *gin.Context) { c.String(http.StatusOK, "OK") }},
+	})
+	require.NoError(t, err)
+
+	send(router, "GET", "/v1/test/items/1")
+	send(router, "GET", "/v1/test/items/2")
+
+	w := send(router, "GET", MetricsPath)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `bukka_http_requests_total{method="GET",route="/v1/test/items/:id",service="metrics_test",status="200"} 2`)
+	assert.NotContains(t, w.Body.String(), `route="`+MetricsPath+`"`)
+}
+
+// Test in flight requests finish before Run returns
+func TestRunGracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, NewServer(address, handler, defaultTimeouts), time.Second)
+	}()
+
+	var res *http.Response
+	reqDone := make(chan struct{})
+	go func() {
+		defer close(reqDone)
+		for i := 0; i < 50; i++ {
+			if res, err = http.Get("http://" + address); err == nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	<-started
+	cancel()
+
+	require.NoError(t, <-done)
+	<-reqDone
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+}
+
+func TestTimeoutsFromConfig(t *testing.T) {
+	timeouts := TimeoutsFromConfig(utils.Config{HTTPConfig: utils.HTTPConfig{HTTPWriteTimeout: time.Minute}})
+	assert.Equal(t, time.Minute, timeouts.Write)
+	assert.Equal(t, defaultTimeouts.Read, timeouts.Read)
+	assert.Equal(t, defaultTimeouts.Shutdown, timeouts.Shutdown)
+}
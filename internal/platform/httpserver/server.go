@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+type Timeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+	// Drain is how long servers keep serving, with readiness failing, once shutdown starts
+	Drain time.Duration
+}
+
+var defaultTimeouts = Timeouts{
+	Read:     5 * time.Second,
+	Write:    15 * time.Second,
+	Idle:     120 * time.Second,
+	Shutdown: 10 * time.Second,
+}
+
+// TimeoutsFromConfig reads the HTTP_*_TIMEOUT settings, unset ones fall back to the defaults.
+func TimeoutsFromConfig(config utils.Config) Timeouts {
+	timeouts := defaultTimeouts
+	if config.HTTPReadTimeout > 0 {
+		timeouts.Read = config.HTTPReadTimeout
+	}
+	if config.HTTPWriteTimeout > 0 {
+		timeouts.Write = config.HTTPWriteTimeout
+	}
+	if config.HTTPIdleTimeout > 0 {
+		timeouts.Idle = config.HTTPIdleTimeout
+	}
+	if config.HTTPShutdownTimeout > 0 {
+		timeouts.Shutdown = config.HTTPShutdownTimeout
+	}
+	if config.HTTPDrainDelay > 0 {
+		timeouts.Drain = config.HTTPDrainDelay
+	}
+	return timeouts
+}
+
+func NewServer(address string, handler http.Handler, timeouts Timeouts) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadTimeout:       timeouts.Read,
+		ReadHeaderTimeout: timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+}
+
+// Run serves until ctx is done and then waits up to shutdownTimeout for in flight requests to finish.
+func Run(ctx context.Context, server *http.Server, shutdownTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info().Msgf("listening on %s", server.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info().Msgf("shutting down %s gracefully", server.Addr)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ListenAndServe serves handler on address until the process gets SIGINT or SIGTERM.
+func ListenAndServe(address string, handler http.Handler, timeouts Timeouts) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return Run(ctx, NewServer(address, handler, timeouts), timeouts.Shutdown)
+}
@@ -0,0 +1,152 @@
+// Package logging configures the zerolog logger of the process and carries a logger through the context of every
+// request and task, tagged with the request ID and, once authenticated, the user and session. Fields that look like
+// credentials are redacted before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/utils"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in and out of the HTTP services.
+const RequestIDHeader = "X-Request-ID"
+
+// Formats accepted by LOG_FORMAT.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+type requestIDKey struct{}
+
+// validRequestID bounds the IDs taken from clients so they can't inject anything into logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Setup makes the global logger write at LOG_LEVEL in LOG_FORMAT, redacted. Without them it logs debug to the
+// console in development and info as JSON elsewhere. The standard log package writes through it too.
+func Setup(config utils.Config) error {
+	level, format, err := settings(config)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(level)
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	log.Logger = New(os.Stderr, format)
+	zerolog.DefaultContextLogger = &log.Logger
+
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(log.Logger)
+	return nil
+}
+
+// SetLevel applies LOG_LEVEL to the global logger when the config is reloaded, LOG_FORMAT only applies on Setup.
+func SetLevel(config utils.Config) error {
+	level, _, err := settings(config)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(level)
+	return nil
+}
+
+// New returns a logger writing redacted lines to w in format.
+func New(w io.Writer, format string) zerolog.Logger {
+	if format == FormatConsole {
+		w = zerolog.ConsoleWriter{Out: w, TimeFormat: time.TimeOnly}
+	}
+	return zerolog.New(redactWriter{next: w}).With().Timestamp().Logger()
+}
+
+func settings(config utils.Config) (zerolog.Level, string, error) {
+	development := config.Development()
+
+	level := zerolog.InfoLevel
+	if development {
+		level = zerolog.DebugLevel
+	}
+	if config.LogLevel != "" {
+		parsed, err := zerolog.ParseLevel(strings.ToLower(config.LogLevel))
+		if err != nil {
+			return level, "", fmt.Errorf("invalid LOG_LEVEL %q: %w", config.LogLevel, err)
+		}
+		level = parsed
+	}
+
+	format := FormatJSON
+	if development {
+		format = FormatConsole
+	}
+	switch config.LogFormat {
+	case "":
+	case FormatJSON, FormatConsole:
+		format = config.LogFormat
+	default:
+		return level, "", fmt.Errorf("invalid LOG_FORMAT %q, expected json or console", config.LogFormat)
+	}
+	return level, format, nil
+}
+
+// FromContext returns the logger of ctx, the global one when ctx has none.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx)
+	if logger.GetLevel() == zerolog.Disabled {
+		return &log.Logger
+	}
+	return logger
+}
+
+// NewRequestID returns a fresh request ID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ParseRequestID returns id when a client may set it as the request ID, or a new one.
+func ParseRequestID(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+	return NewRequestID()
+}
+
+// WithRequestID returns ctx carrying id and a logger tagged with it and with the trace of ctx, if any.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	logCtx := FromContext(ctx).With().Str("request_id", id)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		logCtx = logCtx.Str("trace_id", span.TraceID().String())
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logCtx.Logger().WithContext(ctx)
+}
+
+// RequestID returns the request ID of ctx, "" when there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithUser returns ctx whose logger is tagged with the authenticated user and their session.
+func WithUser(ctx context.Context, userID, sessionID string) context.Context {
+	logCtx := FromContext(ctx).With().Str("user_id", userID)
+	if sessionID != "" {
+		logCtx = logCtx.Str("session_id", sessionID)
+	}
+	return logCtx.Logger().WithContext(ctx)
+}
+
+// With returns ctx whose logger carries the fields added by fields.
+func With(ctx context.Context, fields func(zerolog.Context) zerolog.Context) context.Context {
+	return fields(FromContext(ctx).With()).Logger().WithContext(ctx)
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	buf.Reset()
+	return fields
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, FormatJSON)
+
+	logger.Info().Str("new_password", "hunter2").Str("refreshToken", "abc").Str("email_otp", "123456").
+		Str("footprint", "kept").Msg("changed")
+	fields := decode(t, &buf)
+	require.Equal(t, Redacted, fields["new_password"])
+	require.Equal(t, Redacted, fields["refreshToken"])
+	require.Equal(t, Redacted, fields["email_otp"])
+	require.Equal(t, "kept", fields["footprint"])
+	require.Equal(t, "changed", fields["message"])
+
+	logger.Info().Str("header", "Bearer v2.local.abc").
+		Dict("user", zerolog.Dict().Str("password", "hunter2").Str("name", "ada")).Msg("nested")
+	fields = decode(t, &buf)
+	require.Equal(t, Redacted, fields["header"])
+	require.Equal(t, map[string]any{"password": Redacted, "name": "ada"}, fields["user"])
+
+	// Lines without anything sensitive are written as zerolog wrote them
+	logger.Info().Str("path", "/v1/auth/login").Msg("request")
+	require.Contains(t, buf.String(), `"path":"/v1/auth/login"`)
+}
+
+func TestContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, FormatJSON)
+
+	ctx := logger.WithContext(context.Background())
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithUser(ctx, "user-1", "session-1")
+	require.Equal(t, "req-1", RequestID(ctx))
+
+	FromContext(ctx).Info().Msg("hello")
+	fields := decode(t, &buf)
+	require.Equal(t, "req-1", fields["request_id"])
+	require.Equal(t, "user-1", fields["user_id"])
+	require.Equal(t, "session-1", fields["session_id"])
+
+	require.Empty(t, RequestID(context.Background()))
+	require.NotNil(t, FromContext(context.Background()))
+}
+
+func TestParseRequestID(t *testing.T) {
+	require.Equal(t, "abc-123", ParseRequestID("abc-123"))
+	require.NotEqual(t, "", ParseRequestID(""))
+	require.NotEqual(t, "a b", ParseRequestID("a b"))
+	require.NotEqual(t, "{\"level\":\"error\"}", ParseRequestID("{\"level\":\"error\"}"))
+}
+
+func TestSettings(t *testing.T) {
+	level, format, err := settings(utils.Config{AppConfig: utils.AppConfig{Environment: "development"}})
+	require.NoError(t, err)
+	require.Equal(t, zerolog.DebugLevel, level)
+	require.Equal(t, FormatConsole, format)
+
+	level, format, err = settings(utils.Config{AppConfig: utils.AppConfig{Environment: "production"}})
+	require.NoError(t, err)
+	require.Equal(t, zerolog.InfoLevel, level)
+	require.Equal(t, FormatJSON, format)
+
+	level, format, err = settings(utils.Config{
+		AppConfig:       utils.AppConfig{Environment: "production"},
+		TelemetryConfig: utils.TelemetryConfig{LogLevel: "WARN", LogFormat: FormatConsole},
+	})
+	require.NoError(t, err)
+	require.Equal(t, zerolog.WarnLevel, level)
+	require.Equal(t, FormatConsole, format)
+
+	_, _, err = settings(utils.Config{TelemetryConfig: utils.TelemetryConfig{LogLevel: "loud"}})
+	require.Error(t, err)
+	_, _, err = settings(utils.Config{TelemetryConfig: utils.TelemetryConfig{LogFormat: "xml"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive fields.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys redact fields whose lowercased name contains them, e.g. "new_password" or "refreshToken".
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "credential", "api_key", "apikey", "private_key"}
+
+// sensitiveParts redact fields with one of them as a whole part of their name, e.g. "otp" or "email_otp" but not
+// "footprint".
+var sensitiveParts = map[string]bool{"pwd": true, "otp": true, "pin": true}
+
+// credentialMarkers redact string values containing a credential, whatever their field is called.
+var credentialMarkers = []string{"Bearer ", "Basic ", "v2.local.", "v2.public.", "v4.local.", "v4.public."}
+
+// redactWriter rewrites the JSON lines written by zerolog so sensitive fields never reach next. Lines with nothing
+// to redact are passed on as they are.
+type redactWriter struct {
+	next io.Writer
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	if !mayBeSensitive(p) {
+		return w.next.Write(p)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return w.next.Write(p)
+	}
+	if !redact(fields) {
+		return w.next.Write(p)
+	}
+
+	line, err := json.Marshal(fields)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.next.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	// zerolog expects the length of what it wrote
+	return len(p), nil
+}
+
+// mayBeSensitive cheaply rules out most lines before they are parsed.
+func mayBeSensitive(p []byte) bool {
+	lower := bytes.ToLower(p)
+	for _, key := range sensitiveKeys {
+		if bytes.Contains(lower, []byte(key)) {
+			return true
+		}
+	}
+	for part := range sensitiveParts {
+		if bytes.Contains(lower, []byte(part)) {
+			return true
+		}
+	}
+	for _, marker := range credentialMarkers {
+		if bytes.Contains(p, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces sensitive values in fields and the objects nested in it, reporting whether it changed any.
+func redact(fields map[string]any) bool {
+	changed := false
+	for key, value := range fields {
+		if isSensitiveKey(key) {
+			if value != nil && value != "" {
+				fields[key] = Redacted
+				changed = true
+			}
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			if isSensitiveValue(v) {
+				fields[key] = Redacted
+				changed = true
+			}
+		case map[string]any:
+			changed = redact(v) || changed
+		case []any:
+			for i, item := range v {
+				switch item := item.(type) {
+				case map[string]any:
+					changed = redact(item) || changed
+				case string:
+					if isSensitiveValue(item) {
+						v[i] = Redacted
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return changed
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+	for _, part := range strings.FieldsFunc(lower, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if sensitiveParts[part] {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitiveValue(value string) bool {
+	for _, marker := range credentialMarkers {
+		if strings.Contains(value, marker) {
+			return true
+		}
+	}
+	return false
+}
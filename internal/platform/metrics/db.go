@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, []string{"pool"}, nil)
+}
+
+var (
+	poolTotalConns      = poolDesc("total_conns", "Connections open in the pool.")
+	poolAcquiredConns   = poolDesc("acquired_conns", "Connections in use.")
+	poolIdleConns       = poolDesc("idle_conns", "Connections waiting to be used.")
+	poolMaxConns        = poolDesc("max_conns", "Most connections the pool opens, DB_MAX_OPEN_CONN.")
+	poolAcquires        = poolDesc("acquires_total", "Connections acquired from the pool.")
+	poolAcquireDuration = poolDesc("acquire_duration_seconds_total", "Time spent acquiring connections.")
+	poolEmptyAcquires   = poolDesc("empty_acquires_total", "Acquires that had to wait because no connection was idle.")
+	poolCanceled        = poolDesc("canceled_acquires_total", "Acquires given up because their context was done.")
+	poolNewConns        = poolDesc("new_conns_total", "Connections opened by the pool.")
+)
+
+// PoolCollector exposes the counters of db pools, read with sqlc.NewPoolStats on every scrape.
+type PoolCollector struct {
+	pools map[string]*pgxpool.Pool
+}
+
+// NewPoolCollector collects the primary pool as "primary" and replicas as "replica_0", "replica_1"...
+func NewPoolCollector(primary *pgxpool.Pool, replicas []*pgxpool.Pool) *PoolCollector {
+	pools := map[string]*pgxpool.Pool{"primary": primary}
+	for i, pool := range replicas {
+		pools[fmt.Sprintf("replica_%d", i)] = pool
+	}
+	return &PoolCollector{pools: pools}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		poolTotalConns, poolAcquiredConns, poolIdleConns, poolMaxConns,
+		poolAcquires, poolAcquireDuration, poolEmptyAcquires, poolCanceled, poolNewConns,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, pool := range c.pools {
+		stats := sqlc.NewPoolStats(pool)
+		ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stats.AcquiredConns), name)
+		ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stats.MaxConns), name)
+		ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stats.AcquireCount), name)
+		ch <- prometheus.MustNewConstMetric(poolAcquireDuration, prometheus.CounterValue, stats.AcquireDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stats.EmptyAcquireCount), name)
+		ch <- prometheus.MustNewConstMetric(poolCanceled, prometheus.CounterValue, float64(stats.CanceledAcquireCount), name)
+		ch <- prometheus.MustNewConstMetric(poolNewConns, prometheus.CounterValue, float64(stats.NewConnsCount), name)
+	}
+}
@@ -0,0 +1,215 @@
+// Package metrics holds the Prometheus collectors of every Bukka service and the handler serving them. Labels only
+// take values from small, known sets (route patterns, command names, error keys) so series stay bounded, never ids,
+// raw paths or error messages.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+)
+
+const namespace = "bukka"
+
+// unmatchedRoute labels requests that matched no route, so scanners probing random paths add a single series
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with a method outside those of net/http, which clients can make up freely
+const otherMethod = "other"
+
+// requestMethod returns method when it is one of net/http, otherMethod otherwise.
+func requestMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return otherMethod
+	}
+}
+
+// Registry holds every collector of the process. It is separate from prometheus.DefaultRegisterer so libraries
+// can't add series behind our back.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by service, route pattern and status code.",
+	}, []string{"service", "method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to answer HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "route"})
+
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "issued_total",
+		Help:      "Tokens issued by type.",
+	}, []string{"type"})
+
+	tokensRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "rejected_total",
+		Help:      "Tokens that failed verification by type and reason.",
+	}, []string{"type", "reason"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "Login attempts by method and outcome, failures carry the error key as reason.",
+	}, []string{"method", "outcome", "reason"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by a rate limit, by route and what the limit is keyed by.",
+	}, []string{"method", "route", "key"})
+
+	tasksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tasks",
+		Name:      "processed_total",
+		Help:      "Background tasks processed by type and outcome.",
+	}, []string{"type", "outcome"})
+
+	taskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "tasks",
+		Name:      "duration_seconds",
+		Help:      "Time taken to process background tasks.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"type"})
+
+	eventsHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "handled_total",
+		Help:      "Handler attempts on stream events by stream and outcome.",
+	}, []string{"stream", "outcome"})
+
+	eventsParked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "parked_total",
+		Help:      "Stream events given up on and moved to the dead letter stream.",
+	}, []string{"stream"})
+
+	eventDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "handle_duration_seconds",
+		Help:      "Time taken by the handlers of stream events.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"stream"})
+
+	outboxRelayed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "relayed_total",
+		Help:      "Outbox messages relayed by kind and outcome, delivered, retried or failed.",
+	}, []string{"kind", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		tokensIssued, tokensRejected,
+		logins, rateLimited,
+		tasksProcessed, taskDuration,
+		eventsHandled, eventDuration, eventsParked,
+		outboxRelayed,
+		redisDuration, redisErrors,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records a finished HTTP request. route is the pattern the request matched, "" when none did.
+func ObserveRequest(service, method, route string, status int, took time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	method = requestMethod(method)
+	httpRequests.WithLabelValues(service, method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(service, method, route).Observe(took.Seconds())
+}
+
+// TokenIssued counts a token handed out, tokenType is "access" or "refresh".
+func TokenIssued(tokenType string) {
+	tokensIssued.WithLabelValues(tokenType).Inc()
+}
+
+// TokenRejected counts a token that failed verification, reason must come from a fixed set such as "expired".
+func TokenRejected(tokenType, reason string) {
+	tokensRejected.WithLabelValues(tokenType, reason).Inc()
+}
+
+// Login counts a login attempt with method, e.g. "password" or "google". A failed attempt is labelled with the
+// translation key of its error, every internal error shares apperr.KeyInternal.
+func Login(method string, err error) {
+	if err == nil {
+		logins.WithLabelValues(method, "success", "").Inc()
+		return
+	}
+	logins.WithLabelValues(method, "failure", apperr.From(err).Key).Inc()
+}
+
+// RateLimited counts a request rejected by a rate limit keyed by key, e.g. "ip" or "user".
+func RateLimited(method, route, key string) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	rateLimited.WithLabelValues(requestMethod(method), route, key).Inc()
+}
+
+// ObserveTask records a processed background task.
+func ObserveTask(taskType string, err error, took time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	tasksProcessed.WithLabelValues(taskType, outcome).Inc()
+	taskDuration.WithLabelValues(taskType).Observe(took.Seconds())
+}
+
+// ObserveEvent records a handler attempt on an event of stream.
+func ObserveEvent(stream string, err error, took time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	eventsHandled.WithLabelValues(stream, outcome).Inc()
+	eventDuration.WithLabelValues(stream).Observe(took.Seconds())
+}
+
+// EventParked counts an event of stream moved to the dead letter stream.
+func EventParked(stream string) {
+	eventsParked.WithLabelValues(stream).Inc()
+}
+
+// OutboxRelayed counts an attempt to relay an outbox message of kind, outcome is "delivered", "retried" or
+// "failed" once it ran out of attempts.
+func OutboxRelayed(kind, outcome string) {
+	outboxRelayed.WithLabelValues(kind, outcome).Inc()
+}
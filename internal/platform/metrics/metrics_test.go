@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/steve-mir/bukka_backend/internal/platform/apperr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRequest(t *testing.T) {
+	ObserveRequest("test", http.MethodGet, "/v1/test/items/:id", http.StatusOK, 20*time.Millisecond)
+	ObserveRequest("test", http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("test", http.MethodGet, "/v1/test/items/:id", "200")))
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("test", http.MethodGet, unmatchedRoute, "404")))
+
+	// Methods clients make up share a single series
+	ObserveRequest("test", "FOO1", "", http.StatusBadRequest, time.Millisecond)
+	ObserveRequest("test", "FOO2", "", http.StatusBadRequest, time.Millisecond)
+	require.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues("test", otherMethod, unmatchedRoute, "400")))
+}
+
+func TestLogin(t *testing.T) {
+	mismatch := apperr.New(apperr.CodeUnauthenticated, "test.login.mismatch", "id or password mismatch")
+
+	Login("test", nil)
+	Login("test", mismatch)
+	Login("test", errors.New("connection reset by peer"))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(logins.WithLabelValues("test", "success", "")))
+	require.Equal(t, 1.0, testutil.ToFloat64(logins.WithLabelValues("test", "failure", "test.login.mismatch")))
+	// The message of internal errors never becomes a label
+	require.Equal(t, 1.0, testutil.ToFloat64(logins.WithLabelValues("test", "failure", apperr.KeyInternal)))
+}
+
+func TestRedisHook(t *testing.T) {
+	hook := RedisHook{}
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "get" {
+			return redis.Nil
+		}
+		return errors.New("connection refused")
+	})
+
+	ctx := context.Background()
+	require.ErrorIs(t, process(ctx, redis.NewStringCmd(ctx, "get", "missing")), redis.Nil)
+	require.Error(t, process(ctx, redis.NewStatusCmd(ctx, "set", "key", "value")))
+
+	require.Equal(t, 0.0, testutil.ToFloat64(redisErrors.WithLabelValues("get")), "a missing key is not an error")
+	require.Equal(t, 1.0, testutil.ToFloat64(redisErrors.WithLabelValues("set")))
+}
+
+func TestHandler(t *testing.T) {
+	TokenIssued("access")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `bukka_tokens_issued_total{type="access"} 1`)
+	require.Contains(t, rec.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,245 @@
+// Package migrations applies the SQL migrations embedded from db/migration.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/db/migration"
+)
+
+// advisoryLockID identifies the lock replicas take before auto migrating, it is fixed so every replica agrees
+const advisoryLockID int64 = 4_210_720_001
+
+var (
+	ErrDirty        = errors.New("database schema is dirty, a migration failed halfway and must be fixed with migrate force")
+	ErrSchemaAhead  = errors.New("database schema is newer than this build, deploy a newer version or migrate down")
+	ErrSchemaBehind = errors.New("database schema is older than this build, run migrate up or enable AUTO_MIGRATE")
+)
+
+// Status describes where the database stands compared to the embedded migrations.
+type Status struct {
+	// Version is the applied version, zero when nothing was applied yet
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	// Latest is the highest embedded version
+	Latest uint `json:"latest"`
+}
+
+func (s Status) Pending() bool { return s.Version < s.Latest }
+
+// Check returns why a service must not run on this schema, if any.
+func (s Status) Check() error {
+	switch {
+	case s.Dirty:
+		return ErrDirty
+	case s.Version > s.Latest:
+		return ErrSchemaAhead
+	case s.Version < s.Latest:
+		return ErrSchemaBehind
+	}
+	return nil
+}
+
+func (s Status) String() string {
+	state := "up to date"
+	switch {
+	case s.Dirty:
+		state = "dirty"
+	case s.Version > s.Latest:
+		state = "ahead of this build"
+	case s.Version < s.Latest:
+		state = fmt.Sprintf("%d pending", s.Latest-s.Version)
+	}
+	return fmt.Sprintf("version %d of %d (%s)", s.Version, s.Latest, state)
+}
+
+// Migrator runs the embedded migrations against a database.
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// New uses a connection from connPool, which stays open when the migrator is closed.
+func New(connPool *pgxpool.Pool) (*Migrator, error) {
+	src, err := iofs.New(migration.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// The driver closes this handle on Close, closing it leaves the pool itself open
+	db := stdlib.OpenDBFromPool(connPool)
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "pgx5", driver)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new migration instance: %w", err)
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+	return Status{Version: version, Dirty: dirty, Latest: mg.latest}, nil
+}
+
+// Up applies every pending migration.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down reverts the last steps migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+	return ignoreNoChange(mg.m.Steps(-steps))
+}
+
+// DownAll reverts every applied migration.
+func (mg *Migrator) DownAll() error {
+	return ignoreNoChange(mg.m.Down())
+}
+
+// To migrates up or down to version.
+func (mg *Migrator) To(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force sets the version without running anything and clears the dirty flag, after fixing a failed migration by hand.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// AutoMigrate applies pending migrations while holding a Postgres advisory lock, so replicas starting together
+// migrate one after the other and the later ones find nothing to do. It never migrates a dirty or newer schema.
+func AutoMigrate(ctx context.Context, connPool *pgxpool.Pool) error {
+	conn, err := connPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	log.Info().Msg("waiting for the migration lock")
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return fmt.Errorf("cannot take the migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID); err != nil {
+			log.Error().Err(err).Msg("failed to release the migration lock")
+		}
+	}()
+
+	mg, err := New(connPool)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty || status.Version > status.Latest {
+		return status.Check()
+	}
+	if !status.Pending() {
+		log.Info().Msgf("database schema at %s", status)
+		return nil
+	}
+
+	log.Info().Msgf("migrating database schema from %s", status)
+	if err := mg.Up(); err != nil {
+		return fmt.Errorf("failed to run migrate up: %w", err)
+	}
+	log.Info().Msg("db migrated successfully")
+	return nil
+}
+
+// CheckSchema returns an error when services must not start on the database schema, which is when it is dirty or
+// newer than this build. An older schema is only logged, during a rolling deploy the old build keeps serving on it.
+func CheckSchema(connPool *pgxpool.Pool) error {
+	mg, err := New(connPool)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if err := status.Check(); err != nil {
+		if errors.Is(err, ErrSchemaBehind) {
+			log.Warn().Err(err).Msgf("database schema at %s", status)
+			return nil
+		}
+		return fmt.Errorf("%s: %w", status, err)
+	}
+	return nil
+}
+
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for {
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, os.ErrNotExist) {
+				return version, nil
+			}
+			return 0, err
+		}
+		version = next
+	}
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Info().Msgf(format, v...)
+}
+
+func (migrateLogger) Verbose() bool { return false }
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/steve-mir/bukka_backend/db/migration"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrationsArePaired(t *testing.T) {
+	files, err := fs.Glob(migration.FS, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	names := make(map[string]bool, len(files))
+	for _, file := range files {
+		names[file] = true
+	}
+	for _, file := range files {
+		if up, ok := strings.CutSuffix(file, ".up.sql"); ok {
+			require.True(t, names[up+".down.sql"], "%s has no down migration", file)
+		} else if down, ok := strings.CutSuffix(file, ".down.sql"); ok {
+			require.True(t, names[down+".up.sql"], "%s has no up migration", file)
+		} else {
+			t.Fatalf("unexpected migration file name %s", file)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	src, err := iofs.New(migration.FS, ".")
+	require.NoError(t, err)
+
+	latest, err := latestVersion(src)
+	require.NoError(t, err)
+	require.Equal(t, uint(len(mustGlob(t, "*.up.sql"))), latest)
+}
+
+func TestStatusCheck(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status Status
+		err    error
+	}{
+		{name: "UpToDate", status: Status{Version: 9, Latest: 9}},
+		{name: "Behind", status: Status{Version: 7, Latest: 9}, err: ErrSchemaBehind},
+		{name: "Ahead", status: Status{Version: 10, Latest: 9}, err: ErrSchemaAhead},
+		{name: "Dirty", status: Status{Version: 9, Dirty: true, Latest: 9}, err: ErrDirty},
+		{name: "Empty", status: Status{Latest: 9}, err: ErrSchemaBehind},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorIs(t, tc.status.Check(), tc.err)
+		})
+	}
+}
+
+func mustGlob(t *testing.T, pattern string) []string {
+	files, err := fs.Glob(migration.FS, pattern)
+	require.NoError(t, err)
+	return files
+}
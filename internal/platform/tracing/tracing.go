@@ -0,0 +1,151 @@
+// Package tracing sets up OpenTelemetry for the process and carries trace context where OTel can't on its own:
+// Redis commands and background task payloads. HTTP spans come from otelgin in httpserver and query spans from
+// the pgx tracer in sqlc.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/steve-mir/bukka_backend/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of every span started by Bukka code.
+const InstrumentationName = "github.com/steve-mir/bukka_backend"
+
+// Exporters accepted by TRACING_EXPORTER.
+const (
+	ExporterNone     = "none"
+	ExporterOTLP     = "otlp"
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterStdout   = "stdout"
+)
+
+// Tracer returns the tracer Bukka code starts its spans with.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Setup installs the global tracer provider and W3C propagators for serviceName, exporting with TRACING_EXPORTER.
+// The returned func flushes pending spans and must be called before the process exits. Without an exporter
+// spans are still created, so trace context keeps flowing, but nothing is recorded.
+func Setup(ctx context.Context, config utils.Config, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, closeOutput, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(config.AppVersion),
+		semconv.DeploymentEnvironment(config.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("cannot build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(config.TracingSampleRatio)))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+// newExporter returns the exporter picked by TRACING_EXPORTER, nil when tracing is off, and a func closing the
+// file the stdout exporter writes to.
+func newExporter(ctx context.Context, config utils.Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch config.TracingExporter {
+	case "", ExporterNone:
+		return nil, noClose, nil
+
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{}
+		if config.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.TracingOTLPEndpoint))
+		}
+		if config.TracingOTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		return exporter, noClose, err
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{}
+		if config.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.TracingOTLPEndpoint))
+		}
+		if config.TracingOTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		return exporter, noClose, err
+
+	case ExporterStdout:
+		var out io.Writer = os.Stdout
+		closeOutput := noClose
+		if config.TracingFile != "" {
+			file, err := os.OpenFile(config.TracingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot open TRACING_FILE: %w", err)
+			}
+			out, closeOutput = file, file.Close
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+		if err != nil {
+			closeOutput()
+			return nil, nil, err
+		}
+		return exporter, closeOutput, nil
+	}
+	return nil, nil, fmt.Errorf("unknown TRACING_EXPORTER %q, expected none, otlp, otlp_http or stdout", config.TracingExporter)
+}
+
+// sampleRatio reads TRACING_SAMPLE_RATIO, anything outside (0, 1] samples every trace.
+func sampleRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+// Inject returns the trace context of ctx as a map that can travel in a message, nil when there is none.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns ctx carrying the trace context Inject put in carrier, spans started from it join that trace.
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	shutdown, err := Setup(context.Background(), utils.Config{TelemetryConfig: utils.TelemetryConfig{TracingExporter: ExporterNone}}, "test")
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	require.Nil(t, Inject(context.Background()), "no span, nothing to carry")
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+
+	carrier := Inject(trace.ContextWithSpanContext(context.Background(), parent))
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", carrier["traceparent"])
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	require.Equal(t, traceID, extracted.TraceID())
+	require.Equal(t, spanID, extracted.SpanID())
+	require.True(t, extracted.IsRemote())
+}
+
+func TestSetupStdoutFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	config := utils.Config{TelemetryConfig: utils.TelemetryConfig{TracingExporter: ExporterStdout, TracingFile: file}}
+
+	shutdown, err := Setup(context.Background(), config, "test")
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "test.span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	written, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(written), `"Name":"test.span"`)
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), utils.Config{TelemetryConfig: utils.TelemetryConfig{TracingExporter: "jaeger"}}, "test")
+	require.ErrorContains(t, err, `unknown TRACING_EXPORTER "jaeger"`)
+}
+
+func TestSampleRatio(t *testing.T) {
+	require.Equal(t, 0.2, sampleRatio(0.2))
+	require.Equal(t, 1.0, sampleRatio(1))
+	require.Equal(t, 1.0, sampleRatio(0))
+	require.Equal(t, 1.0, sampleRatio(1.5))
+}
@@ -0,0 +1,150 @@
+// Package rabbitmq publishes messages to RabbitMQ exchanges, for consumers outside the services that read RabbitMQ
+// rather than Redis Streams. Publishes wait for the broker to confirm them, so a message Publish returned nil for is
+// stored by the broker, or dropped by it when no queue is bound to its routing key.
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dialTimeout bounds connecting to the broker
+const dialTimeout = 5 * time.Second
+
+// headerRequestID carries the request ID of the request that published a message.
+const headerRequestID = "request_id"
+
+var messagingRabbitMQ = semconv.MessagingSystemRabbitmq
+
+// Publisher publishes to the exchanges of a broker over a single channel. It connects on the first publish and
+// again after the connection or channel closed, so the broker being down only fails the publishes made meanwhile.
+type Publisher struct {
+	url string
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+func NewPublisher(url string) *Publisher {
+	return &Publisher{url: url}
+}
+
+// Publish publishes payload to exchange with routingKey as a persistent message and waits for the broker to confirm
+// it. The message continues the trace and keeps the request ID of ctx in its headers.
+func (p *Publisher) Publish(ctx context.Context, exchange, routingKey string, payload json.RawMessage) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+exchange,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingRabbitMQ, semconv.MessagingOperationPublish, semconv.MessagingDestinationName(exchange),
+			semconv.MessagingRabbitmqDestinationRoutingKey(routingKey)),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	headers := amqp.Table{}
+	for key, value := range tracing.Inject(ctx) {
+		headers[key] = value
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		headers[headerRequestID] = requestID
+	}
+
+	// The channel is used by one publish at a time so confirmations can't be mixed up
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now().UTC(),
+		Headers:      headers,
+		Body:         payload,
+	})
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("cannot publish to %s: %w", exchange, err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// A confirmation that may still come would be taken for the one of the next publish
+		p.reset()
+		return fmt.Errorf("cannot confirm publish to %s: %w", exchange, err)
+	}
+	if !acked {
+		// The broker closes the channel after errors such as a missing exchange
+		p.reset()
+		return fmt.Errorf("broker rejected publish to %s", exchange)
+	}
+	return nil
+}
+
+// channel returns the channel in confirm mode, connecting first when needed. p.mu must be held.
+func (p *Publisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() && !p.conn.IsClosed() {
+		return p.ch, nil
+	}
+	p.reset()
+
+	conn, err := amqp.DialConfig(p.url, amqp.Config{Dial: amqp.DefaultDial(dialTimeout)})
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to rabbitmq: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot open rabbitmq channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot put rabbitmq channel in confirm mode: %w", err)
+	}
+
+	p.conn, p.ch = conn, ch
+	return ch, nil
+}
+
+// reset closes the connection so the next publish opens a new one. p.mu must be held.
+func (p *Publisher) reset() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.ch = nil, nil
+}
+
+// Close closes the connection to the broker, if any.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.ch = nil, nil
+	if errors.Is(err, amqp.ErrClosed) {
+		return nil
+	}
+	return err
+}
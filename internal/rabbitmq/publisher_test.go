@@ -0,0 +1,23 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishWithoutBroker(t *testing.T) {
+	// A port nothing listens on anymore
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	publisher := NewPublisher("amqp://guest:guest@" + addr + "/")
+	err = publisher.Publish(context.Background(), "users", "user.registered", json.RawMessage(`{}`))
+	require.ErrorContains(t, err, "cannot connect to rabbitmq")
+	require.NoError(t, publisher.Close(), "closing a publisher that never connected is a no-op")
+}
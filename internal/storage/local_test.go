@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStoragePutGetDelete(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = s.Put(ctx, "exports/user/archive.zip", strings.NewReader("content"))
+	require.NoError(t, err)
+
+	r, err := s.Get(ctx, "exports/user/archive.zip")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "content", string(data))
+
+	require.NoError(t, s.Delete(ctx, "exports/user/archive.zip"))
+
+	_, err = s.Get(ctx, "exports/user/archive.zip")
+	require.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestLocalStorageRejectsInvalidKeys(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, key := range []string{"", "/", "../outside", "exports/../../outside"} {
+		err = s.Put(ctx, key, strings.NewReader("content"))
+		require.Error(t, err, key)
+	}
+}
+
+func TestNewLocalStorageRequiresRoot(t *testing.T) {
+	s, err := NewLocalStorage("")
+	require.Error(t, err)
+	require.Nil(t, s)
+}
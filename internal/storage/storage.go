@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage is the abstraction used for storing generated files (e.g. data export archives).
+// Keys are slash separated paths relative to the root of the backing store.
+type Storage interface {
+	// Put stores the content of r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens the object stored under key. The caller must close the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the object stored under key
+	Delete(ctx context.Context, key string) error
+}
@@ -2,37 +2,14 @@ package main
 
 import (
 	"os"
-	"os/exec"
-	"sync"
 
 	"github.com/rs/zerolog/log"
+	"github.com/steve-mir/bukka_backend/internal/cli"
 )
 
-func runMicroservices(command string) {
-	cmd := exec.Command("go", "run", command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Error running microservices %s", command)
-	}
-}
-
 func main() {
-	var wg sync.WaitGroup
-
-	microservices := []string{
-		"internal/app/auth/main.go",
-		"internal/app/menu/main.go",
+	if err := cli.NewRootCommand().Execute(); err != nil {
+		log.Error().Err(err).Msg("bukka exited with an error")
+		os.Exit(1)
 	}
-
-	for _, ms := range microservices {
-		wg.Add(1)
-		go func(microservice string) {
-			defer wg.Done()
-			runMicroservices(microservice)
-		}(ms)
-	}
-
-	wg.Wait()
 }
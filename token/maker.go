@@ -13,7 +13,7 @@ type Maker interface {
 	CreateToken(payloadData PayloadData, duration time.Duration, tokenType TokenType) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
-	VerifyToken(ctx context.Context, cache cache.Cache, token string, tokenType TokenType) (*Payload, error)
+	VerifyToken(ctx context.Context, sessions cache.Cache, token string, tokenType TokenType) (*Payload, error)
 
 	// Add a revoke endpoint
 	RevokeTokenAccessToken(token string, ctx context.Context, store sqlc.Store, cache cache.Cache) error
@@ -3,15 +3,17 @@ package token
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	// "github.com/aead/chacha20poly1305"
 	"github.com/o1egl/paseto"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
 	"github.com/steve-mir/bukka_backend/internal/cache"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
 	"golang.org/x/crypto/pbkdf2"
-	// "github.com/aead/chacha20poly1305"
 )
 
 const minSecretKeySize = 32
@@ -65,10 +67,14 @@ func (maker *PasetoMaker) CreateToken(payloadData PayloadData, duration time.Dur
 	}
 
 	token, err := maker.paseto.Encrypt(key, payload, nil)
-	return token, payload, err
+	if err != nil {
+		return "", payload, err
+	}
+	metrics.TokenIssued(string(tokenType))
+	return token, payload, nil
 }
 
-func (maker *PasetoMaker) VerifyToken(ctx context.Context, cache cache.Cache, token string, tokenType TokenType) (*Payload, error) {
+func (maker *PasetoMaker) VerifyToken(ctx context.Context, sessions cache.Cache, token string, tokenType TokenType) (*Payload, error) {
 	payload := &Payload{}
 
 	var key []byte
@@ -83,23 +89,35 @@ func (maker *PasetoMaker) VerifyToken(ctx context.Context, cache cache.Cache, to
 
 	err := maker.paseto.Decrypt(token, key, payload, nil)
 	if err != nil {
+		metrics.TokenRejected(string(tokenType), "malformed")
 		return nil, ErrInvalidToken
 	}
 
 	err = payload.ValidateExpiry()
 	if err != nil {
+		reason := "expired"
+		if errors.Is(err, ErrTokenNotYetValid) {
+			reason = "not_yet_valid"
+		}
+		metrics.TokenRejected(string(tokenType), reason)
 		return nil, err
 	}
 
 	if payload.TokenType != tokenType {
+		metrics.TokenRejected(string(tokenType), "wrong_type")
 		return nil, ErrInvalidToken
 	}
 
 	if payload.TokenType == AccessToken {
-		sessionID, err := cache.GetKey(ctx, token)
-		if err != nil || sessionID == "" {
-			log.Println("Cache error", err)
-			log.Println("SESSION ID", sessionID)
+		sessionID, err := sessions.GetKey(ctx, token)
+		if err != nil && !errors.Is(err, cache.ErrMiss) {
+			logging.FromContext(ctx).Error().Err(err).Msg("cannot look up access token session")
+			metrics.TokenRejected(string(tokenType), "session_lookup_failed")
+			return nil, ErrInvalidToken
+		}
+		if sessionID == "" {
+			// Logging out deletes the token from the cache
+			metrics.TokenRejected(string(tokenType), "revoked")
 			return nil, ErrInvalidToken
 		}
 	}
@@ -124,18 +142,3 @@ func (maker *PasetoMaker) RevokeTokenAccessToken(token string, ctx context.Conte
 	return store.RevokeSessionById(ctx, payload.SessionID)
 
 }
-
-// TODO: Add telemetry
-
-// package stats
-
-// func RecordTokenIssued() {
-//   // record token issued metrics
-// }
-
-// func RecordTokenInvalid() {
-//   // record invalid token metrics
-// }
-
-// stats.RecordTokenIssued()
-// stats.RecordTokenInvalid()
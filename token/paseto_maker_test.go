@@ -126,8 +126,7 @@ func TestVerifyTokenExpired(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
-	cache := cache.NewCache("0.0.0.0:6379", "default", "", 0)
-	payload, err := maker.VerifyToken(context.Background(), *cache, token, TokenType(AccessToken))
+	payload, err := maker.VerifyToken(context.Background(), cache.NewMemory(), token, TokenType(AccessToken))
 	require.Error(t, err)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload) // Ensure payload is nil when the token is expired. require.Nil(t, payload)
@@ -170,8 +169,7 @@ func TestVerifyTokenInvalidKey(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, invalidMaker)
 
-	cache := cache.NewCache("0.0.0.0:6379", "default", "", 0)
-	payload, err := invalidMaker.VerifyToken(context.Background(), *cache, token, TokenType(AccessToken))
+	payload, err := invalidMaker.VerifyToken(context.Background(), cache.NewMemory(), token, TokenType(AccessToken))
 	require.Error(t, err)
 	require.Nil(t, payload)
 	require.EqualError(t, err, ErrInvalidToken.Error())
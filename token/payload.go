@@ -17,6 +17,8 @@ const (
 	AuthPhone             AuthMethod = "phone"
 	AuthGoogle            AuthMethod = "google"
 	AuthApple             AuthMethod = "apple"
+	AuthAPIKey            AuthMethod = "api_key"
+	AuthOAuth             AuthMethod = "oauth"
 	DefaultNotBeforeDelay            = 15 * time.Minute
 )
 
@@ -47,8 +49,13 @@ type PayloadData struct {
 	Platform      string     `json:"platform"`       // "android", "ios", "web", "desktop"
 	OSVersion     string     `json:"os_version"`     // OS version of the device
 	AppVersion    string     `json:"app_version"`    // version of your app
-	AuthMethod    AuthMethod `json:"auth_method"`    // "email", "phone", "google", "apple"
-	// Scope         []string  `json:"scope"`       // array of permission scopes, []string{"read", "write"},
+	AuthMethod    AuthMethod `json:"auth_method"`    // "email", "phone", "google", "apple", "api_key", "oauth"
+
+	// Scopes lists the permissions of api key and oauth payloads, []string{"cart:read", "cart:write"}
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ImpersonatorID is set when an admin is acting as the subject, SessionID is then the impersonation session
+	ImpersonatorID uuid.UUID `json:"impersonator_id,omitempty"`
 }
 
 type Payload struct {
@@ -69,6 +76,11 @@ func NewPayload(payload PayloadData, duration time.Duration) (*Payload, error) {
 
 }
 
+// IsImpersonated reports whether the token was issued to an admin impersonating the subject.
+func (payload *Payload) IsImpersonated() bool {
+	return payload.ImpersonatorID != uuid.Nil
+}
+
 func (payload *Payload) ValidateExpiry() error {
 	currentTime := time.Now()
 	if payload.TokenType == RefreshToken && currentTime.Before(payload.NotBefore) {
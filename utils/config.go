@@ -1,57 +1,479 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 )
 
+// Profiles ENVIRONMENT may select. Each can override app.env with an app.<profile>.env next to it.
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Redacted replaces the value of secrets in Dump.
+const Redacted = "[REDACTED]"
+
+// Config is the configuration of every command of the binary, one section per concern. The sections are
+// embedded so their fields read as config.DBSource. Fields tagged secret can be read from the file named by
+// <KEY>_FILE and never show up in Dump.
 type Config struct {
-	AppUrl                    string        `mapstructure:"APP_URL"`
-	AppName                   string        `mapstructure:"APP_NAME"`
-	Environment               string        `mapstructure:"ENVIRONMENT"`
-	MigrationUrl              string        `mapstructure:"MIGRATION_URL"`
-	DBDriver                  string        `mapstructure:"DB_DRIVER"`
-	DBSource                  string        `mapstructure:"DB_SOURCE"`
-	HTTPAuthServerAddress     string        `mapstructure:"HTTP_AUTH_SERVER_ADDRESS"`
-	HTTPMenuServerAddress     string        `mapstructure:"HTTP_MENU_SERVER_ADDRESS"`
-	RedisAddress              string        `mapstructure:"REDIS_ADDRESS"`
-	RedisUsername             string        `mapstructure:"REDIS_USERNAME"`
-	RedisPwd                  string        `mapstructure:"REDIS_PWD"`
-	RefreshTokenSymmetricKey  string        `mapstructure:"REFRESH_TOKEN_SYMMETRIC_KEY"`
-	AccessTokenSymmetricKey   string        `mapstructure:"ACCESS_TOKEN_SYMMETRIC_KEY"`
+	AppConfig       `mapstructure:",squash"`
+	HTTPConfig      `mapstructure:",squash"`
+	DBConfig        `mapstructure:",squash"`
+	RedisConfig     `mapstructure:",squash"`
+	TokenConfig     `mapstructure:",squash"`
+	GoogleConfig    `mapstructure:",squash"`
+	SMTPConfig      `mapstructure:",squash"`
+	TelemetryConfig `mapstructure:",squash"`
+	ExportConfig    `mapstructure:",squash"`
+	EventsConfig    `mapstructure:",squash"`
+}
+
+type AppConfig struct {
+	AppUrl      string `mapstructure:"APP_URL"`
+	AppName     string `mapstructure:"APP_NAME"`
+	AppVersion  string `mapstructure:"APP_VERSION"`
+	Environment string `mapstructure:"ENVIRONMENT"`
+	AutoMigrate bool   `mapstructure:"AUTO_MIGRATE"`
+}
+
+// HTTPConfig configures the HTTP services. The rate limits are reloaded while serving.
+type HTTPConfig struct {
+	HTTPAuthServerAddress string        `mapstructure:"HTTP_AUTH_SERVER_ADDRESS"`
+	HTTPMenuServerAddress string        `mapstructure:"HTTP_MENU_SERVER_ADDRESS"`
+	WorkerMetricsAddress  string        `mapstructure:"WORKER_METRICS_ADDRESS"`
+	HTTPReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	HTTPShutdownTimeout   time.Duration `mapstructure:"HTTP_SHUTDOWN_TIMEOUT"`
+	HTTPDrainDelay        time.Duration `mapstructure:"HTTP_DRAIN_DELAY"`
+	HealthCheckTimeout    time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	RateLimitDefault      string        `mapstructure:"RATE_LIMIT_DEFAULT"`
+	RateLimits            string        `mapstructure:"RATE_LIMITS"`
+}
+
+type DBConfig struct {
+	DBSource                 string        `mapstructure:"DB_SOURCE" secret:"true"`
+	DBMaxIdleConn            int           `mapstructure:"DB_MAX_IDLE_CONN"`
+	DBMaxOpenConn            int           `mapstructure:"DB_MAX_OPEN_CONN"`
+	DBMaxIdleTime            int           `mapstructure:"DB_MAX_IDLE_TIME"`
+	DBMaxLifeTime            int           `mapstructure:"DB_MAX_LIFE_TIME"`
+	DBQueryExecMode          string        `mapstructure:"DB_QUERY_EXEC_MODE"`
+	DBStatementCacheCapacity int           `mapstructure:"DB_STATEMENT_CACHE_CAPACITY"`
+	DBStatsInterval          time.Duration `mapstructure:"DB_STATS_INTERVAL"`
+	DBReplicaSources         string        `mapstructure:"DB_REPLICA_SOURCES" secret:"true"`
+	DBReplicaMaxLag          time.Duration `mapstructure:"DB_REPLICA_MAX_LAG"`
+	DBReplicaCheckInterval   time.Duration `mapstructure:"DB_REPLICA_CHECK_INTERVAL"`
+	DBReadYourWritesWindow   time.Duration `mapstructure:"DB_READ_YOUR_WRITES_WINDOW"`
+}
+
+type RedisConfig struct {
+	RedisAddress  string `mapstructure:"REDIS_ADDRESS"`
+	RedisUsername string `mapstructure:"REDIS_USERNAME"`
+	RedisPwd      string `mapstructure:"REDIS_PWD" secret:"true"`
+	// CacheLocalTTL keeps hot cache reads in each instance for that long on top of Redis, 0 turns it off
+	CacheLocalTTL        time.Duration `mapstructure:"CACHE_LOCAL_TTL"`
+	CacheLocalMaxEntries int           `mapstructure:"CACHE_LOCAL_MAX_ENTRIES"`
+}
+
+// TokenConfig configures the access, refresh and ID tokens of the auth service.
+type TokenConfig struct {
+	AccessTokenSymmetricKey   string        `mapstructure:"ACCESS_TOKEN_SYMMETRIC_KEY" secret:"true"`
+	RefreshTokenSymmetricKey  string        `mapstructure:"REFRESH_TOKEN_SYMMETRIC_KEY" secret:"true"`
 	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	DBMaxIdleConn             int           `mapstructure:"DB_MAX_IDLE_CONN"`
-	DBMaxOpenConn             int           `mapstructure:"DB_MAX_OPEN_CONN"`
-	DBMaxIdleTime             int           `mapstructure:"DB_MAX_IDLE_TIME"`
-	DBMaxLifeTime             int           `mapstructure:"DB_MAX_LIFE_TIME"`
-	SMTPName                  string        `mapstructure:"SMTP_NAME"`
-	SMTPAddr                  string        `mapstructure:"SMTP_ADDR"`
-	SMTPHost                  string        `mapstructure:"SMTP_HOST"`
-	SMTPUsername              string        `mapstructure:"SMTP_USERNAME"`
-	SMTPPassword              string        `mapstructure:"SMTP_PASSWORD"`
-	GoogleOauthClientId       string        `mapstructure:"GOOGLE_OAUTH_CLIENT_ID"`
-	GoogleOauthClientSecret   string        `mapstructure:"GOOGLE_OAUTH_CLIENT_SECRET"`
-	GoogleOauthClientRedirect string        `mapstructure:"GOOGLE_REDIRECT"`
-	AppVersion                string        `mapstructure:"APP_VERSION"`
-}
-
-// LoadConfig reads configuration from a file or environment variables.
-// It takes the path to the configuration file as input.
-// It returns the loaded configuration and any error encountered during the process.
+	GuestAccessTokenDuration  time.Duration `mapstructure:"GUEST_ACCESS_TOKEN_DURATION"`
+	GuestRefreshTokenDuration time.Duration `mapstructure:"GUEST_REFRESH_TOKEN_DURATION"`
+	ImpersonationDuration     time.Duration `mapstructure:"IMPERSONATION_TOKEN_DURATION"`
+	OIDCIssuer                string        `mapstructure:"OIDC_ISSUER"`
+	OIDCSigningKeyFile        string        `mapstructure:"OIDC_SIGNING_KEY_FILE"`
+	IDTokenDuration           time.Duration `mapstructure:"ID_TOKEN_DURATION"`
+	OAuthCodeDuration         time.Duration `mapstructure:"OAUTH_CODE_DURATION"`
+}
+
+type GoogleConfig struct {
+	GoogleOauthClientId       string `mapstructure:"GOOGLE_OAUTH_CLIENT_ID"`
+	GoogleOauthClientSecret   string `mapstructure:"GOOGLE_OAUTH_CLIENT_SECRET" secret:"true"`
+	GoogleOauthClientRedirect string `mapstructure:"GOOGLE_REDIRECT"`
+}
+
+type SMTPConfig struct {
+	SMTPName     string `mapstructure:"SMTP_NAME"`
+	SMTPAddr     string `mapstructure:"SMTP_ADDR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD" secret:"true"`
+}
+
+// TelemetryConfig configures traces and logs. The log level is reloaded while serving.
+type TelemetryConfig struct {
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	TracingFile         string  `mapstructure:"TRACING_FILE"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	LogLevel            string  `mapstructure:"LOG_LEVEL"`
+	LogFormat           string  `mapstructure:"LOG_FORMAT"`
+}
+
+type ExportConfig struct {
+	ExportStorageDir       string        `mapstructure:"EXPORT_STORAGE_DIR"`
+	DataExportLinkDuration time.Duration `mapstructure:"DATA_EXPORT_LINK_DURATION"`
+	DataExportCooldown     time.Duration `mapstructure:"DATA_EXPORT_COOLDOWN"`
+}
+
+// EventsConfig configures the outbox relay, which runs with the task processor, and the event streams. Zero values
+// take the defaults of the outbox package, a zero EVENTS_STREAM_MAX_LEN never trims the streams.
+type EventsConfig struct {
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMaxAttempts  int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxRetention    time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	EventsStreamMaxLen int64         `mapstructure:"EVENTS_STREAM_MAX_LEN"`
+	// RabbitMQURL is the broker outbox messages of kind amqp are published to, none when empty
+	RabbitMQURL string `mapstructure:"RABBITMQ_URL" secret:"true"`
+}
+
+// Development reports whether the config is for a developer machine, the default when ENVIRONMENT is not set.
+func (a AppConfig) Development() bool {
+	return a.Environment == "" || a.Environment == EnvDevelopment
+}
+
+// Production reports whether the config is for production.
+func (a AppConfig) Production() bool {
+	return a.Environment == EnvProduction
+}
+
+// LoadConfig reads configuration from the app.env file in path, then from app.<ENVIRONMENT>.env when there is
+// one, then from environment variables, each layer overriding the previous one. Secrets are then read from the
+// files named by their <KEY>_FILE. It returns an error listing every invalid key.
 func LoadConfig(path string) (config Config, err error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+	v := viper.New()
+	v.AutomaticEnv()
 
-	viper.AutomaticEnv()
+	v.SetConfigFile(filepath.Join(path, "app.env"))
+	if err = v.ReadInConfig(); err != nil {
+		return
+	}
 
-	err = viper.ReadInConfig()
-	if err != nil {
+	if profile := v.GetString("ENVIRONMENT"); validProfile(profile) && profile != "" {
+		overlay := filepath.Join(path, "app."+profile+".env")
+		if _, statErr := os.Stat(overlay); statErr == nil {
+			v.SetConfigFile(overlay)
+			if err = v.MergeInConfig(); err != nil {
+				return
+			}
+		}
+	}
+
+	if err = readSecretFiles(v); err != nil {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	if err = v.Unmarshal(&config); err != nil {
+		return
+	}
+	err = config.Validate()
 	return
 }
+
+// readSecretFiles sets every secret whose <KEY>_FILE is set to the content of that file, so secrets can be
+// mounted rather than passed in the environment. The file wins over any other layer.
+func readSecretFiles(v *viper.Viper) error {
+	var errs []error
+	for _, key := range secretKeys() {
+		path := v.GetString(key + "_FILE")
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read %s_FILE: %w", key, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks every section and returns an error listing each invalid key. ENVIRONMENT picks how strict it
+// is, production requires what other profiles can run without.
+func (c Config) Validate() error {
+	err := errors.Join(
+		c.AppConfig.validate(),
+		c.HTTPConfig.validate(),
+		c.DBConfig.validate(),
+		c.RedisConfig.validate(),
+		c.TokenConfig.validate(c.AppConfig),
+		c.GoogleConfig.validate(c.AppConfig),
+		c.SMTPConfig.validate(c.AppConfig),
+		c.TelemetryConfig.validate(),
+		c.ExportConfig.validate(),
+		c.EventsConfig.validate(),
+	)
+	if err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
+	return nil
+}
+
+func validProfile(profile string) bool {
+	switch profile {
+	case "", EnvDevelopment, EnvTest, EnvStaging, EnvProduction:
+		return true
+	}
+	return false
+}
+
+func (a AppConfig) validate() error {
+	var errs []error
+	if !validProfile(a.Environment) {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT %q is not one of %s, %s, %s or %s", a.Environment, EnvDevelopment, EnvTest, EnvStaging, EnvProduction))
+	}
+	errs = append(errs, requireURL("APP_URL", a.AppUrl))
+	return errors.Join(errs...)
+}
+
+func (h HTTPConfig) validate() error {
+	return errors.Join(
+		notNegative("HTTP_READ_TIMEOUT", h.HTTPReadTimeout),
+		notNegative("HTTP_WRITE_TIMEOUT", h.HTTPWriteTimeout),
+		notNegative("HTTP_IDLE_TIMEOUT", h.HTTPIdleTimeout),
+		notNegative("HTTP_SHUTDOWN_TIMEOUT", h.HTTPShutdownTimeout),
+		notNegative("HTTP_DRAIN_DELAY", h.HTTPDrainDelay),
+		notNegative("HEALTH_CHECK_TIMEOUT", h.HealthCheckTimeout),
+	)
+}
+
+func (d DBConfig) validate() error {
+	var errs []error
+	errs = append(errs, required("DB_SOURCE", d.DBSource))
+	if d.DBMaxOpenConn < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONN must not be negative"))
+	}
+	if d.DBMaxIdleConn < 0 {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONN must not be negative"))
+	}
+	switch d.DBQueryExecMode {
+	case "", "cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol":
+	default:
+		errs = append(errs, fmt.Errorf("DB_QUERY_EXEC_MODE %q is not a pgx query exec mode", d.DBQueryExecMode))
+	}
+	return errors.Join(errs...)
+}
+
+func (r RedisConfig) validate() error {
+	var localSize error
+	if r.CacheLocalTTL > 0 && r.CacheLocalMaxEntries <= 0 {
+		localSize = errors.New("CACHE_LOCAL_MAX_ENTRIES must be positive when CACHE_LOCAL_TTL is set")
+	}
+	return errors.Join(
+		required("REDIS_ADDRESS", r.RedisAddress),
+		notNegative("CACHE_LOCAL_TTL", r.CacheLocalTTL),
+		localSize,
+	)
+}
+
+// minTokenKeySize is the size token.NewPasetoMaker requires of both keys.
+const minTokenKeySize = 32
+
+func (t TokenConfig) validate(app AppConfig) error {
+	var errs []error
+	if len(t.AccessTokenSymmetricKey) < minTokenKeySize {
+		errs = append(errs, fmt.Errorf("ACCESS_TOKEN_SYMMETRIC_KEY must be at least %d characters", minTokenKeySize))
+	}
+	if len(t.RefreshTokenSymmetricKey) < minTokenKeySize {
+		errs = append(errs, fmt.Errorf("REFRESH_TOKEN_SYMMETRIC_KEY must be at least %d characters", minTokenKeySize))
+	}
+	errs = append(errs,
+		positive("ACCESS_TOKEN_DURATION", t.AccessTokenDuration),
+		positive("REFRESH_TOKEN_DURATION", t.RefreshTokenDuration),
+		positive("GUEST_ACCESS_TOKEN_DURATION", t.GuestAccessTokenDuration),
+		positive("GUEST_REFRESH_TOKEN_DURATION", t.GuestRefreshTokenDuration),
+		positive("IMPERSONATION_TOKEN_DURATION", t.ImpersonationDuration),
+		positive("ID_TOKEN_DURATION", t.IDTokenDuration),
+		positive("OAUTH_CODE_DURATION", t.OAuthCodeDuration),
+		requireURL("OIDC_ISSUER", t.OIDCIssuer),
+	)
+	// ID tokens signed with an ephemeral key stop verifying after a restart
+	if app.Production() {
+		errs = append(errs, required("OIDC_SIGNING_KEY_FILE", t.OIDCSigningKeyFile))
+	}
+	return errors.Join(errs...)
+}
+
+// validate requires the whole Google client in production, elsewhere Google login may be left unconfigured.
+func (g GoogleConfig) validate(app AppConfig) error {
+	return requireTogether(app.Production(), map[string]string{
+		"GOOGLE_OAUTH_CLIENT_ID":     g.GoogleOauthClientId,
+		"GOOGLE_OAUTH_CLIENT_SECRET": g.GoogleOauthClientSecret,
+		"GOOGLE_REDIRECT":            g.GoogleOauthClientRedirect,
+	})
+}
+
+// validate requires the SMTP server in production, elsewhere emails may be left unsent.
+func (s SMTPConfig) validate(app AppConfig) error {
+	return requireTogether(app.Production(), map[string]string{
+		"SMTP_ADDR":     s.SMTPAddr,
+		"SMTP_HOST":     s.SMTPHost,
+		"SMTP_USERNAME": s.SMTPUsername,
+		"SMTP_PASSWORD": s.SMTPPassword,
+	})
+}
+
+func (t TelemetryConfig) validate() error {
+	var errs []error
+	if t.TracingSampleRatio < 0 || t.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO %v must be between 0 and 1", t.TracingSampleRatio))
+	}
+	if t.LogLevel != "" {
+		if _, err := zerolog.ParseLevel(strings.ToLower(t.LogLevel)); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_LEVEL %q is not a log level", t.LogLevel))
+		}
+	}
+	switch t.LogFormat {
+	case "", "json", "console":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT %q is not json or console", t.LogFormat))
+	}
+	return errors.Join(errs...)
+}
+
+func (e ExportConfig) validate() error {
+	return errors.Join(
+		required("EXPORT_STORAGE_DIR", e.ExportStorageDir),
+		positive("DATA_EXPORT_LINK_DURATION", e.DataExportLinkDuration),
+		notNegative("DATA_EXPORT_COOLDOWN", e.DataExportCooldown),
+	)
+}
+
+func (e EventsConfig) validate() error {
+	var errs []error
+	if e.OutboxBatchSize < 0 {
+		errs = append(errs, errors.New("OUTBOX_BATCH_SIZE must not be negative"))
+	}
+	if e.OutboxMaxAttempts < 0 {
+		errs = append(errs, errors.New("OUTBOX_MAX_ATTEMPTS must not be negative"))
+	}
+	if e.EventsStreamMaxLen < 0 {
+		errs = append(errs, errors.New("EVENTS_STREAM_MAX_LEN must not be negative"))
+	}
+	errs = append(errs,
+		notNegative("OUTBOX_POLL_INTERVAL", e.OutboxPollInterval),
+		notNegative("OUTBOX_RETENTION", e.OutboxRetention),
+	)
+	return errors.Join(errs...)
+}
+
+func required(key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s is required", key)
+	}
+	return nil
+}
+
+func requireURL(key, value string) error {
+	if err := required(key, value); err != nil {
+		return err
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s %q is not an http(s) url", key, value)
+	}
+	return nil
+}
+
+// requireTogether requires every key when always is set, otherwise either all of them or none.
+func requireTogether(always bool, values map[string]string) error {
+	var set, missing []string
+	for key, value := range values {
+		if value == "" {
+			missing = append(missing, key)
+		} else {
+			set = append(set, key)
+		}
+	}
+	if len(missing) == 0 || (!always && len(set) == 0) {
+		return nil
+	}
+
+	var errs []error
+	sort.Strings(missing)
+	for _, key := range missing {
+		errs = append(errs, fmt.Errorf("%s is required", key))
+	}
+	return errors.Join(errs...)
+}
+
+func positive(key string, value time.Duration) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be a positive duration", key)
+	}
+	return nil
+}
+
+func notNegative(key string, value time.Duration) error {
+	if value < 0 {
+		return fmt.Errorf("%s must not be negative", key)
+	}
+	return nil
+}
+
+// Dump returns every key of the config with its value, secrets that are set replaced by Redacted.
+func (c Config) Dump() map[string]any {
+	dump := make(map[string]any)
+	walkConfig(reflect.ValueOf(c), func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("mapstructure")
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if value.IsZero() {
+				dump[key] = ""
+			} else {
+				dump[key] = Redacted
+			}
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			dump[key] = value.Interface().(time.Duration).String()
+		default:
+			dump[key] = value.Interface()
+		}
+	})
+	return dump
+}
+
+// secretKeys returns the keys of the fields tagged secret.
+func secretKeys() []string {
+	var keys []string
+	walkConfig(reflect.ValueOf(Config{}), func(field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("secret") == "true" {
+			keys = append(keys, field.Tag.Get("mapstructure"))
+		}
+	})
+	return keys
+}
+
+// walkConfig calls fn with every keyed field of the sections of v.
+func walkConfig(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			walkConfig(v.Field(i), fn)
+			continue
+		}
+		if field.Tag.Get("mapstructure") != "" {
+			fn(field, v.Field(i))
+		}
+	}
+}
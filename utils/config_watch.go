@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// reloadableKeys are the keys applied while running, every other change waits for a restart.
+var reloadableKeys = map[string]bool{
+	"LOG_LEVEL":          true,
+	"RATE_LIMIT_DEFAULT": true,
+	"RATE_LIMITS":        true,
+}
+
+// reloadDebounce groups the events of one save, editors and Kubernetes volume updates write several.
+const reloadDebounce = 500 * time.Millisecond
+
+// ConfigWatcher reloads the config files when they change and hands the config to the subscribers once the new
+// values are valid. Only reloadableKeys change, environment variables and secrets files are read again but a
+// change to anything else is logged and ignored until the next restart.
+type ConfigWatcher struct {
+	path        string
+	mu          sync.RWMutex
+	current     Config
+	subscribers []func(Config)
+}
+
+// NewConfigWatcher watches the config files in path, config is the one loaded from there at startup.
+func NewConfigWatcher(path string, config Config) *ConfigWatcher {
+	return &ConfigWatcher{path: path, current: config}
+}
+
+// Current returns the config with the reloaded keys applied.
+func (w *ConfigWatcher) Current() Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnReload calls fn with the config every time reloadable keys change. fn must not block.
+func (w *ConfigWatcher) OnReload(fn func(Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload loads the config again and applies the reloadable keys that changed. An invalid config is refused as a
+// whole and the current one is kept.
+func (w *ConfigWatcher) Reload() error {
+	next, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	applied, changed, ignored := applyReloadable(w.current, next)
+	w.current = applied
+	subscribers := make([]func(Config), len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mu.Unlock()
+
+	if len(ignored) > 0 {
+		log.Warn().Strs("keys", ignored).Msg("config changed, restart to apply")
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	log.Info().Strs("keys", changed).Msg("config reloaded")
+	for _, fn := range subscribers {
+		fn(applied)
+	}
+	return nil
+}
+
+// applyReloadable copies the reloadable keys of next into current. It returns the keys it copied and those that
+// changed but can't be applied.
+func applyReloadable(current, next Config) (Config, []string, []string) {
+	var changed, ignored []string
+	applied := reflect.ValueOf(&current).Elem()
+	walkFields(applied, reflect.ValueOf(next), func(key string, dst, src reflect.Value) {
+		if reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			return
+		}
+		if !reloadableKeys[key] {
+			ignored = append(ignored, key)
+			return
+		}
+		dst.Set(src)
+		changed = append(changed, key)
+	})
+	sort.Strings(changed)
+	sort.Strings(ignored)
+	return current, changed, ignored
+}
+
+// walkFields calls fn with the matching keyed fields of dst and src, two values of the same section type.
+func walkFields(dst, src reflect.Value, fn func(key string, dst, src reflect.Value)) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			walkFields(dst.Field(i), src.Field(i), fn)
+			continue
+		}
+		if key := field.Tag.Get("mapstructure"); key != "" {
+			fn(key, dst.Field(i), src.Field(i))
+		}
+	}
+}
+
+// Run reloads the config whenever a file of its directory changes, until ctx is done. The directory is watched
+// rather than the files since editors and Kubernetes config maps replace them.
+func (w *ConfigWatcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot watch config: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(w.path); err != nil {
+		return fmt.Errorf("cannot watch config in %s: %w", w.path, err)
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if configFile(event.Name) {
+				debounce = time.After(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error().Err(err).Msg("config watcher failed")
+		case <-debounce:
+			debounce = nil
+			if err := w.Reload(); err != nil {
+				log.Error().Err(err).Msg("refusing to reload config")
+			}
+		}
+	}
+}
+
+// configFile reports whether name is app.env, a profile of it or the data link Kubernetes swaps on updates.
+func configFile(name string) bool {
+	base := filepath.Base(name)
+	return base == "..data" || (strings.HasPrefix(base, "app.") && strings.HasSuffix(base, ".env"))
+}
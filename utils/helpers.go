@@ -2,48 +2,31 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"math/big"
-	"net"
 	"net/netip"
-
-	"github.com/sqlc-dev/pqtype"
 )
 
-func GetIpAddr(clientIP string) pqtype.Inet {
-	if clientIP == "::1" {
-		clientIP = "127.0.0.1"
-	}
-
-	ip := net.ParseIP(clientIP)
-
-	if ip == nil {
-		// Handle the case where ctx.ClientIP() doesn't return a valid IP address
-		return pqtype.Inet{}
+// GetIpAddr parses a client IP for the inet columns. IPv4 addresses mapped into IPv6 are unmapped and zones are
+// dropped since inet cannot store them. It returns nil when clientIP is not an address.
+func GetIpAddr(clientIP string) *netip.Addr {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return nil
 	}
 
-	inet := pqtype.Inet{
-		IPNet: net.IPNet{
-			IP:   ip,
-			Mask: net.CIDRMask(32, 32), // If you're dealing with IPv4 addresses
-		},
-		Valid: true,
-	}
-	return inet
+	addr = addr.Unmap().WithZone("")
+	return &addr
 }
 
-func GetIpAddrForPgx(clientIP string) (*netip.Addr, error) {
-	if clientIP == "::1" {
-		clientIP = "127.0.0.1"
+// GetIpAddrOrUnspecified is GetIpAddr for NOT NULL inet columns, an unparsable IP is stored as 0.0.0.0.
+func GetIpAddrOrUnspecified(clientIP string) netip.Addr {
+	if addr := GetIpAddr(clientIP); addr != nil {
+		return *addr
 	}
-
-	addr, err := netip.ParseAddr(clientIP)
-	if err != nil {
-		// Handle the error if the IP address string is not valid.
-		return nil, err
-	}
-
-	return &addr, nil
+	return netip.IPv4Unspecified()
 }
 
 func GetKeyForToken(config Config, isRefresh bool) string {
@@ -76,3 +59,10 @@ func GenerateUniqueToken(len int) (string, error) {
 
 	return token, nil
 }
+
+// HashToken returns the hex sha256 of a token, which is stored in its place so a leaked table can't be used to
+// redeem tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
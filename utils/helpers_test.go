@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIpAddr(t *testing.T) {
+	testCases := []struct {
+		clientIP string
+		want     string
+	}{
+		{"203.0.113.7", "203.0.113.7"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"::1", "::1"},
+		{"::ffff:203.0.113.7", "203.0.113.7"},
+		{"fe80::1%eth0", "fe80::1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.clientIP, func(t *testing.T) {
+			addr := GetIpAddr(tc.clientIP)
+			require.NotNil(t, addr)
+			require.Equal(t, tc.want, addr.String())
+		})
+	}
+
+	require.Nil(t, GetIpAddr(""))
+	require.Nil(t, GetIpAddr("not-an-ip"))
+	require.Equal(t, "0.0.0.0", GetIpAddrOrUnspecified("").String())
+}
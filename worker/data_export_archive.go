@@ -0,0 +1,261 @@
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+)
+
+// The export types below mirror the db models but drop secrets (password hashes, refresh tokens,
+// verification codes) and flatten the pgtype wrappers so the archive is readable by the user.
+
+type exportAccount struct {
+	ID                  string     `json:"id"`
+	Email               string     `json:"email"`
+	Phone               string     `json:"phone,omitempty"`
+	Username            string     `json:"username,omitempty"`
+	CreatedAt           *time.Time `json:"created_at,omitempty"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+	IsSuspended         bool       `json:"is_suspended"`
+	IsDeleted           bool       `json:"is_deleted"`
+	IsVerified          bool       `json:"is_verified"`
+	IsEmailVerified     bool       `json:"is_email_verified"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+	VerifiedAt          *time.Time `json:"verified_at,omitempty"`
+	SuspendedAt         *time.Time `json:"suspended_at,omitempty"`
+	PasswordLastChanged *time.Time `json:"password_last_changed,omitempty"`
+	IsMfaEnabled        bool       `json:"is_mfa_enabled"`
+	IsOauthUser         bool       `json:"is_oauth_user"`
+}
+
+type exportProfile struct {
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	ImageUrl  string `json:"image_url,omitempty"`
+}
+
+type exportRole struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type exportSession struct {
+	ID            string     `json:"id"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	LastActiveAt  *time.Time `json:"last_active_at,omitempty"`
+	InvalidatedAt *time.Time `json:"invalidated_at,omitempty"`
+	BlockedAt     *time.Time `json:"blocked_at,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UserAgent     string     `json:"user_agent"`
+	IpAddress     string     `json:"ip_address,omitempty"`
+}
+
+type exportLogin struct {
+	LoginAt   *time.Time `json:"login_at,omitempty"`
+	IpAddress string     `json:"ip_address,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+}
+
+type exportRequest struct {
+	Email     string     `json:"email"`
+	Completed bool       `json:"completed"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+type exportAuditLog struct {
+	Action    string          `json:"action"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	IpAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// buildUserDataArchive collects everything held about the user and returns it as a zip archive
+// with one JSON document per data set.
+func buildUserDataArchive(ctx context.Context, store sqlc.Store, user sqlc.Authentication) ([]byte, error) {
+	files := map[string]interface{}{
+		"account.json": exportAccount{
+			ID:                  user.ID.String(),
+			Email:               user.Email,
+			Phone:               user.Phone.String,
+			Username:            user.Username.String,
+			CreatedAt:           nullTime(user.CreatedAt),
+			UpdatedAt:           nullTime(user.UpdatedAt),
+			IsSuspended:         user.IsSuspended.Bool,
+			IsDeleted:           user.IsDeleted.Bool,
+			IsVerified:          user.IsVerified.Bool,
+			IsEmailVerified:     user.IsEmailVerified.Bool,
+			DeletedAt:           nullTime(user.DeletedAt),
+			VerifiedAt:          nullTime(user.VerifiedAt),
+			SuspendedAt:         nullTime(user.SuspendedAt),
+			PasswordLastChanged: nullTime(user.PasswordLastChanged),
+			IsMfaEnabled:        user.IsMfaEnabled.Bool,
+			IsOauthUser:         user.IsOauthUser.Bool,
+		},
+	}
+
+	profile, err := store.GetUserProfileByUserID(ctx, user.ID)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	files["profile.json"] = exportProfile{
+		FirstName: profile.FirstName.String,
+		LastName:  profile.LastName.String,
+		ImageUrl:  profile.ImageUrl.String,
+	}
+
+	roles, err := store.GetUserRolesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	exportRoles := make([]exportRole, 0, len(roles))
+	for _, r := range roles {
+		exportRoles = append(exportRoles, exportRole{ID: r.RoleID, Name: r.Name})
+	}
+	files["roles.json"] = exportRoles
+
+	sessions, err := store.GetSessionsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	exportSessions := make([]exportSession, 0, len(sessions))
+	for _, s := range sessions {
+		exportSessions = append(exportSessions, exportSession{
+			ID:            s.ID.String(),
+			CreatedAt:     nullTime(s.CreatedAt),
+			LastActiveAt:  nullTime(s.LastActiveAt),
+			InvalidatedAt: nullTime(s.InvalidatedAt),
+			BlockedAt:     nullTime(s.BlockedAt),
+			ExpiresAt:     s.RefreshTokenExp,
+			UserAgent:     s.UserAgent,
+			IpAddress:     inetString(&s.IpAddress),
+		})
+	}
+	files["sessions.json"] = exportSessions
+
+	logins, err := store.GetUserLoginsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logins: %w", err)
+	}
+	exportLogins := make([]exportLogin, 0, len(logins))
+	for _, l := range logins {
+		exportLogins = append(exportLogins, exportLogin{
+			LoginAt:   nullTime(l.LoginAt),
+			IpAddress: inetString(l.IpAddress),
+			UserAgent: l.UserAgent.String,
+		})
+	}
+	files["logins.json"] = exportLogins
+
+	verifications, err := store.GetEmailVerificationRequestsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email verification history: %w", err)
+	}
+	exportVerifications := make([]exportRequest, 0, len(verifications))
+	for _, v := range verifications {
+		exportVerifications = append(exportVerifications, exportRequest{
+			Email:     v.Email,
+			Completed: v.IsVerified.Bool,
+			CreatedAt: nullTime(v.CreatedAt),
+			ExpiresAt: v.ExpiresAt,
+		})
+	}
+	files["email_verifications.json"] = exportVerifications
+
+	resets, err := store.GetPasswordResetRequestsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password reset history: %w", err)
+	}
+	exportResets := make([]exportRequest, 0, len(resets))
+	for _, r := range resets {
+		exportResets = append(exportResets, exportRequest{
+			Email:     r.Email,
+			Completed: r.Used.Bool,
+			CreatedAt: nullTime(r.CreatedAt),
+			ExpiresAt: r.ExpiresAt,
+		})
+	}
+	files["password_resets.json"] = exportResets
+
+	recoveries, err := store.GetAccountRecoveryRequestsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account recovery history: %w", err)
+	}
+	exportRecoveries := make([]exportRequest, 0, len(recoveries))
+	for _, r := range recoveries {
+		exportRecoveries = append(exportRecoveries, exportRequest{
+			Email:     r.Email,
+			Completed: r.Used.Bool,
+			CreatedAt: nullTime(r.RequestedAt),
+			ExpiresAt: r.ExpiresAt,
+		})
+	}
+	files["account_recoveries.json"] = exportRecoveries
+
+	auditLogs, err := store.GetAuditLogsByUserID(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	exportAuditLogs := make([]exportAuditLog, 0, len(auditLogs))
+	for _, a := range auditLogs {
+		exportAuditLogs = append(exportAuditLogs, exportAuditLog{
+			Action:    a.Action,
+			Metadata:  json.RawMessage(a.Metadata),
+			IpAddress: inetString(a.IpAddress),
+			UserAgent: a.UserAgent.String,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	files["activity.json"] = exportAuditLogs
+
+	// TODO: Add orders once the orders service stores data
+
+	return writeZip(files)
+}
+
+func writeZip(files map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to archive: %w", name, err)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func nullTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func inetString(ip *netip.Addr) string {
+	if ip == nil || !ip.IsValid() {
+		return ""
+	}
+	return ip.String()
+}
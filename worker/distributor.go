@@ -2,16 +2,24 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/hibiken/asynq"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
 )
 
 type TaskDistributor interface {
-	DistributeTaskSendVerifyEmail(
+	// DistributeTask enqueues a task of taskType whose payload, a JSON object, was marshalled beforehand, such as
+	// by Enqueue or the outbox. Tasks dropped as duplicates of a unique task count as enqueued.
+	DistributeTask(
 		ctx context.Context,
-		payload *PayloadSendEmail,
+		taskType string,
+		payload json.RawMessage,
 		opts ...asynq.Option,
 	) error
+	Close() error
 }
 
 type RedisTaskDistributor struct {
@@ -24,3 +32,32 @@ func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
 		client: asynq.NewClient(redisOpt),
 	}
 }
+
+// DistributeTask enqueues payload as a task of taskType continuing the trace of ctx.
+func (distributor *RedisTaskDistributor) DistributeTask(
+	ctx context.Context,
+	taskType string,
+	payload json.RawMessage,
+	opts ...asynq.Option,
+) error {
+	fields := rawPayload{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return fmt.Errorf("payload of %s is not a JSON object: %w", taskType, err)
+	}
+
+	task, info, err := distributor.enqueue(ctx, taskType, fields, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+		logging.FromContext(ctx).Info().Str("type", taskType).Msg("dropped duplicate task")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info().Str("type", task.Type()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (distributor *RedisTaskDistributor) Close() error {
+	return distributor.client.Close()
+}
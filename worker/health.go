@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hibiken/asynq"
+)
+
+// serverActive is the status asynq servers report while they process tasks.
+const serverActive = "active"
+
+// ServersCheck reports whether task processors are running. With local set the processor of this process must be
+// one of them, otherwise any will do since tasks enqueued here are processed elsewhere.
+func ServersCheck(inspector *asynq.Inspector, local bool) func(ctx context.Context) error {
+	host, _ := os.Hostname()
+	pid := os.Getpid()
+
+	return func(ctx context.Context) error {
+		servers, err := inspector.Servers()
+		if err != nil {
+			return fmt.Errorf("cannot list task processors: %w", err)
+		}
+		for _, server := range servers {
+			if server.Status != serverActive {
+				continue
+			}
+			if !local || (server.Host == host && server.PID == pid) {
+				return nil
+			}
+		}
+		if local {
+			return errors.New("the task processor of this process is not running")
+		}
+		return errors.New("no task processor is running")
+	}
+}
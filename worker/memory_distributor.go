@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hibiken/asynq"
+)
+
+// EnqueuedTask is a task kept by a MemoryDistributor.
+type EnqueuedTask struct {
+	Type     string
+	Payload  json.RawMessage
+	Queue    string
+	MaxRetry int
+	TaskID   string
+}
+
+// MemoryDistributor keeps the tasks enqueued in memory for tests to inspect, nothing processes them. Like asynq it
+// drops tasks whose ID was already enqueued.
+type MemoryDistributor struct {
+	mu    sync.Mutex
+	tasks []EnqueuedTask
+	ids   map[string]bool
+}
+
+func NewMemoryDistributor() *MemoryDistributor {
+	return &MemoryDistributor{ids: make(map[string]bool)}
+}
+
+func (d *MemoryDistributor) DistributeTask(ctx context.Context, taskType string, payload json.RawMessage, opts ...asynq.Option) error {
+	if !json.Valid(payload) {
+		return fmt.Errorf("payload of %s is not JSON", taskType)
+	}
+
+	task := EnqueuedTask{Type: taskType, Payload: payload, Queue: QueueDefault}
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.QueueOpt:
+			task.Queue = opt.Value().(string)
+		case asynq.MaxRetryOpt:
+			task.MaxRetry = opt.Value().(int)
+		case asynq.TaskIDOpt:
+			task.TaskID = opt.Value().(string)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if task.TaskID != "" {
+		if d.ids[task.TaskID] {
+			return nil
+		}
+		d.ids[task.TaskID] = true
+	}
+	d.tasks = append(d.tasks, task)
+	return nil
+}
+
+// Tasks returns the tasks enqueued so far, oldest first.
+func (d *MemoryDistributor) Tasks() []EnqueuedTask {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]EnqueuedTask(nil), d.tasks...)
+}
+
+func (d *MemoryDistributor) Close() error {
+	return nil
+}
+
+// Enqueued returns the payloads of the tasks of task enqueued on d so far, oldest first.
+func Enqueued[T any](d *MemoryDistributor, task Task[T]) ([]T, error) {
+	var payloads []T
+	for _, enqueued := range d.Tasks() {
+		if enqueued.Type != task.Type {
+			continue
+		}
+		var payload T
+		if err := json.Unmarshal(enqueued.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload of %s: %w", task.Type, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
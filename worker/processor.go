@@ -2,11 +2,16 @@ package worker
 
 import (
 	"context"
-	"database/sql"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/featureflag"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/metrics"
+	"github.com/steve-mir/bukka_backend/internal/storage"
+	"github.com/steve-mir/bukka_backend/mailer"
 	"github.com/steve-mir/bukka_backend/utils"
 )
 
@@ -17,17 +22,20 @@ const (
 
 type TaskProcessor interface {
 	Start() error
-	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	Shutdown()
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  sqlc.Store
-	db     *sql.DB
-	config utils.Config
+	server  *asynq.Server
+	store   sqlc.Store
+	config  utils.Config
+	storage storage.Storage
+	mailer  mailer.EmailSender
+	// flags are evaluated for the subject tasks set with featureflag.WithSubject
+	flags *featureflag.Client
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store sqlc.Store, db *sql.DB, config utils.Config) TaskProcessor {
+func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store sqlc.Store, config utils.Config, flags *featureflag.Client) TaskProcessor {
 
 	server := asynq.NewServer(
 		redisOpt,
@@ -37,25 +45,52 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store sqlc.Store, db *
 				QueueDefault:  5,
 			},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				log.Error().Err(err).Str("type", task.Type()).
-					Bytes("payload", task.Payload()).Msg("process task failed")
+				ctx = taskLogger(ctx, task, readTaskContext(task))
+				logging.FromContext(ctx).Error().Err(err).Msg("process task failed")
 			}),
 			Logger: NewLogger(),
 		})
 
+	exportStorage, err := storage.NewLocalStorage(config.ExportStorageDir)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot create export storage, data exports will fail")
+	}
+
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
-		db:     db,
-		config: config,
+		server:  server,
+		store:   store,
+		config:  config,
+		storage: exportStorage,
+		mailer:  mailer.NewSMTPSender("Bukka", config.SMTPAddr, config.SMTPHost, "2525", config.SMTPUsername, config.SMTPPassword),
+		flags:   flags,
 	}
 }
 
 func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
+	mux.Use(continueTask, observeTask)
 
-	// Register tasks here. Very important
-	mux.HandleFunc(TaskSendEmail, processor.ProcessTaskSendVerifyEmail)
+	// Every task of the catalogue needs a handler here, tasks without one are retried until they are archived
+	Handle(mux, TaskVerifyEmail, processor.processVerifyEmail)
+	Handle(mux, TaskPasswordResetEmail, processor.processPasswordResetEmail)
+	Handle(mux, TaskAccountRecoveryEmail, processor.processAccountRecoveryEmail)
+	Handle(mux, TaskSendEmail, processor.processSendEmail)
+	Handle(mux, TaskExportUserData, processor.processExportUserData)
 
 	return processor.server.Start(mux)
 }
+
+// observeTask records the outcome and duration of every task.
+func observeTask(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		start := time.Now()
+		err := next.ProcessTask(ctx, task)
+		metrics.ObserveTask(task.Type(), err, time.Since(start))
+		return err
+	})
+}
+
+// Shutdown stops fetching new tasks and waits for the running ones to finish.
+func (processor *RedisTaskProcessor) Shutdown() {
+	processor.server.Shutdown()
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// Task describes a task of the catalogue: the type of its payload, where it runs and how it is retried. Enqueue
+// tasks with Enqueue, or outbox.AddTask when they announce a change, and handle them with Handle.
+type Task[T any] struct {
+	Type     string
+	Queue    string
+	MaxRetry int
+	// Timeout bounds an attempt, zero leaves the default of asynq
+	Timeout time.Duration
+	// UniqueKey, when set, makes the tasks whose payloads have the same key run once: enqueueing one while another
+	// is pending, or completed less than UniqueFor ago, is dropped. Outbox messages relayed twice are sent once.
+	UniqueKey func(payload T) string
+	UniqueFor time.Duration
+}
+
+// TaskID returns the ID tasks with payload are enqueued with, "" when the task isn't unique.
+func (t Task[T]) TaskID(payload T) string {
+	if t.UniqueKey == nil {
+		return ""
+	}
+	return t.Type + ":" + t.UniqueKey(payload)
+}
+
+// Options returns the asynq options of a task with payload.
+func (t Task[T]) Options(payload T) []asynq.Option {
+	opts := []asynq.Option{asynq.Queue(t.Queue), asynq.MaxRetry(t.MaxRetry)}
+	if t.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(t.Timeout))
+	}
+	if id := t.TaskID(payload); id != "" {
+		opts = append(opts, asynq.TaskID(id), asynq.Retention(t.UniqueFor))
+	}
+	return opts
+}
+
+// Enqueue enqueues a task with payload now, on top of the options of task.
+func Enqueue[T any](ctx context.Context, distributor TaskDistributor, task Task[T], payload T, opts ...asynq.Option) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload of %s: %w", task.Type, err)
+	}
+	return distributor.DistributeTask(ctx, task.Type, data, append(task.Options(payload), opts...)...)
+}
+
+// Handle makes handler process the tasks of task. Payloads that can't be decoded are not retried.
+func Handle[T any](mux *asynq.ServeMux, task Task[T], handler func(ctx context.Context, payload T) error) {
+	mux.HandleFunc(task.Type, func(ctx context.Context, t *asynq.Task) error {
+		var payload T
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+		}
+		return handler(ctx, payload)
+	})
+}
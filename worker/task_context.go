@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/internal/platform/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var messagingAsynq = semconv.MessagingSystemKey.String("asynq")
+
+// TaskContext is added to task payloads so processing a task joins the trace that enqueued it and logs the ID of
+// the request that did. enqueue fills it in, payloads never set it themselves.
+type TaskContext struct {
+	Trace           map[string]string `json:"trace,omitempty"`
+	OriginRequestID string            `json:"origin_request_id,omitempty"`
+}
+
+func (t *TaskContext) setContext(ctx context.Context) {
+	t.Trace = tracing.Inject(ctx)
+	t.OriginRequestID = logging.RequestID(ctx)
+}
+
+type contextPayload interface {
+	setContext(ctx context.Context)
+}
+
+// rawPayload is a payload marshalled beforehand, setContext adds the fields of TaskContext to it.
+type rawPayload map[string]json.RawMessage
+
+func (p rawPayload) setContext(ctx context.Context) {
+	var taskCtx TaskContext
+	taskCtx.setContext(ctx)
+	if len(taskCtx.Trace) > 0 {
+		p["trace"], _ = json.Marshal(taskCtx.Trace)
+	}
+	if taskCtx.OriginRequestID != "" {
+		p["origin_request_id"], _ = json.Marshal(taskCtx.OriginRequestID)
+	}
+}
+
+// enqueue marshals payload with the context of a producer span and enqueues it as a task of taskType.
+func (distributor *RedisTaskDistributor) enqueue(
+	ctx context.Context,
+	taskType string,
+	payload contextPayload,
+	opts ...asynq.Option,
+) (*asynq.Task, *asynq.TaskInfo, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "enqueue "+taskType,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAsynq, semconv.MessagingOperationPublish, semconv.MessagingDestinationName(taskType)),
+	)
+	defer span.End()
+
+	payload.setContext(ctx)
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	task := asynq.NewTask(taskType, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	span.SetAttributes(attribute.String("messaging.asynq.queue", info.Queue), semconv.MessagingMessageID(info.ID))
+	return task, info, nil
+}
+
+// continueTask runs every task in a consumer span continuing the trace its payload carries, with a logger tagged
+// with the task and the request that enqueued it.
+func continueTask(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		taskCtx := readTaskContext(task)
+
+		ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, taskCtx.Trace), "process "+task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(messagingAsynq, semconv.MessagingOperationDeliver, semconv.MessagingDestinationName(task.Type())),
+		)
+		defer span.End()
+
+		ctx = taskLogger(ctx, task, taskCtx)
+		err := next.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
+
+// readTaskContext returns the context embedded in the payload of task. Payloads enqueued before it existed, or
+// that are not JSON, have none.
+func readTaskContext(task *asynq.Task) TaskContext {
+	var taskCtx TaskContext
+	_ = json.Unmarshal(task.Payload(), &taskCtx)
+	return taskCtx
+}
+
+// taskLogger returns ctx whose logger is tagged with task and the request that enqueued it.
+func taskLogger(ctx context.Context, task *asynq.Task, taskCtx TaskContext) context.Context {
+	return logging.With(ctx, func(logCtx zerolog.Context) zerolog.Context {
+		logCtx = logCtx.Str("task_type", task.Type())
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			logCtx = logCtx.Str("task_id", id)
+		}
+		if taskCtx.OriginRequestID != "" {
+			logCtx = logCtx.Str("request_id", taskCtx.OriginRequestID)
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logCtx = logCtx.Str("trace_id", span.TraceID().String())
+		}
+		return logCtx
+	})
+}
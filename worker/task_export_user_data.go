@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+const dataExportTokenLength = 32
+
+type PayloadExportUserData struct {
+	RequestID uuid.UUID `json:"request_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+var TaskExportUserData = Task[PayloadExportUserData]{
+	Type:      "task:export_user_data",
+	Queue:     QueueDefault,
+	MaxRetry:  3,
+	Timeout:   10 * time.Minute,
+	UniqueKey: func(p PayloadExportUserData) string { return p.RequestID.String() },
+	UniqueFor: time.Hour,
+}
+
+func (processor *RedisTaskProcessor) processExportUserData(ctx context.Context, payload PayloadExportUserData) error {
+	if processor.storage == nil {
+		return fmt.Errorf("export storage is not configured: %w", asynq.SkipRetry)
+	}
+
+	request, err := processor.store.GetDataExportRequestByID(ctx, payload.RequestID)
+	if err != nil {
+		return fmt.Errorf("failed to get data export request: %w", err)
+	}
+	if request.Status == constants.DataExportCompleted {
+		return nil
+	}
+
+	if err := processor.store.MarkDataExportProcessing(ctx, request.ID); err != nil {
+		return fmt.Errorf("failed to update data export request: %w", err)
+	}
+
+	user, err := processor.store.GetUserByID(ctx, payload.UserID)
+	if err != nil {
+		return processor.failDataExport(ctx, request, fmt.Errorf("failed to get user: %w", err))
+	}
+
+	archive, err := buildUserDataArchive(ctx, processor.store, user)
+	if err != nil {
+		return processor.failDataExport(ctx, request, err)
+	}
+
+	key := fmt.Sprintf("exports/%s/%s.zip", user.ID, request.ID)
+	if err := processor.storage.Put(ctx, key, bytes.NewReader(archive)); err != nil {
+		return processor.failDataExport(ctx, request, fmt.Errorf("failed to store archive: %w", err))
+	}
+
+	downloadToken, err := utils.GenerateUniqueToken(dataExportTokenLength)
+	if err != nil {
+		return processor.failDataExport(ctx, request, fmt.Errorf("failed to generate download token: %w", err))
+	}
+	expiresAt := time.Now().Add(processor.config.DataExportLinkDuration)
+
+	link := fmt.Sprintf("%s/v1/auth/data_export/download?token=%s", processor.config.AppUrl, downloadToken)
+	content := fmt.Sprintf("Your personal data export is ready. Download it here: <a href=\"%s\">%s</a>.<br>This link expires on %s.",
+		link, link, expiresAt.Format(time.RFC1123))
+
+	// The token is stored before the link is sent so a link that was sent always works. When sending fails the
+	// request is marked failed, which clears the token, and the retry sends a new link.
+	err = processor.store.CompleteDataExportRequest(ctx, sqlc.CompleteDataExportRequestParams{
+		ID:                request.ID,
+		StorageKey:        pgtype.Text{String: key, Valid: true},
+		DownloadTokenHash: pgtype.Text{String: utils.HashToken(downloadToken), Valid: true},
+		ExpiresAt:         pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete data export request: %w", err)
+	}
+
+	if err := processor.sendEmail(ctx, "Your data export is ready", content, user.Email); err != nil {
+		return processor.failDataExport(ctx, request, fmt.Errorf("failed to send download link: %w", err))
+	}
+
+	recordExportAudit(ctx, processor.store, user.ID, constants.AuditDataExportCompleted, map[string]string{"request_id": request.ID.String()})
+	logging.FromContext(ctx).Info().Str("export_request_id", request.ID.String()).Msg("completed data export")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) failDataExport(ctx context.Context, request sqlc.DataExportRequest, cause error) error {
+	err := processor.store.FailDataExportRequest(ctx, sqlc.FailDataExportRequestParams{
+		ID:    request.ID,
+		Error: pgtype.Text{String: cause.Error(), Valid: true},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("export_request_id", request.ID.String()).Msg("failed to mark data export as failed")
+	}
+
+	recordExportAudit(ctx, processor.store, request.UserID, constants.AuditDataExportFailed, map[string]string{"request_id": request.ID.String()})
+	return cause
+}
+
+func recordExportAudit(ctx context.Context, store sqlc.Store, uid uuid.UUID, action string, metadata map[string]string) {
+	data, _ := json.Marshal(metadata)
+	err := store.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		UserID:   uuid.NullUUID{UUID: uid, Valid: true},
+		ActorID:  uuid.NullUUID{UUID: uid, Valid: true},
+		Action:   action,
+		Metadata: data,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("action", action).Msg("failed to record audit log")
+	}
+}
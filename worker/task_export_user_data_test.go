@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/steve-mir/bukka_backend/constants"
+	"github.com/steve-mir/bukka_backend/db/sqlc"
+	"github.com/steve-mir/bukka_backend/internal/storage"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// exportStore keeps a single data export request, the queries the export doesn't run panic.
+type exportStore struct {
+	sqlc.Store
+	user      sqlc.Authentication
+	request   sqlc.DataExportRequest
+	completed []sqlc.CompleteDataExportRequestParams
+}
+
+func (s *exportStore) GetDataExportRequestByID(ctx context.Context, id uuid.UUID) (sqlc.DataExportRequest, error) {
+	return s.request, nil
+}
+
+func (s *exportStore) MarkDataExportProcessing(ctx context.Context, id uuid.UUID) error {
+	s.request.Status = constants.DataExportProcessing
+	return nil
+}
+
+func (s *exportStore) CompleteDataExportRequest(ctx context.Context, arg sqlc.CompleteDataExportRequestParams) error {
+	s.request.Status = constants.DataExportCompleted
+	s.request.DownloadTokenHash = arg.DownloadTokenHash
+	s.completed = append(s.completed, arg)
+	return nil
+}
+
+func (s *exportStore) FailDataExportRequest(ctx context.Context, arg sqlc.FailDataExportRequestParams) error {
+	s.request.Status = constants.DataExportFailed
+	s.request.Error = arg.Error
+	s.request.DownloadTokenHash = pgtype.Text{}
+	return nil
+}
+
+func (s *exportStore) GetUserByID(ctx context.Context, id uuid.UUID) (sqlc.Authentication, error) {
+	return s.user, nil
+}
+
+func (s *exportStore) GetUserProfileByUserID(ctx context.Context, userID uuid.UUID) (sqlc.User, error) {
+	return sqlc.User{}, pgx.ErrNoRows
+}
+
+func (s *exportStore) GetUserRolesByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.GetUserRolesByUserIDRow, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.Session, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetUserLoginsByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.UserLogin, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetEmailVerificationRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.EmailVerificationRequest, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetPasswordResetRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.PasswordResetRequest, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetAccountRecoveryRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.AccountRecoveryRequest, error) {
+	return nil, nil
+}
+
+func (s *exportStore) GetAuditLogsByUserID(ctx context.Context, userID uuid.NullUUID) ([]sqlc.AuditLog, error) {
+	return nil, nil
+}
+
+func (s *exportStore) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) error {
+	return nil
+}
+
+// fakeMailer fails the first failures emails it is asked to send.
+type fakeMailer struct {
+	failures int
+	sent     []string
+}
+
+func (m *fakeMailer) SendEmail(subject, content string, to, cc, bcc, attachFiles []string) error {
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("smtp unavailable")
+	}
+	m.sent = append(m.sent, content)
+	return nil
+}
+
+func TestExportUserDataRetriesFailedEmail(t *testing.T) {
+	ctx := context.Background()
+	exports, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	user := sqlc.Authentication{ID: uuid.New(), Email: "ada@example.com"}
+	store := &exportStore{user: user, request: sqlc.DataExportRequest{ID: uuid.New(), UserID: user.ID, Status: constants.DataExportPending}}
+	mail := &fakeMailer{failures: 1}
+	processor := &RedisTaskProcessor{
+		store: store,
+		config: utils.Config{
+			AppConfig:    utils.AppConfig{AppUrl: "https://bukka.test"},
+			ExportConfig: utils.ExportConfig{DataExportLinkDuration: time.Hour},
+		},
+		storage: exports,
+		mailer:  mail,
+	}
+	payload := PayloadExportUserData{RequestID: store.request.ID, UserID: user.ID}
+
+	require.Error(t, processor.processExportUserData(ctx, payload))
+	require.Equal(t, constants.DataExportFailed, store.request.Status, "the request isn't completed while its link wasn't sent")
+	require.Len(t, store.completed, 1, "the token is stored before the link is sent")
+	require.False(t, store.request.DownloadTokenHash.Valid, "the token of the link that wasn't sent is cleared")
+
+	require.NoError(t, processor.processExportUserData(ctx, payload), "the retry sends the link")
+	require.Equal(t, constants.DataExportCompleted, store.request.Status)
+	require.Len(t, store.completed, 2)
+	require.Len(t, mail.sent, 1)
+	sentToken := mail.sent[0][strings.Index(mail.sent[0], "token=")+len("token=") : strings.Index(mail.sent[0], "\">")]
+	require.Equal(t, utils.HashToken(sentToken), store.request.DownloadTokenHash.String, "the link sent is the one stored, as a hash")
+
+	require.NoError(t, processor.processExportUserData(ctx, payload))
+	require.Len(t, mail.sent, 1, "a completed export isn't sent again")
+}
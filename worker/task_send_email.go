@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steve-mir/bukka_backend/internal/platform/logging"
+	"github.com/steve-mir/bukka_backend/utils"
+)
+
+// codeValidity is how long the codes the emails carry stay valid, so sending one again later is pointless.
+const codeValidity = 15 * time.Minute
+
+// codeKey is the unique key of the emails carrying a code. The code is hashed so live codes don't show in task IDs,
+// which end up in Redis keys and in the asynq inspector.
+func codeKey(userID uuid.UUID, code string) string {
+	return userID.String() + ":" + utils.HashToken(code)
+}
+
+type PayloadVerifyEmail struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Code   string    `json:"code"`
+}
+
+type PayloadPasswordResetEmail struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Code   string    `json:"code"`
+}
+
+type PayloadAccountRecoveryEmail struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Token  string    `json:"token"`
+}
+
+// PayloadSendEmail is the payload of TaskSendEmail.
+type PayloadSendEmail struct {
+	Username string `json:"username"`
+	Content  string
+}
+
+var (
+	TaskVerifyEmail = Task[PayloadVerifyEmail]{
+		Type:      "task:send_verify_email",
+		Queue:     QueueCritical,
+		MaxRetry:  10,
+		Timeout:   30 * time.Second,
+		UniqueKey: func(p PayloadVerifyEmail) string { return codeKey(p.UserID, p.Code) },
+		UniqueFor: codeValidity,
+	}
+
+	TaskPasswordResetEmail = Task[PayloadPasswordResetEmail]{
+		Type:      "task:send_password_reset_email",
+		Queue:     QueueCritical,
+		MaxRetry:  10,
+		Timeout:   30 * time.Second,
+		UniqueKey: func(p PayloadPasswordResetEmail) string { return codeKey(p.UserID, p.Code) },
+		UniqueFor: codeValidity,
+	}
+
+	TaskAccountRecoveryEmail = Task[PayloadAccountRecoveryEmail]{
+		Type:      "task:send_account_recovery_email",
+		Queue:     QueueCritical,
+		MaxRetry:  10,
+		Timeout:   30 * time.Second,
+		UniqueKey: func(p PayloadAccountRecoveryEmail) string { return codeKey(p.UserID, p.Token) },
+		UniqueFor: codeValidity,
+	}
+
+	// TaskSendEmail sent every email before they had a task each.
+	//
+	// Deprecated: only handled so tasks enqueued by earlier versions are still sent.
+	TaskSendEmail = Task[PayloadSendEmail]{
+		Type:     "task:send_email",
+		Queue:    QueueCritical,
+		MaxRetry: 10,
+	}
+)
+
+func (processor *RedisTaskProcessor) processVerifyEmail(ctx context.Context, payload PayloadVerifyEmail) error {
+	content := fmt.Sprintf("Use this to verify you email. code %s", payload.Code)
+	return processor.sendEmail(ctx, "Verify your email", content, payload.Email)
+}
+
+func (processor *RedisTaskProcessor) processPasswordResetEmail(ctx context.Context, payload PayloadPasswordResetEmail) error {
+	content := fmt.Sprintf("Below is code to reset your password: %s.\nPlease do not share this with anyone", payload.Code)
+	return processor.sendEmail(ctx, "Reset your password", content, payload.Email)
+}
+
+func (processor *RedisTaskProcessor) processAccountRecoveryEmail(ctx context.Context, payload PayloadAccountRecoveryEmail) error {
+	content := fmt.Sprintf("Use this token to recover your account: %s.\nIt expires in 15 minutes, please do not share it with anyone", payload.Token)
+	return processor.sendEmail(ctx, "Recover your account", content, payload.Email)
+}
+
+func (processor *RedisTaskProcessor) processSendEmail(ctx context.Context, payload PayloadSendEmail) error {
+	user, err := processor.store.GetUserByIdentifier(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return processor.sendEmail(ctx, processor.config.AppName, payload.Content, user.Email)
+}
+
+// sendEmail sends an email with subject and content to the address to.
+func (processor *RedisTaskProcessor) sendEmail(ctx context.Context, subject, content, to string) error {
+	if err := processor.mailer.SendEmail(subject, content, []string{to}, []string{}, []string{}, []string{}); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info().Str("email", to).Msg("processed task")
+	return nil
+}
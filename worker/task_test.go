@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/steve-mir/bukka_backend/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+	distributor := NewMemoryDistributor()
+	payload := PayloadVerifyEmail{UserID: uuid.New(), Email: "ada@example.com", Code: "123456"}
+
+	require.NoError(t, Enqueue(ctx, distributor, TaskVerifyEmail, payload))
+	require.NoError(t, Enqueue(ctx, distributor, TaskVerifyEmail, payload), "a duplicate is dropped, not an error")
+	require.NoError(t, Enqueue(ctx, distributor, TaskExportUserData, PayloadExportUserData{RequestID: uuid.New()}, asynq.Queue(QueueCritical)))
+
+	tasks := distributor.Tasks()
+	require.Len(t, tasks, 2)
+	require.Equal(t, TaskVerifyEmail.Type, tasks[0].Type)
+	require.Equal(t, QueueCritical, tasks[0].Queue)
+	require.Equal(t, 10, tasks[0].MaxRetry)
+	require.Equal(t, "task:send_verify_email:"+payload.UserID.String()+":"+utils.HashToken("123456"), tasks[0].TaskID)
+	require.NotContains(t, tasks[0].TaskID, "123456", "the code stays out of the task ID")
+	require.Equal(t, QueueCritical, tasks[1].Queue, "options passed to Enqueue win over those of the task")
+
+	sent, err := Enqueued(distributor, TaskVerifyEmail)
+	require.NoError(t, err)
+	require.Equal(t, []PayloadVerifyEmail{payload}, sent)
+}
+
+func TestTaskOptions(t *testing.T) {
+	task := Task[PayloadSendEmail]{Type: "task:test", Queue: QueueDefault, MaxRetry: 3}
+	require.Empty(t, task.TaskID(PayloadSendEmail{}))
+	require.Len(t, task.Options(PayloadSendEmail{}), 2)
+
+	task.Timeout = time.Minute
+	task.UniqueKey = func(p PayloadSendEmail) string { return p.Username }
+	task.UniqueFor = time.Hour
+	opts := task.Options(PayloadSendEmail{Username: "ada"})
+	require.Len(t, opts, 5)
+	require.Equal(t, asynq.TaskID("task:test:ada").String(), opts[3].String())
+	require.Equal(t, asynq.Retention(time.Hour).String(), opts[4].String())
+}
+
+func TestHandle(t *testing.T) {
+	mux := asynq.NewServeMux()
+	var handled []PayloadSendEmail
+	Handle(mux, TaskSendEmail, func(ctx context.Context, payload PayloadSendEmail) error {
+		handled = append(handled, payload)
+		return nil
+	})
+
+	err := mux.ProcessTask(context.Background(), asynq.NewTask(TaskSendEmail.Type, []byte(`{"username":"ada","Content":"hi"}`)))
+	require.NoError(t, err)
+	require.Equal(t, []PayloadSendEmail{{Username: "ada", Content: "hi"}}, handled)
+
+	err = mux.ProcessTask(context.Background(), asynq.NewTask(TaskSendEmail.Type, []byte(`not json`)))
+	require.True(t, errors.Is(err, asynq.SkipRetry), "a payload that can't be decoded is not retried")
+	require.Len(t, handled, 1)
+}